@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	benchSvc "go.keploy.io/server/v2/pkg/service/bench"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("bench", Bench)
+}
+
+// Bench retrieves the command to measure the overhead of keploy
+func Bench(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var benchCmd = &cobra.Command{
+		Use:   "bench",
+		Short: "Measure the overhead added by keploy",
+	}
+
+	var proxyCmd = &cobra.Command{
+		Use:     "proxy",
+		Short:   "Measure the latency and throughput of synthetic outgoing calls through the proxy in record and mock modes",
+		Example: "sudo -E keploy bench proxy --protocol http --requests 5000 --concurrency 20",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var opts benchSvc.Options
+			var err error
+			if opts.Protocol, err = cmd.Flags().GetString("protocol"); err != nil {
+				utils.LogError(logger, err, "failed to get the protocol flag")
+				return nil
+			}
+			if opts.Requests, err = cmd.Flags().GetInt("requests"); err != nil {
+				utils.LogError(logger, err, "failed to get the requests flag")
+				return nil
+			}
+			if opts.Concurrency, err = cmd.Flags().GetInt("concurrency"); err != nil {
+				utils.LogError(logger, err, "failed to get the concurrency flag")
+				return nil
+			}
+			if opts.PayloadSize, err = cmd.Flags().GetInt("payloadSize"); err != nil {
+				utils.LogError(logger, err, "failed to get the payloadSize flag")
+				return nil
+			}
+			asJSON, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the json flag")
+				return nil
+			}
+
+			svc, err := serviceFactory.GetService(ctx, "bench")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			bencher, ok := svc.(benchSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy bench service interface")
+				return nil
+			}
+			report, err := bencher.Proxy(ctx, opts)
+			if err != nil {
+				utils.LogError(logger, err, "failed to benchmark the proxy")
+				return nil
+			}
+
+			if asJSON {
+				out, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					utils.LogError(logger, err, "failed to marshal the benchmark report")
+					return nil
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			printBench(report)
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(proxyCmd); err != nil {
+		utils.LogError(logger, err, "failed to add bench proxy cmd flags")
+		return nil
+	}
+	benchCmd.AddCommand(proxyCmd)
+	return benchCmd
+}
+
+func printBench(report *benchSvc.Report) {
+	fmt.Printf("\n%d %s requests of %d bytes from %d clients\n\n", report.Requests, report.Protocol, report.PayloadSize, report.Concurrency)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "MODE\tERRORS\tREQ/S\tMEAN\tP50\tP99\tADDED P50")
+	for _, m := range report.Modes {
+		added := "-"
+		if m.Mode != benchSvc.ModeDirect {
+			added = m.AddedLatency.Round(time.Microsecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.0f\t%s\t%s\t%s\t%s\n", m.Mode, m.Errors, m.Throughput,
+			m.Mean.Round(time.Microsecond), m.P50.Round(time.Microsecond), m.P99.Round(time.Microsecond), added)
+	}
+	_ = w.Flush()
+}
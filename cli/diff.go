@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	diffSvc "go.keploy.io/server/v2/pkg/service/diff"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("diff", Diff)
+}
+
+// Diff retrieves the command to list the test cases and mocks changed since a git ref
+func Diff(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var diffCmd = &cobra.Command{
+		Use:     "diff",
+		Short:   "List the test cases and mocks added, removed or modified since a git ref",
+		Example: "keploy diff --base main --json",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			base, err := cmd.Flags().GetString("base")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the base flag")
+				return nil
+			}
+			asJSON, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the json flag")
+				return nil
+			}
+			failOnChange, err := cmd.Flags().GetBool("failOnChange")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the failOnChange flag")
+				return nil
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			differ, ok := svc.(diffSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy diff service interface")
+				return nil
+			}
+			report, err := differ.Diff(ctx, base)
+			if err != nil {
+				utils.LogError(logger, err, "failed to diff the test sets", zap.String("base", base))
+				return nil
+			}
+
+			if asJSON {
+				out, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					utils.LogError(logger, err, "failed to marshal the diff report")
+					return nil
+				}
+				fmt.Println(string(out))
+			} else {
+				printDiff(report)
+			}
+			if failOnChange && report.HasChanges() {
+				cmd.SilenceUsage = true
+				return errors.New("the test cases or mocks changed since " + base)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(diffCmd); err != nil {
+		utils.LogError(logger, err, "failed to add diff cmd flags")
+		return nil
+	}
+	return diffCmd
+}
+
+func printDiff(report *diffSvc.Report) {
+	if !report.HasChanges() {
+		fmt.Printf("No test cases or mocks changed since %s\n", report.Base)
+		return
+	}
+	var sb strings.Builder
+	for _, set := range report.TestSets {
+		sb.WriteString(set.ID + "\n")
+		for _, group := range []struct {
+			kind    string
+			changes diffSvc.Changes
+		}{{"test", set.Tests}, {"mock", set.Mocks}} {
+			for _, name := range group.changes.Added {
+				sb.WriteString(fmt.Sprintf("  + %s %s\n", group.kind, name))
+			}
+			for _, name := range group.changes.Removed {
+				sb.WriteString(fmt.Sprintf("  - %s %s\n", group.kind, name))
+			}
+			for _, name := range group.changes.Modified {
+				sb.WriteString(fmt.Sprintf("  ~ %s %s\n", group.kind, name))
+			}
+		}
+	}
+	fmt.Print(sb.String())
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	gapsSvc "go.keploy.io/server/v2/pkg/service/gaps"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("gaps", Gaps)
+}
+
+// Gaps retrieves the command to list the operations of an OpenAPI spec which have no recorded test cases
+func Gaps(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var gapsCmd = &cobra.Command{
+		Use:     "gaps",
+		Short:   "List the operations of an OpenAPI spec which have no recorded test cases",
+		Example: "keploy gaps --spec ./openapi.yaml --scaffold --appURL http://localhost:8080",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			spec, err := cmd.Flags().GetString("spec")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the spec flag")
+				return nil
+			}
+			scaffold, err := cmd.Flags().GetBool("scaffold")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the scaffold flag")
+				return nil
+			}
+			appURL, err := cmd.Flags().GetString("appURL")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the appURL flag")
+				return nil
+			}
+			asJSON, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the json flag")
+				return nil
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			analyzer, ok := svc.(gapsSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy gaps service interface")
+				return nil
+			}
+			report, err := analyzer.Analyze(ctx, spec, scaffold, appURL)
+			if err != nil {
+				utils.LogError(logger, err, "failed to find the operations without test cases", zap.String("spec", spec))
+				return nil
+			}
+
+			if asJSON {
+				out, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					utils.LogError(logger, err, "failed to marshal the gaps report")
+					return nil
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			printGaps(report)
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(gapsCmd); err != nil {
+		utils.LogError(logger, err, "failed to add gaps cmd flags")
+		return nil
+	}
+	return gapsCmd
+}
+
+func printGaps(report *gapsSvc.Report) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d of %d operations of %s have recorded test cases\n", report.Covered, report.Total, report.Spec))
+	for _, op := range report.Missing() {
+		sb.WriteString(fmt.Sprintf("  - %s %s", op.Method, op.Path))
+		if op.OperationID != "" {
+			sb.WriteString(" (" + op.OperationID + ")")
+		}
+		sb.WriteString("\n")
+	}
+	if report.Scaffolded != "" {
+		sb.WriteString(fmt.Sprintf("Wrote the skeleton test cases of the missing operations to %s\n", report.Scaffolded))
+	}
+	fmt.Print(sb.String())
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	generateSvc "go.keploy.io/server/v2/pkg/service/generate"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("generate", Generate)
+}
+
+// Generate retrieves the command to derive negative and boundary test cases from the recorded ones
+func Generate(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var generateCmd = &cobra.Command{
+		Use:     "generate",
+		Short:   "Derive test cases with missing fields, wrong types and boundary values from the recorded ones",
+		Example: "keploy generate -t test-set-0 --testcases test-1 --invalidStatus 422",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			testSet, err := cmd.Flags().GetString("testset")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the testset flag")
+				return nil
+			}
+			testCases, err := cmd.Flags().GetStringSlice("testcases")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the testcases flag")
+				return nil
+			}
+			invalidStatus, err := cmd.Flags().GetInt("invalidStatus")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the invalidStatus flag")
+				return nil
+			}
+			asJSON, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the json flag")
+				return nil
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			generator, ok := svc.(generateSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy generate service interface")
+				return nil
+			}
+			variants, err := generator.Generate(ctx, testSet, testCases, generateSvc.Options{InvalidStatus: invalidStatus})
+			if err != nil {
+				utils.LogError(logger, err, "failed to generate the variants of the test cases", zap.String("testSet", testSet))
+				return nil
+			}
+
+			if asJSON {
+				out, err := json.MarshalIndent(variants, "", "  ")
+				if err != nil {
+					utils.LogError(logger, err, "failed to marshal the variants")
+					return nil
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("Generated %d test cases in %s\n", len(variants), testSet))
+			for _, v := range variants {
+				sb.WriteString(fmt.Sprintf("  %s\t%s %s of %s, expecting %d\n", v.TestCase, v.Kind, v.Field, v.Source, v.Status))
+			}
+			fmt.Print(sb.String())
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(generateCmd); err != nil {
+		utils.LogError(logger, err, "failed to add generate cmd flags")
+		return nil
+	}
+	return generateCmd
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	mergeSvc "go.keploy.io/server/v2/pkg/service/merge"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("merge", Merge)
+}
+
+// Merge retrieves the command to merge the test cases and mocks as a git merge driver
+func Merge(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var mergeCmd = &cobra.Command{
+		Use:   "merge <base> <ours> <theirs>",
+		Short: "Merge the recorded test cases and mocks by their name, as a git merge driver",
+		Example: `keploy merge --install
+keploy merge %O %A %B`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			install, err := cmd.Flags().GetBool("install")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the install flag")
+				return nil
+			}
+			if !install && len(args) != 3 {
+				return errors.New("merge requires the base, ours and theirs files")
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			merger, ok := svc.(mergeSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy merge service interface")
+				return nil
+			}
+
+			cmd.SilenceUsage = true
+			if install {
+				return merger.Install(ctx)
+			}
+			conflicts, err := merger.Merge(ctx, args[0], args[1], args[2])
+			if err != nil {
+				utils.LogError(logger, err, "failed to merge the yaml documents", zap.String("file", args[1]))
+				return err
+			}
+			if conflicts > 0 {
+				return fmt.Errorf("%d test cases or mocks were changed on both sides, resolve the conflicts in %s", conflicts, args[1])
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(mergeCmd); err != nil {
+		utils.LogError(logger, err, "failed to add merge cmd flags")
+		return nil
+	}
+	return mergeCmd
+}
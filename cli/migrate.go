@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	migrateSvc "go.keploy.io/server/v2/pkg/service/migrate"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("migrate", Migrate)
+}
+
+// Migrate retrieves the command to upgrade the recordings of keploy v1 to the v2 format
+func Migrate(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var migrateCmd = &cobra.Command{
+		Use:     "migrate [path]",
+		Short:   "Upgrade the test cases, mocks and reports recorded by keploy v1 to the v2 format",
+		Example: "keploy migrate ./keploy --appURL http://localhost:8080 --dryRun",
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "./keploy"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			path, err := filepath.Abs(path)
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the absolute path from relative path")
+				return nil
+			}
+			var opts migrateSvc.Options
+			opts.AppURL, err = cmd.Flags().GetString("appURL")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the appURL flag")
+				return nil
+			}
+			opts.DryRun, err = cmd.Flags().GetBool("dryRun")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the dryRun flag")
+				return nil
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			migrator, ok := svc.(migrateSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy migrate service interface")
+				return nil
+			}
+			summary, err := migrator.Migrate(ctx, path, opts)
+			if err != nil {
+				utils.LogError(logger, err, "failed to migrate the keploy directory", zap.String("path", path))
+				return nil
+			}
+			for _, skipped := range summary.Skipped {
+				logger.Warn("couldn't migrate the document, it's kept as it is", zap.String("reason", skipped))
+			}
+			msg := "migrated the keploy directory"
+			if opts.DryRun {
+				msg = "the keploy directory would be migrated"
+			}
+			logger.Info(msg, zap.String("path", path), zap.Strings("newTestSets", summary.TestSets), zap.Int("tests", summary.Tests), zap.Int("mocks", summary.Mocks), zap.Int("reports", summary.Reports), zap.Int("skipped", len(summary.Skipped)))
+			if len(summary.Skipped) > 0 {
+				fmt.Println("run `keploy validate` to check the documents which couldn't be migrated")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(migrateCmd); err != nil {
+		utils.LogError(logger, err, "failed to add migrate cmd flags")
+		return nil
+	}
+	return migrateCmd
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -155,8 +156,49 @@ func NewCmdConfigurator(logger *zap.Logger, config *config.Config) *CmdConfigura
 func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 	var err error
 	switch cmd.Name() {
-	case "update":
+	case "update", "telemetry", "preview", "validate":
 		return nil
+	case "diff":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("base", "main", "Git ref to compare the test sets against")
+		cmd.Flags().Bool("json", false, "Print the changes as json")
+		cmd.Flags().Bool("failOnChange", false, "Exit with an error if any test case or mock changed, e.g. for a pull request check")
+	case "gaps":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("spec", "", "Path to the OpenAPI spec of the application, in yaml or json")
+		cmd.Flags().Bool("scaffold", false, "Write a skeleton test case of each operation without one to a new test set")
+		cmd.Flags().String("appURL", "http://localhost:8080", "Scheme and host of the application, prefixed to the paths of the skeleton test cases")
+		cmd.Flags().Bool("json", false, "Print the operations as json")
+		err := cmd.MarkFlagRequired("spec")
+		if err != nil {
+			errMsg := "failed to mark spec as required flag"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	case "generate":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringP("testset", "t", "", "Test set of the recorded test cases e.g. --testset test-set-0")
+		cmd.Flags().StringSlice("testcases", nil, "Test cases to derive the variants from, all the json ones of the test set by default e.g. --testcases \"test-1,test-2\"")
+		cmd.Flags().Int("invalidStatus", 400, "Status expected of the requests with a missing field or a field of the wrong type")
+		cmd.Flags().Bool("json", false, "Print the generated test cases as json")
+		err := cmd.MarkFlagRequired("testset")
+		if err != nil {
+			errMsg := "failed to mark testset as required flag"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	case "migrate":
+		cmd.Flags().String("appURL", "http://localhost:8080", "Scheme and host of the application, prefixed to the v1 test cases which only recorded the path")
+		cmd.Flags().Bool("dryRun", false, "Report what would be migrated without writing anything")
+	case "proxy":
+		cmd.Flags().String("protocol", "http", "Protocol of the synthetic traffic: http or mongo")
+		cmd.Flags().Int("requests", 1000, "Number of requests sent in each mode")
+		cmd.Flags().Int("concurrency", 10, "Number of concurrent clients")
+		cmd.Flags().Int("payloadSize", 1024, "Size in bytes of the request and response bodies")
+		cmd.Flags().Bool("json", false, "Print the results as json")
+	case "merge":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().Bool("install", false, "Register keploy as the git merge driver of the test cases and mocks")
 	case "config":
 		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated config is stored")
 		cmd.Flags().Bool("generate", false, "Generate a new keploy configuration file")
@@ -179,10 +221,18 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 		cmd.Flags().Uint32("proxyPort", c.cfg.ProxyPort, "Port used by the Keploy proxy server to intercept the outgoing dependency calls")
 		cmd.Flags().Uint32("dnsPort", c.cfg.DNSPort, "Port used by the Keploy DNS server to intercept the DNS queries")
 		cmd.Flags().StringP("command", "c", c.cfg.Command, "Command to start the user application")
+		cmd.Flags().String("image", c.cfg.Image, "Image of the user application run by keploy instead of a command, pinned by digest e.g. myapp@sha256:<digest>")
 		cmd.Flags().DurationP("buildDelay", "b", c.cfg.BuildDelay, "User provided time to wait docker container build")
 		cmd.Flags().String("containerName", c.cfg.ContainerName, "Name of the application's docker container")
 		cmd.Flags().StringP("networkName", "n", c.cfg.NetworkName, "Name of the application's docker network")
 		cmd.Flags().UintSlice("passThroughPorts", config.GetByPassPorts(c.cfg), "Ports to bypass the proxy server and ignore the traffic")
+		cmd.Flags().String("ipFamily", c.cfg.IPFamily, "Address family preferred for the outgoing calls of the application: dual, ipv4 or ipv6")
+		cmd.Flags().String("cgroupPath", c.cfg.CgroupPath, "Cgroup of the application (e.g. system.slice/app.service) to restrict the capture to, absolute or relative to the cgroup2 mount")
+		cmd.Flags().StringSlice("includeProcesses", c.cfg.Processes.Include, "Name patterns of the processes whose outgoing calls are captured e.g. --includeProcesses \"java,node\"")
+		cmd.Flags().StringSlice("excludeProcesses", c.cfg.Processes.Exclude, "Name patterns of the processes whose outgoing calls are passed through e.g. --excludeProcesses \"npm,gradle*\"")
+		cmd.Flags().Bool("enableReverseProxy", c.cfg.ReverseProxy.Enabled, "Capture the traffic by reverse-proxying to the application instead of eBPF hooks e.g. on AWS Fargate")
+		cmd.Flags().Uint32("ingressPort", c.cfg.ReverseProxy.IngressPort, "Port on which keploy accepts the incoming traffic of the application in the reverse proxy mode")
+		cmd.Flags().Uint32("appPort", c.cfg.ReverseProxy.AppPort, "Port of the application to which the incoming traffic is forwarded in the reverse proxy mode")
 		err = cmd.Flags().MarkHidden("port")
 		if err != nil {
 			errMsg := "failed to mark port as hidden flag"
@@ -199,12 +249,55 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 			cmd.Flags().Bool("ignoreOrdering", c.cfg.Test.IgnoreOrdering, "Ignore ordering of array in response")
 			cmd.Flags().Bool("coverage", c.cfg.Test.Coverage, "Enable coverage reporting for the testcases. for golang please set language flag to golang, ref https://keploy.io/docs/server/sdk-installation/go/")
 			cmd.Flags().Bool("removeUnusedMocks", false, "Clear the unused mocks for the passed test-sets")
+			cmd.Flags().String("k8sTarget", c.cfg.Test.K8s.Target, "Kubernetes service/pod to port-forward and replay the testcases against with mocks disabled e.g. svc/my-app")
+			cmd.Flags().String("k8sNamespace", c.cfg.Test.K8s.Namespace, "Namespace of the kubernetes target")
+			cmd.Flags().Uint32("k8sPort", c.cfg.Test.K8s.Port, "Port of the kubernetes target to port-forward to")
+			cmd.Flags().String("kubeconfig", c.cfg.Test.K8s.Kubeconfig, "Path to the kubeconfig file used for port-forwarding")
+			cmd.Flags().StringSlice("testSetOrder", c.cfg.Test.TestSetOrder, "Test sets to run first in the given order, the others follow e.g. --testSetOrder \"smoke,test-set-2\"")
+			cmd.Flags().String("shuffle", c.cfg.Test.Shuffle, "Shuffle the order of the test cases in a test set with a random or the given seed e.g. --shuffle or --shuffle=42")
+			cmd.Flags().Lookup("shuffle").NoOptDefVal = "random"
+			cmd.Flags().Bool("verifyMockOrder", c.cfg.Test.VerifyMockOrder, "Fail the testcases whose outgoing calls are made in a different order than recorded")
+			cmd.Flags().Bool("sqlFingerprint", c.cfg.Test.SQLFingerprint, "Match the MySQL/Postgres queries of the mocks ignoring their literal values and whitespace")
+			cmd.Flags().Bool("reuseApp", c.cfg.Test.ReuseApp, "Launch the application once and reuse it across the test sets, for stateless applications")
+			cmd.Flags().Bool("isolateNetwork", c.cfg.Test.IsolateNetwork, "Run the native application in a fresh network namespace for each test set. The application must listen on all interfaces")
+			cmd.Flags().Bool("watchConfig", c.cfg.Test.WatchConfig, "Reload the noise from keploy.yml whenever it changes, applying it to the test cases run after the change")
+			cmd.Flags().Bool("watch", c.cfg.Test.Watch, "Run the test sets again whenever the source or the binary of the application changes")
+			cmd.Flags().StringSlice("watchPaths", c.cfg.Test.WatchPaths, "Files and directories watched for changes with --watch e.g. --watchPaths \"./src,./bin/app\"")
+			cmd.Flags().String("buildCommand", c.cfg.Test.BuildCommand, "Command run to build the application before running the test sets again with --watch e.g. --buildCommand \"go build -o app .\"")
+			cmd.Flags().String("publishCommand", c.cfg.Test.PublishCommand, "Command publishing the message of a message test case, which it reads from stdin e.g. --publishCommand 'kcat -P -b localhost:9092 -t \"$KEPLOY_MESSAGE_TOPIC\"'")
+			cmd.Flags().StringSlice("grpcTrailers", c.cfg.Test.GrpcTrailers, "Trailers of the grpc responses compared besides grpc-status and grpc-message e.g. --grpcTrailers \"x-request-cost\"")
+			cmd.Flags().Bool("idempotency", c.cfg.Test.Idempotency, "Send the GET, PUT and DELETE test cases twice back to back, failing them when the second response differs beyond the noise")
+			cmd.Flags().StringSlice("mockTags", c.cfg.Test.MockTags, "Active tags, the tagged mocks are loaded only when one of their tags is active e.g. --mockTags \"feature:payments-v2\"")
+			cmd.Flags().String("genericReplay", c.cfg.Test.GenericReplay, "How the requests matching no generic mock are served: match passes them through, verbatim serves the generic mocks of the destination in their recorded order")
+			cmd.Flags().String("rerunFailed", c.cfg.Test.RerunFailed, "Run only the test cases which failed in the given test run e.g. --rerunFailed test-run-3")
+			cmd.Flags().StringToString("projects", c.cfg.Test.Projects, "Other projects served by the graphql server of the coverage mode by their id, each with its own test cases, mocks and reports, selected with the project parameter of the requests e.g. --projects \"payments=/srv/payments,orders=/srv/orders\"")
+			cmd.Flags().StringSlice("requiredEnv", c.cfg.Test.RequiredEnv, "Environment variables the application needs, the test sets fail before they're run when one isn't set e.g. --requiredEnv \"DATABASE_URL,API_KEY\"")
+			cmd.Flags().Int("parallel", c.cfg.Test.Parallel, "Number of the test sets run at once, each against its own instance of the native application isolated with --isolateNetwork")
+			cmd.Flags().String("schemaDrift", c.cfg.Test.SchemaDrift, "Report the fields of the responses whose type drifted from the recorded ones, even if noisy: off, warn or fail")
+			cmd.Flags().Duration("runTimeout", c.cfg.Test.RunTimeout, "Abort the whole test run after this duration, writing the reports of the test sets run until then as timed out e.g. --runTimeout 15m")
+			cmd.Flags().Int("slowestTests", c.cfg.Test.SlowestTests, "Number of the slowest test cases listed in the summary and the run report")
+			cmd.Flags().StringSlice("compareHeaders", c.cfg.Test.CompareHeaders, "Compare only these headers of the responses, the others are informational e.g. --compareHeaders \"Content-Type,Cache-Control\"")
+			cmd.Flags().Bool("debug-matching", c.cfg.Test.DebugMatching, "Write the closest mocks of every outgoing call which matches none, with the reasons they didn't match, to the matching.yaml of the test run")
+			cmd.Flags().String("changedSince", c.cfg.Test.Impact.Base, "Run only the test sets calling the endpoints affected by the files changed since the git ref, mapped with the impact routes in the config e.g. --changedSince origin/main")
 		} else {
 			cmd.Flags().Uint64("recordTimer", 0, "User provided time to record its application")
+			cmd.Flags().Bool("stableTestIDs", c.cfg.Record.StableTestIDs, "Name the test cases after a hash of their request so that re-recording doesn't renumber the unchanged ones")
+			cmd.Flags().Uint32("messagePort", c.cfg.Record.MessagePort, "Port on the loopback interface on which the consumers of the application report the messages they processed, recording each as a test case")
+			cmd.Flags().Bool("captureMessages", c.cfg.Record.CaptureMessages, "Record the kafka messages the application fetches through the proxy as test cases, delivered again by the mocked broker during the tests")
+			cmd.Flags().String("job", c.cfg.Record.Job, "Name of the scheduled job run by the command, recording its outgoing calls as a single test case once it exits e.g. --job nightly-report")
+			cmd.Flags().Duration("maxDuration", c.cfg.Record.MaxDuration, "Stop recording after this duration, writing everything captured until then e.g. --maxDuration 30m")
+			cmd.Flags().Int("maxTestCases", c.cfg.Record.MaxTestCases, "Stop recording once this many test cases are recorded")
+			cmd.Flags().Int64("maxMockBytes", c.cfg.Record.MaxMockBytes, "Stop recording once the recorded mocks take this many bytes")
+			cmd.Flags().Int("maxBodySize", c.cfg.Record.MaxBodySize, "Store the response bodies above this many bytes truncated, comparing them by the hash of their full content")
+			cmd.Flags().StringSlice("mockTags", c.cfg.Record.MockTags, "Tags added to the recorded mocks e.g. --mockTags \"feature:payments-v2\"")
+			cmd.Flags().Bool("genericFallback", c.cfg.Record.GenericFallback, "Record the connections of the protocols no integration supports as generic byte-stream mocks, else pass them through unrecorded")
 		}
 	case "keploy":
 		cmd.PersistentFlags().Bool("debug", c.cfg.Debug, "Run in debug mode")
 		cmd.PersistentFlags().Bool("disableTele", c.cfg.DisableTele, "Run in telemetry mode")
+		cmd.PersistentFlags().Bool("disable-telemetry", false, "Disable the telemetry completely, nothing is collected or sent")
+		cmd.PersistentFlags().String("profile", c.cfg.Profile, "Serve net/http/pprof on the address and write the cpu, heap and goroutine profiles to "+utils.ProfileDir+" on exit")
+		cmd.PersistentFlags().Lookup("profile").NoOptDefVal = "localhost:6060"
 		err = cmd.PersistentFlags().MarkHidden("disableTele")
 		if err != nil {
 			errMsg := "failed to mark telemetry as hidden flag"
@@ -261,6 +354,14 @@ func (c CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 		utils.LogError(c.logger, err, errMsg)
 		return errors.New(errMsg)
 	}
+	if cmd.Flags().Changed("disable-telemetry") {
+		c.cfg.DisableTele, err = cmd.Flags().GetBool("disable-telemetry")
+		if err != nil {
+			errMsg := "failed to read the disable-telemetry flag"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
 	if c.cfg.Debug {
 		logger, err := log.ChangeLogLevel(zap.DebugLevel)
 		*c.logger = *logger
@@ -272,7 +373,24 @@ func (c CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 	}
 	c.logger.Debug("config has been initialised", zap.Any("for cmd", cmd.Name()), zap.Any("config", c.cfg))
 
+	if c.cfg.Profile != "" {
+		err := utils.StartProfiling(c.logger, c.cfg.Profile)
+		if err != nil {
+			errMsg := "failed to start profiling"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+
 	switch cmd.Name() {
+	case "diff", "merge", "gaps", "generate":
+		absPath, err := filepath.Abs(c.cfg.Path)
+		if err != nil {
+			errMsg := "failed to get the absolute path from relative path"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+		c.cfg.Path = absPath + "/keploy"
 	case "record", "test":
 		bypassPorts, err := cmd.Flags().GetUintSlice("passThroughPorts")
 		if err != nil {
@@ -282,7 +400,130 @@ func (c CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 		}
 		config.SetByPassPorts(c.cfg, bypassPorts)
 
-		if c.cfg.Command == "" {
+		err = c.setReverseProxy(cmd)
+		if err != nil {
+			return err
+		}
+
+		err = c.setProcessFilter(cmd)
+		if err != nil {
+			return err
+		}
+
+		switch c.cfg.IPFamily {
+		case "", config.IPFamilyDual, config.IPFamilyV4, config.IPFamilyV6:
+		default:
+			errMsg := fmt.Sprintf("invalid ipFamily %q, supported values are dual, ipv4 and ipv6", c.cfg.IPFamily)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+
+		if cmd.Name() == "test" {
+			err = c.setK8sTarget(cmd)
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("debug-matching") {
+				c.cfg.Test.DebugMatching, err = cmd.Flags().GetBool("debug-matching")
+				if err != nil {
+					errMsg := "failed to read the debug-matching flag"
+					utils.LogError(c.logger, err, errMsg)
+					return errors.New(errMsg)
+				}
+			}
+
+			if cmd.Flags().Changed("changedSince") {
+				c.cfg.Test.Impact.Base, err = cmd.Flags().GetString("changedSince")
+				if err != nil {
+					errMsg := "failed to read the git ref to find the changed files since"
+					utils.LogError(c.logger, err, errMsg)
+					return errors.New(errMsg)
+				}
+			}
+
+			switch c.cfg.Test.SchemaDrift {
+			case "", "off", "warn", "fail":
+			default:
+				errMsg := fmt.Sprintf("invalid schemaDrift %q, supported values are off, warn and fail", c.cfg.Test.SchemaDrift)
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+
+			switch c.cfg.Test.GenericReplay {
+			case "", "match", "verbatim":
+			default:
+				errMsg := fmt.Sprintf("invalid genericReplay %q, supported values are match and verbatim", c.cfg.Test.GenericReplay)
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+
+			// the env sets tell apart the reports of the runs of a test set, so they need distinct names
+			envSets := map[string]bool{}
+			for _, set := range c.cfg.Test.EnvMatrix {
+				if set.Name == "" || envSets[set.Name] {
+					errMsg := fmt.Sprintf("the env sets of the envMatrix need distinct names, found %q", set.Name)
+					utils.LogError(c.logger, nil, errMsg)
+					return errors.New(errMsg)
+				}
+				envSets[set.Name] = true
+			}
+
+			// the proxy tells apart the calls of the app instances of the parallel test sets by their network namespace
+			switch {
+			case c.cfg.Test.Parallel < 0:
+				errMsg := fmt.Sprintf("invalid parallel %d, it should be at least 1", c.cfg.Test.Parallel)
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			case c.cfg.Test.Parallel > 1 && len(c.cfg.Test.EnvMatrix) > 0:
+				errMsg := "the test sets can't be run in parallel under an envMatrix"
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			case c.cfg.Test.Parallel > 1 && c.cfg.Test.K8s.Target != "":
+				errMsg := "the test sets can't be run in parallel against a kubernetes target, as there is a single instance of the app"
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			case c.cfg.Test.Parallel > 1 && !c.cfg.Test.IsolateNetwork:
+				errMsg := "running the test sets in parallel needs isolateNetwork, so that each instance of the app runs in its own network namespace"
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+
+			if c.cfg.Test.Watch && c.cfg.Test.K8s.Target != "" {
+				errMsg := "the watch mode can't be used with a kubernetes target as keploy doesn't run the application"
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+
+			if c.cfg.Test.Shuffle != "" && c.cfg.Test.Shuffle != "random" {
+				if _, err := strconv.ParseInt(c.cfg.Test.Shuffle, 10, 64); err != nil {
+					errMsg := fmt.Sprintf("invalid shuffle seed %q, it should be an integer", c.cfg.Test.Shuffle)
+					utils.LogError(c.logger, err, errMsg)
+					return errors.New(errMsg)
+				}
+			}
+		}
+
+		if c.cfg.Command == "" && c.cfg.Image != "" {
+			if c.cfg.ContainerName == "" {
+				c.cfg.ContainerName = "keploy-app"
+			}
+			if c.cfg.NetworkName == "" {
+				c.cfg.NetworkName = c.cfg.KeployNetwork
+			}
+			command, pinned, err := utils.ImageRunCmd(c.cfg.Image, c.cfg.ContainerName, c.cfg.NetworkName)
+			if err != nil {
+				utils.LogError(c.logger, err, "failed to run the application from its image")
+				return err
+			}
+			if !pinned {
+				c.logger.Warn("the image of the application isn't pinned by digest, it may change between the runs", zap.String("image", c.cfg.Image))
+			}
+			c.cfg.Command = command
+			c.logger.Debug("running the application from its image", zap.String("command", command))
+		}
+
+		if c.cfg.Command == "" && c.cfg.Test.K8s.Target == "" {
 			utils.LogError(c.logger, nil, "missing required -c flag or appCmd in config file")
 			if c.cfg.InDocker {
 				c.logger.Info(`Example usage: keploy test -c "docker run -p 8080:8080 --network myNetworkName myApplicationImageName" --delay 6`)
@@ -294,6 +535,7 @@ func (c CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 
 		if c.cfg.InDocker {
 			c.logger.Info("detected that Keploy is running in a docker container")
+			c.cfg.Command = utils.DockerCompatibleCmd(c.cfg.Command)
 			if len(c.cfg.Path) > 0 {
 				curDir, err := os.Getwd()
 				if err != nil {
@@ -355,6 +597,21 @@ func (c CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 				return errors.New(errMsg)
 			}
 			config.SetSelectedTests(c.cfg, testSets)
+			// the projects keep their test cases, mocks and reports in their own keploy directory
+			for id, path := range c.cfg.Test.Projects {
+				if id == "" {
+					errMsg := "the projects need an id, found an empty one"
+					utils.LogError(c.logger, nil, errMsg)
+					return errors.New(errMsg)
+				}
+				absPath, err := filepath.Abs(path)
+				if err != nil {
+					errMsg := fmt.Sprintf("failed to get the absolute path of the project %s", id)
+					utils.LogError(c.logger, err, errMsg)
+					return errors.New(errMsg)
+				}
+				c.cfg.Test.Projects[id] = absPath + "/keploy"
+			}
 			if c.cfg.Test.Delay <= 5 {
 				c.logger.Warn(fmt.Sprintf("Delay is set to %d seconds, incase your app takes more time to start use --delay to set custom delay", c.cfg.Test.Delay))
 				if c.cfg.InDocker {
@@ -367,3 +624,149 @@ func (c CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 	}
 	return nil
 }
+
+// ReloadConfig reads the config file again, keeping the ports passed with the flags
+func (c CmdConfigurator) ReloadConfig(_ context.Context, cmd *cobra.Command) (*config.Config, error) {
+	if err := viper.ReadInConfig(); err != nil {
+		var configFileNotFoundError viper.ConfigFileNotFoundError
+		if !errors.As(err, &configFileNotFoundError) {
+			errMsg := "failed to read config file"
+			utils.LogError(c.logger, err, errMsg)
+			return nil, errors.New(errMsg)
+		}
+	}
+	// the defaults are left out so that the sections removed from the file are cleared
+	cfg := &config.Config{}
+	if err := viper.Unmarshal(cfg); err != nil {
+		errMsg := "failed to unmarshal the config"
+		utils.LogError(c.logger, err, errMsg)
+		return nil, errors.New(errMsg)
+	}
+	bypassPorts, err := cmd.Flags().GetUintSlice("passThroughPorts")
+	if err != nil {
+		errMsg := "failed to read the ports of outgoing calls to be ignored"
+		utils.LogError(c.logger, err, errMsg)
+		return nil, errors.New(errMsg)
+	}
+	config.SetByPassPorts(cfg, bypassPorts)
+	return cfg, nil
+}
+
+// setK8sTarget overrides the kubernetes target in the config with the flags passed explicitly
+func (c *CmdConfigurator) setK8sTarget(cmd *cobra.Command) error {
+	var err error
+	if cmd.Flags().Changed("k8sTarget") {
+		c.cfg.Test.K8s.Target, err = cmd.Flags().GetString("k8sTarget")
+		if err != nil {
+			errMsg := "failed to read the kubernetes target"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if cmd.Flags().Changed("k8sNamespace") {
+		c.cfg.Test.K8s.Namespace, err = cmd.Flags().GetString("k8sNamespace")
+		if err != nil {
+			errMsg := "failed to read the namespace of the kubernetes target"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if cmd.Flags().Changed("k8sPort") {
+		c.cfg.Test.K8s.Port, err = cmd.Flags().GetUint32("k8sPort")
+		if err != nil {
+			errMsg := "failed to read the port of the kubernetes target"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if cmd.Flags().Changed("kubeconfig") {
+		c.cfg.Test.K8s.Kubeconfig, err = cmd.Flags().GetString("kubeconfig")
+		if err != nil {
+			errMsg := "failed to read the kubeconfig path"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if c.cfg.Test.K8s.Target != "" && c.cfg.Test.K8s.Port == 0 {
+		errMsg := "missing required --k8sPort flag or k8s.port in config file for the kubernetes target"
+		utils.LogError(c.logger, nil, errMsg)
+		return errors.New(errMsg)
+	}
+	return nil
+}
+
+// setProcessFilter overrides the process name patterns in the config with the flags passed explicitly
+func (c *CmdConfigurator) setProcessFilter(cmd *cobra.Command) error {
+	var err error
+	if cmd.Flags().Changed("includeProcesses") {
+		c.cfg.Processes.Include, err = cmd.Flags().GetStringSlice("includeProcesses")
+		if err != nil {
+			errMsg := "failed to read the processes to be included"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if cmd.Flags().Changed("excludeProcesses") {
+		c.cfg.Processes.Exclude, err = cmd.Flags().GetStringSlice("excludeProcesses")
+		if err != nil {
+			errMsg := "failed to read the processes to be excluded"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	for _, pattern := range append(c.cfg.Processes.Include, c.cfg.Processes.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errMsg := fmt.Sprintf("invalid process name pattern %q", pattern)
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	return nil
+}
+
+// setReverseProxy overrides the reverse proxy instrumentation config with the flags passed explicitly
+func (c *CmdConfigurator) setReverseProxy(cmd *cobra.Command) error {
+	var err error
+	if cmd.Flags().Changed("enableReverseProxy") {
+		c.cfg.ReverseProxy.Enabled, err = cmd.Flags().GetBool("enableReverseProxy")
+		if err != nil {
+			errMsg := "failed to read the reverse proxy flag"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if cmd.Flags().Changed("ingressPort") {
+		c.cfg.ReverseProxy.IngressPort, err = cmd.Flags().GetUint32("ingressPort")
+		if err != nil {
+			errMsg := "failed to read the ingress port"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if cmd.Flags().Changed("appPort") {
+		c.cfg.ReverseProxy.AppPort, err = cmd.Flags().GetUint32("appPort")
+		if err != nil {
+			errMsg := "failed to read the app port"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if c.cfg.ReverseProxy.Enabled && cmd.Name() == "record" && (c.cfg.ReverseProxy.IngressPort == 0 || c.cfg.ReverseProxy.AppPort == 0) {
+		errMsg := "missing required --ingressPort and --appPort flags for the reverse proxy mode"
+		utils.LogError(c.logger, nil, errMsg)
+		c.logger.Info(`Example usage: keploy record -c "/path/to/user/app" --enableReverseProxy --ingressPort 8081 --appPort 8080`)
+		return errors.New(errMsg)
+	}
+	for _, appPort := range c.cfg.ReverseProxy.AppPorts {
+		if protocol := config.GetAppPortProtocol(c.cfg, appPort.Port); protocol != config.ProtocolHTTP && protocol != config.ProtocolGRPC {
+			errMsg := fmt.Sprintf("the protocol %s of the app port %d isn't supported, only %s and %s are", protocol, appPort.Port, config.ProtocolHTTP, config.ProtocolGRPC)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if len(c.cfg.ReverseProxy.AppPorts) != 0 && cmd.Name() == "record" && !c.cfg.ReverseProxy.Enabled {
+		// the eBPF hooks capture the incoming http calls of every port
+		c.logger.Warn("the reverseProxy.appPorts are only captured in the reverse proxy mode, they are ignored without --enableReverseProxy")
+	}
+	return nil
+}
@@ -5,18 +5,24 @@ import (
 	"errors"
 
 	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/client"
 	"go.keploy.io/server/v2/pkg/core"
-	"go.keploy.io/server/v2/pkg/core/hooks"
-	"go.keploy.io/server/v2/pkg/core/proxy"
 	"go.keploy.io/server/v2/pkg/platform/telemetry"
 	"go.keploy.io/server/v2/pkg/platform/yaml/configdb"
 	mockdb "go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
 	reportdb "go.keploy.io/server/v2/pkg/platform/yaml/reportdb"
 	testdb "go.keploy.io/server/v2/pkg/platform/yaml/testdb"
 
+	"go.keploy.io/server/v2/pkg/service/bench"
+	"go.keploy.io/server/v2/pkg/service/diff"
+	"go.keploy.io/server/v2/pkg/service/gaps"
+	"go.keploy.io/server/v2/pkg/service/generate"
+	"go.keploy.io/server/v2/pkg/service/merge"
+	"go.keploy.io/server/v2/pkg/service/migrate"
 	"go.keploy.io/server/v2/pkg/service/record"
 	"go.keploy.io/server/v2/pkg/service/replay"
 	"go.keploy.io/server/v2/pkg/service/tools"
+	"go.keploy.io/server/v2/pkg/service/validate"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 )
@@ -25,6 +31,9 @@ type ServiceProvider struct {
 	logger   *zap.Logger
 	configDb *configdb.ConfigDb
 	cfg      *config.Config
+	// replayer is the service of the test command, whose hooks and proxy the other projects share
+	replayer *CommonInternalService
+	tel      Telemetry
 }
 
 type CommonInternalService struct {
@@ -42,6 +51,12 @@ func NewServiceProvider(logger *zap.Logger, configDb *configdb.ConfigDb, cfg *co
 	}
 }
 
+// Telemetry is satisfied by the telemetry service and by its no-op variant used when telemetry is disabled
+type Telemetry interface {
+	client.Telemetry
+	Ping()
+}
+
 func (n *ServiceProvider) GetTelemetryService(ctx context.Context, config config.Config) (*telemetry.Telemetry, error) {
 	installationID, err := n.configDb.GetInstallationID(ctx)
 	if err != nil {
@@ -52,16 +67,15 @@ func (n *ServiceProvider) GetTelemetryService(ctx context.Context, config config
 		Version:        utils.Version,
 		GlobalMap:      map[string]interface{}{},
 		InstallationID: installationID,
+		URL:            config.TelemetryURL,
 	},
 	), nil
 }
 
 func (n *ServiceProvider) GetCommonServices(config config.Config) *CommonInternalService {
-	h := hooks.NewHooks(n.logger, config)
-	p := proxy.New(n.logger, h, config)
-	instrumentation := core.New(n.logger, h, p)
+	instrumentation := client.NewInstrumentation(n.logger, config)
 	testDB := testdb.New(n.logger, config.Path)
-	mockDB := mockdb.New(n.logger, config.Path, "")
+	mockDB := mockdb.New(n.logger, config.Path, "", config.Test.GlobalMocks, config.Test.MockTags)
 	reportDB := reportdb.New(n.logger, config.Path+"/reports")
 	return &CommonInternalService{
 		Instrumentation: instrumentation,
@@ -72,14 +86,37 @@ func (n *ServiceProvider) GetCommonServices(config config.Config) *CommonInterna
 }
 
 func (n *ServiceProvider) GetService(ctx context.Context, cmd string) (interface{}, error) {
-	tel, err := n.GetTelemetryService(ctx, *n.cfg)
-	if err != nil {
-		return nil, err
+	if cmd == "preview" {
+		// previewing the telemetry doesn't send anything, so it's available even when telemetry is disabled
+		return n.GetTelemetryService(ctx, *n.cfg)
+	}
+
+	var tel Telemetry = telemetry.Noop{}
+	if !n.cfg.DisableTele {
+		t, err := n.GetTelemetryService(ctx, *n.cfg)
+		if err != nil {
+			return nil, err
+		}
+		tel = t
 	}
 	tel.Ping()
 	switch cmd {
 	case "config", "update":
 		return tools.NewTools(n.logger, tel), nil
+	case "diff":
+		return diff.New(n.logger, n.cfg.Path), nil
+	case "merge":
+		return merge.New(n.logger, n.cfg.Path), nil
+	case "validate":
+		return validate.New(n.logger), nil
+	case "gaps":
+		return gaps.New(n.logger, testdb.New(n.logger, n.cfg.Path)), nil
+	case "generate":
+		return generate.New(n.logger, testdb.New(n.logger, n.cfg.Path)), nil
+	case "migrate":
+		return migrate.New(n.logger), nil
+	case "bench":
+		return bench.New(n.logger, *n.cfg), nil
 	// TODO: add case for mock
 	case "record", "test", "mock":
 		commonServices := n.GetCommonServices(*n.cfg)
@@ -87,6 +124,7 @@ func (n *ServiceProvider) GetService(ctx context.Context, cmd string) (interface
 			return record.New(n.logger, commonServices.YamlTestDB, commonServices.YamlMockDb, tel, commonServices.Instrumentation, *n.cfg), nil
 		}
 		if cmd == "test" {
+			n.replayer, n.tel = commonServices, tel
 			return replay.NewReplayer(n.logger, commonServices.YamlTestDB, commonServices.YamlMockDb, commonServices.YamlReportDb, tel, commonServices.Instrumentation, *n.cfg), nil
 		}
 		return nil, errors.New("invalid command")
@@ -94,3 +132,18 @@ func (n *ServiceProvider) GetService(ctx context.Context, cmd string) (interface
 		return nil, errors.New("invalid command")
 	}
 }
+
+// GetProjectService returns the replay service of another project served besides the one of the config, with the
+// test cases, mocks and reports under its keploy directory at the path. It shares the hooks and the proxy of the
+// test command, so it's only available once its service was made.
+func (n *ServiceProvider) GetProjectService(path string) (interface{}, error) {
+	if n.replayer == nil {
+		return nil, errors.New("the projects are only served by the test command")
+	}
+	cfg := *n.cfg
+	cfg.Path = path
+	testDB := testdb.New(n.logger, cfg.Path)
+	mockDB := mockdb.New(n.logger, cfg.Path, "", cfg.Test.GlobalMocks, cfg.Test.MockTags)
+	reportDB := reportdb.New(n.logger, cfg.Path+"/reports")
+	return replay.NewReplayer(n.logger, testDB, mockDB, reportDB, n.tel, n.replayer.Instrumentation, cfg), nil
+}
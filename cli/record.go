@@ -34,6 +34,7 @@ func Record(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFa
 				utils.LogError(logger, nil, "service doesn't satisfy record service interface")
 				return nil
 			}
+			reloadOnHangup(ctx, logger, cmd, cmdConfigurator, record.Reload)
 			err = record.Start(ctx)
 			if err != nil {
 				utils.LogError(logger, err, "failed to record")
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// reloadOnChange watches keploy.yml and hands it to the running session whenever it is written
+func reloadOnChange(ctx context.Context, logger *zap.Logger, cmd *cobra.Command, cmdConfigurator CmdConfigurator, reload func(ctx context.Context, cfg config.Config) error) {
+	if viper.ConfigFileUsed() == "" {
+		logger.Warn("keploy.yml wasn't found, there is no config to watch for changes")
+		return
+	}
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		defer utils.Recover(logger)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		logger.Info("keploy.yml changed, reloading the config", zap.String("file", e.Name))
+		cfg, err := cmdConfigurator.ReloadConfig(ctx, cmd)
+		if err != nil {
+			utils.LogError(logger, err, "failed to reload the config, keeping the current one")
+			return
+		}
+		err = reload(ctx, *cfg)
+		if err != nil {
+			utils.LogError(logger, err, "failed to apply the reloaded config")
+		}
+	})
+	viper.WatchConfig()
+}
+
+// reloadOnHangup reads keploy.yml again on every SIGHUP and hands it to the running session,
+// so that the noise, the bypass rules and the filters change without restarting the hooks and the proxy.
+func reloadOnHangup(ctx context.Context, logger *zap.Logger, cmd *cobra.Command, cmdConfigurator CmdConfigurator, reload func(ctx context.Context, cfg config.Config) error) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		defer utils.Recover(logger)
+		defer signal.Stop(sigs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigs:
+				logger.Info("SIGHUP received, reloading the config")
+				cfg, err := cmdConfigurator.ReloadConfig(ctx, cmd)
+				if err != nil {
+					utils.LogError(logger, err, "failed to reload the config, keeping the current one")
+					continue
+				}
+				err = reload(ctx, *cfg)
+				if err != nil {
+					utils.LogError(logger, err, "failed to apply the reloaded config")
+				}
+			}
+		}
+	}()
+}
@@ -4,13 +4,21 @@ import (
 	"context"
 
 	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
 )
 
 type ServiceFactory interface {
 	GetService(ctx context.Context, cmd string) (interface{}, error)
 }
 
+// ProjectServiceFactory makes the services of the other projects served by the graphql server
+type ProjectServiceFactory interface {
+	GetProjectService(path string) (interface{}, error)
+}
+
 type CmdConfigurator interface {
 	AddFlags(cmd *cobra.Command) error
 	ValidateFlags(ctx context.Context, cmd *cobra.Command) error
+	// ReloadConfig reads keploy.yml again, along with the flags and the environment of the command
+	ReloadConfig(ctx context.Context, cmd *cobra.Command) (*config.Config, error)
 }
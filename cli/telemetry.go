@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("telemetry", Telemetry)
+}
+
+// telemetryPreviewer prints the telemetry events without sending them
+type telemetryPreviewer interface {
+	Preview() ([]byte, error)
+}
+
+// Telemetry groups the commands to inspect the telemetry of keploy
+func Telemetry(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var telemetryCmd = &cobra.Command{
+		Use:   "telemetry",
+		Short: "Inspect the telemetry collected by keploy",
+	}
+
+	var previewCmd = &cobra.Command{
+		Use:     "preview",
+		Short:   "Print exactly what would be sent to the telemetry server",
+		Example: "keploy telemetry preview",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			previewer, ok := svc.(telemetryPreviewer)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy telemetry preview interface")
+				return nil
+			}
+			out, err := previewer.Preview()
+			if err != nil {
+				utils.LogError(logger, err, "failed to preview the telemetry events")
+				return nil
+			}
+			fmt.Println(string(out))
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(previewCmd); err != nil {
+		utils.LogError(logger, err, "failed to add telemetry preview cmd flags")
+		return nil
+	}
+
+	telemetryCmd.AddCommand(previewCmd)
+	return telemetryCmd
+}
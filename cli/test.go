@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 
 	"go.keploy.io/server/v2/pkg/graph"
 	"go.keploy.io/server/v2/utils"
@@ -37,8 +38,15 @@ func Test(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFa
 				utils.LogError(logger, nil, "service doesn't satisfy replay service interface")
 				return nil
 			}
+			reloadOnHangup(ctx, logger, cmd, cmdConfigurator, replay.Reload)
+			if cfg.Test.WatchConfig {
+				reloadOnChange(ctx, logger, cmd, cmdConfigurator, replay.Reload)
+			}
 			if cfg.Test.Coverage {
 				g := graph.NewGraph(logger, replay, *cfg)
+				if err := addProjects(logger, cfg, serviceFactory, g); err != nil {
+					return nil
+				}
 				err := g.Serve(ctx)
 				if err != nil {
 					utils.LogError(logger, err, "failed to start graph service")
@@ -47,8 +55,17 @@ func Test(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFa
 			}
 			err = replay.Start(ctx)
 			if err != nil {
-				utils.LogError(logger, err, "failed to replay")
-				return nil
+				if errors.Is(err, context.Canceled) {
+					return nil
+				}
+				// the failing test cases were already reported, only the failures of the infrastructure are logged
+				if replaySvc.ExitCode(err) != replaySvc.ExitTestsFailed {
+					utils.LogError(logger, err, "failed to replay")
+				}
+				// the exit code tells the CI the cause of the failure of the test run
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+				return &utils.ExitError{Code: replaySvc.ExitCode(err), Err: err}
 			}
 
 			return nil
@@ -63,3 +80,35 @@ func Test(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFa
 
 	return testCmd
 }
+
+// addProjects serves the other projects of the config besides its own, each with its own replay service
+func addProjects(logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, g *graph.Graph) error {
+	if len(cfg.Test.Projects) == 0 {
+		return nil
+	}
+	factory, ok := serviceFactory.(ProjectServiceFactory)
+	if !ok {
+		err := errors.New("the service factory can't make the services of the projects")
+		utils.LogError(logger, err, "failed to serve the projects")
+		return err
+	}
+	for id, path := range cfg.Test.Projects {
+		svc, err := factory.GetProjectService(path)
+		if err != nil {
+			utils.LogError(logger, err, "failed to get the service of the project", zap.String("project", id))
+			return err
+		}
+		replay, ok := svc.(replaySvc.Service)
+		if !ok {
+			err := errors.New("service doesn't satisfy replay service interface")
+			utils.LogError(logger, err, "failed to serve the project", zap.String("project", id))
+			return err
+		}
+		if err := g.AddProject(id, replay); err != nil {
+			utils.LogError(logger, err, "failed to serve the project", zap.String("project", id))
+			return err
+		}
+		logger.Info("serving the project", zap.String("project", id), zap.String("path", path))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	validateSvc "go.keploy.io/server/v2/pkg/service/validate"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("validate", Validate)
+}
+
+// Validate retrieves the command to check the recorded test cases, mocks and reports for corruptions
+func Validate(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var validateCmd = &cobra.Command{
+		Use:     "validate [path]",
+		Short:   "Check the recorded test cases, mocks and reports against the schemas of their version",
+		Example: "keploy validate ./keploy",
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "./keploy"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			path, err := filepath.Abs(path)
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the absolute path from relative path")
+				return nil
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			validator, ok := svc.(validateSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy validate service interface")
+				return nil
+			}
+			issues, err := validator.Validate(ctx, path)
+			if err != nil {
+				utils.LogError(logger, err, "failed to validate the keploy directory", zap.String("path", path))
+				return nil
+			}
+			if len(issues) == 0 {
+				logger.Info("all the test cases, mocks and reports are valid", zap.String("path", path))
+				return nil
+			}
+			for _, issue := range issues {
+				rel, err := filepath.Rel(path, issue.File)
+				if err != nil {
+					rel = issue.File
+				}
+				location := rel
+				if issue.Doc > 0 {
+					location = fmt.Sprintf("%s (document %d", rel, issue.Doc)
+					if issue.Name != "" {
+						location += ", " + issue.Name
+					}
+					location += ")"
+				}
+				fmt.Printf("%s: %s\n", location, issue.Message)
+			}
+			cmd.SilenceUsage = true
+			return fmt.Errorf("found %d invalid documents in %s", len(issues), path)
+		},
+	}
+	if err := cmdConfigurator.AddFlags(validateCmd); err != nil {
+		utils.LogError(logger, err, "failed to add validate cmd flags")
+		return nil
+	}
+	return validateCmd
+}
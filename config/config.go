@@ -1,16 +1,22 @@
 // Package config provides configuration structures for the application.
 package config
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Config struct {
 	Path            string        `json:"path" yaml:"path" mapstructure:"path" `
 	Command         string        `json:"command" yaml:"command" mapstructure:"command"`
+	Image           string        `json:"image" yaml:"image" mapstructure:"image"` // image of the app run by keploy when there is no command, preferably pinned by digest
 	Port            uint32        `json:"port" yaml:"port" mapstructure:"port"`
 	DNSPort         uint32        `json:"dnsPort" yaml:"dnsPort" mapstructure:"dnsPort"`
 	ProxyPort       uint32        `json:"proxyPort" yaml:"proxyPort" mapstructure:"proxyPort"`
 	Debug           bool          `json:"debug" yaml:"debug" mapstructure:"debug"`
 	DisableTele     bool          `json:"disableTele" yaml:"disableTele" mapstructure:"disableTele"`
+	TelemetryURL    string        `json:"telemetryURL" yaml:"telemetryURL" mapstructure:"telemetryURL"` // self-hosted telemetry sink, the keploy server if empty
+	Profile         string        `json:"profile" yaml:"profile" mapstructure:"profile"`                // address of the pprof server, profiling is off if empty
 	InDocker        bool          `json:"inDocker" yaml:"inDocker" mapstructure:"inDocker"`
 	ContainerName   string        `json:"containerName" yaml:"containerName" mapstructure:"containerName"`
 	NetworkName     string        `json:"networkName" yaml:"networkName" mapstructure:"networkName"`
@@ -19,13 +25,222 @@ type Config struct {
 	Record          Record        `json:"record" yaml:"record" mapstructure:"record"`
 	ConfigPath      string        `json:"configPath" yaml:"configPath" mapstructure:"configPath"`
 	BypassRules     []BypassRule  `json:"bypassRules" yaml:"bypassRules" mapstructure:"bypassRules"`
+	IgnoreRules     []IgnoreRule  `json:"ignoreRules" yaml:"ignoreRules" mapstructure:"ignoreRules"`
 	KeployContainer string        `json:"keployContainer" yaml:"keployContainer" mapstructure:"keployContainer"`
 	KeployNetwork   string        `json:"keployNetwork" yaml:"keployNetwork" mapstructure:"keployNetwork"`
+	ReverseProxy    ReverseProxy  `json:"reverseProxy" yaml:"reverseProxy" mapstructure:"reverseProxy"`
+	UnixSockets     []UnixSocket  `json:"unixSockets" yaml:"unixSockets" mapstructure:"unixSockets"`
+	// UDPDependencies are the udp dependencies whose datagrams are sent through the proxy. The hooks don't
+	// intercept the datagrams, the dns queries aside, so the app has to send them to the port of the proxy itself.
+	UDPDependencies []UDPDependency `json:"udpDependencies" yaml:"udpDependencies" mapstructure:"udpDependencies"`
+	// IPFamily is the address family preferred in the DNS answers of the proxy: dual (default), ipv4 or ipv6
+	IPFamily string `json:"ipFamily" yaml:"ipFamily" mapstructure:"ipFamily"`
+	// DNS customizes the answers of the DNS server of the proxy
+	DNS DNS `json:"dns" yaml:"dns" mapstructure:"dns"`
+	// CgroupPath restricts the eBPF redirection to the processes of a cgroup (e.g. system.slice/app.service),
+	// either absolute or relative to the cgroup2 mount. Empty means the root cgroup.
+	CgroupPath string        `json:"cgroupPath" yaml:"cgroupPath" mapstructure:"cgroupPath"`
+	Processes  ProcessFilter `json:"processes" yaml:"processes" mapstructure:"processes"`
+	Masking    Masking       `json:"masking" yaml:"masking" mapstructure:"masking"`
+}
+
+// Masking replaces the values of the fields whose names match the patterns (e.g. "password", "card*")
+// with deterministic hashes when the test cases and mocks are written.
+type Masking struct {
+	Fields []string `json:"fields" yaml:"fields" mapstructure:"fields"`
+}
+
+// ProcessFilter picks the processes of the application whose outgoing calls are captured, by name pattern
+// (e.g. "npm", "java*"). Calls of the other processes, such as the shells and build tools launched along
+// with the application, are passed through to their destination without being recorded or mocked.
+type ProcessFilter struct {
+	Include []string `json:"include" yaml:"include" mapstructure:"include"`
+	Exclude []string `json:"exclude" yaml:"exclude" mapstructure:"exclude"`
+}
+
+// UnixSocket routes the outgoing calls of the application over a unix socket through the proxy.
+// Keploy listens on Path, which the application should connect to, and forwards the calls to Target.
+// Port is the conventional port of the dependency (e.g. 5432 for postgres) used to pick the integration.
+type UnixSocket struct {
+	Path   string `json:"path" yaml:"path" mapstructure:"path"`
+	Target string `json:"target" yaml:"target" mapstructure:"target"`
+	Port   uint32 `json:"port" yaml:"port" mapstructure:"port"`
+}
+
+// UDPDependency routes the datagrams the application sends to a udp dependency (e.g. statsd) through the proxy.
+// The eBPF hooks don't intercept the datagrams: keploy listens on 127.0.0.1:Port, which the application has to be
+// configured to send the datagrams to instead of Target, and forwards them to Target. The
+// datagrams are recorded as mocks and answered from them in the tests, unless PassThrough is set in which case
+// they're forwarded to Target in the tests too.
+type UDPDependency struct {
+	Port        uint32 `json:"port" yaml:"port" mapstructure:"port"`
+	Target      string `json:"target" yaml:"target" mapstructure:"target"`
+	PassThrough bool   `json:"passThrough" yaml:"passThrough" mapstructure:"passThrough"`
+}
+
+// Protocols which can be served on the declared application ports
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// Address families which can be preferred for the outgoing calls of the application
+const (
+	IPFamilyDual = "dual"
+	IPFamilyV4   = "ipv4"
+	IPFamilyV6   = "ipv6"
+)
+
+// AppPort declares a port on which the application listens along with the protocol served on it, which the reverse
+// proxy captures the traffic of. The eBPF hooks capture the incoming http calls of every port and ignore the
+// declared ones.
+type AppPort struct {
+	Port     uint32 `json:"port" yaml:"port" mapstructure:"port"`
+	Protocol string `json:"protocol" yaml:"protocol" mapstructure:"protocol"` // http (default) or grpc
+	// IngressPort is the port on which keploy accepts the traffic for this app port
+	IngressPort uint32 `json:"ingressPort" yaml:"ingressPort" mapstructure:"ingressPort"`
+}
+
+// ReverseProxy configures the instrumentation used where eBPF isn't available (e.g. AWS Fargate).
+// Keploy listens on IngressPort and reverse-proxies to the application on AppPort, while the outgoing
+// calls of the application go through the explicit proxy on EgressPort via the HTTP_PROXY/HTTPS_PROXY env vars.
+// The egress proxy listens on the loopback only, so the application must run on the same host.
+type ReverseProxy struct {
+	Enabled     bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	IngressPort uint32 `json:"ingressPort" yaml:"ingressPort" mapstructure:"ingressPort"`
+	AppPort     uint32 `json:"appPort" yaml:"appPort" mapstructure:"appPort"`
+	EgressPort  uint32 `json:"egressPort" yaml:"egressPort" mapstructure:"egressPort"`
+	// AppPorts are the other ports of the application which the reverse proxy captures, by their protocol
+	AppPorts []AppPort `json:"appPorts" yaml:"appPorts" mapstructure:"appPorts"`
 }
 
 type Record struct {
 	Filters     []Filter      `json:"filters" yaml:"filters" mapstructure:"filters"`
 	RecordTimer time.Duration `json:"recordTimer" yaml:"recordTimer" mapstructure:"recordTimer"`
+	// StableTestIDs names the test cases after a hash of their request instead of their capture order
+	StableTestIDs bool            `json:"stableTestIDs" yaml:"stableTestIDs" mapstructure:"stableTestIDs"`
+	Buffers       TrackerBuffers  `json:"buffers" yaml:"buffers" mapstructure:"buffers"`
+	Tracker       TrackerTimeouts `json:"tracker" yaml:"tracker" mapstructure:"tracker"`
+	Persistence   Persistence     `json:"persistence" yaml:"persistence" mapstructure:"persistence"`
+	MockQueue     MockQueue       `json:"mockQueue" yaml:"mockQueue" mapstructure:"mockQueue"`
+	// DrainTimeout bounds the wait for the in-flight captures and writes once recording is stopped
+	DrainTimeout time.Duration `json:"drainTimeout" yaml:"drainTimeout" mapstructure:"drainTimeout"`
+	// MessagePort is the port on which the consumers of the app report the messages they processed, each
+	// recorded as a test case along with the outgoing calls made while processing it. It listens on the
+	// loopback interface only. Zero disables it.
+	MessagePort uint32 `json:"messagePort" yaml:"messagePort" mapstructure:"messagePort"`
+	// CaptureMessages records the kafka messages the app fetches through the proxy as test cases, the
+	// mocked broker delivering them again during the tests, without the consumers reporting them
+	CaptureMessages bool `json:"captureMessages" yaml:"captureMessages" mapstructure:"captureMessages"`
+	// Job names the scheduled or async job run by the command. Its outgoing calls are recorded as a single
+	// test case once it exits, as it has no incoming call. Empty records the incoming calls as usual.
+	Job string `json:"job" yaml:"job" mapstructure:"job"`
+	// Session groups the test cases sent in the same session of a client into scenarios
+	Session Session `json:"session" yaml:"session" mapstructure:"session"`
+	// DestinationRewrites send the outgoing calls to alternates of their destinations while recording
+	DestinationRewrites []DestinationRewrite `json:"destinationRewrites" yaml:"destinationRewrites" mapstructure:"destinationRewrites"`
+	// MaxDuration, MaxTestCases and MaxMockBytes stop recording once the session reaches them, so that a
+	// forgotten session doesn't fill the disk. Zero leaves them unbounded.
+	MaxDuration  time.Duration `json:"maxDuration" yaml:"maxDuration" mapstructure:"maxDuration"`
+	MaxTestCases int           `json:"maxTestCases" yaml:"maxTestCases" mapstructure:"maxTestCases"`
+	MaxMockBytes int64         `json:"maxMockBytes" yaml:"maxMockBytes" mapstructure:"maxMockBytes"`
+	// MaxBodySize is the size in bytes above which the body of a recorded response is stored truncated,
+	// along with the hash of its full content. Zero stores the bodies whole.
+	MaxBodySize int `json:"maxBodySize" yaml:"maxBodySize" mapstructure:"maxBodySize"`
+	// MockTags are added to the recorded mocks, e.g. the state of the feature flags of the app, so that the
+	// recordings of several states can be replayed with the mocks of one of them
+	MockTags []string `json:"mockTags" yaml:"mockTags" mapstructure:"mockTags"`
+	// GenericFallback records the connections of the protocols no integration supports as generic byte-stream
+	// mocks. They're passed through unrecorded when it's off.
+	GenericFallback bool `json:"genericFallback" yaml:"genericFallback" mapstructure:"genericFallback"`
+}
+
+// DNS customizes the answers of the DNS server of the proxy. The Records are answered as is in record and test
+// mode, the other queries are resolved with the Upstream resolver (host:port) while recording, with the resolver of
+// the system when it's empty. InterceptDoH answers the queries sent over DNS-over-HTTPS to the DoHHosts and over
+// DNS-over-TLS to port 853 with the DNS server of the proxy too, as the apps resolving with them would bypass it.
+type DNS struct {
+	Records      []DNSRecord `json:"records" yaml:"records" mapstructure:"records"`
+	Upstream     string      `json:"upstream" yaml:"upstream" mapstructure:"upstream"`
+	InterceptDoH bool        `json:"interceptDoH" yaml:"interceptDoH" mapstructure:"interceptDoH"`
+	DoHHosts     []string    `json:"dohHosts" yaml:"dohHosts" mapstructure:"dohHosts"`
+}
+
+// DNSRecord answers the A and AAAA queries of the Name, or of its subdomains for a name like *.internal, with IPs
+type DNSRecord struct {
+	Name string   `json:"name" yaml:"name" mapstructure:"name"`
+	IPs  []string `json:"ips" yaml:"ips" mapstructure:"ips"`
+}
+
+// DestinationRewrite sends the outgoing calls to From to To while recording, e.g. to record against a staging
+// database instead of the production one without changing the config of the app. Both are host:port or host,
+// From then matching any port and To keeping the port of the call.
+type DestinationRewrite struct {
+	From string `json:"from" yaml:"from" mapstructure:"from"`
+	To   string `json:"to" yaml:"to" mapstructure:"to"`
+}
+
+// Session identifies the session of a client by the value of the Cookie or, if it has none, of the Header
+// (e.g. X-Session-ID or the trace id of traceparent). The test cases of a session are recorded as a scenario,
+// replayed in order as a unit. Both empty disables the scenarios.
+type Session struct {
+	Cookie string `json:"cookie" yaml:"cookie" mapstructure:"cookie"`
+	Header string `json:"header" yaml:"header" mapstructure:"header"`
+}
+
+// MockQueue bounds the captured mocks waiting to be written. Once Size mocks are waiting, Overflow decides
+// whether the proxy waits for room (block, default), the mock is dropped (drop) or it is spilled to a file
+// in SpillDir (spill, the temp dir if empty) until the queue drains.
+type MockQueue struct {
+	Size     int    `json:"size" yaml:"size" mapstructure:"size"`
+	Overflow string `json:"overflow" yaml:"overflow" mapstructure:"overflow"`
+	SpillDir string `json:"spillDir" yaml:"spillDir" mapstructure:"spillDir"`
+}
+
+// Overflow policies of the mock queue
+const (
+	OverflowBlock = "block"
+	OverflowDrop  = "drop"
+	OverflowSpill = "spill"
+)
+
+// Persistence tunes how the captured test cases are written. They are queued, up to QueueSize, and written
+// in batches of BatchSize or every FlushInterval. Fsync is when the written files are synced to the disk:
+// none (left to the os), batch (after every batch, default) or always (after every test case).
+type Persistence struct {
+	QueueSize     int           `json:"queueSize" yaml:"queueSize" mapstructure:"queueSize"`
+	BatchSize     int           `json:"batchSize" yaml:"batchSize" mapstructure:"batchSize"`
+	FlushInterval time.Duration `json:"flushInterval" yaml:"flushInterval" mapstructure:"flushInterval"`
+	Fsync         string        `json:"fsync" yaml:"fsync" mapstructure:"fsync"`
+}
+
+// Policies of syncing the written test cases to the disk
+const (
+	FsyncNone   = "none"
+	FsyncBatch  = "batch"
+	FsyncAlways = "always"
+)
+
+// TrackerTimeouts tunes when the incoming calls are captured and their connections dropped. A response is taken
+// as complete once its connection is idle for ResponseIdle, or StreamingResponseIdle for the streamed responses
+// (server-sent events, chunked and grpc). Connections idle for InactivityThreshold are dropped, and the trackers
+// are checked every SweepInterval. Zero values fall back to the defaults.
+type TrackerTimeouts struct {
+	InactivityThreshold   time.Duration `json:"inactivityThreshold" yaml:"inactivityThreshold" mapstructure:"inactivityThreshold"`
+	SweepInterval         time.Duration `json:"sweepInterval" yaml:"sweepInterval" mapstructure:"sweepInterval"`
+	ResponseIdle          time.Duration `json:"responseIdle" yaml:"responseIdle" mapstructure:"responseIdle"`
+	StreamingResponseIdle time.Duration `json:"streamingResponseIdle" yaml:"streamingResponseIdle" mapstructure:"streamingResponseIdle"`
+}
+
+// TrackerBuffers bounds the memory used to hold the incoming calls until they are captured as test cases.
+// A message which would take a connection past ConnSize, or all of them past TotalSize, is spilled to a file
+// in SpillDir (the temp dir if empty), or its connection is evicted if Spill is off. Sizes are in bytes, 0 is unbounded.
+type TrackerBuffers struct {
+	MaxConnections int    `json:"maxConnections" yaml:"maxConnections" mapstructure:"maxConnections"`
+	ConnSize       int64  `json:"connSize" yaml:"connSize" mapstructure:"connSize"`
+	TotalSize      int64  `json:"totalSize" yaml:"totalSize" mapstructure:"totalSize"`
+	Spill          bool   `json:"spill" yaml:"spill" mapstructure:"spill"`
+	SpillDir       string `json:"spillDir" yaml:"spillDir" mapstructure:"spillDir"`
 }
 
 type BypassRule struct {
@@ -34,6 +249,19 @@ type BypassRule struct {
 	Port uint   `json:"port" yaml:"port" mapstructure:"port"`
 }
 
+// IgnoreRule declares an outgoing destination (e.g. analytics or error reporting) which is neither recorded
+// nor mocked. The proxy answers its http calls with empty 200s ("stub", default) or drops its connections ("blackhole").
+type IgnoreRule struct {
+	Host   string `json:"host" yaml:"host" mapstructure:"host"` // regex matched against the host name or the ip of the destination
+	Port   uint   `json:"port" yaml:"port" mapstructure:"port"`
+	Action string `json:"action" yaml:"action" mapstructure:"action"`
+}
+
+const (
+	IgnoreActionStub      = "stub"
+	IgnoreActionBlackhole = "blackhole"
+)
+
 type Filter struct {
 	BypassRule `mapstructure:",squash"`
 	URLMethods []string          `json:"urlMethods" yaml:"urlMethods" mapstructure:"urlMethods"`
@@ -48,9 +276,131 @@ type Test struct {
 	Coverage           bool                `json:"coverage" yaml:"coverage" mapstructure:"coverage"`                                // boolean to capture the coverage in test
 	CoverageReportPath string              `json:"coverageReportPath" yaml:"coverageReportPath " mapstructure:"coverageReportPath"` // directory path to store the coverage files
 	IgnoreOrdering     bool                `json:"ignoreOrdering" yaml:"ignoreOrdering" mapstructure:"ignoreOrdering"`
+	UnorderedFields    UnorderedFields     `json:"unorderedFields" yaml:"unorderedFields" mapstructure:"unorderedFields"`
+	JSONCompare        JSONCompare         `json:"jsonCompare" yaml:"jsonCompare" mapstructure:"jsonCompare"`
 	MongoPassword      string              `json:"mongoPassword" yaml:"mongoPassword" mapstructure:"mongoPassword"`
 	Language           string              `json:"language" yaml:"language" mapstructure:"language"`
 	RemoveUnusedMocks  bool                `json:"removeUnusedMocks" yaml:"removeUnusedMocks" mapstructure:"removeUnusedMocks"`
+	K8s                K8sTarget           `json:"k8s" yaml:"k8s" mapstructure:"k8s"`
+	IsolateNetwork     bool                `json:"isolateNetwork" yaml:"isolateNetwork" mapstructure:"isolateNetwork"`    // run the native app in a fresh network namespace for each test set
+	ReuseApp           bool                `json:"reuseApp" yaml:"reuseApp" mapstructure:"reuseApp"`                      // launch the app once and reuse it across the test sets
+	TestSetOrder       []string            `json:"testSetOrder" yaml:"testSetOrder" mapstructure:"testSetOrder"`          // test sets to run first in the given order, the others follow
+	Shuffle            string              `json:"shuffle" yaml:"shuffle" mapstructure:"shuffle"`                         // shuffle the test cases of a set with the given seed or "random"
+	VerifyMockOrder    bool                `json:"verifyMockOrder" yaml:"verifyMockOrder" mapstructure:"verifyMockOrder"` // fail the test cases whose outgoing calls are made in a different order than recorded
+	SQLFingerprint     bool                `json:"sqlFingerprint" yaml:"sqlFingerprint" mapstructure:"sqlFingerprint"`    // match the sql queries of the mocks ignoring their literal values and whitespace
+	WatchConfig        bool                `json:"watchConfig" yaml:"watchConfig" mapstructure:"watchConfig"`             // reload the noise from keploy.yml whenever it changes during the test run
+	Watch              bool                `json:"watch" yaml:"watch" mapstructure:"watch"`                               // run the test sets again whenever the watched files change
+	WatchPaths         []string            `json:"watchPaths" yaml:"watchPaths" mapstructure:"watchPaths"`                // files and directories watched, e.g. the source or the binary of the app
+	WatchExclude       []string            `json:"watchExclude" yaml:"watchExclude" mapstructure:"watchExclude"`          // name patterns of the files and directories which aren't watched
+	BuildCommand       string              `json:"buildCommand" yaml:"buildCommand" mapstructure:"buildCommand"`          // command building the app before the test sets are run again
+	Impact             Impact              `json:"impact" yaml:"impact" mapstructure:"impact"`
+	PublishCommand     string              `json:"publishCommand" yaml:"publishCommand" mapstructure:"publishCommand"` // command publishing the message of a message test case, read from stdin
+	StateCheck         StateCheck          `json:"stateCheck" yaml:"stateCheck" mapstructure:"stateCheck"`
+	GrpcTrailers       []string            `json:"grpcTrailers" yaml:"grpcTrailers" mapstructure:"grpcTrailers"`       // trailers of the grpc responses compared besides grpc-status and grpc-message
+	SlowestTests       int                 `json:"slowestTests" yaml:"slowestTests" mapstructure:"slowestTests"`       // number of the slowest test cases listed in the summary and the run report
+	CompareHeaders     []string            `json:"compareHeaders" yaml:"compareHeaders" mapstructure:"compareHeaders"` // when set, only these headers of the responses are compared, the others are informational
+	RunTimeout         time.Duration       `json:"runTimeout" yaml:"runTimeout" mapstructure:"runTimeout"`             // wall-clock budget of the whole test run, after which it's aborted as timed out
+	SchemaDrift        string              `json:"schemaDrift" yaml:"schemaDrift" mapstructure:"schemaDrift"`          // off, warn or fail on the fields of the responses whose type drifted from the recorded ones
+	Idempotency        bool                `json:"idempotency" yaml:"idempotency" mapstructure:"idempotency"`          // send the GET, PUT and DELETE requests twice, failing when the second response differs
+	Auth               Auth                `json:"auth" yaml:"auth" mapstructure:"auth"`
+	URLRewrites        []URLRewrite        `json:"urlRewrites" yaml:"urlRewrites" mapstructure:"urlRewrites"`
+	GlobalMocks        []string            `json:"globalMocks" yaml:"globalMocks" mapstructure:"globalMocks"` // test sets replayed with the shared mocks of the global-mocks directory besides their own, all of them when empty
+	MockTags           []string            `json:"mockTags" yaml:"mockTags" mapstructure:"mockTags"`          // active tags, the tagged mocks are loaded only when one of their tags is active
+	EnvMatrix          []EnvSet            `json:"envMatrix" yaml:"envMatrix" mapstructure:"envMatrix"`
+	GenericReplay      string              `json:"genericReplay" yaml:"genericReplay" mapstructure:"genericReplay"` // match or verbatim, serving the generic mocks in their recorded order to the requests matching none of them
+	Parallel           int                 `json:"parallel" yaml:"parallel" mapstructure:"parallel"`                // number of the test sets run at once, each against its own instance of the app
+	RerunFailed        string              `json:"rerunFailed" yaml:"rerunFailed" mapstructure:"rerunFailed"`       // id of a previous test run whose failed test cases are the only ones run
+	RequiredEnv        []string            `json:"requiredEnv" yaml:"requiredEnv" mapstructure:"requiredEnv"`       // environment variables the app needs, checked before each test set is run
+	Projects           map[string]string   `json:"projects" yaml:"projects" mapstructure:"projects"`                // paths of the other projects served by the graphql server by their id
+	DebugMatching      bool                `json:"debugMatching" yaml:"debugMatching" mapstructure:"debugMatching"` // trace the closest mocks of the outgoing calls matching none to the matching.yaml of the test run
+}
+
+// EnvSet is a set of environment variables of the app, e.g. the state of its feature flags. Each test set is run
+// once under every env set of the EnvMatrix, the app being launched with the NAME=value variables of the Env.
+type EnvSet struct {
+	Name string   `json:"name" yaml:"name" mapstructure:"name"`
+	Env  []string `json:"env" yaml:"env" mapstructure:"env"`
+}
+
+// URLRewrite rewrites the url and the Host header of the simulated requests whose url matches Match, e.g. recorded
+// behind a gateway as https://api.example.com/orders while the app serves http://localhost:8080. The first rule
+// matching a request applies. The empty parts of Match match any url and the empty parts of To are kept, the
+// PathPrefix of To replacing the one of Match.
+type URLRewrite struct {
+	Match URLParts `json:"match" yaml:"match" mapstructure:"match"`
+	To    URLParts `json:"to" yaml:"to" mapstructure:"to"`
+}
+
+type URLParts struct {
+	Scheme     string `json:"scheme" yaml:"scheme" mapstructure:"scheme"`
+	Host       string `json:"host" yaml:"host" mapstructure:"host"`
+	Port       string `json:"port" yaml:"port" mapstructure:"port"`
+	PathPrefix string `json:"pathPrefix" yaml:"pathPrefix" mapstructure:"pathPrefix"`
+}
+
+// Auth obtains a fresh token before each test set, which replaces the recorded one in the Header of the simulated
+// requests having it, as the recorded tokens have usually expired. Type is oauth2 for the client credentials
+// grant, login to send a request returning the token in its json body at TokenPath (e.g. data.token), or script
+// to run a command printing the token. Empty keeps the recorded tokens.
+type Auth struct {
+	Type   string     `json:"type" yaml:"type" mapstructure:"type"`
+	Header string     `json:"header" yaml:"header" mapstructure:"header"`
+	Scheme string     `json:"scheme" yaml:"scheme" mapstructure:"scheme"` // prefixed to the token in the header, like Bearer
+	OAuth2 AuthOAuth2 `json:"oauth2" yaml:"oauth2" mapstructure:"oauth2"`
+	Login  AuthLogin  `json:"login" yaml:"login" mapstructure:"login"`
+	Script string     `json:"script" yaml:"script" mapstructure:"script"`
+}
+
+type AuthOAuth2 struct {
+	TokenURL     string   `json:"tokenURL" yaml:"tokenURL" mapstructure:"tokenURL"`
+	ClientID     string   `json:"clientID" yaml:"clientID" mapstructure:"clientID"`
+	ClientSecret string   `json:"clientSecret" yaml:"clientSecret" mapstructure:"clientSecret"`
+	Scopes       []string `json:"scopes" yaml:"scopes" mapstructure:"scopes"`
+}
+
+type AuthLogin struct {
+	URL       string            `json:"url" yaml:"url" mapstructure:"url"`
+	Method    string            `json:"method" yaml:"method" mapstructure:"method"`
+	Header    map[string]string `json:"header" yaml:"header" mapstructure:"header"`
+	Body      string            `json:"body" yaml:"body" mapstructure:"body"`
+	TokenPath string            `json:"tokenPath" yaml:"tokenPath" mapstructure:"tokenPath"`
+}
+
+// StateCheck sets the commands running the verification queries of the test cases against a test database
+// once they are simulated. A command reads the query from stdin and prints its result, e.g.
+// psql "$DATABASE_URL" -At for sql or mongosh "$MONGO_URL" --quiet --json=relaxed for mongo. The queries can only
+// run against a real database, so the calls of the app to the test database must be passed through with the
+// bypass rules for it to hold the data.
+type StateCheck struct {
+	SQLCommand   string `json:"sqlCommand" yaml:"sqlCommand" mapstructure:"sqlCommand"`
+	MongoCommand string `json:"mongoCommand" yaml:"mongoCommand" mapstructure:"mongoCommand"`
+}
+
+// Impact runs only the test sets calling the endpoints affected by the files changed since Base, a git ref
+// such as origin/main. A changed file matching none of the Routes nor Ignore affects every endpoint.
+type Impact struct {
+	Base   string        `json:"base" yaml:"base" mapstructure:"base"`
+	Routes []ImpactRoute `json:"routes" yaml:"routes" mapstructure:"routes"`
+	Ignore []string      `json:"ignore" yaml:"ignore" mapstructure:"ignore"` // patterns of the changed files affecting no endpoint e.g. docs/**
+}
+
+// ImpactRoute maps the source files, as patterns relative to the working directory e.g. handlers/user*.go,
+// to the endpoints they serve, as path patterns optionally preceded by a method e.g. "POST /users/*".
+// A * matches within a path segment and a ** across them.
+type ImpactRoute struct {
+	Files     []string `json:"files" yaml:"files" mapstructure:"files"`
+	Endpoints []string `json:"endpoints" yaml:"endpoints" mapstructure:"endpoints"`
+}
+
+// K8sTarget describes a deployed kubernetes workload to replay the test cases against.
+// When Target is set, keploy port-forwards to it and runs the tests with mocks disabled.
+type K8sTarget struct {
+	Kubeconfig string `json:"kubeconfig" yaml:"kubeconfig" mapstructure:"kubeconfig"`
+	Context    string `json:"context" yaml:"context" mapstructure:"context"`
+	Namespace  string `json:"namespace" yaml:"namespace" mapstructure:"namespace"`
+	Target     string `json:"target" yaml:"target" mapstructure:"target"` // e.g. svc/my-app or pod/my-app-7d9f
+	Port       uint32 `json:"port" yaml:"port" mapstructure:"port"`
+	LocalPort  uint32 `json:"localPort" yaml:"localPort" mapstructure:"localPort"`
 }
 
 type Globalnoise struct {
@@ -58,6 +408,27 @@ type Globalnoise struct {
 	Testsets TestsetNoise `json:"test-sets" yaml:"test-sets" mapstructure:"test-sets"`
 }
 
+// UnorderedFields lists the array paths of the responses compared as multisets, e.g. body.tags, while the other
+// arrays stay order-sensitive unless ignoreOrdering is set. The paths of a test set add to the global ones.
+type UnorderedFields struct {
+	Global   []string            `json:"global" yaml:"global" mapstructure:"global"`
+	Testsets map[string][]string `json:"test-sets" yaml:"test-sets" mapstructure:"test-sets"`
+}
+
+// JSONCompare sets how the json bodies are canonicalized before they are compared. The options of a test set
+// replace the global ones.
+type JSONCompare struct {
+	Global   JSONCompareOptions            `json:"global" yaml:"global" mapstructure:"global"`
+	Testsets map[string]JSONCompareOptions `json:"test-sets" yaml:"test-sets" mapstructure:"test-sets"`
+}
+
+type JSONCompareOptions struct {
+	TrailingZeros        bool `json:"trailingZeros" yaml:"trailingZeros" mapstructure:"trailingZeros"`                      // "1.50" equals "1.5"
+	NumericStrings       bool `json:"numericStrings" yaml:"numericStrings" mapstructure:"numericStrings"`                   // "5" equals 5
+	NullAsAbsent         bool `json:"nullAsAbsent" yaml:"nullAsAbsent" mapstructure:"nullAsAbsent"`                         // a key set to null equals a missing key
+	UnicodeNormalization bool `json:"unicodeNormalization" yaml:"unicodeNormalization" mapstructure:"unicodeNormalization"` // the strings are compared in their NFC form
+}
+
 type (
 	Noise        map[string][]string
 	GlobalNoise  map[string]map[string][]string
@@ -91,3 +462,14 @@ func SetSelectedTests(conf *Config, testSets []string) {
 		conf.Test.SelectedTests[testSet] = []string{}
 	}
 }
+
+// GetAppPortProtocol returns the protocol declared for the given application port in the reverse proxy config.
+// Undeclared ports are treated as http.
+func GetAppPortProtocol(conf *Config, port uint32) string {
+	for _, appPort := range conf.ReverseProxy.AppPorts {
+		if appPort.Port == port && appPort.Protocol != "" {
+			return strings.ToLower(appPort.Protocol)
+		}
+	}
+	return ProtocolHTTP
+}
@@ -11,11 +11,14 @@ import (
 var defaultConfig = `
 path: ""
 command: ""
+image: ""
 port: 0
 proxyPort: 16789
 dnsPort: 26789
 debug: false
 disableTele: false
+telemetryURL: ""
+profile: ""
 inDocker: false
 containerName: ""
 networkName: ""
@@ -30,14 +33,148 @@ test:
   coverage: false
   coverageReportPath: ""
   ignoreOrdering: true
+  unorderedFields:
+    global: []
+    test-sets: {}
+  jsonCompare:
+    global:
+      trailingZeros: false
+      numericStrings: false
+      nullAsAbsent: false
+      unicodeNormalization: false
+    test-sets: {}
   mongoPassword: "default@123"
   language: ""
   removeUnusedMocks: false
+  k8s:
+    kubeconfig: ""
+    context: ""
+    namespace: ""
+    target: ""
+    port: 0
+    localPort: 0
+  isolateNetwork: false
+  reuseApp: false
+  testSetOrder: []
+  shuffle: ""
+  verifyMockOrder: false
+  sqlFingerprint: false
+  watchConfig: false
+  watch: false
+  watchPaths: ["."]
+  watchExclude: ["node_modules", "vendor", "*.log"]
+  buildCommand: ""
+  impact:
+    base: ""
+    routes: []
+    ignore: []
+  publishCommand: ""
+  stateCheck:
+    sqlCommand: ""
+    mongoCommand: ""
+  grpcTrailers: []
+  compareHeaders: []
+  slowestTests: 5
+  runTimeout: 0s
+  schemaDrift: warn
+  idempotency: false
+  auth:
+    type: ""
+    header: Authorization
+    scheme: Bearer
+    oauth2:
+      tokenURL: ""
+      clientID: ""
+      clientSecret: ""
+      scopes: []
+    login:
+      url: ""
+      method: POST
+      header: {}
+      body: ""
+      tokenPath: access_token
+    script: ""
+  urlRewrites: []
+  globalMocks: []
+  envMatrix: []
+  genericReplay: "match"
+  parallel: 1
+  rerunFailed: ""
+  requiredEnv: []
+  mockTags: []
+  projects: {}
+  debugMatching: false
 record:
   recordTimer: 0s
   filters: []
+  stableTestIDs: false
+  buffers:
+    maxConnections: 10000
+    connSize: 8388608
+    totalSize: 536870912
+    spill: true
+    spillDir: ""
+  tracker:
+    inactivityThreshold: 1m
+    sweepInterval: 100ms
+    responseIdle: 2s
+    streamingResponseIdle: 30s
+  persistence:
+    queueSize: 1000
+    batchSize: 32
+    flushInterval: 1s
+    fsync: batch
+  mockQueue:
+    size: 500
+    overflow: block
+    spillDir: ""
+  drainTimeout: 10s
+  messagePort: 0
+  captureMessages: false
+  job: ""
+  session:
+    cookie: ""
+    header: ""
+  maxDuration: 0s
+  maxTestCases: 0
+  maxMockBytes: 0
+  maxBodySize: 0
+  destinationRewrites: []
+  mockTags: []
+  genericFallback: true
 configPath: ""
 bypassRules: []
+ignoreRules: []
+reverseProxy:
+  enabled: false
+  ingressPort: 0
+  appPort: 0
+  egressPort: 16790
+  appPorts: []
+unixSockets: []
+udpDependencies: []
+ipFamily: "dual"
+dns:
+  records: []
+  upstream: ""
+  interceptDoH: false
+  dohHosts:
+    - "dns.google"
+    - "cloudflare-dns.com"
+    - "one.one.one.one"
+    - "dns.quad9.net"
+    - "doh.opendns.com"
+    - "8.8.8.8"
+    - "8.8.4.4"
+    - "1.1.1.1"
+    - "1.0.0.1"
+    - "9.9.9.9"
+cgroupPath: ""
+processes:
+  include: []
+  exclude: []
+masking:
+  fields: []
 `
 
 func GetDefaultConfig() string {
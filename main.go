@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -56,6 +57,7 @@ func start(ctx context.Context) {
 		return
 	}
 	defer utils.DeleteLogs(logger)
+	defer utils.StopProfiling(logger)
 	defer utils.Recover(logger)
 	configDb := configdb.NewConfigDb(logger)
 	if dsn != "" {
@@ -67,6 +69,10 @@ func start(ctx context.Context) {
 	cmdConfigurator := provider.NewCmdConfigurator(logger, conf)
 	rootCmd := cli.Root(ctx, logger, svcProvider, cmdConfigurator)
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *utils.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,118 @@
+// Package client lets Go programs embed the record and replay services of keploy with their own
+// config, storage and logger instead of shelling out to the CLI.
+//
+//	cfg := config.New() // the defaults used by the CLI
+//	cfg.Command = "./my-app"
+//	c, err := client.New(*cfg, client.Options{Logger: logger})
+//	...
+//	err = c.Replayer().Start(ctx)
+package client
+
+import (
+	"errors"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/core/hooks"
+	"go.keploy.io/server/v2/pkg/core/proxy"
+	"go.keploy.io/server/v2/pkg/core/reverseproxy"
+	"go.keploy.io/server/v2/pkg/platform/telemetry"
+	mockdb "go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
+	reportdb "go.keploy.io/server/v2/pkg/platform/yaml/reportdb"
+	testdb "go.keploy.io/server/v2/pkg/platform/yaml/testdb"
+	"go.keploy.io/server/v2/pkg/service/record"
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.uber.org/zap"
+)
+
+// TestDB stores the test cases, it's used by both the record and the replay services.
+type TestDB interface {
+	record.TestDB
+	replay.TestDB
+}
+
+// MockDB stores the mocks, it's used by both the record and the replay services.
+type MockDB interface {
+	record.MockDB
+	replay.MockDB
+}
+
+// ReportDB stores the reports of the test runs.
+type ReportDB = replay.ReportDB
+
+// Telemetry receives the usage events of the record and replay services.
+type Telemetry interface {
+	record.Telemetry
+	replay.Telemetry
+}
+
+// Options are the dependencies of the services. The unset ones default to what the CLI uses:
+// the yaml files under Config.Path for the storage and disabled telemetry.
+type Options struct {
+	Logger    *zap.Logger
+	TestDB    TestDB
+	MockDB    MockDB
+	ReportDB  ReportDB
+	Telemetry Telemetry
+}
+
+type Client struct {
+	logger          *zap.Logger
+	cfg             config.Config
+	testDB          TestDB
+	mockDB          MockDB
+	reportDB        ReportDB
+	telemetry       Telemetry
+	instrumentation *core.Core
+}
+
+func New(cfg config.Config, opts Options) (*Client, error) {
+	if opts.Logger == nil {
+		return nil, errors.New("logger is required")
+	}
+	c := &Client{
+		logger:          opts.Logger,
+		cfg:             cfg,
+		testDB:          opts.TestDB,
+		mockDB:          opts.MockDB,
+		reportDB:        opts.ReportDB,
+		telemetry:       opts.Telemetry,
+		instrumentation: NewInstrumentation(opts.Logger, cfg),
+	}
+	if c.testDB == nil {
+		c.testDB = testdb.New(c.logger, cfg.Path)
+	}
+	if c.mockDB == nil {
+		c.mockDB = mockdb.New(c.logger, cfg.Path, "", cfg.Test.GlobalMocks, cfg.Test.MockTags)
+	}
+	if c.reportDB == nil {
+		c.reportDB = reportdb.New(c.logger, cfg.Path+"/reports")
+	}
+	if c.telemetry == nil {
+		c.telemetry = telemetry.Noop{}
+	}
+	return c, nil
+}
+
+// NewInstrumentation creates the hooks and the proxy which capture the traffic of the application.
+// The reverse proxy replaces the eBPF hooks when it's enabled in the config.
+func NewInstrumentation(logger *zap.Logger, cfg config.Config) *core.Core {
+	var h core.Hooks
+	if cfg.ReverseProxy.Enabled {
+		h = reverseproxy.New(logger, cfg)
+	} else {
+		h = hooks.NewHooks(logger, cfg)
+	}
+	p := proxy.New(logger, h, cfg)
+	return core.New(logger, h, p, cfg)
+}
+
+// Recorder returns the service which records the test cases and mocks of the application.
+func (c *Client) Recorder() record.Service {
+	return record.New(c.logger, c.testDB, c.mockDB, c.telemetry, c.instrumentation, c.cfg)
+}
+
+// Replayer returns the service which replays the recorded test cases against the application.
+func (c *Client) Replayer() replay.Service {
+	return replay.NewReplayer(c.logger, c.testDB, c.mockDB, c.reportDB, c.telemetry, c.instrumentation, c.cfg)
+}
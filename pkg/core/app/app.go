@@ -28,10 +28,14 @@ func NewApp(logger *zap.Logger, id uint64, cmd string, opts Options) *App {
 		id:               id,
 		cmd:              cmd,
 		kind:             utils.FindDockerCmd(cmd),
+		runtime:          utils.FindContainerRuntime(cmd),
 		keployContainer:  "keploy-v2",
 		container:        opts.Container,
 		containerDelay:   opts.DockerDelay,
 		containerNetwork: opts.DockerNetwork,
+		isolateNetwork:   opts.IsolateNetwork,
+		onStart:          opts.OnStart,
+		output:           newOutput(),
 	}
 	return app
 }
@@ -42,6 +46,7 @@ type App struct {
 	id               uint64
 	cmd              string
 	kind             utils.CmdType
+	runtime          utils.ContainerRuntime
 	containerDelay   time.Duration
 	container        string
 	containerNetwork string
@@ -50,6 +55,13 @@ type App struct {
 	keployContainer  string
 	keployIPv4       string
 	inodeChan        chan uint64
+	isolateNetwork   bool
+	netNs            *netNs
+	output           *output
+	// env is added to the environment of the command for the current run
+	env []string
+	// onStart is told the pid of the app, or of its container, whenever it starts
+	onStart func(pid uint32)
 }
 
 type Options struct {
@@ -58,9 +70,16 @@ type Options struct {
 	Container     string
 	DockerDelay   time.Duration
 	DockerNetwork string
+	// IsolateNetwork runs a native app inside a fresh network namespace on every run
+	IsolateNetwork bool
+	// OnStart is called with the pid of the app, or of its container, whenever it starts
+	OnStart func(pid uint32)
 }
 
 func (a *App) Setup(_ context.Context) error {
+	if a.runtime == utils.RuntimeNerdctl && a.kind != utils.Native {
+		return a.setupNerdctl()
+	}
 	d, err := docker.New(a.logger)
 	if err != nil {
 		return err
@@ -79,6 +98,15 @@ func (a *App) Setup(_ context.Context) error {
 		}
 	default:
 		// setup native binary
+		if a.isolateNetwork {
+			if _, err := exec.LookPath("ip"); err != nil {
+				return errors.New("the ip command (iproute2) is required to isolate the network of the app")
+			}
+			a.netNs = newNetNs(a.id)
+			// the app reaches the keploy proxy and is reached by the tests over the veth pair
+			a.keployIPv4 = a.netNs.hostIP
+			a.containerIPv4 = a.netNs.appIP
+		}
 	}
 	return nil
 }
@@ -91,6 +119,11 @@ func (a *App) ContainerIPv4Addr() string {
 	return a.containerIPv4
 }
 
+// IsolatedNetwork tells if the native app runs in its own network namespace
+func (a *App) IsolatedNetwork() bool {
+	return a.netNs != nil
+}
+
 func (a *App) SetupDocker() error {
 	var err error
 	cont, net, err := parseDockerCmd(a.cmd)
@@ -213,6 +246,11 @@ func (a *App) SetupCompose() error {
 	return nil
 }
 
+// Output returns the lines printed by the app from the start to the end time
+func (a *App) Output(from, to time.Time) []models.AppOutputLine {
+	return a.output.between(from, to)
+}
+
 func (a *App) Kind(_ context.Context) utils.CmdType {
 	return a.kind
 }
@@ -288,6 +326,9 @@ func (a *App) handleDockerEvents(ctx context.Context, e events.Message) (bool, e
 
 		a.inodeChan <- inode
 		a.logger.Debug("container started and successfully extracted inode", zap.Any("inode", inode))
+		if a.onStart != nil {
+			a.onStart(uint32(info.State.Pid))
+		}
 		if info.NetworkSettings == nil || info.NetworkSettings.Networks == nil {
 			a.logger.Debug("container network settings not available", zap.Any("containerDetails.NetworkSettings", info.NetworkSettings))
 			return false, nil
@@ -411,9 +452,13 @@ func (a *App) runDocker(ctx context.Context) models.AppError {
 	}
 }
 
-func (a *App) Run(ctx context.Context, inodeChan chan uint64) models.AppError {
+func (a *App) Run(ctx context.Context, inodeChan chan uint64, opts models.RunOptions) models.AppError {
 	a.inodeChan = inodeChan
+	a.env = opts.Env
 
+	if a.runtime == utils.RuntimeNerdctl && a.kind == utils.Docker {
+		return a.runNerdctl(ctx)
+	}
 	if a.kind == utils.DockerCompose || a.kind == utils.Docker {
 		return a.runDocker(ctx)
 	}
@@ -437,6 +482,17 @@ func (a *App) run(ctx context.Context) models.AppError {
 		cmd = exec.CommandContext(ctx, "sudo", "-E", "-u", os.Getenv("SUDO_USER"), "env", "PATH="+os.Getenv("PATH"), "sh", "-c", userCmd)
 	}
 
+	if a.netNs != nil {
+		err := a.netNs.create(a.logger)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to create the network namespace for the app")
+			return models.AppError{AppErrorType: models.ErrInternal, Err: err}
+		}
+		defer a.netNs.delete(a.logger)
+		args := a.netNs.wrap(cmd.Args...)
+		cmd = exec.CommandContext(ctx, args[0], args[1:]...)
+	}
+
 	// Set the cancel function for the command
 	cmd.Cancel = func() error {
 
@@ -449,9 +505,14 @@ func (a *App) run(ctx context.Context) models.AppError {
 		Setpgid: true,
 	}
 
-	// Set the output of the command
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// the variables of the run override the inherited ones, a docker command passes them to the container with -e NAME
+	if len(a.env) != 0 {
+		cmd.Env = append(os.Environ(), a.env...)
+	}
+
+	// Set the output of the command, its lines are kept to be attached to the failing test cases
+	cmd.Stdout = a.output.writer("stdout", os.Stdout)
+	cmd.Stderr = a.output.writer("stderr", os.Stderr)
 
 	a.logger.Debug("", zap.Any("executing cli", cmd.String()))
 
@@ -459,6 +520,9 @@ func (a *App) run(ctx context.Context) models.AppError {
 	if err != nil {
 		return models.AppError{AppErrorType: models.ErrCommandError, Err: err}
 	}
+	if a.onStart != nil {
+		a.onStart(uint32(cmd.Process.Pid))
+	}
 
 	err = cmd.Wait()
 	select {
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// nerdctl runs the containers on containerd, which has no docker api. Keploy runs on the host next to it, so the
+// proxy is reached by the app through the gateway of its network and the metadata of the container is read with
+// nerdctl inspect instead of the docker events.
+
+func (a *App) setupNerdctl() error {
+	if a.kind == utils.DockerCompose {
+		return errors.New("nerdctl compose isn't supported yet, run the app with nerdctl run instead")
+	}
+	cont, network, err := parseDockerCmd(a.cmd)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to parse the container and network names from the nerdctl command", zap.String("cmd", a.cmd))
+		return err
+	}
+	if a.container == "" {
+		a.container = cont
+	}
+	if a.containerNetwork == "" {
+		a.containerNetwork = network
+	}
+
+	out, err := exec.Command("nerdctl", "network", "inspect", a.containerNetwork, "--format", "{{range .IPAM.Config}}{{.Gateway}} {{end}}").Output()
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to inspect the network of the app", zap.String("network", a.containerNetwork))
+		return err
+	}
+	gateways := strings.Fields(string(out))
+	if len(gateways) == 0 {
+		return fmt.Errorf("failed to find the gateway of the network:%v", a.containerNetwork)
+	}
+	a.keployIPv4 = gateways[0]
+	a.logger.Debug("the app reaches keploy through the gateway of its network", zap.String("network", a.containerNetwork), zap.String("gateway", a.keployIPv4))
+	return nil
+}
+
+func (a *App) runNerdctl(ctx context.Context) models.AppError {
+	if a.cmd == "" {
+		return models.AppError{}
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	defer func() {
+		err := g.Wait()
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to run the nerdctl app")
+		}
+	}()
+
+	errCh := make(chan models.AppError, 1)
+	g.Go(func() error {
+		defer utils.Recover(a.logger)
+		errCh <- a.run(ctx)
+		return nil
+	})
+
+	metaErr := a.getNerdctlMeta(ctx)
+	if metaErr != nil && !errors.Is(metaErr, context.Canceled) {
+		return models.AppError{AppErrorType: models.ErrInternal, Err: metaErr}
+	}
+
+	select {
+	case appErr := <-errCh:
+		return appErr
+	case <-ctx.Done():
+		return models.AppError{AppErrorType: models.ErrCtxCanceled, Err: nil}
+	}
+}
+
+// getNerdctlMeta polls the container until it's started, then sends its inode to the hooks and keeps its ip
+func (a *App) getNerdctlMeta(ctx context.Context) error {
+	timer := time.NewTimer(a.containerDelay)
+	defer timer.Stop()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return errors.New("timeout waiting for the container to start")
+		case <-ticker.C:
+			out, err := exec.CommandContext(ctx, "nerdctl", "inspect", a.container, "--format", "{{.State.Pid}} {{.NetworkSettings.IPAddress}}").Output()
+			if err != nil {
+				a.logger.Debug("still waiting for the container to start.", zap.String("containerName", a.container))
+				continue
+			}
+			fields := strings.Fields(string(out))
+			if len(fields) < 2 {
+				continue
+			}
+			pid, err := strconv.Atoi(fields[0])
+			if err != nil || pid == 0 {
+				continue
+			}
+			inode, err := getInode(pid)
+			if err != nil {
+				return err
+			}
+			a.inodeChan <- inode
+			a.containerIPv4 = fields[1]
+			a.logger.Debug("container started and successfully extracted inode", zap.Any("inode", inode), zap.String("ip", a.containerIPv4))
+			return nil
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// the ephemeral ports of the network namespaces are taken from disjoint ranges below the default range of the host
+const (
+	nsPortBase  = 10000
+	nsPortRange = 1000
+	nsPortSlots = 22
+)
+
+// netNs is a dedicated network namespace for a native app, connected to the keploy host
+// through a veth pair. It is recreated on every run of the app so that leftover connections,
+// reused ephemeral ports and stray listeners of a previous run don't leak into the next one.
+// The apps run at once in their own namespaces share the hooks, which key the destinations of
+// the calls by their source port, so each namespace picks its source ports from its own range.
+type netNs struct {
+	name     string
+	hostVeth string
+	appVeth  string
+	hostIP   string
+	appIP    string
+	portLow  int
+	portHigh int
+}
+
+func newNetNs(id uint64) *netNs {
+	subnet := id % 256
+	return &netNs{
+		name:     fmt.Sprintf("keploy-%d", id),
+		hostVeth: fmt.Sprintf("kp%dh", id),
+		appVeth:  fmt.Sprintf("kp%da", id),
+		hostIP:   fmt.Sprintf("10.201.%d.1", subnet),
+		appIP:    fmt.Sprintf("10.201.%d.2", subnet),
+		portLow:  nsPortBase + int(id%nsPortSlots)*nsPortRange,
+		portHigh: nsPortBase + int(id%nsPortSlots+1)*nsPortRange - 1,
+	}
+}
+
+// create sets up the network namespace along with the veth pair, removing any leftover of a previous run first
+func (n *netNs) create(logger *zap.Logger) error {
+	n.delete(logger)
+
+	cmds := [][]string{
+		{"netns", "add", n.name},
+		{"link", "add", n.hostVeth, "type", "veth", "peer", "name", n.appVeth},
+		{"link", "set", n.appVeth, "netns", n.name},
+		{"addr", "add", n.hostIP + "/24", "dev", n.hostVeth},
+		{"link", "set", n.hostVeth, "up"},
+		{"netns", "exec", n.name, "ip", "addr", "add", n.appIP + "/24", "dev", n.appVeth},
+		{"netns", "exec", n.name, "ip", "link", "set", n.appVeth, "up"},
+		{"netns", "exec", n.name, "ip", "link", "set", "lo", "up"},
+		{"netns", "exec", n.name, "ip", "route", "add", "default", "via", n.hostIP},
+		{"netns", "exec", n.name, "sysctl", "-q", "-w", fmt.Sprintf("net.ipv4.ip_local_port_range=%d %d", n.portLow, n.portHigh)},
+	}
+	for _, args := range cmds {
+		err := runIP(args...)
+		if err != nil {
+			n.delete(logger)
+			return err
+		}
+	}
+	logger.Debug("created the network namespace for the app", zap.String("netns", n.name), zap.String("appIP", n.appIP), zap.Int("portLow", n.portLow), zap.Int("portHigh", n.portHigh))
+	return nil
+}
+
+// delete removes the network namespace, the veth pair is removed along with it
+func (n *netNs) delete(logger *zap.Logger) {
+	err := runIP("netns", "delete", n.name)
+	if err != nil {
+		logger.Debug("failed to delete the network namespace of the app", zap.String("netns", n.name), zap.Error(err))
+	}
+	// the host end of the veth pair outlives the namespace if the peer was never moved into it
+	_ = runIP("link", "delete", n.hostVeth)
+}
+
+// wrap prefixes the command args to run them inside the network namespace
+func (n *netNs) wrap(args ...string) []string {
+	return append([]string{"ip", "netns", "exec", n.name}, args...)
+}
+
+func runIP(args ...string) error {
+	out, err := exec.Command("ip", args...).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			return err
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// maxOutputLines bounds the lines of the app output kept in memory, the oldest are dropped first
+const maxOutputLines = 10000
+
+// output keeps the last lines written by the app on its stdout and stderr with the time they were written,
+// so that the lines printed while a test case ran can be attached to its result.
+type output struct {
+	mu    sync.Mutex
+	lines []models.AppOutputLine
+}
+
+func newOutput() *output {
+	return &output{}
+}
+
+// writer returns a writer of the stream which forwards the output to w besides keeping its lines
+func (o *output) writer(stream string, w io.Writer) io.Writer {
+	return io.MultiWriter(w, &streamWriter{out: o, stream: stream})
+}
+
+func (o *output) add(line models.AppOutputLine) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lines = append(o.lines, line)
+	if len(o.lines) > maxOutputLines {
+		o.lines = append([]models.AppOutputLine{}, o.lines[len(o.lines)-maxOutputLines:]...)
+	}
+}
+
+// between returns the lines written from the start to the end time, both included
+func (o *output) between(from, to time.Time) []models.AppOutputLine {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var lines []models.AppOutputLine
+	for _, l := range o.lines {
+		if l.Time >= from.UnixMilli() && l.Time <= to.UnixMilli() {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// streamWriter splits the output of a stream into lines, a partial line is kept until its end is written
+type streamWriter struct {
+	out     *output
+	stream  string
+	pending []byte
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	s.pending = append(s.pending, p...)
+	for {
+		i := bytes.IndexByte(s.pending, '\n')
+		if i < 0 {
+			break
+		}
+		s.out.add(models.AppOutputLine{
+			Time:   time.Now().UnixMilli(),
+			Stream: s.stream,
+			Line:   string(bytes.TrimSuffix(s.pending[:i], []byte("\r"))),
+		})
+		s.pending = s.pending[i+1:]
+	}
+	return len(p), nil
+}
@@ -8,9 +8,11 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/core/app"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -25,13 +27,17 @@ type Core struct {
 	apps          sync.Map
 	proxyStarted  bool
 	hostConfigStr string // hosts string in the nsswitch.conf of linux system. To restore the system hosts configuration after completion of test
+	proxyPort     uint32 // port of the proxy, switched to a free one if the configured port is taken
+	dnsPort       uint32 // port of the dns servers, switched to a free one if the configured port is taken
 }
 
-func New(logger *zap.Logger, hook Hooks, proxy Proxy) *Core {
+func New(logger *zap.Logger, hook Hooks, proxy Proxy, cfg config.Config) *Core {
 	return &Core{
-		logger: logger,
-		Hooks:  hook,
-		Proxy:  proxy,
+		logger:    logger,
+		Hooks:     hook,
+		Proxy:     proxy,
+		proxyPort: cfg.ProxyPort,
+		dnsPort:   cfg.DNSPort,
 	}
 }
 
@@ -39,9 +45,16 @@ func (c *Core) Setup(ctx context.Context, cmd string, opts models.SetupOptions)
 	// create a new app and store it in the map
 	id := uint64(c.id.Next())
 	a := app.NewApp(c.logger, id, cmd, app.Options{
-		DockerNetwork: opts.DockerNetwork,
-		Container:     opts.Container,
-		DockerDelay:   opts.DockerDelay,
+		DockerNetwork:  opts.DockerNetwork,
+		Container:      opts.Container,
+		DockerDelay:    opts.DockerDelay,
+		IsolateNetwork: opts.IsolateNetwork,
+		// the calls over the unix sockets are told apart by the pid of the app
+		OnStart: func(pid uint32) {
+			if err := c.Proxy.SetAppPid(ctx, id, pid); err != nil {
+				utils.LogError(c.logger, err, "failed to set the pid of the app in the proxy", zap.Uint64("appID", id))
+			}
+		},
 	})
 	c.apps.Store(id, a)
 
@@ -90,6 +103,14 @@ func (c *Core) Hook(ctx context.Context, id uint64, opts models.HookOptions) err
 	default:
 	}
 
+	// the apps run in parallel in their own network namespaces share the hooks and the proxy of the first one. The
+	// namespaces take their source ports from disjoint ranges, so the destinations the hooks key by the source port
+	// don't collide, and the proxy tells the apps of the calls apart by the ip of their namespace.
+	if c.proxyStarted && a.IsolatedNetwork() {
+		c.logger.Debug("sharing the hooks and the proxy with the app", zap.Uint64("appID", id), zap.String("ip", a.ContainerIPv4Addr()))
+		return c.Proxy.SetAppIP(ctx, id, a.ContainerIPv4Addr())
+	}
+
 	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
 	if !ok {
 		return errors.New("failed to get the error group from the context")
@@ -132,12 +153,22 @@ func (c *Core) Hook(ctx context.Context, id uint64, opts models.HookOptions) err
 		return nil
 	})
 
+	if !c.proxyStarted {
+		err = c.selectPorts()
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to select the ports for the proxy")
+			return hookErr
+		}
+	}
+
 	//load hooks
 	err = c.Hooks.Load(hookCtx, id, HookCfg{
 		AppID:      id,
 		Pid:        0,
 		IsDocker:   isDocker,
 		KeployIPV4: a.KeployIPv4Addr(),
+		ProxyPort:  c.proxyPort,
+		DNSPort:    c.dnsPort,
 	})
 	if err != nil {
 		utils.LogError(c.logger, err, "failed to load hooks")
@@ -159,10 +190,16 @@ func (c *Core) Hook(ctx context.Context, id uint64, opts models.HookOptions) err
 	// if there is another containerized app, then we need to pass new (ip:port) of proxy to the eBPF
 	// as the network namespace is different for each container and so is the keploy/proxy IP to communicate with the app.
 	// start proxy
-	err = c.Proxy.StartProxy(proxyCtx, ProxyOptions{
+	proxyOpts := ProxyOptions{
 		DNSIPv4Addr: a.KeployIPv4Addr(),
-		//DnsIPv6Addr: ""
-	})
+		Port:        c.proxyPort,
+		DNSPort:     c.dnsPort,
+	}
+	if a.KeployIPv4Addr() != "" {
+		// answer the AAAA queries with the ipv4-mapped address of the keploy container
+		proxyOpts.DNSIPv6Addr = "::ffff:" + a.KeployIPv4Addr()
+	}
+	err = c.Proxy.StartProxy(proxyCtx, proxyOpts)
 	if err != nil {
 		utils.LogError(c.logger, err, "failed to start proxy")
 		return hookErr
@@ -181,7 +218,30 @@ func (c *Core) Hook(ctx context.Context, id uint64, opts models.HookOptions) err
 	return nil
 }
 
-func (c *Core) Run(ctx context.Context, id uint64, _ models.RunOptions) models.AppError {
+// selectPorts switches the proxy and dns ports to free ones if the configured ports are already taken,
+// e.g. by another keploy instance on a shared CI runner.
+func (c *Core) selectPorts() error {
+	proxyPort, err := getAvailablePort(c.proxyPort, false)
+	if err != nil {
+		return fmt.Errorf("failed to find a free port for the proxy: %w", err)
+	}
+	if proxyPort != c.proxyPort {
+		c.logger.Warn(fmt.Sprintf("proxy port %d is already in use, using port %d instead", c.proxyPort, proxyPort))
+		c.proxyPort = proxyPort
+	}
+
+	dnsPort, err := getAvailablePort(c.dnsPort, true)
+	if err != nil {
+		return fmt.Errorf("failed to find a free port for the dns server: %w", err)
+	}
+	if dnsPort != c.dnsPort {
+		c.logger.Warn(fmt.Sprintf("dns port %d is already in use, using port %d instead", c.dnsPort, dnsPort))
+		c.dnsPort = dnsPort
+	}
+	return nil
+}
+
+func (c *Core) Run(ctx context.Context, id uint64, opts models.RunOptions) models.AppError {
 	a, err := c.getApp(id)
 	if err != nil {
 		utils.LogError(c.logger, err, "failed to get app")
@@ -225,7 +285,7 @@ func (c *Core) Run(ctx context.Context, id uint64, _ models.RunOptions) models.A
 	runAppErrGrp.Go(func() error {
 		defer utils.Recover(c.logger)
 		defer close(appErrCh)
-		appErr := a.Run(runAppCtx, inodeChan)
+		appErr := a.Run(runAppCtx, inodeChan, opts)
 		if appErr.Err != nil {
 			utils.LogError(c.logger, appErr, "error while running the app")
 			appErrCh <- appErr
@@ -243,6 +303,16 @@ func (c *Core) Run(ctx context.Context, id uint64, _ models.RunOptions) models.A
 	}
 }
 
+// GetAppOutput returns the lines printed by the app on its stdout and stderr from the start to the end time
+func (c *Core) GetAppOutput(_ context.Context, id uint64, from, to time.Time) ([]models.AppOutputLine, error) {
+	a, err := c.getApp(id)
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to get app")
+		return nil, err
+	}
+	return a.Output(from, to), nil
+}
+
 func (c *Core) GetAppIP(_ context.Context, id uint64) (string, error) {
 
 	a, err := c.getApp(id)
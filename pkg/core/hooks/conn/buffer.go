@@ -0,0 +1,138 @@
+package conn
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// BufferStats counts what the trackers did to stay within their memory limits
+type BufferStats struct {
+	// Spilled is the number of messages moved to the disk and SpilledBytes their size
+	Spilled      int64
+	SpilledBytes int64
+	// Evicted is the number of connections whose buffered calls were dropped
+	Evicted int64
+}
+
+// budget accounts the memory held by the messages of all the trackers of a factory
+type budget struct {
+	limits config.TrackerBuffers
+	used   atomic.Int64
+
+	spilled      atomic.Int64
+	spilledBytes atomic.Int64
+	evicted      atomic.Int64
+	// warned is set once the user has been told about the first eviction
+	warned atomic.Bool
+}
+
+func newBudget(limits config.TrackerBuffers) *budget {
+	return &budget{limits: limits}
+}
+
+// reserve takes n bytes from the budget for a connection already holding connUsed bytes in memory
+func (b *budget) reserve(connUsed, n int64) bool {
+	if b.limits.ConnSize > 0 && connUsed+n > b.limits.ConnSize {
+		return false
+	}
+	if b.limits.TotalSize > 0 && b.used.Add(n) > b.limits.TotalSize {
+		b.used.Add(-n)
+		return false
+	}
+	if b.limits.TotalSize <= 0 {
+		b.used.Add(n)
+	}
+	return true
+}
+
+func (b *budget) release(n int64) {
+	b.used.Add(-n)
+}
+
+func (b *budget) stats() BufferStats {
+	return BufferStats{
+		Spilled:      b.spilled.Load(),
+		SpilledBytes: b.spilledBytes.Load(),
+		Evicted:      b.evicted.Load(),
+	}
+}
+
+// message holds the data of a request or a response, in memory until it outgrows the budget and in a file after
+type message struct {
+	data []byte
+	file *os.File
+	size int64
+}
+
+func newMessage() *message {
+	return &message{data: []byte{}}
+}
+
+// bytes returns the data of the message, read back from the disk if it was spilled
+func (m *message) bytes() ([]byte, error) {
+	if m.file == nil {
+		return m.data, nil
+	}
+	_, err := m.file.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(m.file)
+}
+
+// spill moves the data of the message to a file in the directory
+func (m *message) spill(logger *zap.Logger, dir string) error {
+	file, err := os.CreateTemp(dir, "keploy-conn-*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(m.data)
+	if err != nil {
+		closeSpill(logger, file)
+		return err
+	}
+	m.file = file
+	m.data = nil
+	return nil
+}
+
+func (m *message) write(chunk []byte) error {
+	m.size += int64(len(chunk))
+	if m.file != nil {
+		_, err := m.file.Write(chunk)
+		return err
+	}
+	m.data = append(m.data, chunk...)
+	return nil
+}
+
+// memSize is the number of bytes of the message held in memory
+func (m *message) memSize() int64 {
+	if m.file != nil {
+		return 0
+	}
+	return int64(len(m.data))
+}
+
+// discard removes the spilled file of the message
+func (m *message) discard(logger *zap.Logger) {
+	if m.file != nil {
+		closeSpill(logger, m.file)
+		m.file = nil
+	}
+	m.data = nil
+}
+
+func closeSpill(logger *zap.Logger, file *os.File) {
+	if err := file.Close(); err != nil {
+		utils.LogError(logger, err, "failed to close the spilled message", zap.String("file", file.Name()))
+	}
+	if err := os.Remove(file.Name()); err != nil {
+		utils.LogError(logger, err, "failed to remove the spilled message", zap.String("file", file.Name()))
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -21,20 +23,87 @@ var Emoji = "\U0001F430" + " Keploy:"
 type Factory struct {
 	connections         map[ID]*Tracker
 	inactivityThreshold time.Duration
+	timeouts            config.TrackerTimeouts
 	mutex               *sync.RWMutex
 	logger              *zap.Logger
+	// maxConnections caps the number of trackers, the least recently active one is evicted past it
+	maxConnections int
+	budget         *budget
 }
 
+// Defaults of the tracker timeouts
+const (
+	DefaultInactivityThreshold   = time.Minute
+	DefaultSweepInterval         = 100 * time.Millisecond
+	DefaultResponseIdle          = 2 * time.Second
+	DefaultStreamingResponseIdle = 30 * time.Second
+)
+
 // NewFactory creates a new instance of the factory.
-func NewFactory(inactivityThreshold time.Duration, logger *zap.Logger) *Factory {
+func NewFactory(timeouts config.TrackerTimeouts, limits config.TrackerBuffers, logger *zap.Logger) *Factory {
+	if limits.SpillDir == "" {
+		limits.SpillDir = os.TempDir()
+	}
+	if timeouts.InactivityThreshold <= 0 {
+		timeouts.InactivityThreshold = DefaultInactivityThreshold
+	}
+	if timeouts.SweepInterval <= 0 {
+		timeouts.SweepInterval = DefaultSweepInterval
+	}
+	if timeouts.ResponseIdle <= 0 {
+		timeouts.ResponseIdle = DefaultResponseIdle
+	}
+	if timeouts.StreamingResponseIdle <= 0 {
+		timeouts.StreamingResponseIdle = DefaultStreamingResponseIdle
+	}
+	// a connection mustn't be dropped while its response may still be coming
+	if timeouts.InactivityThreshold < timeouts.StreamingResponseIdle {
+		logger.Warn("the tracker inactivity threshold is shorter than the streaming response idle time, raising it", zap.Duration("inactivityThreshold", timeouts.InactivityThreshold), zap.Duration("streamingResponseIdle", timeouts.StreamingResponseIdle))
+		timeouts.InactivityThreshold = timeouts.StreamingResponseIdle
+	}
 	return &Factory{
 		connections:         make(map[ID]*Tracker),
 		mutex:               &sync.RWMutex{},
-		inactivityThreshold: inactivityThreshold,
+		inactivityThreshold: timeouts.InactivityThreshold,
+		timeouts:            timeouts,
 		logger:              logger,
+		maxConnections:      limits.MaxConnections,
+		budget:              newBudget(limits),
 	}
 }
 
+// SweepInterval is how often the trackers should be processed
+func (factory *Factory) SweepInterval() time.Duration {
+	return factory.timeouts.SweepInterval
+}
+
+// Stats returns the number of messages spilled to the disk and of connections evicted to stay within the limits
+func (factory *Factory) Stats() BufferStats {
+	return factory.budget.stats()
+}
+
+// Flush makes the trackers complete their current responses without waiting for the idle time
+func (factory *Factory) Flush() {
+	factory.mutex.RLock()
+	defer factory.mutex.RUnlock()
+	for _, tracker := range factory.connections {
+		tracker.flush()
+	}
+}
+
+// InFlight returns the number of conns holding a call which hasn't been captured yet
+func (factory *Factory) InFlight() int {
+	factory.mutex.RLock()
+	defer factory.mutex.RUnlock()
+	n := 0
+	for _, tracker := range factory.connections {
+		if tracker.inFlight() {
+			n++
+		}
+	}
+	return n
+}
+
 // ProcessActiveTrackers iterates over all conn the trackers and checks if they are complete. If so, it captures the ingress call and
 // deletes the tracker. If the tracker is inactive for a long time, it deletes it.
 func (factory *Factory) ProcessActiveTrackers(ctx context.Context, t chan *models.TestCase) {
@@ -46,6 +115,11 @@ func (factory *Factory) ProcessActiveTrackers(ctx context.Context, t chan *model
 		case <-ctx.Done():
 			return
 		default:
+			if ws := tracker.completedWebSocket(factory.inactivityThreshold); ws != nil {
+				captureWebSocket(ctx, factory.logger, t, ws)
+				trackersToDelete = append(trackersToDelete, connID)
+				continue
+			}
 			ok, requestBuf, responseBuf, reqTimestampTest, resTimestampTest := tracker.IsComplete()
 			if ok {
 
@@ -74,6 +148,7 @@ func (factory *Factory) ProcessActiveTrackers(ctx context.Context, t chan *model
 
 	// Delete all the processed trackers.
 	for _, key := range trackersToDelete {
+		factory.connections[key].Release()
 		delete(factory.connections, key)
 	}
 }
@@ -85,18 +160,77 @@ func (factory *Factory) GetOrCreate(connectionID ID) *Tracker {
 	defer factory.mutex.Unlock()
 	tracker, ok := factory.connections[connectionID]
 	if !ok {
-		factory.connections[connectionID] = NewTracker(connectionID, factory.logger)
+		if factory.maxConnections > 0 && len(factory.connections) >= factory.maxConnections {
+			factory.evictLeastActive()
+		}
+		factory.connections[connectionID] = NewTracker(connectionID, factory.logger, factory.budget, factory.timeouts)
 		return factory.connections[connectionID]
 	}
 	return tracker
 }
 
+// evictLeastActive drops the tracker which has been inactive the longest to make room for a new conn
+func (factory *Factory) evictLeastActive() {
+	var oldestID ID
+	var oldest *Tracker
+	var oldestActivity uint64
+	for connID, tracker := range factory.connections {
+		activity := tracker.lastActivity()
+		if oldest == nil || activity < oldestActivity {
+			oldestID, oldest, oldestActivity = connID, tracker, activity
+		}
+	}
+	if oldest == nil {
+		return
+	}
+	oldest.Release()
+	delete(factory.connections, oldestID)
+	factory.budget.evicted.Add(1)
+	factory.logger.Debug("evicted the least recently active connection", zap.Any("connectionID", oldestID), zap.Int("maxConnections", factory.maxConnections))
+}
+
 func capture(_ context.Context, logger *zap.Logger, t chan *models.TestCase, req *http.Request, resp *http.Response, reqTimeTest time.Time, resTimeTest time.Time) {
-	reqBody, err := io.ReadAll(req.Body)
+	tc, err := testCaseOf(logger, req, resp, reqTimeTest, resTimeTest)
 	if err != nil {
-		utils.LogError(logger, err, "failed to read the http request body")
+		utils.LogError(logger, err, "failed to record the ingress requests")
 		return
 	}
+	t <- tc
+}
+
+// captureWebSocket records the websocket session as a test case of its handshake and its messages, the outgoing
+// calls made until its last message being its mocks
+func captureWebSocket(_ context.Context, logger *zap.Logger, t chan *models.TestCase, ws *wsSession) {
+	req, err := pkg.ParseHTTPRequest(ws.req)
+	if err != nil {
+		utils.LogError(logger, err, "failed to parse the websocket upgrade request")
+		return
+	}
+	resp, err := pkg.ParseHTTPResponse(ws.resp, req)
+	if err != nil {
+		utils.LogError(logger, err, "failed to parse the websocket upgrade response")
+		return
+	}
+	end := ws.respTime
+	if n := len(ws.frames); n > 0 {
+		end = ws.frames[n-1].Timestamp
+	}
+	tc, err := testCaseOf(logger, req, resp, ws.reqTime, end)
+	if err != nil {
+		utils.LogError(logger, err, "failed to record the websocket connection")
+		return
+	}
+	tc.Kind = models.WEBSOCKET
+	tc.Frames = ws.frames
+	t <- tc
+}
+
+// testCaseOf returns the http test case of the request and its response
+func testCaseOf(logger *zap.Logger, req *http.Request, resp *http.Response, reqTimeTest time.Time, resTimeTest time.Time) (*models.TestCase, error) {
+	reqBody, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the http request body: %w", err)
+	}
 
 	defer func() {
 		err := resp.Body.Close()
@@ -107,10 +241,9 @@ func capture(_ context.Context, logger *zap.Logger, t chan *models.TestCase, req
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		utils.LogError(logger, err, "failed to read the http response body")
-		return
+		return nil, fmt.Errorf("failed to read the http response body: %w", err)
 	}
-	t <- &models.TestCase{
+	return &models.TestCase{
 		Version: models.GetVersion(),
 		Name:    pkg.ToYamlHTTPHeader(req.Header)["Keploy-Test-Name"],
 		Kind:    models.HTTP,
@@ -137,9 +270,5 @@ func capture(_ context.Context, logger *zap.Logger, t chan *models.TestCase, req
 		},
 		Noise: map[string][]string{},
 		// Mocks: mocks,
-	}
-	if err != nil {
-		utils.LogError(logger, err, "failed to record the ingress requests")
-		return
-	}
+	}, nil
 }
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/cilium/ebpf"
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
 
@@ -24,22 +25,29 @@ import (
 var eventAttributesSize = int(unsafe.Sizeof(SocketDataEvent{}))
 
 // ListenSocket starts the socket event listeners
-func ListenSocket(ctx context.Context, l *zap.Logger, openMap, dataMap, closeMap *ebpf.Map) (<-chan *models.TestCase, error) {
+func ListenSocket(ctx context.Context, l *zap.Logger, timeouts config.TrackerTimeouts, limits config.TrackerBuffers, openMap, dataMap, closeMap *ebpf.Map) (<-chan *models.TestCase, error) {
 	t := make(chan *models.TestCase, 500)
 	err := initRealTimeOffset()
 	if err != nil {
 		utils.LogError(l, err, "failed to initialize real time offset")
 		return nil, errors.New("failed to start socket listeners")
 	}
-	c := NewFactory(time.Minute, l)
+	c := NewFactory(timeouts, limits, l)
 	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
 	if !ok {
 		return nil, errors.New("failed to get the error group from the context")
 	}
+	// the calls in flight when keploy is stopped are captured before the hooks are unloaded
+	unregister := utils.RegisterDrain(func(drainCtx context.Context) {
+		drain(drainCtx, l, c)
+	})
 	g.Go(func() error {
 		defer utils.Recover(l)
+		defer unregister()
+		done := make(chan struct{})
 		go func() {
 			defer utils.Recover(l)
+			defer close(done)
 			for {
 				select {
 				case <-ctx.Done():
@@ -47,12 +55,16 @@ func ListenSocket(ctx context.Context, l *zap.Logger, openMap, dataMap, closeMap
 				default:
 					// TODO refactor this to directly consume the events from the maps
 					c.ProcessActiveTrackers(ctx, t)
-					time.Sleep(100 * time.Millisecond)
+					time.Sleep(c.SweepInterval())
 				}
 			}
 		}()
 		<-ctx.Done()
+		<-done
 		close(t)
+		if stats := c.Stats(); stats.Spilled > 0 || stats.Evicted > 0 {
+			l.Info("connection buffers exceeded the memory limits while recording", zap.Int64("spilledMessages", stats.Spilled), zap.Int64("spilledBytes", stats.SpilledBytes), zap.Int64("evictedConnections", stats.Evicted))
+		}
 		return nil
 	})
 
@@ -74,6 +86,28 @@ func ListenSocket(ctx context.Context, l *zap.Logger, openMap, dataMap, closeMap
 	return t, err
 }
 
+// drain flushes the trackers and waits for their calls to be captured
+func drain(ctx context.Context, l *zap.Logger, c *Factory) {
+	// the events already in the buffers of the kernel reach the trackers first
+	select {
+	case <-time.After(c.SweepInterval()):
+	case <-ctx.Done():
+	}
+	c.Flush()
+	for {
+		n := c.InFlight()
+		if n == 0 {
+			return
+		}
+		select {
+		case <-time.After(c.SweepInterval()):
+		case <-ctx.Done():
+			l.Warn("dropped the calls still in flight after draining", zap.Int("connections", n))
+			return
+		}
+	}
+}
+
 func open(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
 
 	r, err := perf.NewReader(m, os.Getpagesize())
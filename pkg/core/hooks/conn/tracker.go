@@ -1,12 +1,14 @@
 package conn
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
@@ -37,16 +39,27 @@ type Tracker struct {
 	// userReqSizes is a slice of the total number of Request bytes received in the user side
 	userReqSizes []uint64
 	// userRespBufs is a slice of the Response data received in the user side on this conn
-	userResps [][]byte
+	userResps []*message
 	// userReqBufs is a slice of the Request data received in the user side on this conn
-	userReqs [][]byte
+	userReqs []*message
 
 	// req and resp are the buffers to store the request and response data for the current request
 	// reset after 2 seconds of inactivity
 	respSize uint64
 	reqSize  uint64
-	resp     []byte
-	req      []byte
+	resp     *message
+	req      *message
+
+	// budget bounds the memory of the buffered messages, memUsed being the share of this conn
+	budget  *budget
+	memUsed int64
+
+	// timeouts gives the idle time after which the current response is taken as complete,
+	// longer when streamed is set for the server-sent events, chunked and grpc responses
+	timeouts config.TrackerTimeouts
+	streamed bool
+	// flushing is set while keploy drains, the current response is then complete without waiting for the idle time
+	flushing bool
 
 	// Additional fields to know when to capture request or response info
 	// reset after 2 seconds of inactivity
@@ -64,30 +77,50 @@ type Tracker struct {
 
 	reqTimestamps []time.Time
 	isNewRequest  bool
+
+	// ws is the websocket session of the conn once a request upgraded it
+	ws *wsSession
 }
 
-func NewTracker(connID ID, logger *zap.Logger) *Tracker {
+func NewTracker(connID ID, logger *zap.Logger, budget *budget, timeouts config.TrackerTimeouts) *Tracker {
 	return &Tracker{
 		connID:          connID,
-		req:             []byte{},
-		resp:            []byte{},
+		req:             newMessage(),
+		resp:            newMessage(),
 		kernelRespSizes: []uint64{},
 		kernelReqSizes:  []uint64{},
 		userRespSizes:   []uint64{},
 		userReqSizes:    []uint64{},
-		userResps:       [][]byte{},
-		userReqs:        [][]byte{},
+		userResps:       []*message{},
+		userReqs:        []*message{},
 		mutex:           sync.RWMutex{},
 		logger:          logger,
 		firstRequest:    true,
 		isNewRequest:    true,
+		budget:          budget,
+		timeouts:        timeouts,
 	}
 }
 
 func (conn *Tracker) ToBytes() ([]byte, []byte) {
 	conn.mutex.RLock()
 	defer conn.mutex.RUnlock()
-	return conn.req, conn.resp
+	req, err := conn.req.bytes()
+	if err != nil {
+		utils.LogError(conn.logger, err, "failed to read the buffered request")
+	}
+	resp, err := conn.resp.bytes()
+	if err != nil {
+		utils.LogError(conn.logger, err, "failed to read the buffered response")
+	}
+	return req, resp
+}
+
+// lastActivity returns the time of the last event of the conn in nanoseconds
+func (conn *Tracker) lastActivity() uint64 {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+	return conn.lastActivityTimestamp
 }
 
 func (conn *Tracker) IsInactive(duration time.Duration) bool {
@@ -96,6 +129,20 @@ func (conn *Tracker) IsInactive(duration time.Duration) bool {
 	return uint64(time.Now().UnixNano())-conn.lastActivityTimestamp > uint64(duration.Nanoseconds())
 }
 
+// flush stops waiting for more of the current response, the app being stopped
+func (conn *Tracker) flush() {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	conn.flushing = true
+}
+
+// inFlight tells if the conn holds a call which hasn't been captured yet
+func (conn *Tracker) inFlight() bool {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+	return atomic.LoadInt32(&conn.recTestCounter) > 0 || conn.lastChunkWasResp
+}
+
 func (conn *Tracker) incRecordTestCount() {
 	atomic.AddInt32(&conn.recTestCounter, 1)
 }
@@ -115,8 +162,15 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 	// Calculate the time elapsed since the last activity in nanoseconds.
 	elapsedTime := currentTimestamp - conn.lastActivityTimestamp
 
-	//Caveat: Added a timeout of 4 seconds, after this duration we assume that the last response data event would have come.
+	//Caveat: Added an idle timeout (2 seconds by default), after this duration we assume that the last response data event would have come.
 	// This will ensure that we capture the requests responses where Connection:keep-alive is enabled.
+	responseIdle := conn.timeouts.ResponseIdle
+	if conn.streamed {
+		responseIdle = conn.timeouts.StreamingResponseIdle
+	}
+	if conn.flushing {
+		responseIdle = 0
+	}
 
 	recordTraffic := false
 
@@ -162,8 +216,8 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 			}
 
 			if len(conn.userReqs) > 0 && len(conn.userResps) > 0 { //validated request, response
-				requestBuf = conn.userReqs[0]
-				responseBuf = conn.userResps[0]
+				requestBuf = conn.take(conn.userReqs[0])
+				responseBuf = conn.take(conn.userResps[0])
 
 				//popping out the current request & response data
 				conn.userReqs = conn.userReqs[1:]
@@ -183,7 +237,7 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 		// // decrease the recTestCounter
 		conn.decRecordTestCount()
 		conn.logger.Debug("verified recording", zap.Any("recordTraffic", recordTraffic))
-	} else if conn.lastChunkWasResp && elapsedTime >= uint64(time.Second*2) { // Check if 2 seconds has passed since the last activity.
+	} else if conn.lastChunkWasResp && elapsedTime >= uint64(responseIdle) { // Check if the response idle time has passed since the last activity.
 		conn.logger.Debug("might be last request on the conn")
 
 		if len(conn.userReqSizes) > 0 && len(conn.kernelReqSizes) > 0 {
@@ -207,11 +261,11 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 			}
 
 			if len(conn.userReqs) > 0 { //validated request, invalided response
-				requestBuf = conn.userReqs[0]
+				requestBuf = conn.take(conn.userReqs[0])
 				//popping out the current request data
 				conn.userReqs = conn.userReqs[1:]
 
-				responseBuf = conn.resp
+				responseBuf = conn.take(conn.resp)
 				respTimestamp = time.Now()
 			} else {
 				conn.logger.Debug("no data buffer for request", zap.Any("Length of RecvBufQueue", len(conn.userReqs)))
@@ -224,7 +278,7 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 		}
 
 		conn.logger.Debug(fmt.Sprintf("recording traffic after verifying the request data (but not response data):%v", recordTraffic))
-		//treat immediate next request as first request (2 seconds after last activity)
+		//treat immediate next request as first request (response idle time after last activity)
 		// this can be to avoid potential corruption in the conn
 		conn.reset()
 
@@ -260,10 +314,108 @@ func (conn *Tracker) reset() {
 	conn.firstRequest = true
 	conn.lastChunkWasResp = false
 	conn.lastChunkWasReq = false
+	conn.streamed = false
 	conn.reqSize = 0
 	conn.respSize = 0
-	conn.resp = []byte{}
-	conn.req = []byte{}
+	conn.free(conn.resp)
+	conn.free(conn.req)
+	conn.resp = newMessage()
+	conn.req = newMessage()
+}
+
+// write appends the chunk to the message within the memory limits. Past them the message is spilled to
+// the disk, or the buffered calls of the conn are evicted if it can't be.
+func (conn *Tracker) write(msg *message, chunk []byte) {
+	n := int64(len(chunk))
+	if msg.file == nil && !conn.budget.reserve(conn.memUsed, n) {
+		if !conn.spill(msg) {
+			conn.evict()
+			return
+		}
+	}
+	if msg.file == nil {
+		conn.memUsed += n
+	} else {
+		conn.budget.spilledBytes.Add(n)
+	}
+	if err := msg.write(chunk); err != nil {
+		utils.LogError(conn.logger, err, "failed to buffer the message", zap.Any("connectionID", conn.connID))
+		conn.evict()
+	}
+}
+
+// spill moves the message out of the memory of the conn, if spilling is enabled
+func (conn *Tracker) spill(msg *message) bool {
+	if !conn.budget.limits.Spill {
+		return false
+	}
+	held := msg.memSize()
+	if err := msg.spill(conn.logger, conn.budget.limits.SpillDir); err != nil {
+		utils.LogError(conn.logger, err, "failed to spill the message to the disk", zap.String("dir", conn.budget.limits.SpillDir))
+		return false
+	}
+	conn.memUsed -= held
+	conn.budget.release(held)
+	conn.budget.spilled.Add(1)
+	conn.budget.spilledBytes.Add(held)
+	conn.logger.Debug("spilled a message to the disk", zap.Any("connectionID", conn.connID), zap.Int64("size", msg.size))
+	return true
+}
+
+// evict drops the calls buffered on the conn, the next calls on it are captured afresh
+func (conn *Tracker) evict() {
+	conn.release()
+	conn.kernelReqSizes, conn.kernelRespSizes = []uint64{}, []uint64{}
+	conn.userReqSizes, conn.userRespSizes = []uint64{}, []uint64{}
+	conn.reqTimestamps = nil
+	atomic.StoreInt32(&conn.recTestCounter, 0)
+	conn.isNewRequest = true
+	conn.reset()
+
+	conn.budget.evicted.Add(1)
+	if conn.budget.warned.CompareAndSwap(false, true) {
+		conn.logger.Warn("dropped the buffered calls of a connection to stay within the memory limits, some test cases may be missing. Raise record.buffers in the config to keep them", zap.Int64("connSize", conn.budget.limits.ConnSize), zap.Int64("totalSize", conn.budget.limits.TotalSize))
+	}
+	conn.logger.Debug("evicted the buffered calls of the connection", zap.Any("connectionID", conn.connID))
+}
+
+// release frees the memory and the spilled files of all the messages of the conn
+func (conn *Tracker) release() {
+	for _, msg := range conn.userReqs {
+		conn.free(msg)
+	}
+	for _, msg := range conn.userResps {
+		conn.free(msg)
+	}
+	conn.userReqs, conn.userResps = []*message{}, []*message{}
+	conn.free(conn.req)
+	conn.free(conn.resp)
+	conn.req, conn.resp = newMessage(), newMessage()
+	conn.releaseWebSocket()
+}
+
+func (conn *Tracker) free(msg *message) {
+	held := msg.memSize()
+	conn.memUsed -= held
+	conn.budget.release(held)
+	msg.discard(conn.logger)
+}
+
+// take returns the data of the message and frees it
+func (conn *Tracker) take(msg *message) []byte {
+	data, err := msg.bytes()
+	if err != nil {
+		utils.LogError(conn.logger, err, "failed to read the spilled message", zap.Any("connectionID", conn.connID))
+	}
+	conn.free(msg)
+	return data
+}
+
+// Release frees the buffers of the conn once it isn't tracked anymore
+func (conn *Tracker) Release() {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	conn.release()
 }
 
 func (conn *Tracker) verifyRequestData(expectedRecvBytes, actualRecvBytes uint64) bool {
@@ -293,6 +445,21 @@ func (conn *Tracker) AddDataEvent(event SocketDataEvent) {
 
 	conn.logger.Debug(fmt.Sprintf("Got a data event from eBPF, Direction:%v || current Event Size:%v || ConnectionID:%v\n", event.Direction, event.MsgSize, event.ConnID))
 
+	if conn.ws != nil {
+		if event.MsgSize > EventBodyMaxSize {
+			// the rest of the frame isn't in the event
+			conn.breakWebSocket(fmt.Errorf("the data event of %d bytes was truncated", event.MsgSize))
+			return
+		}
+		switch event.Direction {
+		case IngressTraffic:
+			conn.addFrames(conn.ws.client, event.Msg[:event.MsgSize])
+		case EgressTraffic:
+			conn.addFrames(conn.ws.server, event.Msg[:event.MsgSize])
+		}
+		return
+	}
+
 	switch event.Direction {
 	case EgressTraffic:
 		// Capturing the timestamp of response as the response just started to come.
@@ -308,8 +475,17 @@ func (conn *Tracker) AddDataEvent(event SocketDataEvent) {
 		if event.MsgSize > EventBodyMaxSize {
 			msgLength = EventBodyMaxSize
 		}
+		// the headers of the response are in its first chunk
+		if conn.resp.size == 0 {
+			if (conn.firstRequest || conn.lastChunkWasReq) && isWebSocketUpgrade(event.Msg[:msgLength]) {
+				conn.firstRequest = false
+				conn.upgrade(event.Msg[:msgLength])
+				return
+			}
+			conn.streamed = isStreamedResponse(event.Msg[:msgLength])
+		}
 		// Append the message (up to msgLength) to the conn's sent buffer
-		conn.resp = append(conn.resp, event.Msg[:msgLength]...)
+		conn.write(conn.resp, event.Msg[:msgLength])
 		conn.respSize += uint64(event.MsgSize)
 
 		//Handling multiple request on same conn to support conn:keep-alive
@@ -318,7 +494,7 @@ func (conn *Tracker) AddDataEvent(event SocketDataEvent) {
 			conn.reqSize = 0
 
 			conn.userReqs = append(conn.userReqs, conn.req)
-			conn.req = []byte{}
+			conn.req = newMessage()
 
 			conn.lastChunkWasReq = false
 			conn.lastChunkWasResp = true
@@ -342,7 +518,7 @@ func (conn *Tracker) AddDataEvent(event SocketDataEvent) {
 			msgLength = EventBodyMaxSize
 		}
 		// Append the message (up to msgLength) to the conn's receive buffer
-		conn.req = append(conn.req, event.Msg[:msgLength]...)
+		conn.write(conn.req, event.Msg[:msgLength])
 		conn.reqSize += uint64(event.MsgSize)
 
 		//Handling multiple request on same conn to support conn:keep-alive
@@ -353,7 +529,7 @@ func (conn *Tracker) AddDataEvent(event SocketDataEvent) {
 			conn.respSize = 0
 
 			conn.userResps = append(conn.userResps, conn.resp)
-			conn.resp = []byte{}
+			conn.resp = newMessage()
 
 			conn.lastChunkWasReq = true
 			conn.lastChunkWasResp = false
@@ -388,6 +564,9 @@ func (conn *Tracker) AddCloseEvent(event SocketCloseEvent) {
 		conn.logger.Debug("Changed close info timestamp due to new request", zap.Any("from", conn.closeTimestamp), zap.Any("to", event.TimestampNano))
 	}
 	conn.closeTimestamp = event.TimestampNano
+	if conn.ws != nil {
+		conn.ws.closed = true
+	}
 	conn.logger.Debug(fmt.Sprintf("Got a close event from eBPF on connectionId:%v\n", event.ConnID))
 }
 
@@ -395,6 +574,18 @@ func (conn *Tracker) UpdateTimestamps() {
 	conn.lastActivityTimestamp = uint64(time.Now().UnixNano())
 }
 
+// isStreamedResponse tells if the http response is streamed from the headers in its first chunk
+func isStreamedResponse(chunk []byte) bool {
+	end := bytes.Index(chunk, []byte("\r\n\r\n"))
+	if end < 0 {
+		end = len(chunk)
+	}
+	headers := bytes.ToLower(chunk[:end])
+	return bytes.Contains(headers, []byte("text/event-stream")) ||
+		bytes.Contains(headers, []byte("transfer-encoding: chunked")) ||
+		bytes.Contains(headers, []byte("application/grpc"))
+}
+
 // ConvertUnixNanoToTime takes a Unix timestamp in nanoseconds as a uint64 and returns the corresponding time.Time
 func ConvertUnixNanoToTime(unixNano uint64) time.Time {
 	// Unix time is the number of seconds since January 1, 1970 UTC,
@@ -0,0 +1,287 @@
+package conn
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// A tracker switches to a websocket session when the app answers an upgrade request with 101 Switching Protocols.
+// The frames sent on the conn afterwards are parsed from the data events of each direction, the fragments of a
+// message being joined, and the session is captured as a websocket test case once the conn is closed, or once it's
+// inactive or keploy drains.
+
+// the opcodes of the websocket frames, see https://www.rfc-editor.org/rfc/rfc6455#section-5.2
+const (
+	opContinuation byte = 0x0
+	opText         byte = 0x1
+	opBinary       byte = 0x2
+	opClose        byte = 0x8
+	opPing         byte = 0x9
+	opPong         byte = 0xa
+)
+
+// maxFrameSize is the largest frame accepted, larger sizes being a corrupted stream
+const maxFrameSize = 64 << 20
+
+var errIncompleteFrame = errors.New("incomplete websocket frame")
+
+// wsFrame is a frame of a websocket message, its payload unmasked
+type wsFrame struct {
+	fin        bool
+	compressed bool
+	opcode     byte
+	payload    []byte
+}
+
+// nextFrame parses the frame at the start of the buffer and returns the rest of the buffer
+func nextFrame(buf []byte) (wsFrame, []byte, error) {
+	if len(buf) < 2 {
+		return wsFrame{}, buf, errIncompleteFrame
+	}
+	f := wsFrame{
+		fin:        buf[0]&0x80 != 0,
+		compressed: buf[0]&0x40 != 0,
+		opcode:     buf[0] & 0x0f,
+	}
+	masked := buf[1]&0x80 != 0
+	size := uint64(buf[1] & 0x7f)
+	pos := 2
+	switch size {
+	case 126:
+		if len(buf) < pos+2 {
+			return wsFrame{}, buf, errIncompleteFrame
+		}
+		size = uint64(binary.BigEndian.Uint16(buf[pos:]))
+		pos += 2
+	case 127:
+		if len(buf) < pos+8 {
+			return wsFrame{}, buf, errIncompleteFrame
+		}
+		size = binary.BigEndian.Uint64(buf[pos:])
+		pos += 8
+	}
+	if size > maxFrameSize {
+		return wsFrame{}, buf, fmt.Errorf("invalid websocket frame size %d", size)
+	}
+	var mask []byte
+	if masked {
+		if len(buf) < pos+4 {
+			return wsFrame{}, buf, errIncompleteFrame
+		}
+		mask = buf[pos : pos+4]
+		pos += 4
+	}
+	if uint64(len(buf)-pos) < size {
+		return wsFrame{}, buf, errIncompleteFrame
+	}
+	f.payload = append([]byte{}, buf[pos:pos+int(size)]...)
+	for i := range mask {
+		for j := i; j < len(f.payload); j += 4 {
+			f.payload[j] ^= mask[i]
+		}
+	}
+	return f, buf[pos+int(size):], nil
+}
+
+// wsStream is a direction of a websocket session
+type wsStream struct {
+	origin models.OriginType
+	// rest holds the bytes which don't make a complete frame yet
+	rest []byte
+	// message is the fragmented message being sent, nil between the messages
+	message *wsFrame
+	closed  bool
+}
+
+// wsSession is the websocket connection of a tracker, with its handshake
+type wsSession struct {
+	req      []byte
+	resp     []byte
+	reqTime  time.Time
+	respTime time.Time
+	// deflate is set when the permessage-deflate extension was negotiated
+	deflate bool
+	client  *wsStream
+	server  *wsStream
+	frames  []models.WebSocketFrame
+	// size is the memory held by the frames, within the budget of the conn
+	size int64
+	// closed is set by the close event of the conn, broken when its frames can't be parsed anymore
+	closed bool
+	broken bool
+}
+
+func newWSSession(req, resp []byte, reqTime time.Time) *wsSession {
+	return &wsSession{
+		req:      req,
+		resp:     resp,
+		reqTime:  reqTime,
+		respTime: time.Now(),
+		deflate:  bytes.Contains(bytes.ToLower(resp), []byte("permessage-deflate")),
+		client:   &wsStream{origin: models.FromClient},
+		server:   &wsStream{origin: models.FromServer},
+	}
+}
+
+// isWebSocketUpgrade tells if the first chunk of a response accepts the upgrade of the conn to a websocket
+func isWebSocketUpgrade(chunk []byte) bool {
+	end := bytes.Index(chunk, []byte("\r\n\r\n"))
+	if end < 0 || !bytes.HasPrefix(chunk, []byte("HTTP/1.1 101")) {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(chunk[:end]), []byte("upgrade: websocket"))
+}
+
+// upgrade switches the conn to a websocket session, the pending request being its handshake and the chunk the
+// 101 response, which may be followed by the first frames of the server
+func (conn *Tracker) upgrade(chunk []byte) {
+	req := conn.take(conn.req)
+	conn.req = newMessage()
+	conn.reqSize = 0
+	var reqTime time.Time
+	if n := len(conn.reqTimestamps); n > 0 {
+		reqTime = conn.reqTimestamps[n-1]
+		conn.reqTimestamps = conn.reqTimestamps[:n-1]
+	}
+	end := bytes.Index(chunk, []byte("\r\n\r\n")) + 4
+	conn.ws = newWSSession(req, append([]byte{}, chunk[:end]...), reqTime)
+	conn.lastChunkWasReq, conn.lastChunkWasResp = false, false
+	conn.isNewRequest = true
+	conn.logger.Debug("the connection is upgraded to a websocket", zap.Any("connectionID", conn.connID))
+	if end < len(chunk) {
+		conn.addFrames(conn.ws.server, chunk[end:])
+	}
+}
+
+// addFrames parses the frames of the chunk sent in the direction of the stream
+func (conn *Tracker) addFrames(stream *wsStream, chunk []byte) {
+	ws := conn.ws
+	if ws.broken {
+		return
+	}
+	stream.rest = append(stream.rest, chunk...)
+	for {
+		f, rest, err := nextFrame(stream.rest)
+		if errors.Is(err, errIncompleteFrame) {
+			return
+		}
+		if err != nil {
+			conn.breakWebSocket(err)
+			return
+		}
+		stream.rest = rest
+
+		switch f.opcode {
+		case opContinuation:
+			if stream.message == nil {
+				conn.breakWebSocket(errors.New("websocket continuation frame without a message"))
+				return
+			}
+			stream.message.payload = append(stream.message.payload, f.payload...)
+			stream.message.fin = f.fin
+		case opText, opBinary:
+			stream.message = &f
+		default:
+			// the control frames may come between the fragments of a message
+			if f.opcode == opClose {
+				stream.closed = true
+			}
+			conn.addMessage(stream, f)
+			continue
+		}
+		if stream.message.fin {
+			conn.addMessage(stream, *stream.message)
+			stream.message = nil
+		}
+	}
+}
+
+// addMessage appends the complete message to the frames of the session
+func (conn *Tracker) addMessage(stream *wsStream, f wsFrame) {
+	ws := conn.ws
+	payload := f.payload
+	if f.compressed && ws.deflate {
+		inflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(append(payload, 0x00, 0x00, 0xff, 0xff))))
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			conn.breakWebSocket(fmt.Errorf("failed to inflate the websocket message: %w", err))
+			return
+		}
+		payload = inflated
+	}
+	frame := models.WebSocketFrame{Origin: stream.origin, Timestamp: time.Now()}
+	switch f.opcode {
+	case opText:
+		frame.Type, frame.Data = models.WebSocketText, string(payload)
+	case opBinary:
+		frame.Type, frame.Data = models.WebSocketBinary, base64.StdEncoding.EncodeToString(payload)
+	case opClose:
+		frame.Type = models.WebSocketClose
+		if len(payload) >= 2 {
+			frame.Data = fmt.Sprintf("%d %s", binary.BigEndian.Uint16(payload), payload[2:])
+		}
+	case opPing:
+		frame.Type, frame.Data = models.WebSocketPing, string(payload)
+	case opPong:
+		frame.Type, frame.Data = models.WebSocketPong, string(payload)
+	default:
+		conn.breakWebSocket(fmt.Errorf("unknown websocket opcode %d", f.opcode))
+		return
+	}
+
+	n := int64(len(frame.Data))
+	if !conn.budget.reserve(conn.memUsed, n) {
+		conn.breakWebSocket(errors.New("the websocket messages outgrew the memory limits of the connection"))
+		return
+	}
+	conn.memUsed += n
+	ws.size += n
+	ws.frames = append(ws.frames, frame)
+}
+
+// breakWebSocket stops capturing the session whose frames can't be captured
+func (conn *Tracker) breakWebSocket(err error) {
+	ws := conn.ws
+	ws.broken = true
+	conn.releaseWebSocket()
+	conn.logger.Warn("failed to capture the websocket connection, it won't be recorded", zap.Any("connectionID", conn.connID), zap.Error(err))
+}
+
+// releaseWebSocket frees the memory of the frames of the session
+func (conn *Tracker) releaseWebSocket() {
+	if conn.ws == nil {
+		return
+	}
+	conn.memUsed -= conn.ws.size
+	conn.budget.release(conn.ws.size)
+	conn.ws.size = 0
+	conn.ws.frames = nil
+}
+
+// completedWebSocket returns the websocket session of the conn once it's closed, inactive for longer than the
+// inactivity threshold or drained, and after the calls made on the conn before its upgrade are captured
+func (conn *Tracker) completedWebSocket(inactivity time.Duration) *wsSession {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	ws := conn.ws
+	if ws == nil || ws.broken || conn.recTestCounter > 0 {
+		return nil
+	}
+	inactive := uint64(time.Now().UnixNano())-conn.lastActivityTimestamp > uint64(inactivity.Nanoseconds())
+	if !ws.closed && !(ws.client.closed && ws.server.closed) && !conn.flushing && !inactive {
+		return nil
+	}
+	conn.ws = nil
+	conn.memUsed -= ws.size
+	conn.budget.release(ws.size)
+	ws.size = 0
+	return ws
+}
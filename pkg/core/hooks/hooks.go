@@ -25,12 +25,16 @@ import (
 
 func NewHooks(logger *zap.Logger, cfg config.Config) *Hooks {
 	return &Hooks{
-		logger:    logger,
-		sess:      core.NewSessions(),
-		m:         sync.Mutex{},
-		proxyIP:   "127.0.0.1",
-		proxyPort: cfg.ProxyPort,
-		dnsPort:   cfg.DNSPort,
+		logger:     logger,
+		sess:       core.NewSessions(),
+		m:          sync.Mutex{},
+		proxyIP:    "127.0.0.1",
+		proxyIP6:   "::1",
+		proxyPort:  cfg.ProxyPort,
+		dnsPort:    cfg.DNSPort,
+		cgroupPath: cfg.CgroupPath,
+		buffers:    cfg.Record.Buffers,
+		tracker:    cfg.Record.Tracker,
 	}
 }
 
@@ -38,8 +42,14 @@ type Hooks struct {
 	logger    *zap.Logger
 	sess      *core.Sessions
 	proxyIP   string
+	proxyIP6  string
 	proxyPort uint32
 	dnsPort   uint32
+	// cgroup to attach the cgroup hooks to, the root cgroup if empty
+	cgroupPath string
+	// buffers bounds the memory used to track the incoming calls and tracker tunes their timeouts
+	buffers config.TrackerBuffers
+	tracker config.TrackerTimeouts
 
 	m sync.Mutex
 	// eBPF C shared maps
@@ -112,8 +122,18 @@ func (h *Hooks) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
 		return nil
 	})
 
-	if opts.IsDocker {
+	if opts.ProxyPort != 0 {
+		h.proxyPort = opts.ProxyPort
+	}
+	if opts.DNSPort != 0 {
+		h.dnsPort = opts.DNSPort
+	}
+
+	// a native app running in its own network namespace reaches the proxy through the keploy ip as well
+	if opts.IsDocker || opts.KeployIPV4 != "" {
 		h.proxyIP = opts.KeployIPV4
+		// the keploy container is reachable over ipv6 sockets of the app through its ipv4-mapped address
+		h.proxyIP6 = opts.KeployIPV4
 	}
 
 	proxyIP, err := IPv4ToUint32(h.proxyIP)
@@ -121,7 +141,12 @@ func (h *Hooks) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
 		return fmt.Errorf("failed to convert ip string:[%v] to 32-bit integer", opts.KeployIPV4)
 	}
 
-	err = h.SendProxyInfo(proxyIP, h.proxyPort, [4]uint32{0000, 0000, 0000, 0001})
+	proxyIP6, err := IPv6ToUint32(h.proxyIP6)
+	if err != nil {
+		return fmt.Errorf("failed to convert ip string:[%v] to 128-bit integer", h.proxyIP6)
+	}
+
+	err = h.SendProxyInfo(proxyIP, h.proxyPort, proxyIP6)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to send proxy info to kernel", zap.Any("NewProxyIp", proxyIP))
 		return err
@@ -215,10 +240,10 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 	}
 	h.tcpv4Ret = tcpRC4
 
-	// Get the first-mounted cgroupv2 path.
-	cGroupPath, err := detectCgroupPath(h.logger)
+	// Get the first-mounted cgroupv2 path or the configured cgroup under it.
+	cGroupPath, err := resolveCgroupPath(h.logger, h.cgroupPath)
 	if err != nil {
-		utils.LogError(h.logger, err, "failed to detect the cgroup path")
+		utils.LogError(h.logger, err, "failed to detect the cgroup path", zap.String("cgroupPath", h.cgroupPath))
 		return err
 	}
 
@@ -488,7 +513,7 @@ func (h *Hooks) Record(ctx context.Context, _ uint64) (<-chan *models.TestCase,
 	// TODO use the session to get the app id
 	// and then use the app id to get the test cases chan
 	// and pass that to eBPF consumers/listeners
-	return conn.ListenSocket(ctx, h.logger, h.objects.SocketOpenEvents, h.objects.SocketDataEvents, h.objects.SocketCloseEvents)
+	return conn.ListenSocket(ctx, h.logger, h.tracker, h.buffers, h.objects.SocketOpenEvents, h.objects.SocketDataEvents, h.objects.SocketCloseEvents)
 }
 
 func (h *Hooks) unLoad(_ context.Context) {
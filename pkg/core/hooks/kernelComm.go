@@ -31,6 +31,7 @@ func (h *Hooks) Get(_ context.Context, srcPort uint16) (*core.NetworkAddress, er
 		IPv4Addr: d.DestIP4,
 		IPv6Addr: d.DestIP6,
 		Port:     d.DestPort,
+		Pid:      d.KernelPid,
 	}, nil
 }
 
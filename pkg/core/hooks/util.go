@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"net"
 	"os"
 	"path/filepath"
@@ -27,6 +28,21 @@ func IPv4ToUint32(ipStr string) (uint32, error) {
 	return 0, errors.New("failed to parse IP address")
 }
 
+// IPv6ToUint32 converts a string representation of an IP address to four 32-bit integers.
+// IPv4 addresses are converted to their IPv4-mapped IPv6 form so that dual-stack sockets can reach them.
+func IPv6ToUint32(ipStr string) ([4]uint32, error) {
+	var ip6 [4]uint32
+	ipAddr := net.ParseIP(ipStr)
+	if ipAddr == nil {
+		return ip6, errors.New("failed to parse IP address")
+	}
+	ipAddr = ipAddr.To16()
+	for i := 0; i < 4; i++ {
+		ip6[i] = binary.BigEndian.Uint32(ipAddr[i*4 : i*4+4])
+	}
+	return ip6, nil
+}
+
 // detectCgroupPath returns the first-found mount point of type cgroup2
 // and stores it in the cgroupPath global variable.
 func detectCgroupPath(logger *zap.Logger) (string, error) {
@@ -53,6 +69,36 @@ func detectCgroupPath(logger *zap.Logger) (string, error) {
 	return "", errors.New("cgroup2 not mounted")
 }
 
+// resolveCgroupPath returns the cgroup to attach the cgroup hooks to. Attaching them to a nested cgroup
+// (a container or a systemd slice) limits the redirection to the processes in it instead of the whole host.
+func resolveCgroupPath(logger *zap.Logger, cgroup string) (string, error) {
+	root, err := detectCgroupPath(logger)
+	if err != nil {
+		return "", err
+	}
+	if cgroup == "" {
+		return root, nil
+	}
+
+	path := cgroup
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+	if rel, err := filepath.Rel(root, path); err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("cgroup %s is not under the cgroup2 mount %s", cgroup, root)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cgroup %s: %w", cgroup, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("cgroup %s is not a directory", cgroup)
+	}
+	return path, nil
+}
+
 func getSelfInodeNumber() (uint64, error) {
 	p := filepath.Join("/proc", "self", "ns", "pid")
 
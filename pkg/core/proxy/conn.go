@@ -22,3 +22,8 @@ func (c *Conn) Read(p []byte) (int, error) {
 	}
 	return c.r.Read(p)
 }
+
+// NetConn returns the underlying connection
+func (c *Conn) NetConn() net.Conn {
+	return c.Conn
+}
@@ -6,8 +6,10 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/miekg/dns"
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
@@ -71,6 +73,16 @@ func generateCacheKey(name string, qtype uint16) string {
 func (p *Proxy) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 
 	p.logger.Debug("", zap.Any("Source socket info", w.RemoteAddr().String()))
+	msg := p.dnsReply(r)
+	p.logger.Debug("Writing dns info back to the client...")
+	err := w.WriteMsg(msg)
+	if err != nil {
+		utils.LogError(p.logger, err, "failed to write dns info back to the client")
+	}
+}
+
+// dnsReply answers the queries of the dns message, with the static records first
+func (p *Proxy) dnsReply(r *dns.Msg) *dns.Msg {
 	msg := new(dns.Msg)
 	msg.SetReply(r)
 	msg.Authoritative = true
@@ -78,6 +90,17 @@ func (p *Proxy) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	for _, question := range r.Question {
 		p.logger.Debug("", zap.Any("Record Type", question.Qtype), zap.Any("Received Query", question.Name))
 
+		// answer the queries of the non-preferred address family with no records, so that the app uses the preferred one
+		if !p.isFamilyAllowed(question.Qtype) {
+			p.logger.Debug("skipping the dns query of the non-preferred address family", zap.Any("Record Type", question.Qtype), zap.Any("ipFamily", p.ipFamily))
+			continue
+		}
+
+		if answers, ok := p.staticAnswers(question); ok {
+			msg.Answer = append(msg.Answer, answers...)
+			continue
+		}
+
 		key := generateCacheKey(question.Name, question.Qtype)
 
 		// Check if the answer is cached
@@ -89,7 +112,11 @@ func (p *Proxy) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 			// If not found in cache, resolve the DNS query only in case of record mode
 			//TODO: Add support for passThrough here using the src<->dst mapping
 			if models.GetMode() == models.MODE_RECORD {
-				answers = resolveDNSQuery(p.logger, question.Name)
+				if p.dns.Upstream != "" {
+					answers = exchangeUpstream(p.logger, p.dns.Upstream, question)
+				} else {
+					answers = filterAnswers(resolveDNSQuery(p.logger, question.Name), question.Qtype)
+				}
 			}
 
 			if len(answers) == 0 {
@@ -126,10 +153,18 @@ func (p *Proxy) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 
 	p.logger.Debug(fmt.Sprintf("dns msg sending back:\n%v\n", msg))
 	p.logger.Debug(fmt.Sprintf("dns msg RCODE sending back:\n%v\n", msg.Rcode))
-	p.logger.Debug("Writing dns info back to the client...")
-	err := w.WriteMsg(msg)
-	if err != nil {
-		utils.LogError(p.logger, err, "failed to write dns info back to the client")
+	return msg
+}
+
+// isFamilyAllowed returns false for the A/AAAA queries of the address family which isn't preferred
+func (p *Proxy) isFamilyAllowed(qtype uint16) bool {
+	switch p.ipFamily {
+	case config.IPFamilyV4:
+		return qtype != dns.TypeAAAA
+	case config.IPFamilyV6:
+		return qtype != dns.TypeA
+	default:
+		return true
 	}
 }
 
@@ -171,6 +206,20 @@ func resolveDNSQuery(logger *zap.Logger, domain string) []dns.RR {
 	return answers
 }
 
+// filterAnswers keeps only the address records matching the type of the query
+func filterAnswers(answers []dns.RR, qtype uint16) []dns.RR {
+	if qtype != dns.TypeA && qtype != dns.TypeAAAA {
+		return answers
+	}
+	var filtered []dns.RR
+	for _, answer := range answers {
+		if answer.Header().Rrtype == qtype {
+			filtered = append(filtered, answer)
+		}
+	}
+	return filtered
+}
+
 func (p *Proxy) stopDNSServers(_ context.Context) error {
 	// stop tcp dns server
 	if err := p.stopTCPDNSServer(); err != nil {
@@ -204,3 +253,47 @@ func (p *Proxy) stopUDPDNSServer() error {
 	}
 	return nil
 }
+
+// staticAnswers returns the answers of the static records matching the question, ok is false when none matches
+func (p *Proxy) staticAnswers(question dns.Question) ([]dns.RR, bool) {
+	if question.Qtype != dns.TypeA && question.Qtype != dns.TypeAAAA {
+		return nil, false
+	}
+	name := strings.ToLower(strings.TrimSuffix(question.Name, "."))
+	for _, record := range p.dns.Records {
+		pattern := strings.ToLower(strings.TrimSuffix(record.Name, "."))
+		if pattern != name && !(strings.HasPrefix(pattern, "*.") && strings.HasSuffix(name, pattern[1:])) {
+			continue
+		}
+		var answers []dns.RR
+		for _, s := range record.IPs {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				p.logger.Warn("ignoring the invalid ip of the dns record", zap.String("name", record.Name), zap.String("ip", s))
+				continue
+			}
+			hdr := dns.RR_Header{Name: question.Name, Rrtype: question.Qtype, Class: dns.ClassINET, Ttl: 3600}
+			if ipv4 := ip.To4(); ipv4 != nil && question.Qtype == dns.TypeA {
+				answers = append(answers, &dns.A{Hdr: hdr, A: ipv4})
+			} else if ip.To4() == nil && question.Qtype == dns.TypeAAAA {
+				answers = append(answers, &dns.AAAA{Hdr: hdr, AAAA: ip})
+			}
+		}
+		p.logger.Debug("answering the dns query with the static record", zap.String("name", record.Name), zap.Any("answers", answers))
+		return answers, true
+	}
+	return nil, false
+}
+
+// exchangeUpstream resolves the question with the upstream resolver
+func exchangeUpstream(logger *zap.Logger, upstream string, question dns.Question) []dns.RR {
+	m := new(dns.Msg)
+	m.SetQuestion(question.Name, question.Qtype)
+	client := &dns.Client{Timeout: 5 * time.Second}
+	resp, _, err := client.Exchange(m, upstream)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("failed to resolve the dns query for:%v with the upstream resolver", question.Name), zap.String("upstream", upstream), zap.Error(err))
+		return nil
+	}
+	return resp.Answer
+}
@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/miekg/dns"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// dotPort is the port of DNS-over-TLS
+const dotPort = 853
+
+// dohMediaType is the content type of the dns messages sent over https
+const dohMediaType = "application/dns-message"
+
+// isEncryptedDNS tells if the tls connection carries DNS-over-TLS, sent to port 853, or DNS-over-HTTPS, sent to
+// one of the DoH hosts by its server name or ip
+func (p *Proxy) isEncryptedDNS(serverName, dstAddr string, port uint32) (dot bool, doh bool) {
+	if port == dotPort {
+		return true, false
+	}
+	if port != 443 {
+		return false, false
+	}
+	ip, _, err := net.SplitHostPort(dstAddr)
+	if err != nil {
+		ip = dstAddr
+	}
+	for _, host := range p.dns.DoHHosts {
+		if strings.EqualFold(host, serverName) || host == ip {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// serveEncryptedDNS answers the dns queries read from the decrypted connection until the client closes it
+func (p *Proxy) serveEncryptedDNS(logger *zap.Logger, conn net.Conn, dot bool) error {
+	if dot {
+		logger.Debug("answering the dns-over-tls queries")
+		dnsConn := &dns.Conn{Conn: conn}
+		for {
+			m, err := dnsConn.ReadMsg()
+			if err != nil {
+				if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+					return nil
+				}
+				utils.LogError(logger, err, "failed to read the dns-over-tls query")
+				return err
+			}
+			if err := dnsConn.WriteMsg(p.dnsReply(m)); err != nil {
+				utils.LogError(logger, err, "failed to write the dns-over-tls answer")
+				return err
+			}
+		}
+	}
+
+	logger.Debug("answering the dns-over-https queries")
+	reader := bufio.NewReader(conn)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			utils.LogError(logger, err, "failed to read the dns-over-https request")
+			return err
+		}
+		resp := p.answerDoH(req)
+		err = resp.Write(conn)
+		if err != nil {
+			utils.LogError(logger, err, "failed to write the dns-over-https response")
+			return err
+		}
+	}
+}
+
+// answerDoH answers the dns query of the request, sent in the dns parameter of a GET or as the body of a POST
+func (p *Proxy) answerDoH(req *http.Request) *http.Response {
+	var packed []byte
+	var err error
+	switch req.Method {
+	case http.MethodGet:
+		packed, err = base64.RawURLEncoding.DecodeString(req.URL.Query().Get("dns"))
+	case http.MethodPost:
+		packed, err = io.ReadAll(req.Body)
+	default:
+		err = fmt.Errorf("unsupported method %s", req.Method)
+	}
+	if err := req.Body.Close(); err != nil {
+		p.logger.Debug("failed to close the body of the dns-over-https request", zap.Error(err))
+	}
+
+	m := new(dns.Msg)
+	if err == nil {
+		err = m.Unpack(packed)
+	}
+	if err == nil {
+		packed, err = p.dnsReply(m).Pack()
+	}
+	if err != nil {
+		p.logger.Debug("failed to answer the dns-over-https request", zap.Error(err))
+		return dohResponse(req, http.StatusBadRequest, "", []byte(err.Error()))
+	}
+	return dohResponse(req, http.StatusOK, dohMediaType, packed)
+}
+
+func dohResponse(req *http.Request, status int, contentType string, body []byte) *http.Response {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return &http.Response{
+		StatusCode:    status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,96 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// egress counts the outgoing connections of the record sessions, by app
+type egress struct {
+	mu    sync.Mutex
+	calls map[uint64]map[models.EgressCalls]int
+}
+
+func (e *egress) add(id uint64, host string, port uint32, protocol, outcome string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.calls == nil {
+		e.calls = map[uint64]map[models.EgressCalls]int{}
+	}
+	if e.calls[id] == nil {
+		e.calls[id] = map[models.EgressCalls]int{}
+	}
+	e.calls[id][models.EgressCalls{Host: host, Port: port, Protocol: protocol, Outcome: outcome}]++
+}
+
+// GetEgressCalls returns the outgoing connections made by the app while recording, by destination, protocol and outcome
+func (p *Proxy) GetEgressCalls(_ context.Context, id uint64) ([]models.EgressCalls, error) {
+	p.egress.mu.Lock()
+	defer p.egress.mu.Unlock()
+	var calls []models.EgressCalls
+	for c, n := range p.egress.calls[id] {
+		c.Connections = n
+		calls = append(calls, c)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].Host != calls[j].Host {
+			return calls[i].Host < calls[j].Host
+		}
+		if calls[i].Port != calls[j].Port {
+			return calls[i].Port < calls[j].Port
+		}
+		if calls[i].Protocol != calls[j].Protocol {
+			return calls[i].Protocol < calls[j].Protocol
+		}
+		return calls[i].Outcome < calls[j].Outcome
+	})
+	return calls, nil
+}
+
+// egressHost names the destination of a connection by its server name or http host, else by its address
+func egressHost(serverName, reqHost, dstAddr string) string {
+	if serverName != "" {
+		return serverName
+	}
+	if reqHost != "" {
+		return reqHost
+	}
+	if host, _, err := net.SplitHostPort(dstAddr); err == nil {
+		return host
+	}
+	return dstAddr
+}
+
+// isBypassed tells if the first http request of the connection matches a bypass rule, as the http parser tells it
+func isBypassed(rules []config.BypassRule, initialBuf []byte, port uint32) bool {
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(initialBuf)))
+	if err != nil {
+		return false
+	}
+	for _, rule := range rules {
+		if rule.Port != 0 && rule.Port != uint(port) {
+			continue
+		}
+		if rule.Host != "" {
+			if regex, err := regexp.Compile(rule.Host); err != nil || !regex.MatchString(req.Host) {
+				continue
+			}
+		}
+		if rule.Path != "" {
+			if regex, err := regexp.Compile(rule.Path); err != nil || !regex.MatchString(req.URL.String()) {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// stubResponse is the canned answer to the http calls of the ignored destinations
+const stubResponse = "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n"
+
+// matchIgnoreRule returns the ignore rule matching the destination of the connection, if any
+func (p *Proxy) matchIgnoreRule(logger *zap.Logger, hosts []string, port uint32) *config.IgnoreRule {
+	for i, rule := range p.ignoreRules {
+		if rule.Port != 0 && rule.Port != uint(port) {
+			continue
+		}
+		if rule.Host == "" {
+			return &p.ignoreRules[i]
+		}
+		regex, err := regexp.Compile(rule.Host)
+		if err != nil {
+			utils.LogError(logger, err, "failed to compile the host regex of the ignore rule", zap.String("host", rule.Host))
+			continue
+		}
+		for _, host := range hosts {
+			if host != "" && regex.MatchString(host) {
+				return &p.ignoreRules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// httpHost returns the host of the http request in the initial buffer of a connection and whether it's one
+func httpHost(initialBuf []byte) (string, bool) {
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(initialBuf)))
+	if err != nil {
+		return "", false
+	}
+	host, _, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		return req.Host, true
+	}
+	return host, true
+}
+
+// handleIgnored answers the calls to an ignored destination without recording or mocking them
+func (p *Proxy) handleIgnored(logger *zap.Logger, srcConn net.Conn, rule *config.IgnoreRule, isHTTP bool) error {
+	if rule.Action == config.IgnoreActionBlackhole || !isHTTP {
+		logger.Debug("dropping the connection to an ignored destination")
+		return nil
+	}
+
+	logger.Debug("stubbing the calls to an ignored destination")
+	reader := bufio.NewReader(srcConn)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			// the client is done with the connection
+			return nil
+		}
+		_, err = io.Copy(io.Discard, req.Body)
+		if err != nil {
+			return nil
+		}
+		if err := req.Body.Close(); err != nil {
+			return err
+		}
+		_, err = srcConn.Write([]byte(stubResponse))
+		if err != nil {
+			utils.LogError(logger, err, "failed to write the stub response of the ignored destination")
+			return err
+		}
+		if req.Close {
+			return nil
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package generic
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net"
@@ -15,7 +16,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, _ models.OutgoingOptions) error {
+func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	genericRequests := [][]byte{reqBuf}
 	logger.Debug("Into the generic parser in test mode")
 	errCh := make(chan error, 1)
@@ -56,6 +57,15 @@ func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 			if err != nil {
 				utils.LogError(logger, err, "error while matching generic mocks")
 			}
+			if !matched && opts.GenericVerbatim {
+				matched, genericResponses, err = nextVerbatim(mockDb, dstCfg.Port)
+				if err != nil {
+					utils.LogError(logger, err, "error while getting the next generic mock")
+				}
+				if matched {
+					logger.Debug("serving the next generic mock of the destination verbatim", zap.Any("port", dstCfg.Port))
+				}
+			}
 
 			if !matched {
 				err := clientConn.SetReadDeadline(time.Time{})
@@ -64,6 +74,14 @@ func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 					return
 				}
 
+				if opts.MatchDebug != nil {
+					mocks, err := mockDb.GetUnFilteredMocks()
+					if err != nil {
+						utils.LogError(logger, err, "failed to get the mocks closest to the generic request")
+					}
+					util.TraceUnmatched(logger, opts.MatchDebug, models.GENERIC, util.Excerpt(bytes.Join(genericRequests, nil)), closestMocks(mocks, genericRequests, dstCfg.Port))
+				}
+
 				logger.Debug("the genericRequests before pass through are", zap.Any("length", len(genericRequests)))
 				for _, genReq := range genericRequests {
 					logger.Debug("the genericRequests are:", zap.Any("h", string(genReq)))
@@ -84,6 +102,14 @@ func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 				continue
 			}
 			for _, genericResponse := range genericResponses {
+				if genericResponse.Failure != "" {
+					logger.Debug("replaying the failure of the destination", zap.Any("failure", genericResponse.Failure))
+					err := pUtil.InjectFailure(ctx, clientConn, genericResponse.Failure)
+					if err != nil {
+						utils.LogError(logger, err, "failed to replay the failure of the destination")
+					}
+					return
+				}
 				encoded := []byte(genericResponse.Message[0].Data)
 				if genericResponse.Message[0].Type != models.String {
 					encoded, err = util.DecodeBase64(genericResponse.Message[0].Data)
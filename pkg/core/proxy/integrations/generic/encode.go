@@ -50,6 +50,8 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 	clientBuffChan := make(chan []byte)
 	destBuffChan := make(chan []byte)
 	errChan := make(chan error)
+	// the errors of the destination are read apart, its failures being recorded in the mocks
+	destErrChan := make(chan error)
 	//TODO: where to close the error channel since it is used in both the go routines
 	//close(errChan)
 
@@ -70,7 +72,7 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 	g.Go(func() error {
 		defer utils.Recover(logger)
 		defer close(destBuffChan)
-		pUtil.ReadBuffConn(ctx, logger, destConn, destBuffChan, errChan)
+		pUtil.ReadBuffConn(ctx, logger, destConn, destBuffChan, destErrChan)
 		return nil
 	})
 
@@ -78,6 +80,23 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 	var reqTimestampMock = time.Now()
 	var resTimestampMock time.Time
 
+	saveLastMock := func() {
+		if len(genericRequests) > 0 && len(genericResponses) > 0 {
+			mocks <- &models.Mock{
+				Version: models.GetVersion(),
+				Name:    "mocks",
+				Kind:    models.GENERIC,
+				Spec: models.MockSpec{
+					GenericRequests:  genericRequests,
+					GenericResponses: genericResponses,
+					ReqTimestampMock: reqTimestampMock,
+					ResTimestampMock: resTimestampMock,
+					Metadata:         mockMetadata(destConn),
+				},
+			}
+		}
+	}
+
 	// ticker := time.NewTicker(1 * time.Second)
 	logger.Debug("the iteration for the generic request starts", zap.Any("genericReqs", len(genericRequests)), zap.Any("genericResps", len(genericResponses)))
 	for {
@@ -89,8 +108,7 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 				copy(genericResponsesCopy, genericResponses)
 				copy(genericRequestsCopy, genericRequests)
 
-				metadata := make(map[string]string)
-				metadata["type"] = "config"
+				metadata := mockMetadata(destConn)
 				// Save the mock
 				mocks <- &models.Mock{
 					Version: models.GetVersion(),
@@ -121,8 +139,7 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 				copy(genericResponseCopy, genericResponses)
 				copy(genericRequestsCopy, genericRequests)
 				go func(reqs []models.GenericPayload, resps []models.GenericPayload) {
-					metadata := make(map[string]string)
-					metadata["type"] = "config"
+					metadata := mockMetadata(destConn)
 					// Save the mock
 					mocks <- &models.Mock{
 						Version: models.GetVersion(),
@@ -199,9 +216,47 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 			prevChunkWasReq = false
 		case err := <-errChan:
 			if err == io.EOF {
+				// save the last exchange of the connection, e.g. the subscription and the messages pushed to it
+				saveLastMock()
 				return nil
 			}
 			return err
+		case err := <-destErrChan:
+			failure := pUtil.ConnFailureOf(err)
+			if failure == "" {
+				if err == io.EOF {
+					saveLastMock()
+					return nil
+				}
+				return err
+			}
+			logger.Debug("the destination failed, recording its failure", zap.Any("failure", failure), zap.Error(err))
+			if prevChunkWasReq {
+				reqTimestampMock = time.Now()
+			}
+			resTimestampMock = time.Now()
+			// the failure is replayed after the responses which were sent before it
+			genericResponses = append(genericResponses, models.GenericPayload{
+				Origin:  models.FromServer,
+				Failure: failure,
+			})
+			saveLastMock()
+			if failure == models.ConnReset {
+				if err := pUtil.InjectFailure(ctx, clientConn, failure); err != nil {
+					utils.LogError(logger, err, "failed to reset the client connection")
+				}
+			}
+			return err
 		}
 	}
 }
+
+// mockMetadata returns the metadata of the generic mocks recorded on the connection, with the port of the
+// destination by which they're served in order with the verbatim replay
+func mockMetadata(destConn net.Conn) map[string]string {
+	metadata := map[string]string{"type": "config"}
+	if _, port, err := net.SplitHostPort(destConn.RemoteAddr().String()); err == nil {
+		metadata["port"] = port
+	}
+	return metadata
+}
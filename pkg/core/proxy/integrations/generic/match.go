@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 
@@ -27,6 +29,10 @@ func fuzzyMatch(ctx context.Context, reqBuff [][]byte, mockDb integrations.MockM
 			var unfilteredMocks []*models.Mock
 
 			for _, mock := range mocks {
+				// the datagrams of the udp dependencies are kept like the generic mocks but matched by the proxy
+				if mock.Kind != models.GENERIC {
+					continue
+				}
 				if mock.TestModeInfo.IsFiltered {
 					filteredMocks = append(filteredMocks, mock)
 				} else {
@@ -134,3 +140,92 @@ func findBinaryMatch(tcsMocks []*models.Mock, reqBuffs [][]byte) int {
 	}
 	return mxIdx
 }
+
+// nextVerbatim returns the responses of the earliest generic mock of the destination port which wasn't served
+// yet, the mocks of the current test case first. The mocks recorded without a port are served to any destination.
+func nextVerbatim(mockDb integrations.MockMemDb, port uint) (bool, []models.GenericPayload, error) {
+	for {
+		mocks, err := mockDb.GetUnFilteredMocks()
+		if err != nil {
+			return false, nil, fmt.Errorf("error while getting unfiltered mocks %v", err)
+		}
+		var candidates []*models.Mock
+		for _, mock := range mocks {
+			if mock.Kind != models.GENERIC || mock.TestModeInfo.SortOrder == math.MaxInt64 {
+				continue
+			}
+			if p, ok := mock.Spec.Metadata["port"]; ok && p != strconv.Itoa(int(port)) {
+				continue
+			}
+			candidates = append(candidates, mock)
+		}
+		if len(candidates) == 0 {
+			return false, nil, nil
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].TestModeInfo.IsFiltered != candidates[j].TestModeInfo.IsFiltered {
+				return candidates[i].TestModeInfo.IsFiltered
+			}
+			return candidates[i].Spec.ReqTimestampMock.Before(candidates[j].Spec.ReqTimestampMock)
+		})
+
+		next := candidates[0]
+		original := *next
+		updated := *next
+		updated.TestModeInfo.IsFiltered = false
+		updated.TestModeInfo.SortOrder = math.MaxInt64
+		// another connection served the mock meanwhile, the next one is looked up again
+		if !mockDb.UpdateUnFilteredMock(&original, &updated) {
+			continue
+		}
+		responses := make([]models.GenericPayload, len(next.Spec.GenericResponses))
+		copy(responses, next.Spec.GenericResponses)
+		return true, responses, nil
+	}
+}
+
+// closestMocks compares the requests which matched no mock with the generic mocks of the destination, on their number
+// and their similarity one by one, the mocks of other ports being reported as such
+func closestMocks(mocks []*models.Mock, reqBuffs [][]byte, port uint) []models.MockCandidate {
+	var candidates []models.MockCandidate
+	for _, mock := range mocks {
+		if mock.Kind != models.GENERIC {
+			continue
+		}
+		var mismatches []string
+		fields := 2 + max(len(mock.Spec.GenericRequests), len(reqBuffs))
+		equal := 0.0
+		if p, ok := mock.Spec.Metadata["port"]; ok && p != strconv.Itoa(int(port)) {
+			mismatches = append(mismatches, fmt.Sprintf("port: the mock has %s, the request %d", p, port))
+		} else {
+			equal++
+		}
+		if len(mock.Spec.GenericRequests) != len(reqBuffs) {
+			mismatches = append(mismatches, fmt.Sprintf("messages: the mock has %d, the request %d", len(mock.Spec.GenericRequests), len(reqBuffs)))
+		} else {
+			equal++
+		}
+		for i := 0; i < min(len(mock.Spec.GenericRequests), len(reqBuffs)); i++ {
+			if len(mock.Spec.GenericRequests[i].Message) == 0 {
+				continue
+			}
+			data := mock.Spec.GenericRequests[i].Message[0].Data
+			recorded, err := util.DecodeBase64(data)
+			if err != nil {
+				// the printable messages are recorded as they are
+				recorded = []byte(data)
+			}
+			similarity := util.Similarity(recorded, reqBuffs[i])
+			equal += similarity
+			if similarity < 1 {
+				mismatches = append(mismatches, fmt.Sprintf("message %d: %.0f%% similar", i+1, similarity*100))
+			}
+		}
+		candidates = append(candidates, models.MockCandidate{
+			Mock:       mock.Name,
+			Score:      util.Score(equal, fields),
+			Mismatches: mismatches,
+		})
+	}
+	return candidates
+}
@@ -2,12 +2,10 @@ package grpc
 
 import (
 	"context"
-	"encoding/binary"
-	"fmt"
-	"github.com/protocolbuffers/protoscope"
 	"sync"
 	"time"
 
+	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
 )
 
@@ -85,7 +83,7 @@ func (sic *StreamInfoCollection) AddPayloadForRequest(streamID uint32, payload [
 	// We cannot modify non pointer values in nested entries in map.
 	// Create a copy and overwrite it.
 	info := sic.StreamInfo[streamID]
-	info.GrpcReq.Body = createLengthPrefixedMessageFromPayload(payload)
+	info.GrpcReq.Body = pkg.GrpcLengthPrefixedMessage(payload)
 	sic.StreamInfo[streamID] = info
 }
 
@@ -99,7 +97,7 @@ func (sic *StreamInfoCollection) AddPayloadForResponse(streamID uint32, payload
 	// We cannot modify non pointer values in nested entries in map.
 	// Create a copy and overwrite it.
 	info := sic.StreamInfo[streamID]
-	info.GrpcResp.Body = createLengthPrefixedMessageFromPayload(payload)
+	info.GrpcResp.Body = pkg.GrpcLengthPrefixedMessage(payload)
 	sic.StreamInfo[streamID] = info
 }
 
@@ -135,45 +133,3 @@ func (sic *StreamInfoCollection) ResetStream(streamID uint32) {
 
 	delete(sic.StreamInfo, streamID)
 }
-
-func createLengthPrefixedMessageFromPayload(data []byte) models.GrpcLengthPrefixedMessage {
-	msg := models.GrpcLengthPrefixedMessage{}
-
-	// If the body is not length prefixed, we return the default value.
-	if len(data) < 5 {
-		return msg
-	}
-
-	// The first byte is the compression flag.
-	msg.CompressionFlag = uint(data[0])
-
-	// The next 4 bytes are message length.
-	msg.MessageLength = binary.BigEndian.Uint32(data[1:5])
-
-	// The payload could be empty. We only parse it if it is present.
-	if len(data) >= 5 {
-		// Use protoscope to decode the message.
-		msg.DecodedData = protoscope.Write(data[5:], protoscope.WriterOptions{})
-	}
-
-	return msg
-}
-
-func createPayloadFromLengthPrefixedMessage(msg models.GrpcLengthPrefixedMessage) ([]byte, error) {
-	scanner := protoscope.NewScanner(msg.DecodedData)
-	encodedData, err := scanner.Exec()
-	if err != nil {
-		return nil, fmt.Errorf("could not encode grpc msg using protoscope: %v", err)
-	}
-
-	// Note that the encoded length is present in the msg, but it is also equal to the len of encodedData.
-	// We should give the preference to the length of encodedData, since the mocks might have been altered.
-
-	// Reserve 1 byte for compression flag, 4 bytes for length capture.
-	payload := make([]byte, 1+4)
-	payload[0] = uint8(msg.CompressionFlag)
-	binary.BigEndian.PutUint32(payload[1:5], uint32(len(encodedData)))
-	payload = append(payload, encodedData...)
-
-	return payload, nil
-}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/utils"
 
@@ -127,7 +128,7 @@ func (srv *Transcoder) ProcessDataFrame(ctx context.Context, dataFrame *http2.Da
 		return err
 	}
 
-	payload, err := createPayloadFromLengthPrefixedMessage(grpcMockResp.Body)
+	payload, err := pkg.GrpcPayload(grpcMockResp.Body)
 	if err != nil {
 		utils.LogError(srv.logger, err, "could not create grpc payload from mocks")
 		return err
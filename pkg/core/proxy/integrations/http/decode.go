@@ -14,6 +14,7 @@ import (
 
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	iUtil "go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	"go.keploy.io/server/v2/pkg/core/proxy/util"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -101,6 +102,13 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 			if !match {
 				if !isPassThrough(logger, request, dstCfg.Port, opts) {
 					utils.LogError(logger, nil, "Didn't match any preExisting http mock", zap.Any("metadata", getReqMeta(request)))
+					if opts.MatchDebug != nil {
+						mocks, err := mockDb.GetFilteredMocks()
+						if err != nil {
+							utils.LogError(logger, err, "failed to get the mocks closest to the http request")
+						}
+						iUtil.TraceUnmatched(logger, opts.MatchDebug, models.HTTP, request.Method+" "+request.URL.String(), closestMocks(logger, mocks, request, reqBody))
+					}
 				}
 
 				_, err = util.PassThrough(ctx, logger, clientConn, dstCfg, [][]byte{reqBuf})
@@ -111,6 +119,16 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 				}
 			}
 
+			if match && stub.Spec.HTTPResp.Failure != "" {
+				logger.Debug("replaying the failure of the destination", zap.Any("failure", stub.Spec.HTTPResp.Failure), zap.Any("metadata", getReqMeta(request)))
+				err = util.InjectFailure(ctx, clientConn, stub.Spec.HTTPResp.Failure)
+				if err != nil {
+					utils.LogError(logger, err, "failed to replay the failure of the destination", zap.Any("metadata", getReqMeta(request)))
+				}
+				errCh <- nil
+				return
+			}
+
 			statusLine := fmt.Sprintf("HTTP/%d.%d %d %s\r\n", stub.Spec.HTTPReq.ProtoMajor, stub.Spec.HTTPReq.ProtoMinor, stub.Spec.HTTPResp.StatusCode, http.StatusText(stub.Spec.HTTPResp.StatusCode))
 
 			body := stub.Spec.HTTPResp.Body
@@ -17,7 +17,7 @@ import (
 )
 
 // encodeHTTP function parses the HTTP request and response text messages to capture outgoing network calls as mocks.
-func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn, destConn net.Conn, mocks chan<- *models.Mock, opts models.OutgoingOptions) error {
+func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn, destConn net.Conn, mocks chan<- *models.Mock, opts models.OutgoingOptions, seq *sequenceScope) error {
 
 	remoteAddr := destConn.RemoteAddr().(*net.TCPAddr)
 	destPort := uint(remoteAddr.Port)
@@ -145,7 +145,7 @@ func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 							reqTimestampMock: reqTimestampMock,
 							resTimestampMock: resTimestampMock,
 						}
-						err := ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts)
+						err := ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts, seq)
 						if err != nil {
 							utils.LogError(logger, err, "failed to parse the final http request and response")
 							errCh <- err
@@ -154,6 +154,25 @@ func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 					}
 					break
 				}
+				if failure := util.ConnFailureOf(err); failure != "" {
+					logger.Debug("the destination failed, recording its failure", zap.Any("failure", failure), zap.Error(err))
+					m := &finalHTTP{
+						req:              finalReq,
+						reqTimestampMock: reqTimestampMock,
+						resTimestampMock: time.Now(),
+						failure:          failure,
+					}
+					if parseErr := ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts, seq); parseErr != nil {
+						utils.LogError(logger, parseErr, "failed to parse the failed http request")
+					}
+					if failure == models.ConnReset {
+						if resetErr := util.InjectFailure(ctx, clientConn, failure); resetErr != nil {
+							utils.LogError(logger, resetErr, "failed to reset the client connection")
+						}
+					}
+					errCh <- err
+					return nil
+				}
 				utils.LogError(logger, err, "failed to read the response message from the destination server")
 				errCh <- err
 				return nil
@@ -187,7 +206,7 @@ func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 						reqTimestampMock: reqTimestampMock,
 						resTimestampMock: resTimestampMock,
 					}
-					parseErr := ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts)
+					parseErr := ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts, seq)
 					if parseErr != nil {
 						utils.LogError(logger, parseErr, "failed to parse the final http request and response")
 						errCh <- parseErr
@@ -209,7 +228,7 @@ func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 				resTimestampMock: resTimestampMock,
 			}
 
-			err = ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts)
+			err = ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts, seq)
 			if err != nil {
 				utils.LogError(logger, err, "failed to parse the final http request and response")
 				errCh <- err
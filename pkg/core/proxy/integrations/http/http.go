@@ -28,11 +28,14 @@ func init() {
 type HTTP struct {
 	logger *zap.Logger
 	//opts  globalOptions //other global options set by the proxy
+	// sequences links the redirected and retried requests of the connections of every app and client while recording
+	sequences *sequencer
 }
 
 func NewHTTP(logger *zap.Logger) integrations.Integrations {
 	return &HTTP{
-		logger: logger,
+		logger:    logger,
+		sequences: newSequencer(),
 	}
 }
 
@@ -41,6 +44,8 @@ type finalHTTP struct {
 	resp             []byte
 	reqTimestampMock time.Time
 	resTimestampMock time.Time
+	// failure is the failure of the connection which the request got instead of the response
+	failure models.ConnFailure
 }
 
 // MatchType function determines if the outgoing network call is HTTP by comparing the
@@ -68,7 +73,7 @@ func (h *HTTP) RecordOutgoing(ctx context.Context, src net.Conn, dst net.Conn, m
 		utils.LogError(logger, err, "failed to read the initial http message")
 		return err
 	}
-	err = encodeHTTP(ctx, logger, reqBuf, src, dst, mocks, opts)
+	err = encodeHTTP(ctx, logger, reqBuf, src, dst, mocks, opts, h.sequences.scope(ctx, src))
 	if err != nil {
 		utils.LogError(logger, err, "failed to encode the http message into the yaml")
 		return err
@@ -95,8 +100,9 @@ func (h *HTTP) MockOutgoing(ctx context.Context, src net.Conn, dstCfg *integrati
 	return nil
 }
 
-// ParseFinalHTTP is used to parse the final http request and response and save it in a yaml file
-func ParseFinalHTTP(_ context.Context, logger *zap.Logger, mock *finalHTTP, destPort uint, mocks chan<- *models.Mock, opts models.OutgoingOptions) error {
+// ParseFinalHTTP is used to parse the final http request and response and save it in a yaml file. The requests
+// following a redirect or retrying a failed request are saved as the steps of the sequence of the first one.
+func ParseFinalHTTP(_ context.Context, logger *zap.Logger, mock *finalHTTP, destPort uint, mocks chan<- *models.Mock, opts models.OutgoingOptions, seq *sequenceScope) error {
 	var req *http.Request
 	// converts the request message buffer to http request
 	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(mock.req)))
@@ -116,6 +122,10 @@ func ParseFinalHTTP(_ context.Context, logger *zap.Logger, mock *finalHTTP, dest
 		}
 	}
 
+	if mock.failure != "" {
+		return parseFailedHTTP(logger, mock, req, reqBody, destPort, mocks, opts, seq)
+	}
+
 	// converts the response message buffer to http response
 	respParsed, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(mock.resp)), req)
 	if err != nil {
@@ -163,22 +173,15 @@ func ParseFinalHTTP(_ context.Context, logger *zap.Logger, mock *finalHTTP, dest
 		logger.Debug("The request is a passThrough request", zap.Any("metadata", getReqMeta(req)))
 		return nil
 	}
+	id, step := seq.link(req, reqBody, respParsed.StatusCode, respParsed.Header.Get("Location"), false, mock.reqTimestampMock, mock.resTimestampMock)
 
 	mocks <- &models.Mock{
 		Version: models.GetVersion(),
 		Name:    "mocks",
 		Kind:    models.HTTP,
 		Spec: models.MockSpec{
-			Metadata: meta,
-			HTTPReq: &models.HTTPReq{
-				Method:     models.Method(req.Method),
-				ProtoMajor: req.ProtoMajor,
-				ProtoMinor: req.ProtoMinor,
-				URL:        req.URL.String(),
-				Header:     pkg.ToYamlHTTPHeader(req.Header),
-				Body:       string(reqBody),
-				URLParams:  pkg.URLParams(req),
-			},
+			Metadata: withSequence(meta, id, step),
+			HTTPReq:  mockReq(req, reqBody),
 			HTTPResp: &models.HTTPResp{
 				StatusCode: respParsed.StatusCode,
 				Header:     pkg.ToYamlHTTPHeader(respParsed.Header),
@@ -191,3 +194,42 @@ func ParseFinalHTTP(_ context.Context, logger *zap.Logger, mock *finalHTTP, dest
 	}
 	return nil
 }
+
+// parseFailedHTTP saves the mock of the request whose connection failed, which replays the failure
+func parseFailedHTTP(logger *zap.Logger, mock *finalHTTP, req *http.Request, reqBody []byte, destPort uint, mocks chan<- *models.Mock, opts models.OutgoingOptions, seq *sequenceScope) error {
+	if isPassThrough(logger, req, destPort, opts) {
+		logger.Debug("The request is a passThrough request", zap.Any("metadata", getReqMeta(req)))
+		return nil
+	}
+	id, step := seq.link(req, reqBody, 0, "", true, mock.reqTimestampMock, mock.resTimestampMock)
+	mocks <- &models.Mock{
+		Version: models.GetVersion(),
+		Name:    "mocks",
+		Kind:    models.HTTP,
+		Spec: models.MockSpec{
+			Metadata: withSequence(map[string]string{
+				"name":      "Http",
+				"type":      models.HTTPClient,
+				"operation": req.Method,
+			}, id, step),
+			HTTPReq:          mockReq(req, reqBody),
+			HTTPResp:         &models.HTTPResp{Failure: mock.failure},
+			Created:          time.Now().Unix(),
+			ReqTimestampMock: mock.reqTimestampMock,
+			ResTimestampMock: mock.resTimestampMock,
+		},
+	}
+	return nil
+}
+
+func mockReq(req *http.Request, reqBody []byte) *models.HTTPReq {
+	return &models.HTTPReq{
+		Method:     models.Method(req.Method),
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		URL:        req.URL.String(),
+		Header:     pkg.ToYamlHTTPHeader(req.Header),
+		Body:       string(reqBody),
+		URLParams:  pkg.URLParams(req),
+	}
+}
@@ -3,7 +3,11 @@ package http
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/agnivade/levenshtein"
 	"github.com/cloudflare/cfssl/log"
@@ -73,6 +77,7 @@ func match(ctx context.Context, logger *zap.Logger, matchParams *matchParams, mo
 				}
 			}
 
+			eligibleMocks = inSequence(eligibleMocks, tcsMocks)
 			if len(eligibleMocks) == 0 {
 				return false, nil, nil
 			}
@@ -191,3 +196,78 @@ func fuzzyMatch(tcsMocks []*models.Mock, reqBuff []byte) (bool, *models.Mock) {
 	}
 	return false, &models.Mock{}
 }
+
+// closestMocks compares the request which matched no mock with the http mocks on the fields they are matched on, the
+// body adding its similarity to the score of the mocks
+func closestMocks(logger *zap.Logger, mocks []*models.Mock, req *http.Request, reqBody []byte) []models.MockCandidate {
+	var candidates []models.MockCandidate
+	firstUnserved := firstUnservedSteps(mocks)
+	for _, mock := range mocks {
+		if mock.Kind != models.HTTP {
+			continue
+		}
+		var mismatches []string
+		if mock.Spec.HTTPReq.Method != models.Method(req.Method) {
+			mismatches = append(mismatches, fmt.Sprintf("method: the mock has %s, the request %s", mock.Spec.HTTPReq.Method, req.Method))
+		}
+		parsedURL, err := url.Parse(mock.Spec.HTTPReq.URL)
+		if err != nil {
+			logger.Debug("failed to parse the url of the mock", zap.String("mock", mock.Name), zap.Error(err))
+			continue
+		}
+		if parsedURL.Path != req.URL.Path {
+			mismatches = append(mismatches, fmt.Sprintf("path: the mock has %s, the request %s", parsedURL.Path, req.URL.Path))
+		}
+		if diff := keysDiff(mock.Spec.HTTPReq.Header, req.Header); diff != "" {
+			mismatches = append(mismatches, "header keys: "+diff)
+		}
+		if diff := keysDiff(mock.Spec.HTTPReq.URLParams, req.URL.Query()); diff != "" {
+			mismatches = append(mismatches, "query keys: "+diff)
+		}
+		mockBodyIsJSON, reqBodyIsJSON := isJSON([]byte(mock.Spec.HTTPReq.Body)), isJSON(reqBody)
+		if mockBodyIsJSON != reqBodyIsJSON {
+			mismatches = append(mismatches, fmt.Sprintf("body type: the mock has json %t, the request json %t", mockBodyIsJSON, reqBodyIsJSON))
+		}
+		// the method, the path, the header keys, the query keys and the body type are equal or not, the body partially
+		similarity := util.Similarity([]byte(mock.Spec.HTTPReq.Body), reqBody)
+		equal := float64(5-len(mismatches)) + similarity
+		if similarity < 1 {
+			mismatches = append(mismatches, fmt.Sprintf("body: %.0f%% similar", similarity*100))
+		}
+		if id, step := sequenceOf(mock); id != "" && step > firstUnserved[id] {
+			// the order of the redirects and the retries isn't a field of the request, the score is left as it is
+			mismatches = append(mismatches, fmt.Sprintf("sequence: step %d of %s waits for step %d to be served", step, id, firstUnserved[id]))
+		}
+		candidates = append(candidates, models.MockCandidate{
+			Mock:       mock.Name,
+			Score:      util.Score(equal, 6),
+			Mismatches: mismatches,
+		})
+	}
+	return candidates
+}
+
+// keysDiff describes the keys missing from the request and the unexpected ones, empty when the keys are the same
+func keysDiff(mockKeys map[string]string, reqKeys map[string][]string) string {
+	var missing, unexpected []string
+	for key := range mockKeys {
+		if _, ok := reqKeys[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	for key := range reqKeys {
+		if _, ok := mockKeys[key]; !ok {
+			unexpected = append(unexpected, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	var diff []string
+	if len(missing) > 0 {
+		diff = append(diff, "missing "+strings.Join(missing, ", "))
+	}
+	if len(unexpected) > 0 {
+		diff = append(diff, "unexpected "+strings.Join(unexpected, ", "))
+	}
+	return strings.Join(diff, "; ")
+}
@@ -0,0 +1,197 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// The requests which follow a redirect or retry a failed request are recorded as the steps of the sequence of the
+// first request, in the metadata of their mocks. In the test mode a step is served only once the steps before it
+// were, so that the redirect chains and the retry attempts of a call replay in their recorded order even when the
+// attempts are identical requests or are sent on new connections.
+
+const (
+	// sequenceKey and sequenceStepKey are the metadata of the mocks of the steps of a sequence
+	sequenceKey     = "sequence"
+	sequenceStepKey = "sequenceStep"
+	// followUpTimeout is the longest wait for the redirected or retried request after the response
+	followUpTimeout = 30 * time.Second
+)
+
+// sequenceStep is the last recorded step of a sequence, waiting for the request which follows it
+type sequenceStep struct {
+	id   string
+	step int
+	at   time.Time
+}
+
+// sequencer links the redirected and retried requests to their sequences while recording, across the connections
+// of the same app session and client
+type sequencer struct {
+	mu sync.Mutex
+	// the sequences waiting for a request, by the scope and the keys of the request which follows their last step
+	pending map[string]sequenceStep
+	count   int
+}
+
+func newSequencer() *sequencer {
+	return &sequencer{pending: map[string]sequenceStep{}}
+}
+
+// sequenceScope is the sequencer of the connections of an app session coming from a client, so that the identical
+// requests of other apps or clients recorded at the same time don't continue their sequences
+type sequenceScope struct {
+	*sequencer
+	origin string
+}
+
+// scope returns the sequencer of the app session and the client of the connection
+func (s *sequencer) scope(ctx context.Context, src net.Conn) *sequenceScope {
+	appID, _ := ctx.Value(models.AppIDKey).(uint64)
+	client := src.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(client); err == nil {
+		client = host
+	}
+	return &sequenceScope{sequencer: s, origin: fmt.Sprintf("%d %s", appID, client)}
+}
+
+// link returns the sequence and the step of the request, an empty sequence for the requests which neither follow nor
+// are followed by another one. A response redirecting the request or failing it makes the sequence wait for the
+// redirected or the retried request.
+func (s *sequenceScope) link(req *http.Request, reqBody []byte, statusCode int, location string, failed bool, reqTime, resTime time.Time) (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, pending := range s.pending {
+		if reqTime.Sub(pending.at) > followUpTimeout {
+			delete(s.pending, key)
+		}
+	}
+
+	id, step := "", 0
+	for _, key := range []string{s.key(retryKey(req.Method, req.Host, req.URL.RequestURI(), reqBody)), s.key(redirectKey(req.Host, req.URL.RequestURI()))} {
+		if pending, ok := s.pending[key]; ok {
+			delete(s.pending, key)
+			id, step = pending.id, pending.step+1
+			break
+		}
+	}
+
+	var next string
+	switch {
+	case failed || isRetryable(statusCode):
+		next = retryKey(req.Method, req.Host, req.URL.RequestURI(), reqBody)
+	case isRedirect(statusCode) && location != "":
+		base := &url.URL{Scheme: "http", Host: req.Host, Path: req.URL.Path, RawQuery: req.URL.RawQuery}
+		target, err := base.Parse(location)
+		if err != nil {
+			break
+		}
+		next = redirectKey(target.Host, target.RequestURI())
+	}
+	if next == "" {
+		return id, step
+	}
+	if id == "" {
+		s.count++
+		id, step = fmt.Sprintf("%s-%d", strconv.FormatInt(reqTime.UnixNano(), 36), s.count), 1
+	}
+	s.pending[s.key(next)] = sequenceStep{id: id, step: step, at: resTime}
+	return id, step
+}
+
+// key is the pending key of the request in the scope
+func (s *sequenceScope) key(requestKey string) string {
+	return s.origin + " " + requestKey
+}
+
+func retryKey(method, host, uri string, body []byte) string {
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	return fmt.Sprintf("retry %s %s%s %x", method, host, uri, h.Sum64())
+}
+
+func redirectKey(host, uri string) string {
+	return "redirect " + host + uri
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// isRetryable reports whether the clients retry the requests which got the status, the server errors, the timeouts
+// and the rate limits
+func isRetryable(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+}
+
+// withSequence adds the sequence and the step of the request to the metadata of its mock
+func withSequence(meta map[string]string, id string, step int) map[string]string {
+	if id != "" {
+		meta[sequenceKey] = id
+		meta[sequenceStepKey] = strconv.Itoa(step)
+	}
+	return meta
+}
+
+// sequenceOf returns the sequence and the step of the mock, an empty sequence for the mocks in none
+func sequenceOf(mock *models.Mock) (string, int) {
+	id := mock.Spec.Metadata[sequenceKey]
+	if id == "" {
+		return "", 0
+	}
+	step, err := strconv.Atoi(mock.Spec.Metadata[sequenceStepKey])
+	if err != nil {
+		return "", 0
+	}
+	return id, step
+}
+
+// inSequence leaves out the mocks of the steps whose previous steps weren't served yet, the served mocks being
+// consumed, and puts the next steps of the sequences in progress first so that they are preferred to the mocks of
+// the identical requests of other calls
+func inSequence(eligible, unserved []*models.Mock) []*models.Mock {
+	firstUnserved := firstUnservedSteps(unserved)
+	var next, rest []*models.Mock
+	for _, mock := range eligible {
+		id, step := sequenceOf(mock)
+		switch {
+		case id == "":
+			rest = append(rest, mock)
+		case step > firstUnserved[id]:
+			// an earlier step of the sequence is still to be served
+		case step > 1:
+			next = append(next, mock)
+		default:
+			rest = append(rest, mock)
+		}
+	}
+	return append(next, rest...)
+}
+
+// firstUnservedSteps returns the first step of every sequence which is still to be served
+func firstUnservedSteps(unserved []*models.Mock) map[string]int {
+	firstUnserved := map[string]int{}
+	for _, mock := range unserved {
+		id, step := sequenceOf(mock)
+		if id == "" {
+			continue
+		}
+		if first, ok := firstUnserved[id]; !ok || step < first {
+			firstUnserved[id] = step
+		}
+	}
+	return firstUnserved
+}
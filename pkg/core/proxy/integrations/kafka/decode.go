@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
+	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func decodeKafka(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, _ *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
+	logger.Debug("Into the kafka parser in test mode")
+	errCh := make(chan error, 1)
+	go func(errCh chan error, buf []byte) {
+		defer utils.Recover(logger)
+		defer close(errCh)
+		for {
+			messages, rest, err := splitMessages(buf)
+			if err != nil {
+				utils.LogError(logger, err, "failed to split the kafka requests")
+				errCh <- err
+				return
+			}
+			buf = rest
+			for _, message := range messages {
+				if err := respond(ctx, logger, clientConn, mockDb, message, opts); err != nil {
+					if ctx.Err() == nil {
+						errCh <- err
+					}
+					return
+				}
+			}
+
+			buffer, err := pUtil.ReadBytes(ctx, logger, clientConn)
+			if err != nil {
+				if err != io.EOF {
+					utils.LogError(logger, err, "failed to read the request message in proxy for kafka dependency")
+				}
+				errCh <- err
+				return
+			}
+			buf = append(buf, buffer...)
+		}
+	}(errCh, reqBuf)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+}
+
+// respond writes the response of the mock matching the request to the client, with the correlation id of the
+// request. A request matching no mock ends the connection, the client connecting again rather than waiting for the
+// response until it times out.
+func respond(ctx context.Context, logger *zap.Logger, clientConn net.Conn, mockDb integrations.MockMemDb, message []byte, opts models.OutgoingOptions) error {
+	h, err := parseRequestHeader(message)
+	if err != nil {
+		utils.LogError(logger, err, "failed to parse the kafka request header")
+		return err
+	}
+	topics, err := topicsOf(h, message)
+	if err != nil {
+		logger.Debug("failed to parse the topics of the kafka request", zap.String("api", apiName(h.apiKey)), zap.Error(err))
+	}
+	matched, responses, err := match(ctx, logger, mockDb, h, topics, message)
+	if err != nil {
+		utils.LogError(logger, err, "error while matching kafka mocks")
+	}
+	if !matched {
+		logger.Warn("no kafka mock matched the request, closing the connection", zap.String("api", apiName(h.apiKey)), zap.Int16("version", h.apiVersion), zap.Strings("topics", topics))
+		if opts.MatchDebug != nil {
+			mocks, err := mockDb.GetUnFilteredMocks()
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the mocks closest to the kafka request")
+			}
+			request := fmt.Sprintf("%s v%d topics %v", apiName(h.apiKey), h.apiVersion, topics)
+			util.TraceUnmatched(logger, opts.MatchDebug, models.KAFKA, request, closestMocks(mocks, h, topics, message))
+		}
+		return fmt.Errorf("no kafka mock matched the %s request", apiName(h.apiKey))
+	}
+	for _, response := range responses {
+		decoded, err := util.DecodeBase64(response.Message)
+		if err != nil {
+			utils.LogError(logger, err, "failed to decode the base64 response")
+			return err
+		}
+		_, err = clientConn.Write(frame(withCorrelationID(decoded, h.correlationID)))
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			utils.LogError(logger, err, "failed to write the response message to the client application")
+			return err
+		}
+	}
+	return nil
+}
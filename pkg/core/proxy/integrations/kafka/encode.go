@@ -0,0 +1,202 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
+	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func encodeKafka(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn, destConn net.Conn, mocks chan<- *models.Mock, _ models.OutgoingOptions) error {
+	clientBuffChan := make(chan []byte)
+	destBuffChan := make(chan []byte)
+	errChan := make(chan error)
+	destErrChan := make(chan error)
+
+	//get the error group from the context
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		return errors.New("failed to get the error group from the context")
+	}
+
+	// read requests from client
+	g.Go(func() error {
+		defer utils.Recover(logger)
+		defer close(clientBuffChan)
+		pUtil.ReadBuffConn(ctx, logger, clientConn, clientBuffChan, errChan)
+		return nil
+	})
+	// read responses from destination
+	g.Go(func() error {
+		defer utils.Recover(logger)
+		defer close(destBuffChan)
+		pUtil.ReadBuffConn(ctx, logger, destConn, destBuffChan, destErrChan)
+		return nil
+	})
+
+	port := ""
+	if _, p, err := net.SplitHostPort(destConn.RemoteAddr().String()); err == nil {
+		port = p
+	}
+	// the requests waiting for their responses by their correlation ids, the clients pipelining their requests
+	pending := map[int32]*models.Mock{}
+	// the bytes of the messages which aren't complete yet
+	var clientRest, destRest []byte
+	// recording stops at the first message which can't be parsed, the connection being forwarded as it is
+	recording := true
+
+	save := func(mock *models.Mock) {
+		select {
+		case <-ctx.Done():
+		case mocks <- mock:
+		}
+	}
+
+	onRequest := func(buffer []byte) {
+		if !recording {
+			return
+		}
+		var messages [][]byte
+		var err error
+		messages, clientRest, err = splitMessages(append(clientRest, buffer...))
+		if err != nil {
+			logger.Debug("failed to split the kafka requests, the connection isn't recorded anymore", zap.Error(err))
+			recording = false
+			return
+		}
+		for _, message := range messages {
+			h, err := parseRequestHeader(message)
+			if err != nil {
+				logger.Debug("failed to parse the kafka request header, the connection isn't recorded anymore", zap.Error(err))
+				recording = false
+				return
+			}
+			topics, err := topicsOf(h, message)
+			if err != nil {
+				logger.Debug("failed to parse the topics of the kafka request", zap.String("api", apiName(h.apiKey)), zap.Error(err))
+			}
+			metadata := map[string]string{"operation": apiName(h.apiKey)}
+			if port != "" {
+				metadata["port"] = port
+			}
+			if len(topics) > 0 {
+				metadata["topics"] = strings.Join(topics, ",")
+			}
+			mock := &models.Mock{
+				Version: models.GetVersion(),
+				Name:    "mocks",
+				Kind:    models.KAFKA,
+				Spec: models.MockSpec{
+					Metadata: metadata,
+					KafkaRequests: []models.KafkaPayload{{
+						Origin:        models.FromClient,
+						APIKey:        h.apiKey,
+						APIVersion:    h.apiVersion,
+						CorrelationID: h.correlationID,
+						ClientID:      h.clientID,
+						Topics:        topics,
+						Message:       util.EncodeBase64(message),
+					}},
+					ReqTimestampMock: time.Now(),
+				},
+			}
+			if producesNoResponse(h, message) {
+				mock.Spec.ResTimestampMock = mock.Spec.ReqTimestampMock
+				save(mock)
+				continue
+			}
+			pending[h.correlationID] = mock
+		}
+	}
+
+	onResponse := func(buffer []byte) {
+		if !recording {
+			return
+		}
+		var messages [][]byte
+		var err error
+		messages, destRest, err = splitMessages(append(destRest, buffer...))
+		if err != nil {
+			logger.Debug("failed to split the kafka responses, the connection isn't recorded anymore", zap.Error(err))
+			recording = false
+			return
+		}
+		for _, message := range messages {
+			correlationID, err := correlationIDOf(message)
+			if err != nil {
+				continue
+			}
+			mock, ok := pending[correlationID]
+			if !ok {
+				logger.Debug("the kafka broker responded to no request", zap.Int32("correlationID", correlationID))
+				continue
+			}
+			delete(pending, correlationID)
+			mock.Spec.KafkaResponses = []models.KafkaPayload{{
+				Origin:        models.FromServer,
+				CorrelationID: correlationID,
+				Message:       util.EncodeBase64(message),
+			}}
+			// the records delivered to the consumers are kept readable, the consumed messages being captured from them
+			if req := mock.Spec.KafkaRequests[0]; req.APIKey == apiFetch {
+				records, err := fetchedRecords(req.APIVersion, message)
+				if err != nil {
+					logger.Debug("failed to decode the records of the kafka fetch response", zap.Error(err))
+				}
+				mock.Spec.KafkaResponses[0].Records = records
+			}
+			mock.Spec.ResTimestampMock = time.Now()
+			save(mock)
+		}
+	}
+
+	_, err := destConn.Write(reqBuf)
+	if err != nil {
+		utils.LogError(logger, err, "failed to write request message to the destination server")
+		return err
+	}
+	onRequest(reqBuf)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case buffer := <-clientBuffChan:
+			// Write the request message to the destination
+			_, err := destConn.Write(buffer)
+			if err != nil {
+				utils.LogError(logger, err, "failed to write request message to the destination server")
+				return err
+			}
+			onRequest(buffer)
+		case buffer := <-destBuffChan:
+			// Write the response message to the client
+			_, err := clientConn.Write(buffer)
+			if err != nil {
+				utils.LogError(logger, err, "failed to write response message to the client")
+				return err
+			}
+			onResponse(buffer)
+		case err := <-errChan:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case err := <-destErrChan:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
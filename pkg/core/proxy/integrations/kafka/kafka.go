@@ -0,0 +1,89 @@
+// Package kafka provides the integration of the kafka brokers, recording and replaying the requests of the wire
+// protocol of the producers and the consumers.
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/core/proxy/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	integrations.Register("kafka", NewKafka)
+}
+
+type Kafka struct {
+	logger *zap.Logger
+}
+
+func NewKafka(logger *zap.Logger) integrations.Integrations {
+	return &Kafka{
+		logger: logger,
+	}
+}
+
+// MatchType determines if the outgoing network call is kafka by the header of the first request of the client,
+// usually an ApiVersions or a Metadata request
+func (k *Kafka) MatchType(_ context.Context, reqBuf []byte) bool {
+	if len(reqBuf) < 14 {
+		return false
+	}
+	size := int(int32(binary.BigEndian.Uint32(reqBuf)))
+	if size < 10 || size > maxMessageSize || size < len(reqBuf)-4 {
+		return false
+	}
+	// the postgres startup message has the same layout as the version 0 of a metadata request
+	if binary.BigEndian.Uint32(reqBuf[4:8]) == 0x00030000 {
+		return false
+	}
+	h, err := parseRequestHeader(reqBuf[4:])
+	if err != nil || h.apiVersion > 20 {
+		return false
+	}
+	for _, c := range []byte(h.clientID) {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func (k *Kafka) RecordOutgoing(ctx context.Context, src net.Conn, dst net.Conn, mocks chan<- *models.Mock, opts models.OutgoingOptions) error {
+	logger := k.logger.With(zap.Any("Client IP Address", src.RemoteAddr().String()), zap.Any("Client ConnectionID", ctx.Value(models.ClientConnectionIDKey).(string)), zap.Any("Destination ConnectionID", ctx.Value(models.DestConnectionIDKey).(string)))
+
+	reqBuf, err := util.ReadInitialBuf(ctx, logger, src)
+	if err != nil {
+		utils.LogError(logger, err, "failed to read the initial kafka message")
+		return err
+	}
+
+	err = encodeKafka(ctx, logger, reqBuf, src, dst, mocks, opts)
+	if err != nil {
+		utils.LogError(logger, err, "failed to encode the kafka message into the yaml")
+		return err
+	}
+	return nil
+}
+
+func (k *Kafka) MockOutgoing(ctx context.Context, src net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
+	logger := k.logger.With(zap.Any("Client IP Address", src.RemoteAddr().String()), zap.Any("Client ConnectionID", util.GetNextID()), zap.Any("Destination ConnectionID", util.GetNextID()))
+
+	reqBuf, err := util.ReadInitialBuf(ctx, logger, src)
+	if err != nil {
+		utils.LogError(logger, err, "failed to read the initial kafka message")
+		return err
+	}
+
+	err = decodeKafka(ctx, logger, reqBuf, src, dstCfg, mockDb, opts)
+	if err != nil {
+		utils.LogError(logger, err, "failed to decode the kafka message")
+		return err
+	}
+	return nil
+}
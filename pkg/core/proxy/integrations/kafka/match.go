@@ -0,0 +1,160 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// match returns the responses of the mock recorded for the request, of the same api, version and topics. The mocks
+// recorded during the test case are consumed, the others are reused e.g. for the ApiVersions and the Metadata
+// requests of the connections the clients open at startup. Among them the mock of the same request is preferred, and
+// then the one of the same correlation id, which the clients number the requests of a connection with. The fetches
+// delivering a recorded message are only matched during its test case, so that the message isn't delivered again.
+func match(ctx context.Context, logger *zap.Logger, mockDb integrations.MockMemDb, h requestHeader, topics []string, message []byte) (bool, []models.KafkaPayload, error) {
+	for {
+		if ctx.Err() != nil {
+			return false, nil, ctx.Err()
+		}
+		mocks, err := mockDb.GetUnFilteredMocks()
+		if err != nil {
+			return false, nil, fmt.Errorf("error while getting unfiltered mocks %v", err)
+		}
+
+		var filteredMocks []*models.Mock
+		var unfilteredMocks []*models.Mock
+		for _, mock := range mocks {
+			if mock.Kind != models.KAFKA || len(mock.Spec.KafkaRequests) == 0 {
+				continue
+			}
+			if mock.TestModeInfo.IsFiltered {
+				filteredMocks = append(filteredMocks, mock)
+			} else if mock.Spec.Metadata["message"] == "" {
+				unfilteredMocks = append(unfilteredMocks, mock)
+			}
+		}
+
+		var matched *models.Mock
+		if idx := findBestMatch(filteredMocks, h, topics, message); idx != -1 {
+			matched = filteredMocks[idx]
+		} else if idx := findBestMatch(unfilteredMocks, h, topics, message); idx != -1 {
+			matched = unfilteredMocks[idx]
+		}
+		if matched == nil {
+			return false, nil, nil
+		}
+
+		logger.Debug("Matched mock", zap.String("mock", matched.Name))
+		responses := make([]models.KafkaPayload, len(matched.Spec.KafkaResponses))
+		copy(responses, matched.Spec.KafkaResponses)
+		if matched.TestModeInfo.IsFiltered {
+			originalMatchedMock := *matched
+			matched.TestModeInfo.IsFiltered = false
+			matched.TestModeInfo.SortOrder = math.MaxInt64
+			if !mockDb.UpdateUnFilteredMock(&originalMatchedMock, matched) {
+				// the mock was consumed by another connection meanwhile
+				continue
+			}
+		} else if err := mockDb.FlagMockAsUsed(matched); err != nil {
+			logger.Error("failed to flag mock as used", zap.Error(err))
+		}
+		return true, responses, nil
+	}
+}
+
+// findBestMatch returns the index of the mock of the same api, version and topics which is the closest to the request
+func findBestMatch(mocks []*models.Mock, h requestHeader, topics []string, message []byte) int {
+	best, bestIdx := -1, -1
+	for idx, mock := range mocks {
+		req := mock.Spec.KafkaRequests[0]
+		if req.APIKey != h.apiKey || req.APIVersion != h.apiVersion || !sameTopics(req.Topics, topics) {
+			continue
+		}
+		score := 0
+		if recorded, err := util.DecodeBase64(req.Message); err == nil && sameRequest(recorded, message) {
+			score += 2
+		}
+		if req.CorrelationID == h.correlationID {
+			score++
+		}
+		if score > best {
+			best, bestIdx = score, idx
+		}
+	}
+	return bestIdx
+}
+
+// sameRequest reports whether the requests are the same but for their correlation ids
+func sameRequest(a, b []byte) bool {
+	if len(a) != len(b) || len(a) < 8 {
+		return false
+	}
+	return bytes.Equal(a[:4], b[:4]) && bytes.Equal(a[8:], b[8:])
+}
+
+func sameTopics(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, topic := range a {
+		seen[topic]++
+	}
+	for _, topic := range b {
+		if seen[topic] == 0 {
+			return false
+		}
+		seen[topic]--
+	}
+	return true
+}
+
+// closestMocks compares the request which matched no mock with the kafka mocks on their api, version, topics, body
+// and correlation id, the body adding its similarity to the score of the mocks
+func closestMocks(mocks []*models.Mock, h requestHeader, topics []string, message []byte) []models.MockCandidate {
+	var candidates []models.MockCandidate
+	for _, mock := range mocks {
+		if mock.Kind != models.KAFKA || len(mock.Spec.KafkaRequests) == 0 {
+			continue
+		}
+		req := mock.Spec.KafkaRequests[0]
+		var mismatches []string
+		if req.APIKey != h.apiKey {
+			mismatches = append(mismatches, fmt.Sprintf("api: the mock has %s, the request %s", apiName(req.APIKey), apiName(h.apiKey)))
+		}
+		if req.APIVersion != h.apiVersion {
+			mismatches = append(mismatches, fmt.Sprintf("version: the mock has %d, the request %d", req.APIVersion, h.apiVersion))
+		}
+		if !sameTopics(req.Topics, topics) {
+			mismatches = append(mismatches, fmt.Sprintf("topics: the mock has %v, the request %v", req.Topics, topics))
+		}
+		if req.CorrelationID != h.correlationID {
+			mismatches = append(mismatches, fmt.Sprintf("correlation id: the mock has %d, the request %d", req.CorrelationID, h.correlationID))
+		}
+		// the body is compared without the correlation id
+		similarity := 0.0
+		if recorded, err := util.DecodeBase64(req.Message); err == nil {
+			if sameRequest(recorded, message) {
+				similarity = 1
+			} else if len(recorded) >= 8 && len(message) >= 8 {
+				similarity = util.Similarity(recorded[8:], message[8:])
+			}
+		}
+		equal := float64(4-len(mismatches)) + similarity
+		if similarity < 1 {
+			mismatches = append(mismatches, fmt.Sprintf("body: %.0f%% similar", similarity*100))
+		}
+		candidates = append(candidates, models.MockCandidate{
+			Mock:       mock.Name,
+			Score:      util.Score(equal, 5),
+			Mismatches: mismatches,
+		})
+	}
+	return candidates
+}
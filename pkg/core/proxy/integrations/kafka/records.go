@@ -0,0 +1,214 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// the compression codecs of the record batches, in the lowest bits of their attributes
+const (
+	compressionNone = 0
+	compressionGzip = 1
+	compressionMask = 0x07
+	// controlBatch marks the batches of the transaction markers, which aren't delivered to the consumers
+	controlBatch = 0x20
+)
+
+// fetchedRecords returns the records the fetch response delivers, those of the record batches compressed with
+// another codec than gzip being left out. The topics of the responses of version 13 and later are their hex ids.
+func fetchedRecords(apiVersion int16, message []byte) ([]models.KafkaRecord, error) {
+	v := apiVersion
+	r := &reader{buf: message, compact: v >= flexibleVersions[apiFetch]}
+	r.skip(4) // correlation id
+	r.taggedFields()
+	if v >= 1 {
+		r.skip(4) // throttle time
+	}
+	if v >= 7 {
+		r.skip(2 + 4) // error code and session id
+	}
+	var records []models.KafkaRecord
+	n := r.arrayLen()
+	for i := 0; i < n && r.err == nil; i++ {
+		var topic string
+		if v >= 13 {
+			topic = r.uuid()
+		} else {
+			topic = r.nullableString()
+		}
+		partitions := r.arrayLen()
+		for p := 0; p < partitions && r.err == nil; p++ {
+			partition := r.int32()
+			r.skip(2 + 8) // error code and high watermark
+			if v >= 4 {
+				r.skip(8) // last stable offset
+			}
+			if v >= 5 {
+				r.skip(8) // log start offset
+			}
+			if v >= 4 {
+				aborted := r.arrayLen()
+				for a := 0; a < aborted && r.err == nil; a++ {
+					r.skip(8 + 8) // producer id and first offset
+					r.taggedFields()
+				}
+			}
+			if v >= 11 {
+				r.skip(4) // preferred read replica
+			}
+			batches := r.bytesValue()
+			r.taggedFields()
+			if r.err != nil {
+				break
+			}
+			decoded, err := batchRecords(batches)
+			if err != nil {
+				return records, err
+			}
+			for _, record := range decoded {
+				record.Topic, record.Partition = topic, partition
+				records = append(records, record)
+			}
+		}
+		r.taggedFields()
+	}
+	return records, r.err
+}
+
+// batchRecords decodes the record batches (magic 2) of a partition. The brokers may cut the last batch short at
+// the size limit of the fetch, which the consumers skip as well.
+func batchRecords(buf []byte) ([]models.KafkaRecord, error) {
+	var records []models.KafkaRecord
+	for len(buf) >= 12 {
+		baseOffset := int64(binary.BigEndian.Uint64(buf))
+		size := int(int32(binary.BigEndian.Uint32(buf[8:])))
+		if size < 0 || len(buf) < 12+size {
+			break
+		}
+		batch := buf[12 : 12+size]
+		buf = buf[12+size:]
+
+		r := &reader{buf: batch}
+		r.skip(4) // partition leader epoch
+		magic := r.int8()
+		if r.err != nil || magic != 2 {
+			// the message sets of the older magics aren't decoded
+			continue
+		}
+		r.skip(4) // crc
+		attributes := r.int16()
+		r.skip(4 + 8 + 8 + 8 + 2 + 4) // last offset delta, timestamps, producer id and epoch and base sequence
+		count := int(r.int32())
+		if r.err != nil {
+			return records, r.err
+		}
+		if attributes&controlBatch != 0 {
+			continue
+		}
+
+		body := batch[r.pos:]
+		switch attributes & compressionMask {
+		case compressionNone:
+		case compressionGzip:
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				return records, err
+			}
+			body, err = io.ReadAll(zr)
+			if err != nil {
+				return records, err
+			}
+		default:
+			continue
+		}
+
+		br := &reader{buf: body}
+		for i := 0; i < count && br.err == nil; i++ {
+			length := int(br.varint())
+			start := br.pos
+			br.skip(1)  // attributes
+			br.varint() // timestamp delta
+			offsetDelta := br.varint()
+			record := models.KafkaRecord{
+				Offset: baseOffset + offsetDelta,
+				Key:    string(br.varBytes()),
+				Value:  string(br.varBytes()),
+			}
+			headers := int(br.varint())
+			for h := 0; h < headers && br.err == nil; h++ {
+				if record.Headers == nil {
+					record.Headers = map[string]string{}
+				}
+				key := string(br.varBytes())
+				record.Headers[key] = string(br.varBytes())
+			}
+			if br.err != nil {
+				return records, br.err
+			}
+			if br.pos != start+length {
+				return records, fmt.Errorf("invalid kafka record of length %d", length)
+			}
+			records = append(records, record)
+		}
+		if br.err != nil {
+			return records, br.err
+		}
+	}
+	return records, nil
+}
+
+func (r *reader) int8() int8 {
+	start := r.pos
+	r.skip(1)
+	if r.err != nil {
+		return 0
+	}
+	return int8(r.buf[start])
+}
+
+// varint reads the zigzag encoded varints of the records
+func (r *reader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.buf[r.pos:])
+	if n <= 0 {
+		r.err = errIncomplete
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+// varBytes reads the keys and the values of the records, whose lengths are varints
+func (r *reader) varBytes() []byte {
+	n := int(r.varint())
+	if n < 0 || r.err != nil {
+		return nil
+	}
+	start := r.pos
+	r.skip(n)
+	if r.err != nil {
+		return nil
+	}
+	return r.buf[start:r.pos]
+}
+
+// bytesValue reads the nullable bytes, e.g. the records of a partition
+func (r *reader) bytesValue() []byte {
+	n := r.length(func() int { return int(r.int32()) })
+	if n < 0 || r.err != nil {
+		return nil
+	}
+	start := r.pos
+	r.skip(n)
+	if r.err != nil {
+		return nil
+	}
+	return r.buf[start:r.pos]
+}
@@ -0,0 +1,384 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// the kafka apis whose requests are parsed for their topics, see https://kafka.apache.org/protocol#protocol_api_keys
+const (
+	apiProduce     int16 = 0
+	apiFetch       int16 = 1
+	apiListOffsets int16 = 2
+	apiMetadata    int16 = 3
+	// maxAPIKey is the largest api key a broker accepts
+	maxAPIKey int16 = 80
+)
+
+// apiNames are the names of the common apis written in the metadata of the mocks
+var apiNames = map[int16]string{
+	0:  "Produce",
+	1:  "Fetch",
+	2:  "ListOffsets",
+	3:  "Metadata",
+	8:  "OffsetCommit",
+	9:  "OffsetFetch",
+	10: "FindCoordinator",
+	11: "JoinGroup",
+	12: "Heartbeat",
+	13: "LeaveGroup",
+	14: "SyncGroup",
+	15: "DescribeGroups",
+	16: "ListGroups",
+	17: "SaslHandshake",
+	18: "ApiVersions",
+	19: "CreateTopics",
+	22: "InitProducerId",
+	36: "SaslAuthenticate",
+}
+
+// apiName returns the name of the api, its key for the apis which aren't named
+func apiName(key int16) string {
+	if name, ok := apiNames[key]; ok {
+		return name
+	}
+	return fmt.Sprintf("ApiKey%d", key)
+}
+
+// flexibleVersions are the first versions of the parsed apis whose requests have tagged fields and compact strings
+// and arrays
+var flexibleVersions = map[int16]int16{
+	apiProduce:     9,
+	apiFetch:       12,
+	apiListOffsets: 6,
+	apiMetadata:    9,
+}
+
+// maxMessageSize is the largest message accepted, larger sizes being a corrupted stream
+const maxMessageSize = 100 << 20
+
+// errIncomplete is a buffer which ends before the message it starts
+var errIncomplete = errors.New("incomplete kafka message")
+
+// nextMessage returns the first message of the buffer without its size, and the rest of the buffer
+func nextMessage(buf []byte) ([]byte, []byte, error) {
+	if len(buf) < 4 {
+		return nil, buf, errIncomplete
+	}
+	size := int(int32(binary.BigEndian.Uint32(buf)))
+	if size < 4 || size > maxMessageSize {
+		return nil, buf, fmt.Errorf("invalid kafka message size %d", size)
+	}
+	if len(buf) < 4+size {
+		return nil, buf, errIncomplete
+	}
+	return buf[4 : 4+size], buf[4+size:], nil
+}
+
+// splitMessages returns the complete messages of the buffer, with the bytes of the message which isn't complete yet
+func splitMessages(buf []byte) ([][]byte, []byte, error) {
+	var messages [][]byte
+	for len(buf) > 0 {
+		message, rest, err := nextMessage(buf)
+		if errors.Is(err, errIncomplete) {
+			break
+		}
+		if err != nil {
+			return messages, buf, err
+		}
+		messages = append(messages, message)
+		buf = rest
+	}
+	return messages, buf, nil
+}
+
+// frame returns the message with its size, as it is sent
+func frame(message []byte) []byte {
+	buf := make([]byte, 4, 4+len(message))
+	binary.BigEndian.PutUint32(buf, uint32(len(message)))
+	return append(buf, message...)
+}
+
+// requestHeader is the header of a request of a kafka client
+type requestHeader struct {
+	apiKey        int16
+	apiVersion    int16
+	correlationID int32
+	clientID      string
+	// size is the length of the header in the message
+	size int
+}
+
+// parseRequestHeader parses the header at the start of a request message
+func parseRequestHeader(message []byte) (requestHeader, error) {
+	r := &reader{buf: message}
+	h := requestHeader{
+		apiKey:        r.int16(),
+		apiVersion:    r.int16(),
+		correlationID: r.int32(),
+	}
+	// the client id is never a compact string, even in the flexible versions
+	h.clientID = r.nullableString()
+	if r.err != nil {
+		return requestHeader{}, r.err
+	}
+	if h.apiKey < 0 || h.apiKey > maxAPIKey || h.apiVersion < 0 {
+		return requestHeader{}, fmt.Errorf("invalid kafka request of api key %d and version %d", h.apiKey, h.apiVersion)
+	}
+	if isFlexible(h) {
+		r.compact = true
+		r.taggedFields()
+	}
+	h.size = r.pos
+	return h, r.err
+}
+
+func isFlexible(h requestHeader) bool {
+	first, ok := flexibleVersions[h.apiKey]
+	return ok && h.apiVersion >= first
+}
+
+// correlationIDOf returns the correlation id of a response message
+func correlationIDOf(message []byte) (int32, error) {
+	if len(message) < 4 {
+		return 0, errIncomplete
+	}
+	return int32(binary.BigEndian.Uint32(message)), nil
+}
+
+// withCorrelationID returns a copy of the message with the correlation id, which starts the responses
+func withCorrelationID(message []byte, correlationID int32) []byte {
+	copied := append([]byte{}, message...)
+	if len(copied) >= 4 {
+		binary.BigEndian.PutUint32(copied, uint32(correlationID))
+	}
+	return copied
+}
+
+// topicsOf returns the topics of the produce, fetch, list offsets and metadata requests. The topics of the fetch
+// requests sent by their ids are returned as the hex ids.
+func topicsOf(h requestHeader, message []byte) ([]string, error) {
+	r := &reader{buf: message, pos: h.size, compact: isFlexible(h)}
+	v := h.apiVersion
+	var topics []string
+	switch h.apiKey {
+	case apiProduce:
+		if v >= 3 {
+			r.nullableString() // transactional id
+		}
+		r.skip(2 + 4) // acks and timeout
+		n := r.arrayLen()
+		for i := 0; i < n && r.err == nil; i++ {
+			topics = append(topics, r.nullableString())
+			partitions := r.arrayLen()
+			for p := 0; p < partitions && r.err == nil; p++ {
+				r.skip(4) // partition index
+				r.bytes() // records
+				r.taggedFields()
+			}
+			r.taggedFields()
+		}
+	case apiFetch:
+		if v < 15 {
+			r.skip(4) // replica id
+		}
+		r.skip(4 + 4) // max wait and min bytes
+		if v >= 3 {
+			r.skip(4) // max bytes
+		}
+		if v >= 4 {
+			r.skip(1) // isolation level
+		}
+		if v >= 7 {
+			r.skip(4 + 4) // session id and epoch
+		}
+		n := r.arrayLen()
+		for i := 0; i < n && r.err == nil; i++ {
+			if v >= 13 {
+				topics = append(topics, r.uuid())
+			} else {
+				topics = append(topics, r.nullableString())
+			}
+			partitions := r.arrayLen()
+			for p := 0; p < partitions && r.err == nil; p++ {
+				r.skip(4) // partition
+				if v >= 9 {
+					r.skip(4) // current leader epoch
+				}
+				r.skip(8) // fetch offset
+				if v >= 12 {
+					r.skip(4) // last fetched epoch
+				}
+				if v >= 5 {
+					r.skip(8) // log start offset
+				}
+				r.skip(4) // partition max bytes
+				r.taggedFields()
+			}
+			r.taggedFields()
+		}
+	case apiListOffsets:
+		r.skip(4) // replica id
+		if v >= 2 {
+			r.skip(1) // isolation level
+		}
+		n := r.arrayLen()
+		for i := 0; i < n && r.err == nil; i++ {
+			topics = append(topics, r.nullableString())
+			partitions := r.arrayLen()
+			for p := 0; p < partitions && r.err == nil; p++ {
+				r.skip(4) // partition index
+				if v >= 4 {
+					r.skip(4) // current leader epoch
+				}
+				r.skip(8) // timestamp
+				if v == 0 {
+					r.skip(4) // max num offsets
+				}
+				r.taggedFields()
+			}
+			r.taggedFields()
+		}
+	case apiMetadata:
+		// the topics are null or empty when the metadata of all of them is requested
+		n := r.arrayLen()
+		for i := 0; i < n && r.err == nil; i++ {
+			if v >= 10 {
+				id := r.uuid()
+				if name := r.nullableString(); name != "" {
+					id = name
+				}
+				topics = append(topics, id)
+			} else {
+				topics = append(topics, r.nullableString())
+			}
+			r.taggedFields()
+		}
+	}
+	return topics, r.err
+}
+
+// producesNoResponse reports whether the request is a produce request with acks set to 0, which the broker doesn't
+// answer
+func producesNoResponse(h requestHeader, message []byte) bool {
+	if h.apiKey != apiProduce {
+		return false
+	}
+	r := &reader{buf: message, pos: h.size, compact: isFlexible(h)}
+	if h.apiVersion >= 3 {
+		r.nullableString()
+	}
+	return r.int16() == 0 && r.err == nil
+}
+
+// reader reads the primitive types of the kafka protocol, keeping the first error
+type reader struct {
+	buf []byte
+	pos int
+	err error
+	// compact is set for the flexible versions, whose strings, bytes and arrays have varint lengths
+	compact bool
+}
+
+func (r *reader) skip(n int) {
+	if r.err != nil {
+		return
+	}
+	if n < 0 || r.pos+n > len(r.buf) {
+		r.err = errIncomplete
+		return
+	}
+	r.pos += n
+}
+
+func (r *reader) int16() int16 {
+	start := r.pos
+	r.skip(2)
+	if r.err != nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(r.buf[start:]))
+}
+
+func (r *reader) int32() int32 {
+	start := r.pos
+	r.skip(4)
+	if r.err != nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(r.buf[start:]))
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		r.err = errIncomplete
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+// length reads the length of a string, bytes or an array, -1 for the null ones
+func (r *reader) length(classic func() int) int {
+	if r.compact {
+		return int(r.uvarint()) - 1
+	}
+	return classic()
+}
+
+func (r *reader) nullableString() string {
+	n := r.length(func() int { return int(r.int16()) })
+	if n < 0 || r.err != nil {
+		return ""
+	}
+	start := r.pos
+	r.skip(n)
+	if r.err != nil {
+		return ""
+	}
+	return string(r.buf[start:r.pos])
+}
+
+func (r *reader) bytes() {
+	n := r.length(func() int { return int(r.int32()) })
+	if n > 0 {
+		r.skip(n)
+	}
+}
+
+func (r *reader) arrayLen() int {
+	n := r.length(func() int { return int(r.int32()) })
+	if n > len(r.buf) {
+		// every element takes a byte at least
+		r.err = fmt.Errorf("invalid kafka array length %d", n)
+		return 0
+	}
+	return n
+}
+
+func (r *reader) uuid() string {
+	start := r.pos
+	r.skip(16)
+	if r.err != nil {
+		return ""
+	}
+	return hex.EncodeToString(r.buf[start:r.pos])
+}
+
+// taggedFields skips the tagged fields of the flexible versions
+func (r *reader) taggedFields() {
+	if !r.compact {
+		return
+	}
+	n := r.uvarint()
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		r.uvarint() // tag
+		r.skip(int(r.uvarint()))
+	}
+}
@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+// defaultAwaitTime is how long the server blocks a getMore of a tailable await cursor when no maxTimeMS is given.
+const defaultAwaitTime = time.Second
+
+// isTailableCursor reports whether the request opens a cursor which stays open after its recorded batches
+// are consumed, i.e. a tailable find or a change stream aggregation.
+func isTailableCursor(op Operation) bool {
+	msg, ok := op.(*opMsg)
+	if !ok {
+		return false
+	}
+	for _, section := range msg.sections {
+		single, ok := section.(*opMsgSectionSingle)
+		if !ok {
+			continue
+		}
+		if tailable, ok := single.msg.Lookup("tailable").BooleanOK(); ok && tailable {
+			return true
+		}
+		pipeline, ok := single.msg.Lookup("pipeline").ArrayOK()
+		if !ok {
+			continue
+		}
+		stages, err := pipeline.Values()
+		if err != nil || len(stages) == 0 {
+			continue
+		}
+		if stage, ok := stages[0].DocumentOK(); ok {
+			if _, err := stage.LookupErr("$changeStream"); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getMoreDetails returns the cursor id, the namespace and the await time of a getMore request
+func getMoreDetails(op Operation) (int64, string, time.Duration, bool) {
+	msg, ok := op.(*opMsg)
+	if !ok {
+		return 0, "", 0, false
+	}
+	for _, section := range msg.sections {
+		single, ok := section.(*opMsgSectionSingle)
+		if !ok {
+			continue
+		}
+		cursorID, ok := single.msg.Lookup("getMore").Int64OK()
+		if !ok {
+			continue
+		}
+		collection, _ := single.msg.Lookup("collection").StringValueOK()
+		db, _ := single.msg.Lookup("$db").StringValueOK()
+		awaitTime := defaultAwaitTime
+		if maxTimeMS, ok := single.msg.Lookup("maxTimeMS").AsInt64OK(); ok && maxTimeMS > 0 {
+			awaitTime = time.Duration(maxTimeMS) * time.Millisecond
+		}
+		return cursorID, db + "." + collection, awaitTime, true
+	}
+	return 0, "", 0, false
+}
+
+// emptyBatchReply answers the getMore of a tailable cursor whose recorded batches are all consumed.
+// Like the server, it waits for the await time and returns an empty batch keeping the cursor open,
+// so the application keeps polling instead of the call being passed through to the database.
+func emptyBatchReply(ctx context.Context, cursorID int64, ns string, awaitTime time.Duration) (*opMsg, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(awaitTime):
+	}
+	doc, err := bson.Marshal(bson.D{
+		{Key: "cursor", Value: bson.D{
+			{Key: "nextBatch", Value: bson.A{}},
+			{Key: "id", Value: cursorID},
+			{Key: "ns", Value: ns},
+		}},
+		{Key: "ok", Value: 1.0},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &opMsg{
+		flags:    wiremessage.MsgFlag(0),
+		sections: []opMsgSection{&opMsgSectionSingle{msg: bsoncore.Document(doc)}},
+	}, nil
+}
@@ -28,6 +28,8 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 		defer utils.Recover(logger)
 		defer close(errCh)
 		var readRequestDelay time.Duration
+		// the cursors of this connection which stay open once their recorded batches are consumed
+		tailableCursors := map[int64]bool{}
 		for {
 			configMocks, err := mockDb.GetUnFilteredMocks()
 			if err != nil {
@@ -86,7 +88,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 						errCh <- err
 						return
 					}
-					requestBuffers = append(requestBuffers, reqBuf)
+					requestBuffers = append(requestBuffers, requestBuffer1)
 					readRequestDelay = time.Since(started)
 
 					if len(requestBuffer1) == 0 {
@@ -99,15 +101,15 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 						errCh <- err
 						return
 					}
-					if mongoReqVal, ok := mongoReq.(models.MongoOpMessage); ok && !hasSecondSetBit(mongoReqVal.FlagBits) {
-						logger.Debug("the request from the client is complete since the more_to_come flagbit is 0")
-						break
-					}
 					mongoRequests = append(mongoRequests, models.MongoRequest{
 						Header:    &reqHeader,
 						Message:   mongoReq,
 						ReadDelay: int64(readRequestDelay),
 					})
+					if mongoReqVal, ok := mongoReq.(*models.MongoOpMessage); ok && !hasSecondSetBit(mongoReqVal.FlagBits) {
+						logger.Debug("the request from the client is complete since the more_to_come flagbit is 0")
+						break
+					}
 				}
 			}
 			if isHeartBeat(logger, opReq, *mongoRequests[0].Header, mongoRequests[0].Message) {
@@ -245,6 +247,30 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 					return
 				}
 				if !matched {
+					if cursorID, ns, awaitTime, ok := getMoreDetails(opReq); ok && tailableCursors[cursorID] {
+						logger.Debug("the recorded batches of the tailable cursor are consumed, replying with an empty batch", zap.Int64("cursorID", cursorID))
+						message, err := emptyBatchReply(ctx, cursorID, ns, awaitTime)
+						if err != nil {
+							if ctx.Err() != nil {
+								return
+							}
+							utils.LogError(logger, err, "failed to build the empty batch reply of the tailable cursor")
+							errCh <- err
+							return
+						}
+						_, err = clientConn.Write(message.Encode(mongoRequests[0].Header.RequestID, wiremessage.NextRequestID()))
+						if err != nil {
+							if ctx.Err() != nil {
+								return
+							}
+							utils.LogError(logger, err, "failed to write the empty batch reply to mongo client")
+							errCh <- err
+							return
+						}
+						reqBuf = []byte("read form client conn")
+						requestBuffers = [][]byte{}
+						continue
+					}
 					logger.Debug("mongo request not matched with any tcsMocks", zap.Any("request", mongoRequests))
 					reqBuf, err = util.PassThrough(ctx, logger, clientConn, dstCfg, requestBuffers)
 					if err != nil {
@@ -270,6 +296,9 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 						errCh <- err
 						return
 					}
+					if cursorID, ok := message.CursorID(); ok && cursorID != 0 && isTailableCursor(opReq) {
+						tailableCursors[cursorID] = true
+					}
 					requestID := wiremessage.NextRequestID()
 					_, err = clientConn.Write(message.Encode(responseTo, requestID))
 					if err != nil {
@@ -122,15 +122,15 @@ func encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 						errCh <- err
 						return nil
 					}
-					if mongoReqVal, ok := mongoReq.(models.MongoOpMessage); ok && !hasSecondSetBit(mongoReqVal.FlagBits) {
-						logger.Debug("the request from the client is complete since the more_to_come flagbit is 0")
-						break
-					}
 					mongoRequests = append(mongoRequests, models.MongoRequest{
 						Header:    &reqHeader,
 						Message:   mongoReq,
 						ReadDelay: int64(readRequestDelay),
 					})
+					if mongoReqVal, ok := mongoReq.(*models.MongoOpMessage); ok && !hasSecondSetBit(mongoReqVal.FlagBits) {
+						logger.Debug("the request from the client is complete since the more_to_come flagbit is 0")
+						break
+					}
 				}
 			}
 
@@ -244,15 +244,15 @@ func encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 						errCh <- err
 						return nil
 					}
-					if mongoRespVal, ok := mongoResp.(models.MongoOpMessage); ok && !hasSecondSetBit(mongoRespVal.FlagBits) {
-						logger.Debug("the response from the server is complete since the more_to_come flagbit is 0")
-						break
-					}
 					mongoResponses = append(mongoResponses, models.MongoResponse{
 						Header:    &respHeader,
 						Message:   mongoResp,
 						ReadDelay: int64(readResponseDelay),
 					})
+					if mongoRespVal, ok := mongoResp.(*models.MongoOpMessage); ok && !hasSecondSetBit(mongoRespVal.FlagBits) {
+						logger.Debug("the response from the server is complete since the more_to_come flagbit is 0")
+						break
+					}
 				}
 			}
 
@@ -35,6 +35,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 	go func(errCh chan error, configMocks []*models.Mock, tcsMocks []*models.Mock, prevRequest string, requestBuffers [][]byte) {
 		defer utils.Recover(logger)
 		defer close(errCh)
+		stmts := preparedStmts{}
 		for {
 			//log.Debug("Config and TCS Mocks", zap.Any("configMocks", configMocks), zap.Any("tcsMocks", tcsMocks))
 			if firstLoop || doHandshakeAgain {
@@ -73,13 +74,13 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 				matchedReqIndex := 0
 				configMocks[matchedIndex].Spec.MySQLResponses = append(configMocks[matchedIndex].Spec.MySQLResponses[:matchedReqIndex], configMocks[matchedIndex].Spec.MySQLResponses[matchedReqIndex+1:]...)
 				if len(configMocks[matchedIndex].Spec.MySQLResponses) == 0 {
-					configMocks = append(configMocks[:matchedIndex], configMocks[matchedIndex+1:]...)
 					err = mockDb.FlagMockAsUsed(configMocks[matchedIndex])
 					if err != nil {
 						utils.LogError(logger, err, "Failed to flag mock as used")
 						errCh <- err
 						return
 					}
+					configMocks = append(configMocks[:matchedIndex], configMocks[matchedIndex+1:]...)
 				}
 				//h.SetConfigMocks(configMocks)
 				firstLoop = false
@@ -148,6 +149,20 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 					expectingHandshakeResponseTest = false
 				}
 
+				if exec, ok := decodedRequest.(*ComStmtExecute); ok {
+					err = stmts.resolve(exec, requestBuffer[4:])
+					if err != nil {
+						logger.Debug("failed to decode the parameters of the prepared statement", zap.Error(err))
+					}
+				}
+
+				// the commands the server doesn't answer aren't recorded, the client going on with the next one
+				if expectsNoResponse(oprRequest) {
+					logger.Debug("no response is expected for the command", zap.String("oprRequest", oprRequest))
+					stmts.close(decodedRequest)
+					continue
+				}
+
 				prevRequest = ""
 				logger.Debug("Logging request buffer and operation request",
 					zap.ByteString("requestBuffer", requestBuffer),
@@ -161,14 +176,9 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 					},
 					Message: decodedRequest,
 				}
-				if oprRequest == "COM_STMT_CLOSE" {
-					logger.Debug("COM_STMT_CLOSE received")
-					errCh <- err
-					return
-				}
 				//TODO: both in case of no match or some other error, we are receiving the error.
 				// Due to this, there will be no passthrough in case of no match.
-				matchedResponse, matchedIndex, _, err := matchRequestWithMock(ctx, mysqlRequest, configMocks, tcsMocks, mockDb)
+				matchedResponse, matchedIndex, _, err := matchRequestWithMock(ctx, mysqlRequest, configMocks, tcsMocks, mockDb, opts)
 				if err != nil {
 					utils.LogError(logger, err, "Failed to match request with mock")
 					errCh <- err
@@ -196,6 +206,10 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 					continue
 				}
 
+				if matchedResponse.Header.PacketType == "COM_STMT_PREPARE_OK" {
+					stmts.prepare(decodedRequest, matchedResponse.Message)
+				}
+
 				responseBinary, err := encodeToBinary(&matchedResponse.Message, matchedResponse.Header, matchedResponse.Header.PacketType, 1)
 				logger.Debug("Response binary",
 					zap.ByteString("responseBinary", responseBinary),
@@ -530,6 +530,7 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 		mysqlRequests  []models.MySQLRequest
 		mysqlResponses []models.MySQLResponse
 	)
+	stmts := preparedStmts{}
 	for {
 		select {
 		case <-ctx.Done():
@@ -555,6 +556,12 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 				utils.LogError(logger, err, "failed to decode the MySQL packet from the client")
 				return err
 			}
+			if exec, ok := mysqlRequest.(*ComStmtExecute); ok {
+				err = stmts.resolve(exec, queryBuffer[4:])
+				if err != nil {
+					logger.Debug("failed to decode the parameters of the prepared statement", zap.Error(err))
+				}
+			}
 			mysqlRequests = append([]models.MySQLRequest{}, models.MySQLRequest{
 				Header: &models.MySQLPacketHeader{
 					PacketLength: requestHeader.PayloadLength,
@@ -563,7 +570,7 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 				},
 				Message: mysqlRequest,
 			})
-			res, err := destConn.Write(queryBuffer)
+			_, err = destConn.Write(queryBuffer)
 			if err != nil {
 				if ctx.Err() != nil {
 					return ctx.Err()
@@ -571,9 +578,14 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 				utils.LogError(logger, err, "failed to write query to mysql server")
 				return err
 			}
-			if res == 9 {
+			if operation == "COM_QUIT" {
 				return nil
 			}
+			// nothing is recorded for the commands the server doesn't answer
+			if expectsNoResponse(operation) {
+				stmts.close(mysqlRequest)
+				continue
+			}
 			queryResponse, err := util.ReadBytes(ctx, logger, destConn)
 			if err != nil {
 				utils.LogError(logger, err, "failed to read query response from mysql server")
@@ -598,6 +610,9 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 			if len(queryResponse) == 0 || responseOperation == "COM_STMT_CLOSE" {
 				break
 			}
+			if responseOperation == "COM_STMT_PREPARE_OK" {
+				stmts.prepare(mysqlRequest, mysqlResp)
+			}
 			mysqlResponses = append([]models.MySQLResponse{}, models.MySQLResponse{
 				Header: &models.MySQLPacketHeader{
 					PacketLength: responseHeader.PayloadLength,
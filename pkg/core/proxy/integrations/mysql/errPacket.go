@@ -1,8 +1,11 @@
 package mysql
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
+
+	"go.keploy.io/server/v2/pkg/models"
 )
 
 type ERRPacket struct {
@@ -31,3 +34,30 @@ func decodeMySQLErr(data []byte) (*ERRPacket, error) {
 	packet.ErrorMessage = string(data[9:])
 	return packet, nil
 }
+
+// encodeMySQLErr encodes the recorded error of the server, e.g. a duplicate key, so that the app gets the same error
+// when the query is replayed
+func encodeMySQLErr(packet *models.MySQLERRPacket, header *models.MySQLPacketHeader) ([]byte, error) {
+	payload := new(bytes.Buffer)
+	payload.WriteByte(0xff)
+	if err := binary.Write(payload, binary.LittleEndian, packet.ErrorCode); err != nil {
+		return nil, err
+	}
+	sqlState := packet.SQLState
+	if len(sqlState) != 5 {
+		// the generic state of the errors without a specific one
+		sqlState = "HY000"
+	}
+	payload.WriteByte('#')
+	payload.WriteString(sqlState)
+	payload.WriteString(packet.ErrorMessage)
+
+	buf := new(bytes.Buffer)
+	packetLength := uint32(payload.Len())
+	buf.WriteByte(byte(packetLength))
+	buf.WriteByte(byte(packetLength >> 8))
+	buf.WriteByte(byte(packetLength >> 16))
+	buf.WriteByte(header.PacketNumber)
+	buf.Write(payload.Bytes())
+	return buf.Bytes(), nil
+}
@@ -1,7 +1,6 @@
 package mysql
 
 import (
-	"encoding/base64"
 	"encoding/binary"
 	"fmt"
 )
@@ -13,49 +12,25 @@ type ComStmtExecute struct {
 	NullBitmap     string           `json:"null_bitmap,omitempty" yaml:"null_bitmap,omitempty,flow"`
 	ParamCount     uint16           `json:"param_count,omitempty" yaml:"param_count,omitempty,flow"`
 	Parameters     []BoundParameter `json:"parameters,omitempty" yaml:"parameters,omitempty,flow"`
+	Query          string           `json:"query,omitempty" yaml:"query,omitempty"`
 }
 
 type BoundParameter struct {
 	Type     byte   `json:"type,omitempty" yaml:"type,omitempty,flow"`
 	Unsigned byte   `json:"unsigned,omitempty" yaml:"unsigned,omitempty,flow"`
 	Value    []byte `json:"value,omitempty" yaml:"value,omitempty,flow"`
+	Decoded  string `json:"decoded,omitempty" yaml:"decoded,omitempty"`
 }
 
-func decodeComStmtExecute(packet []byte) (ComStmtExecute, error) {
-	// removed the print statement for cleanliness
-	if len(packet) < 14 { // the minimal size of the packet without parameters should be 14, not 13
-		return ComStmtExecute{}, fmt.Errorf("packet length less than 14 bytes")
+// decodeComStmtExecute decodes the fixed part of the packet, the parameters need the parameter count
+// of the prepared statement, they are decoded by the preparedStmts of the connection.
+func decodeComStmtExecute(packet []byte) (*ComStmtExecute, error) {
+	if len(packet) < 10 {
+		return nil, fmt.Errorf("packet length less than 10 bytes")
 	}
-	var NullBitmapValue []byte
-
-	stmtExecute := ComStmtExecute{}
+	stmtExecute := &ComStmtExecute{}
 	stmtExecute.StatementID = binary.LittleEndian.Uint32(packet[1:5])
 	stmtExecute.Flags = packet[5]
 	stmtExecute.IterationCount = binary.LittleEndian.Uint32(packet[6:10])
-
-	// the next bytes are reserved for the Null-Bitmap, Parameter Bound Flag and Bound Parameters if they exist
-	// if the length of the packet is greater than 14, then there are parameters
-	if len(packet) > 14 {
-		nullBitmapLength := int((stmtExecute.ParamCount + 7) / 8)
-
-		NullBitmapValue = packet[10 : 10+nullBitmapLength]
-		stmtExecute.NullBitmap = base64.StdEncoding.EncodeToString(NullBitmapValue)
-		stmtExecute.ParamCount = binary.LittleEndian.Uint16(packet[10+nullBitmapLength:])
-
-		// in case new parameters are bound, the new types and values are sent
-		if packet[10+nullBitmapLength] == 1 {
-			// read the types and values of the new parameters
-			stmtExecute.Parameters = make([]BoundParameter, stmtExecute.ParamCount)
-			for i := 0; i < int(stmtExecute.ParamCount); i++ {
-				index := 10 + nullBitmapLength + 1 + 2*i
-				if index+1 >= len(packet) {
-					return ComStmtExecute{}, fmt.Errorf("packet length less than expected while reading parameters")
-				}
-				stmtExecute.Parameters[i].Type = packet[index]
-				stmtExecute.Parameters[i].Unsigned = packet[index+1]
-			}
-		}
-	}
-
 	return stmtExecute, nil
 }
@@ -3,12 +3,15 @@ package mysql
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	"go.keploy.io/server/v2/pkg/models"
 )
 
-func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest, configMocks, tcsMocks []*models.Mock, mockDb integrations.MockMemDb) (*models.MySQLResponse, int, string, error) {
+func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest, configMocks, tcsMocks []*models.Mock, mockDb integrations.MockMemDb, opts models.OutgoingOptions) (*models.MySQLResponse, int, string, error) {
 	//TODO: any reason to write the similar code twice?
 	allMocks := append([]*models.Mock(nil), configMocks...)
 	allMocks = append(allMocks, tcsMocks...)
@@ -26,7 +29,7 @@ func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest,
 			if ctx.Err() != nil {
 				return nil, -1, "", ctx.Err()
 			}
-			matchCount := compareMySQLRequests(mysqlRequest, mockReq)
+			matchCount := compareMySQLRequests(mysqlRequest, mockReq, opts.SQLFingerprint)
 			if matchCount > maxMatchCount {
 				maxMatchCount = matchCount
 				matchedIndex = i
@@ -55,11 +58,12 @@ func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest,
 		configMocks[matchedIndex].Spec.MySQLRequests = append(configMocks[matchedIndex].Spec.MySQLRequests[:matchedReqIndex], configMocks[matchedIndex].Spec.MySQLRequests[matchedReqIndex+1:]...)
 		configMocks[matchedIndex].Spec.MySQLResponses = append(configMocks[matchedIndex].Spec.MySQLResponses[:matchedReqIndex], configMocks[matchedIndex].Spec.MySQLResponses[matchedReqIndex+1:]...)
 		if len(configMocks[matchedIndex].Spec.MySQLResponses) == 0 {
-			configMocks = append(configMocks[:matchedIndex], configMocks[matchedIndex+1:]...)
+			// the mock is flagged before it's removed, the next one taking its index
 			err := mockDb.FlagMockAsUsed(configMocks[matchedIndex])
 			if err != nil {
 				return nil, -1, "", fmt.Errorf("failed to flag mock as used: %v", err.Error())
 			}
+			configMocks = append(configMocks[:matchedIndex], configMocks[matchedIndex+1:]...)
 			// deleteConfigMock
 		}
 		//h.SetConfigMocks(configMocks)
@@ -71,11 +75,11 @@ func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest,
 		tcsMocks[realIndex].Spec.MySQLRequests = append(tcsMocks[realIndex].Spec.MySQLRequests[:matchedReqIndex], tcsMocks[realIndex].Spec.MySQLRequests[matchedReqIndex+1:]...)
 		tcsMocks[realIndex].Spec.MySQLResponses = append(tcsMocks[realIndex].Spec.MySQLResponses[:matchedReqIndex], tcsMocks[realIndex].Spec.MySQLResponses[matchedReqIndex+1:]...)
 		if len(tcsMocks[realIndex].Spec.MySQLResponses) == 0 {
-			tcsMocks = append(tcsMocks[:realIndex], tcsMocks[realIndex+1:]...)
 			err := mockDb.FlagMockAsUsed(tcsMocks[realIndex])
 			if err != nil {
 				return nil, -1, "", fmt.Errorf("failed to flag mock as used: %v", err.Error())
 			}
+			tcsMocks = append(tcsMocks[:realIndex], tcsMocks[realIndex+1:]...)
 			// deleteTcsMock
 		}
 		//h.SetTcsMocks(tcsMocks)
@@ -84,7 +88,7 @@ func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest,
 	return bestMatch, matchedIndex, mockType, nil
 }
 
-func compareMySQLRequests(req1, req2 models.MySQLRequest) int {
+func compareMySQLRequests(req1, req2 models.MySQLRequest, fingerprint bool) int {
 	matchCount := 0
 
 	// Compare Header fields
@@ -100,8 +104,28 @@ func compareMySQLRequests(req1, req2 models.MySQLRequest) int {
 		if !ok {
 			return 0
 		}
-		if packet.Query == packet3.Query {
+		if sameQuery(packet.Query, packet3.Query, fingerprint) {
+			matchCount += 5
+		}
+	}
+	// the prepared statements are matched on their text and decoded parameters
+	if req1.Header.PacketType == "COM_STMT_PREPARE" && req2.Header.PacketType == "COM_STMT_PREPARE" {
+		packet, ok1 := req1.Message.(*ComStmtPreparePacket)
+		mockPacket, ok2 := req2.Message.(*models.MySQLComStmtPreparePacket)
+		if ok1 && ok2 && sameQuery(packet.Query, mockPacket.Query, fingerprint) {
+			matchCount += 5
+		}
+	}
+	if req1.Header.PacketType == "COM_STMT_EXECUTE" && req2.Header.PacketType == "COM_STMT_EXECUTE" {
+		packet, ok1 := req1.Message.(*ComStmtExecute)
+		mockPacket, ok2 := req2.Message.(*models.MySQLComStmtExecute)
+		if ok1 && ok2 && packet.Query != "" && sameQuery(packet.Query, mockPacket.Query, fingerprint) {
 			matchCount += 5
+			// the bound parameters are the literals of a prepared statement, their types and normalized values are
+			// part of its fingerprint
+			if sameParameters(packet.Parameters, mockPacket.Parameters) || (fingerprint && sameParameterFingerprints(packet.Parameters, mockPacket.Parameters)) {
+				matchCount += 5
+			}
 		}
 	}
 	if req1.Header.PacketLength == req2.Header.PacketLength {
@@ -115,3 +139,48 @@ func compareMySQLRequests(req1, req2 models.MySQLRequest) int {
 	}
 	return matchCount
 }
+
+func sameParameters(params []BoundParameter, mockParams []models.BoundParameter) bool {
+	if len(params) != len(mockParams) {
+		return false
+	}
+	for i := range params {
+		if params[i].Decoded != mockParams[i].Decoded {
+			return false
+		}
+	}
+	return true
+}
+
+// sameParameterFingerprints compares the parameters on their types and their values with the whitespace around
+// them and the formatting of the numbers normalized
+func sameParameterFingerprints(params []BoundParameter, mockParams []models.BoundParameter) bool {
+	if len(params) != len(mockParams) {
+		return false
+	}
+	for i := range params {
+		if params[i].Type != mockParams[i].Type || params[i].Unsigned != mockParams[i].Unsigned {
+			return false
+		}
+		if normalizeParameter(params[i].Decoded) != normalizeParameter(mockParams[i].Decoded) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeParameter(value string) string {
+	value = strings.TrimSpace(value)
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return value
+}
+
+// sameQuery compares the queries as is, or on their fingerprints when the literals are ignored
+func sameQuery(query, mockQuery string, fingerprint bool) bool {
+	if query == mockQuery {
+		return true
+	}
+	return fingerprint && util.FingerprintSQL(query) == util.FingerprintSQL(mockQuery)
+}
@@ -98,6 +98,13 @@ func encodeToBinary(packet interface{}, header *models.MySQLPacketHeader, operat
 		}
 		data, err = encodeMySQLOK(p, header)
 		bypassHeader = true
+	case "MySQLErr":
+		p, ok := packet.(*models.MySQLERRPacket)
+		if !ok {
+			return nil, fmt.Errorf("invalid packet type for MySQLErr: expected *MySQLERRPacket, got %T", packet)
+		}
+		data, err = encodeMySQLErr(p, header)
+		bypassHeader = true
 	case "COM_STMT_PREPARE_OK":
 		p, ok := packet.(*models.MySQLStmtPrepareOk)
 		if !ok {
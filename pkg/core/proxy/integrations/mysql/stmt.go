@@ -0,0 +1,240 @@
+package mysql
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// the column types of the bound parameters, see https://dev.mysql.com/doc/dev/mysql-server/latest/field__types_8h.html
+const (
+	typeTiny      = 0x01
+	typeShort     = 0x02
+	typeLong      = 0x03
+	typeFloat     = 0x04
+	typeDouble    = 0x05
+	typeNull      = 0x06
+	typeTimestamp = 0x07
+	typeLongLong  = 0x08
+	typeInt24     = 0x09
+	typeDate      = 0x0a
+	typeTime      = 0x0b
+	typeDateTime  = 0x0c
+	typeYear      = 0x0d
+)
+
+type preparedStmt struct {
+	query     string
+	numParams uint16
+	// types are the parameter types of the last execution, they aren't resent if they didn't change
+	types []BoundParameter
+}
+
+// preparedStmts are the statements prepared on a connection, by statement id
+type preparedStmts map[uint32]*preparedStmt
+
+// prepare registers the statement once the server has answered its COM_STMT_PREPARE
+func (s preparedStmts) prepare(request, response interface{}) {
+	var query string
+	switch req := request.(type) {
+	case *ComStmtPreparePacket:
+		query = req.Query
+	case *models.MySQLComStmtPreparePacket:
+		query = req.Query
+	default:
+		return
+	}
+	switch resp := response.(type) {
+	case *StmtPrepareOk:
+		s[resp.StatementID] = &preparedStmt{query: query, numParams: resp.NumParams}
+	case *models.MySQLStmtPrepareOk:
+		s[resp.StatementID] = &preparedStmt{query: query, numParams: resp.NumParams}
+	}
+}
+
+// close forgets the statement once the client has closed it, its id being reused by the next prepared statement
+func (s preparedStmts) close(request interface{}) {
+	if req, ok := request.(*ComStmtClosePacket); ok {
+		delete(s, req.StatementID)
+	}
+}
+
+// resolve fills the statement text and the decoded parameters of the execution
+func (s preparedStmts) resolve(exec *ComStmtExecute, packet []byte) error {
+	stmt, ok := s[exec.StatementID]
+	if !ok {
+		return nil
+	}
+	exec.Query = stmt.query
+	exec.ParamCount = stmt.numParams
+	if stmt.numParams == 0 {
+		return nil
+	}
+
+	n := int(stmt.numParams)
+	pos := 10
+	nullBitmapLength := (n + 7) / 8
+	if len(packet) < pos+nullBitmapLength+1 {
+		return fmt.Errorf("packet length less than expected while reading the null bitmap")
+	}
+	nullBitmap := packet[pos : pos+nullBitmapLength]
+	exec.NullBitmap = base64.StdEncoding.EncodeToString(nullBitmap)
+	pos += nullBitmapLength
+
+	newParamsBound := packet[pos] == 1
+	pos++
+	params := make([]BoundParameter, n)
+	if newParamsBound {
+		if len(packet) < pos+2*n {
+			return fmt.Errorf("packet length less than expected while reading the parameter types")
+		}
+		for i := range params {
+			params[i].Type = packet[pos]
+			params[i].Unsigned = packet[pos+1]
+			pos += 2
+		}
+		stmt.types = params
+	} else if len(stmt.types) == n {
+		copy(params, stmt.types)
+	}
+
+	for i := range params {
+		params[i].Value = nil
+		if nullBitmap[i/8]&(1<<(uint(i)%8)) != 0 || params[i].Type == typeNull {
+			params[i].Decoded = "NULL"
+			continue
+		}
+		value, decoded, next, err := decodeParamValue(packet, pos, params[i].Type, params[i].Unsigned&0x80 != 0)
+		if err != nil {
+			return err
+		}
+		params[i].Value = value
+		params[i].Decoded = decoded
+		pos = next
+	}
+	exec.Parameters = params
+	return nil
+}
+
+// decodeParamValue decodes the binary protocol value of a parameter at the position into its readable form
+func decodeParamValue(packet []byte, pos int, paramType byte, unsigned bool) ([]byte, string, int, error) {
+	size := 0
+	switch paramType {
+	case typeTiny:
+		size = 1
+	case typeShort, typeYear:
+		size = 2
+	case typeLong, typeInt24, typeFloat:
+		size = 4
+	case typeLongLong, typeDouble:
+		size = 8
+	case typeDate, typeDateTime, typeTimestamp, typeTime:
+		if pos >= len(packet) {
+			return nil, "", pos, fmt.Errorf("packet length less than expected while reading the parameter values")
+		}
+		size = 1 + int(packet[pos])
+	default:
+		// the strings, blobs, decimals and json are length encoded
+		if pos >= len(packet) {
+			return nil, "", pos, fmt.Errorf("packet length less than expected while reading the parameter values")
+		}
+		offset := pos
+		str, err := readLengthEncodedString(packet, &offset)
+		if err != nil {
+			return nil, "", pos, err
+		}
+		return packet[pos:offset], str, offset, nil
+	}
+	if len(packet) < pos+size {
+		return nil, "", pos, fmt.Errorf("packet length less than expected while reading the parameter values")
+	}
+	value := packet[pos : pos+size]
+
+	var decoded string
+	switch paramType {
+	case typeTiny:
+		if unsigned {
+			decoded = strconv.FormatUint(uint64(value[0]), 10)
+		} else {
+			decoded = strconv.FormatInt(int64(int8(value[0])), 10)
+		}
+	case typeShort, typeYear:
+		v := binary.LittleEndian.Uint16(value)
+		if unsigned || paramType == typeYear {
+			decoded = strconv.FormatUint(uint64(v), 10)
+		} else {
+			decoded = strconv.FormatInt(int64(int16(v)), 10)
+		}
+	case typeLong, typeInt24:
+		v := binary.LittleEndian.Uint32(value)
+		if unsigned {
+			decoded = strconv.FormatUint(uint64(v), 10)
+		} else {
+			decoded = strconv.FormatInt(int64(int32(v)), 10)
+		}
+	case typeLongLong:
+		v := binary.LittleEndian.Uint64(value)
+		if unsigned {
+			decoded = strconv.FormatUint(v, 10)
+		} else {
+			decoded = strconv.FormatInt(int64(v), 10)
+		}
+	case typeFloat:
+		decoded = strconv.FormatFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(value))), 'g', -1, 32)
+	case typeDouble:
+		decoded = strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(value)), 'g', -1, 64)
+	case typeTime:
+		decoded = decodeTime(value[1:])
+	default:
+		decoded = decodeDateTime(value[1:])
+	}
+	return value, decoded, pos + size, nil
+}
+
+// decodeDateTime formats the binary DATE, DATETIME and TIMESTAMP values
+func decodeDateTime(b []byte) string {
+	var year, month, day, hour, minute, second int
+	var micro uint32
+	if len(b) >= 4 {
+		year = int(binary.LittleEndian.Uint16(b[0:2]))
+		month, day = int(b[2]), int(b[3])
+	}
+	if len(b) >= 7 {
+		hour, minute, second = int(b[4]), int(b[5]), int(b[6])
+	}
+	if len(b) >= 11 {
+		micro = binary.LittleEndian.Uint32(b[7:11])
+	}
+	out := fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+	if len(b) >= 7 {
+		out += fmt.Sprintf(" %02d:%02d:%02d", hour, minute, second)
+	}
+	if micro != 0 {
+		out += fmt.Sprintf(".%06d", micro)
+	}
+	return out
+}
+
+// decodeTime formats the binary TIME values
+func decodeTime(b []byte) string {
+	if len(b) < 8 {
+		return "00:00:00"
+	}
+	sign := ""
+	if b[0] == 1 {
+		sign = "-"
+	}
+	days := binary.LittleEndian.Uint32(b[1:5])
+	hours := uint32(b[5]) + days*24
+	out := fmt.Sprintf("%s%02d:%02d:%02d", sign, hours, b[6], b[7])
+	if len(b) >= 12 {
+		if micro := binary.LittleEndian.Uint32(b[8:12]); micro != 0 {
+			out += fmt.Sprintf(".%06d", micro)
+		}
+	}
+	return out
+}
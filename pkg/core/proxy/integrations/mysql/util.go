@@ -18,6 +18,12 @@ var (
 	expectingHandshakeResponseTest = false
 )
 
+// expectsNoResponse reports whether the server doesn't answer the command, so that the connection goes on with the
+// next command of the client instead of waiting for a response
+func expectsNoResponse(operation string) bool {
+	return operation == "COM_STMT_CLOSE" || operation == "COM_STMT_SEND_LONG_DATA"
+}
+
 func bytesToMySQLPacket(buffer []byte) CustomPacket {
 	if buffer == nil || len(buffer) < 4 {
 		log.Fatalf("Error: buffer is nil or too short to be a valid MySQL packet")
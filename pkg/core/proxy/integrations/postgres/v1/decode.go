@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"strings"
 	"time"
 
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
@@ -17,13 +16,17 @@ import (
 	"go.uber.org/zap"
 )
 
-func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, _ models.OutgoingOptions) error {
+func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	pgRequests := [][]byte{reqBuf}
 	errCh := make(chan error, 1)
+	if connectionID, ok := ctx.Value(models.ClientConnectionIDKey).(string); ok {
+		defer forgetTestPS(connectionID)
+	}
 
 	go func(errCh chan error, pgRequests [][]byte) {
 		// close should be called from the producer of the channel
 		defer close(errCh)
+		listener := newNotifier()
 		for {
 			// Since protocol packets have to be parsed for checking stream end,
 			// clientConnection have deadline for read to determine the end of stream.
@@ -54,10 +57,16 @@ func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 			}
 
 			if len(pgRequests) == 0 {
-				logger.Debug("the postgres request buffer is empty")
+				// the client is idle, send the notifications recorded for its channels
+				err = listener.dispatch(logger, clientConn, mockDb)
+				if err != nil {
+					errCh <- err
+					return
+				}
 				continue
 			}
-			matched, pgResponses, err := matchingReadablePG(ctx, logger, pgRequests, mockDb)
+			listener.track(pgRequests)
+			matched, pgResponses, err := matchingReadablePG(ctx, logger, pgRequests, mockDb, opts)
 			if err != nil {
 				errCh <- fmt.Errorf("error while matching tcs mocks %v", err)
 				return
@@ -86,6 +95,7 @@ func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 					errCh <- err
 				}
 			}
+			listener.lastEvent = time.Now()
 			// Clear the buffer for the next dependency call
 			pgRequests = [][]byte{}
 		}
@@ -124,7 +134,8 @@ func getRecordPrepStatement(allMocks []*models.Mock) PrepMap {
 				p := 0
 				for _, header := range req.PacketTypes {
 					if header == "P" {
-						if strings.Contains(req.Parses[p].Name, "S_") {
+						// the unnamed statement is parsed again by every query
+						if req.Parses[p].Name != "" {
 							psMap[req.Parses[p].Query] = req.Parses[p].Name
 							querydata = append(querydata, QueryData{PrepIdentifier: req.Parses[p].Name,
 								Query: req.Parses[p].Query,
@@ -258,6 +258,39 @@ func encodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 			}
 			prevChunkWasReq = true
 		case buffer := <-destBuffChan:
+			if isNotificationOnly(buffer) {
+				_, err := clientConn.Write(buffer)
+				if err != nil {
+					utils.LogError(logger, err, "failed to write the notification message to the client")
+					return err
+				}
+				connectionID := ctx.Value(models.ClientConnectionIDKey).(string)
+				// save the completed exchange first to keep the order of the messages of the connection
+				if !prevChunkWasReq && len(pgRequests) > 0 && len(pgResponses) > 0 {
+					mocks <- &models.Mock{
+						Version: models.GetVersion(),
+						Name:    "mocks",
+						Kind:    models.Postgres,
+						Spec: models.MockSpec{
+							PostgresRequests:  pgRequests,
+							PostgresResponses: pgResponses,
+							ReqTimestampMock:  reqTimestampMock,
+							ResTimestampMock:  resTimestampMock,
+							Metadata:          map[string]string{"type": "config"},
+						},
+						ConnectionID: connectionID,
+					}
+					pgRequests = []models.Backend{}
+					pgResponses = []models.Frontend{}
+				}
+				lastEvent := resTimestampMock
+				if lastEvent.IsZero() {
+					lastEvent = reqTimestampMock
+				}
+				mocks <- notificationMock(logger, buffer, time.Since(lastEvent), connectionID)
+				resTimestampMock = time.Now()
+				continue
+			}
 			if prevChunkWasReq {
 				// store the request timestamp
 				reqTimestampMock = time.Now()
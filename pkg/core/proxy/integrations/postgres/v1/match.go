@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"math"
 	"reflect"
-	"strings"
 
 	"github.com/jackc/pgproto3/v2"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
@@ -15,54 +14,9 @@ import (
 	"go.uber.org/zap"
 )
 
-var testmap TestPrepMap
-
-func getTestPS(reqBuff [][]byte, logger *zap.Logger, ConnectionID string) {
-	// maintain a map of current prepared statements and their corresponding connection id
-	// if it's the prepared statement match the query with the recorded prepared statement and return the response of that matched prepared statement at that connection
-	// so if parse is coming save to a same map
-	actualPgReq := decodePgRequest(reqBuff[0], logger)
-	if actualPgReq == nil {
-		return
-	}
-	testmap2 := make(TestPrepMap)
-	if testmap != nil {
-		testmap2 = testmap
-	}
-	querydata := make([]QueryData, 0)
-	if len(actualPgReq.PacketTypes) > 0 && actualPgReq.PacketTypes[0] != "p" && actualPgReq.Identfier != "StartupRequest" {
-		p := 0
-		for _, header := range actualPgReq.PacketTypes {
-			if header == "P" {
-				if strings.Contains(actualPgReq.Parses[p].Name, "S_") && !IsValuePresent(ConnectionID, actualPgReq.Parses[p].Name) {
-					querydata = append(querydata, QueryData{PrepIdentifier: actualPgReq.Parses[p].Name, Query: actualPgReq.Parses[p].Query})
-				}
-				p++
-			}
-		}
-	}
-
-	// also append the query data for the prepared statement
-	if len(querydata) > 0 {
-		testmap2[ConnectionID] = append(testmap2[ConnectionID], querydata...)
-		// fmt.Println("Test Prepared statement Map", testmap2)
-		testmap = testmap2
-	}
-
-}
-
-func IsValuePresent(connectionid string, value string) bool {
-	if testmap != nil {
-		for _, v := range testmap[connectionid] {
-			if v.PrepIdentifier == value {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-func matchingReadablePG(ctx context.Context, logger *zap.Logger, requestBuffers [][]byte, mockDb integrations.MockMemDb) (bool, []models.Frontend, error) {
+func matchingReadablePG(ctx context.Context, logger *zap.Logger, requestBuffers [][]byte, mockDb integrations.MockMemDb, opts models.OutgoingOptions) (bool, []models.Frontend, error) {
+	// the statements closed by the requests are forgotten once they're answered
+	defer closeTestPS(requestBuffers, ctx.Value(models.ClientConnectionIDKey).(string))
 	for {
 		select {
 		case <-ctx.Done():
@@ -77,12 +31,14 @@ func matchingReadablePG(ctx context.Context, logger *zap.Logger, requestBuffers
 			ConnectionID := ctx.Value(models.ClientConnectionIDKey).(string)
 
 			recordedPrep := getRecordPrepStatement(tcsMocks)
+			// maintain test prepare statement map for each connection id
+			getTestPS(requestBuffers, logger, ConnectionID)
 			reqGoingOn := decodePgRequest(requestBuffers[0], logger)
 			if reqGoingOn != nil {
 				logger.Debug("PacketTypes", zap.Any("PacketTypes", reqGoingOn.PacketTypes))
 				// fmt.Println("REQUEST GOING ON - ", reqGoingOn)
 				logger.Debug("ConnectionId-", zap.String("ConnectionId", ConnectionID))
-				logger.Debug("TestMap*****", zap.Any("TestMap", testmap))
+				logger.Debug("TestMap*****", zap.Any("TestMap", testStatements(ConnectionID)))
 			}
 			// if recordedPrep != nil {
 			// 	fmt.Println("PREPARED STATEMENT", recordedPrep)
@@ -198,8 +154,6 @@ func matchingReadablePG(ctx context.Context, logger *zap.Logger, requestBuffers
 
 					}
 				}
-				// maintain test prepare statement map for each connection id
-				getTestPS(requestBuffers, logger, ConnectionID)
 			}
 
 			logger.Debug("Sorted Mocks: ", zap.Any("Len of sortedTcsMocks", len(sortedTcsMocks)))
@@ -219,6 +173,12 @@ func matchingReadablePG(ctx context.Context, logger *zap.Logger, requestBuffers
 					}
 					// fmt.Println("Matched In Absolute Custom Matching for sorted!!!", matchedMock.Name)
 				}
+				if !matched && opts.SQLFingerprint {
+					if idx := findFingerprintMatch(sortedTcsMocks, requestBuffers, logger); idx != -1 {
+						matched = true
+						matchedMock = tcsMocks[idx]
+					}
+				}
 				idx = findBinaryStreamMatch(logger, sortedTcsMocks, requestBuffers, sorted)
 				if idx != -1 && !matched {
 					matched = true
@@ -238,6 +198,12 @@ func matchingReadablePG(ctx context.Context, logger *zap.Logger, requestBuffers
 					}
 					// fmt.Println("Matched In Absolute Custom Matching for Unsorted", matchedMock.Name)
 				}
+				if !matched && opts.SQLFingerprint {
+					if idx := findFingerprintMatch(tcsMocks, requestBuffers, logger); idx != -1 {
+						matched = true
+						matchedMock = tcsMocks[idx]
+					}
+				}
 				idx = findBinaryStreamMatch(logger, tcsMocks, requestBuffers, sorted)
 				// check if the validate the query with the matched mock
 				// if the query is same then return the response of that mock
@@ -440,6 +406,43 @@ func findPGStreamMatch(tcsMocks []*models.Mock, requestBuffers [][]byte, logger
 	return mxIdx, nil
 }
 
+// findFingerprintMatch returns the first mock with the same messages whose queries have the same fingerprint,
+// the bound parameters are ignored as they are the literals of the extended protocol queries.
+func findFingerprintMatch(tcsMocks []*models.Mock, requestBuffers [][]byte, logger *zap.Logger) int {
+	if len(requestBuffers) != 1 {
+		return -1
+	}
+	actualPgReq := decodePgRequest(requestBuffers[0], logger)
+	if actualPgReq == nil {
+		return -1
+	}
+	for idx, mock := range tcsMocks {
+		if len(mock.Spec.PostgresRequests) != 1 {
+			continue
+		}
+		mockReq := mock.Spec.PostgresRequests[0]
+		if !reflect.DeepEqual(actualPgReq.PacketTypes, mockReq.PacketTypes) || len(actualPgReq.Parses) != len(mockReq.Parses) {
+			continue
+		}
+		matched := true
+		for i := range actualPgReq.Parses {
+			if util.FingerprintSQL(actualPgReq.Parses[i].Query) != util.FingerprintSQL(mockReq.Parses[i].Query) {
+				matched = false
+				break
+			}
+		}
+		for _, packet := range actualPgReq.PacketTypes {
+			if packet == "Q" && util.FingerprintSQL(actualPgReq.Query.String) != util.FingerprintSQL(mockReq.Query.String) {
+				matched = false
+			}
+		}
+		if matched {
+			return idx
+		}
+	}
+	return -1
+}
+
 // check what are the queries for the given ps of actualPgReq
 // check if the execute query is present for that or not
 // mark that mock true and return the response by changing the res format like
@@ -447,6 +450,7 @@ func findPGStreamMatch(tcsMocks []*models.Mock, requestBuffers [][]byte, logger
 func changeResToPS(mock *models.Mock, actualPgReq *models.Backend, logger *zap.Logger, connectionID string) (bool, *models.Mock) {
 	actualpackets := actualPgReq.PacketTypes
 	mockPackets := mock.Spec.PostgresRequests[0].PacketTypes
+	statements := testStatements(connectionID)
 
 	// [P, B, E, P, B, D, E] => [B, E, B, E]
 	// write code that of packet is ["B", "E"] and mockPackets ["P", "B", "D", "E"] handle it in case1
@@ -464,7 +468,7 @@ func changeResToPS(mock *models.Mock, actualPgReq *models.Backend, logger *zap.L
 		if actualPgReq.Parses[0].Query != mock.Spec.PostgresRequests[0].Parses[1].Query {
 			return false, nil
 		}
-		newMock = sliceCommandTag(mock, logger, testmap[connectionID], actualPgReq, 1)
+		newMock = sliceCommandTag(mock, logger, statements, actualPgReq, 1)
 		return true, newMock
 	}
 
@@ -473,7 +477,7 @@ func changeResToPS(mock *models.Mock, actualPgReq *models.Backend, logger *zap.L
 	if reflect.DeepEqual(actualpackets, []string{"B", "E"}) && reflect.DeepEqual(mockPackets, []string{"P", "B", "D", "E"}) {
 		// fmt.Println("Handling Case 2 for mock", mock.Name)
 		ps = actualPgReq.Binds[0].PreparedStatement
-		for _, v := range testmap[connectionID] {
+		for _, v := range statements {
 			if v.Query == mock.Spec.PostgresRequests[0].Parses[0].Query && v.PrepIdentifier == ps {
 				ischanged = true
 				break
@@ -484,7 +488,7 @@ func changeResToPS(mock *models.Mock, actualPgReq *models.Backend, logger *zap.L
 	if ischanged {
 		// if strings.Contains(ps, "S_") {
 		// fmt.Println("Inside Prepared Statement")
-		newMock = sliceCommandTag(mock, logger, testmap[connectionID], actualPgReq, 2)
+		newMock = sliceCommandTag(mock, logger, statements, actualPgReq, 2)
 		// }
 		return true, newMock
 	}
@@ -497,7 +501,7 @@ func changeResToPS(mock *models.Mock, actualPgReq *models.Backend, logger *zap.L
 		// fmt.Println("Handling Case 3 for mock", mock.Name)
 		ischanged1 := false
 		ps1 := actualPgReq.Binds[0].PreparedStatement
-		for _, v := range testmap[connectionID] {
+		for _, v := range statements {
 			if v.Query == mock.Spec.PostgresRequests[0].Parses[0].Query && v.PrepIdentifier == ps1 {
 				ischanged1 = true
 				break
@@ -506,14 +510,14 @@ func changeResToPS(mock *models.Mock, actualPgReq *models.Backend, logger *zap.L
 		//Matched In Binary Matching for Unsorted mock-222
 		ischanged2 := false
 		ps2 := actualPgReq.Binds[1].PreparedStatement
-		for _, v := range testmap[connectionID] {
+		for _, v := range statements {
 			if v.Query == mock.Spec.PostgresRequests[0].Parses[1].Query && v.PrepIdentifier == ps2 {
 				ischanged2 = true
 				break
 			}
 		}
 		if ischanged1 && ischanged2 {
-			newMock = sliceCommandTag(mock, logger, testmap[connectionID], actualPgReq, 2)
+			newMock = sliceCommandTag(mock, logger, statements, actualPgReq, 2)
 			return true, newMock
 		}
 	}
@@ -524,14 +528,14 @@ func changeResToPS(mock *models.Mock, actualPgReq *models.Backend, logger *zap.L
 		// get the query for the prepared statement of test mode
 		ischanged := false
 		ps := actualPgReq.Binds[1].PreparedStatement
-		for _, v := range testmap[connectionID] {
+		for _, v := range statements {
 			if v.Query == mock.Spec.PostgresRequests[0].Parses[0].Query && v.PrepIdentifier == ps {
 				ischanged = true
 				break
 			}
 		}
 		if ischanged {
-			newMock = sliceCommandTag(mock, logger, testmap[connectionID], actualPgReq, 2)
+			newMock = sliceCommandTag(mock, logger, statements, actualPgReq, 2)
 			return true, newMock
 		}
 
@@ -566,7 +570,7 @@ func PreparedStatementMatch(mock *models.Mock, actualPgReq *models.Backend, logg
 	var foo = false
 	for idx, bind := range binds {
 		currentPs := bind.PreparedStatement
-		currentQuerydata := testmap[ConnectionID]
+		currentQuerydata := testStatements(ConnectionID)
 		currentQuery := ""
 		// check in the map that what's the current query for this preparedstatement
 		// then will check what is the recorded prepared statement for this query
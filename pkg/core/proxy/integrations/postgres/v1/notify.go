@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"encoding/binary"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgproto3/v2"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// listenRegex matches the LISTEN and UNLISTEN commands along with their channel
+var listenRegex = regexp.MustCompile(`(?i)\b(UN)?LISTEN\s+("[^"]+"|\*|[\w$]+)`)
+
+// isNotificationOnly reports whether the buffer from the server only holds NotificationResponse messages,
+// i.e. it's an asynchronous notification and not the response of a request.
+func isNotificationOnly(buffer []byte) bool {
+	if len(buffer) < 5 {
+		return false
+	}
+	for i := 0; i < len(buffer); {
+		if i+5 > len(buffer) || buffer[i] != 'A' {
+			return false
+		}
+		i += 1 + int(binary.BigEndian.Uint32(buffer[i+1:]))
+		if i > len(buffer) {
+			return false
+		}
+	}
+	return true
+}
+
+// notificationMock records the notifications of the buffer along with the delay since the previous
+// message of the connection, so that they are replayed with the same timing.
+func notificationMock(logger *zap.Logger, buffer []byte, delay time.Duration, connectionID string) *models.Mock {
+	var responses []models.Frontend
+	channel := ""
+	for i := 0; i+5 <= len(buffer); {
+		end := i + 1 + int(binary.BigEndian.Uint32(buffer[i+1:]))
+		msg := pgproto3.NotificationResponse{}
+		err := msg.Decode(buffer[i+5 : end])
+		if err != nil {
+			utils.LogError(logger, err, "failed to decode the postgres notification")
+			break
+		}
+		channel = msg.Channel
+		responses = append(responses, models.Frontend{
+			PacketTypes:          []string{"A"},
+			Identfier:            "ServerResponse",
+			NotificationResponse: msg,
+			MsgType:              'A',
+		})
+		i = end
+	}
+	now := time.Now()
+	return &models.Mock{
+		Version: models.GetVersion(),
+		Name:    "mocks",
+		Kind:    models.Postgres,
+		Spec: models.MockSpec{
+			PostgresResponses: responses,
+			ReqTimestampMock:  now,
+			ResTimestampMock:  now,
+			Metadata: map[string]string{
+				"type":         "config",
+				"notification": channel,
+				"delay":        strconv.FormatInt(delay.Milliseconds(), 10),
+			},
+		},
+		ConnectionID: connectionID,
+	}
+}
+
+// notifier replays the recorded notifications of the channels which the connection listens to
+type notifier struct {
+	channels map[string]bool
+	sent     map[string]bool
+	// lastEvent is the time of the previous message sent to the client
+	lastEvent time.Time
+}
+
+func newNotifier() *notifier {
+	return &notifier{
+		channels:  map[string]bool{},
+		sent:      map[string]bool{},
+		lastEvent: time.Now(),
+	}
+}
+
+// track updates the channels of the connection from the LISTEN and UNLISTEN commands of the requests
+func (n *notifier) track(requests [][]byte) {
+	for _, req := range requests {
+		if len(req) == 0 || (req[0] != 'Q' && req[0] != 'P') {
+			continue
+		}
+		for _, match := range listenRegex.FindAllStringSubmatch(string(req), -1) {
+			channel := match[2]
+			if strings.HasPrefix(channel, `"`) {
+				channel = strings.Trim(channel, `"`)
+			} else {
+				channel = strings.ToLower(channel)
+			}
+			switch {
+			case match[1] == "":
+				n.channels[channel] = true
+			case channel == "*":
+				n.channels = map[string]bool{}
+			default:
+				delete(n.channels, channel)
+			}
+		}
+	}
+}
+
+// dispatch writes the next recorded notifications of the listened channels once their delay has elapsed
+func (n *notifier) dispatch(logger *zap.Logger, clientConn net.Conn, mockDb integrations.MockMemDb) error {
+	if len(n.channels) == 0 {
+		return nil
+	}
+	mocks, err := mockDb.GetUnFilteredMocks()
+	if err != nil {
+		return err
+	}
+	for _, mock := range mocks {
+		channel, ok := mock.Spec.Metadata["notification"]
+		if !ok || len(mock.Spec.PostgresRequests) != 0 || n.sent[mock.Name] || !n.channels[channel] {
+			continue
+		}
+		delay, err := strconv.ParseInt(mock.Spec.Metadata["delay"], 10, 64)
+		if err != nil {
+			delay = 0
+		}
+		if time.Since(n.lastEvent) < time.Duration(delay)*time.Millisecond {
+			// the notifications are sent in the recorded order
+			return nil
+		}
+		for _, resp := range mock.Spec.PostgresResponses {
+			encoded, err := postgresDecoderFrontend(resp)
+			if err != nil {
+				utils.LogError(logger, err, "failed to encode the recorded postgres notification")
+				return err
+			}
+			_, err = clientConn.Write(encoded)
+			if err != nil {
+				utils.LogError(logger, err, "failed to write the postgres notification to the client application")
+				return err
+			}
+		}
+		logger.Debug("sent the recorded postgres notification", zap.String("channel", channel), zap.String("mock", mock.Name))
+		n.sent[mock.Name] = true
+		n.lastEvent = time.Now()
+		if err := mockDb.FlagMockAsUsed(mock); err != nil {
+			utils.LogError(logger, err, "failed to flag the postgres notification mock as used")
+		}
+	}
+	return nil
+}
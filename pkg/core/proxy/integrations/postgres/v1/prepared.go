@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/jackc/pgproto3/v2"
+	"go.uber.org/zap"
+)
+
+// testmap holds the prepared statements of the app in test mode by the id of its connection. The drivers name the
+// statements differently on each run, e.g. S_1 for jdbc and stmtcache_<hash> for pgx, so they're matched with the
+// recorded ones by their queries.
+var (
+	testmap   TestPrepMap
+	testmapMu sync.RWMutex
+)
+
+// getTestPS saves the named statements parsed by the requests of the connection, a statement parsed again under
+// the same name replacing its query
+func getTestPS(reqBuff [][]byte, logger *zap.Logger, ConnectionID string) {
+	testmapMu.Lock()
+	defer testmapMu.Unlock()
+	if testmap == nil {
+		testmap = make(TestPrepMap)
+	}
+	for _, buf := range reqBuff {
+		for _, msg := range frontendMessages(buf) {
+			parse, ok := msg.(*pgproto3.Parse)
+			// the unnamed statement is parsed again by every query
+			if !ok || parse.Name == "" {
+				continue
+			}
+			statements := removeStatement(testmap[ConnectionID], parse.Name)
+			testmap[ConnectionID] = append(statements, QueryData{PrepIdentifier: parse.Name, Query: parse.Query})
+		}
+	}
+	logger.Debug("the prepared statements of the connection", zap.String("ConnectionId", ConnectionID), zap.Any("statements", testmap[ConnectionID]))
+}
+
+// closeTestPS forgets the statements closed by the requests of the connection, whose names may be reused for other
+// queries
+func closeTestPS(reqBuff [][]byte, ConnectionID string) {
+	testmapMu.Lock()
+	defer testmapMu.Unlock()
+	for _, buf := range reqBuff {
+		for _, msg := range frontendMessages(buf) {
+			if closeMsg, ok := msg.(*pgproto3.Close); ok && closeMsg.Object_Type == 'S' {
+				testmap[ConnectionID] = removeStatement(testmap[ConnectionID], closeMsg.Name)
+			}
+		}
+	}
+	if len(testmap[ConnectionID]) == 0 {
+		delete(testmap, ConnectionID)
+	}
+}
+
+// forgetTestPS forgets the statements of the connection once it's closed
+func forgetTestPS(ConnectionID string) {
+	testmapMu.Lock()
+	defer testmapMu.Unlock()
+	delete(testmap, ConnectionID)
+}
+
+// testStatements returns the prepared statements of the connection
+func testStatements(ConnectionID string) []QueryData {
+	testmapMu.RLock()
+	defer testmapMu.RUnlock()
+	return append([]QueryData(nil), testmap[ConnectionID]...)
+}
+
+func IsValuePresent(connectionid string, value string) bool {
+	for _, v := range testStatements(connectionid) {
+		if v.PrepIdentifier == value {
+			return true
+		}
+	}
+	return false
+}
+
+func removeStatement(statements []QueryData, name string) []QueryData {
+	kept := statements[:0]
+	for _, v := range statements {
+		if v.PrepIdentifier != name {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// frontendMessages decodes the parse and close messages of the request buffer, the others being left out
+func frontendMessages(buf []byte) []pgproto3.FrontendMessage {
+	if len(buf) >= 8 && isStartupPacket(buf) {
+		return nil
+	}
+	var msgs []pgproto3.FrontendMessage
+	for len(buf) >= 5 {
+		length := int(binary.BigEndian.Uint32(buf[1:5]))
+		if length < 4 || len(buf) < length+1 {
+			break
+		}
+		body := buf[5 : length+1]
+		var msg pgproto3.FrontendMessage
+		switch buf[0] {
+		case 'P':
+			msg = &pgproto3.Parse{}
+		case 'C':
+			msg = &pgproto3.Close{}
+		}
+		if msg != nil && msg.Decode(body) == nil {
+			msgs = append(msgs, msg)
+		}
+		buf = buf[length+1:]
+	}
+	return msgs
+}
@@ -0,0 +1,174 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
+	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func decodeRedis(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, _ *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
+	logger.Debug("Into the redis parser in test mode")
+	errCh := make(chan error, 1)
+	go func(errCh chan error, buf []byte) {
+		defer utils.Recover(logger)
+		defer close(errCh)
+		for {
+			// the batch, e.g. a pipeline or a MULTI/EXEC block, is matched once its last command is read
+			commands, rest, err := parseFrames(buf, parseCommand)
+			if err != nil {
+				utils.LogError(logger, err, "failed to parse the redis command")
+				errCh <- err
+				return
+			}
+			if len(commands) > 0 && len(rest) == 0 {
+				// the rest of the batch may be sent in another chunk
+				more, err := readPending(ctx, logger, clientConn)
+				if err != nil {
+					errCh <- err
+					return
+				}
+				if len(more) > 0 {
+					buf = append(buf, more...)
+					continue
+				}
+				if err := replyToBatch(ctx, logger, clientConn, mockDb, buf, commands, opts); err != nil {
+					if ctx.Err() == nil {
+						errCh <- err
+					}
+					return
+				}
+				buf = nil
+			}
+
+			buffer, err := pUtil.ReadBytes(ctx, logger, clientConn)
+			if err != nil {
+				if err != io.EOF {
+					utils.LogError(logger, err, "failed to read the request message in proxy for redis dependency")
+				}
+				errCh <- err
+				return
+			}
+			buf = append(buf, buffer...)
+		}
+	}(errCh, reqBuf)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+}
+
+// batchWait is how long the rest of a batch of commands is waited for once its last command read is complete
+const batchWait = 10 * time.Millisecond
+
+// readPending returns the bytes the client sends within the batch wait, nil if it sends none
+func readPending(ctx context.Context, logger *zap.Logger, clientConn net.Conn) ([]byte, error) {
+	if err := clientConn.SetReadDeadline(time.Now().Add(batchWait)); err != nil {
+		utils.LogError(logger, err, "failed to set the read deadline for the client conn")
+		return nil, err
+	}
+	buffer, err := pUtil.ReadBytes(ctx, logger, clientConn)
+	if resetErr := clientConn.SetReadDeadline(time.Time{}); resetErr != nil {
+		utils.LogError(logger, resetErr, "failed to reset the read deadline for the client conn")
+		return nil, resetErr
+	}
+	// the batch is replied to before the connection closed by the client is handled
+	if netErr, ok := err.(net.Error); (ok && netErr.Timeout()) || err == io.EOF {
+		return buffer, nil
+	}
+	if err != nil {
+		utils.LogError(logger, err, "failed to read the request message in proxy for redis dependency")
+		return nil, err
+	}
+	return buffer, nil
+}
+
+// replyToBatch writes the replies of the batch of commands to the client. The generic mocks recorded for the redis
+// connections before they were parsed hold the whole batch, so they are matched with it as a whole. Otherwise each
+// command gets the replies of its redis mock, in the order of the commands, however the batch was split into chunks.
+func replyToBatch(ctx context.Context, logger *zap.Logger, clientConn net.Conn, mockDb integrations.MockMemDb, batch []byte, commands []models.RedisValue, opts models.OutgoingOptions) error {
+	matched, responses, err := matchGeneric(ctx, mockDb, batch)
+	if err != nil {
+		utils.LogError(logger, err, "error while matching the generic mocks of the redis commands")
+	}
+	if matched {
+		logger.Debug("replaying the generic mock of the redis commands", zap.Int("commands", len(commands)))
+		if err := writeGeneric(ctx, logger, clientConn, responses); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			utils.LogError(logger, err, "failed to write the response message to the client application")
+			return err
+		}
+		return nil
+	}
+	for _, command := range commands {
+		if err := replyTo(ctx, logger, clientConn, mockDb, command, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replyTo writes the replies of the mock matching the command to the client, an error reply when none matches so
+// that the client doesn't wait for the replies forever
+func replyTo(ctx context.Context, logger *zap.Logger, clientConn net.Conn, mockDb integrations.MockMemDb, command models.RedisValue, opts models.OutgoingOptions) error {
+	args := commandArgs(command)
+	matched, replies, err := match(ctx, logger, mockDb, args)
+	if err != nil {
+		utils.LogError(logger, err, "error while matching redis mocks")
+	}
+	if !matched {
+		logger.Debug("no redis mock matched the command", zap.Strings("command", args))
+		if opts.MatchDebug != nil {
+			mocks, err := mockDb.GetUnFilteredMocks()
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the mocks closest to the redis command")
+			}
+			util.TraceUnmatched(logger, opts.MatchDebug, models.REDIS, util.Excerpt([]byte(strings.Join(args, " "))), closestMocks(mocks, args))
+		}
+		name := ""
+		if len(args) > 0 {
+			name = strings.ToUpper(args[0])
+		}
+		_, err := clientConn.Write(encodeValue(models.RedisValue{Type: typeError, Value: "ERR keploy found no mock for the command " + name}))
+		if err != nil {
+			utils.LogError(logger, err, "failed to write the error reply to the client application")
+		}
+		return err
+	}
+
+	for _, reply := range replies {
+		if reply.ReadDelay > 0 {
+			// the message was pushed by the server, send it with the recorded timing
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(reply.ReadDelay)):
+			}
+		}
+		_, err := clientConn.Write(encodeValue(reply.Message))
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			utils.LogError(logger, err, "failed to write the response message to the client application")
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// exchange is a command of the client with the replies of the server, recorded as a mock. The messages pushed to the
+// subscriptions of the connection are recorded with the last command sent before them.
+type exchange struct {
+	command   models.RedisValue
+	replies   []models.RedisPayload
+	pending   int
+	reqTime   time.Time
+	resTime   time.Time
+	operation string
+}
+
+func encodeRedis(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn, destConn net.Conn, mocks chan<- *models.Mock, _ models.OutgoingOptions) error {
+	clientBuffChan := make(chan []byte)
+	destBuffChan := make(chan []byte)
+	errChan := make(chan error)
+	destErrChan := make(chan error)
+
+	//get the error group from the context
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		return errors.New("failed to get the error group from the context")
+	}
+
+	// read requests from client
+	g.Go(func() error {
+		defer utils.Recover(logger)
+		defer close(clientBuffChan)
+		pUtil.ReadBuffConn(ctx, logger, clientConn, clientBuffChan, errChan)
+		return nil
+	})
+	// read responses from destination
+	g.Go(func() error {
+		defer utils.Recover(logger)
+		defer close(destBuffChan)
+		pUtil.ReadBuffConn(ctx, logger, destConn, destBuffChan, destErrChan)
+		return nil
+	})
+
+	metadata := mockMetadata(destConn)
+	// the exchanges which aren't saved yet, in the order of their commands
+	var exchanges []*exchange
+	// the bytes of the frames which aren't complete yet
+	var clientRest, destRest []byte
+	// recording stops at the first frame which can't be parsed, the connection being forwarded as it is
+	recording := true
+	subscribed := false
+	lastChunk := time.Now()
+
+	save := func(ex *exchange) {
+		if len(ex.replies) == 0 {
+			return
+		}
+		mock := &models.Mock{
+			Version: models.GetVersion(),
+			Name:    "mocks",
+			Kind:    models.REDIS,
+			Spec: models.MockSpec{
+				Metadata:         map[string]string{"operation": ex.operation},
+				RedisRequests:    []models.RedisPayload{{Origin: models.FromClient, Message: ex.command}},
+				RedisResponses:   ex.replies,
+				ReqTimestampMock: ex.reqTime,
+				ResTimestampMock: ex.resTime,
+			},
+		}
+		for k, v := range metadata {
+			mock.Spec.Metadata[k] = v
+		}
+		select {
+		case <-ctx.Done():
+		case mocks <- mock:
+		}
+	}
+	// saveCompleted saves the exchanges whose replies were all read, but the last one which may still get pushed messages
+	saveCompleted := func() {
+		for len(exchanges) > 1 && exchanges[0].pending <= 0 {
+			save(exchanges[0])
+			exchanges = exchanges[1:]
+		}
+	}
+	saveAll := func() {
+		for _, ex := range exchanges {
+			save(ex)
+		}
+		exchanges = nil
+	}
+
+	onRequest := func(buffer []byte) {
+		if !recording {
+			return
+		}
+		var commands []models.RedisValue
+		var err error
+		commands, clientRest, err = parseFrames(append(clientRest, buffer...), parseCommand)
+		if err != nil {
+			logger.Debug("failed to parse the redis command, the connection isn't recorded anymore", zap.Error(err))
+			recording = false
+		}
+		for _, command := range commands {
+			args := commandArgs(command)
+			if isSubscription(args) {
+				subscribed = true
+			}
+			operation := ""
+			if len(args) > 0 {
+				operation = strings.ToUpper(args[0])
+			}
+			exchanges = append(exchanges, &exchange{
+				command:   command,
+				pending:   replyCount(args),
+				reqTime:   time.Now(),
+				operation: operation,
+			})
+		}
+		saveCompleted()
+	}
+
+	onResponse := func(buffer []byte) {
+		if !recording {
+			return
+		}
+		var replies []models.RedisValue
+		var err error
+		replies, destRest, err = parseFrames(append(destRest, buffer...), parseValue)
+		if err != nil {
+			logger.Debug("failed to parse the redis reply, the connection isn't recorded anymore", zap.Error(err))
+			recording = false
+		}
+		for _, reply := range replies {
+			if len(exchanges) == 0 {
+				logger.Debug("the redis server sent a reply before any command", zap.Any("type", reply.Type))
+				continue
+			}
+			payload := models.RedisPayload{Origin: models.FromServer, Message: reply}
+			// the replies go to the oldest command waiting for them
+			ex := exchanges[len(exchanges)-1]
+			pushed := isPushed(reply, subscribed)
+			if !pushed {
+				for _, waiting := range exchanges {
+					if waiting.pending > 0 {
+						ex = waiting
+						break
+					}
+				}
+			}
+			if pushed || ex.pending <= 0 {
+				payload.ReadDelay = int64(time.Since(lastChunk))
+			} else if reply.Type != typeAttribute {
+				// the attributes come before the reply they describe
+				ex.pending--
+			}
+			ex.replies = append(ex.replies, payload)
+			ex.resTime = time.Now()
+		}
+	}
+
+	_, err := destConn.Write(reqBuf)
+	if err != nil {
+		utils.LogError(logger, err, "failed to write request message to the destination server")
+		return err
+	}
+	onRequest(reqBuf)
+
+	for {
+		select {
+		case <-ctx.Done():
+			saveAll()
+			return ctx.Err()
+		case buffer := <-clientBuffChan:
+			// Write the request message to the destination
+			_, err := destConn.Write(buffer)
+			if err != nil {
+				utils.LogError(logger, err, "failed to write request message to the destination server")
+				return err
+			}
+			onRequest(buffer)
+			lastChunk = time.Now()
+		case buffer := <-destBuffChan:
+			// Write the response message to the client
+			_, err := clientConn.Write(buffer)
+			if err != nil {
+				utils.LogError(logger, err, "failed to write response message to the client")
+				return err
+			}
+			onResponse(buffer)
+			lastChunk = time.Now()
+		case err := <-errChan:
+			saveAll()
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case err := <-destErrChan:
+			saveAll()
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// mockMetadata returns the metadata of the redis mocks recorded on the connection
+func mockMetadata(destConn net.Conn) map[string]string {
+	metadata := map[string]string{}
+	if _, port, err := net.SplitHostPort(destConn.RemoteAddr().String()); err == nil {
+		metadata["port"] = port
+	}
+	return metadata
+}
@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
+	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// matchGeneric returns the responses of the generic mock recorded for the batch of commands before the redis
+// connections were parsed. The generic mocks hold the raw chunks of a whole batch, e.g. a pipeline or a MULTI/EXEC
+// block, so they are matched with the batch as a whole. The mocks recorded during the test case are consumed, the
+// others are reused.
+func matchGeneric(ctx context.Context, mockDb integrations.MockMemDb, batch []byte) (bool, []models.GenericPayload, error) {
+	for {
+		if ctx.Err() != nil {
+			return false, nil, ctx.Err()
+		}
+		mocks, err := mockDb.GetUnFilteredMocks()
+		if err != nil {
+			return false, nil, fmt.Errorf("error while getting unfiltered mocks %v", err)
+		}
+
+		var matched *models.Mock
+		for _, mock := range mocks {
+			if mock.Kind != models.GENERIC || !bytes.Equal(genericBytes(mock.Spec.GenericRequests), batch) {
+				continue
+			}
+			if mock.TestModeInfo.IsFiltered {
+				matched = mock
+				break
+			}
+			if matched == nil {
+				matched = mock
+			}
+		}
+		if matched == nil {
+			return false, nil, nil
+		}
+
+		responses := make([]models.GenericPayload, len(matched.Spec.GenericResponses))
+		copy(responses, matched.Spec.GenericResponses)
+		if matched.TestModeInfo.IsFiltered {
+			originalMatchedMock := *matched
+			matched.TestModeInfo.IsFiltered = false
+			matched.TestModeInfo.SortOrder = math.MaxInt64
+			if !mockDb.UpdateUnFilteredMock(&originalMatchedMock, matched) {
+				// the mock was consumed by another connection meanwhile
+				continue
+			}
+		} else if err := mockDb.FlagMockAsUsed(matched); err != nil {
+			return true, responses, fmt.Errorf("failed to flag mock as used: %v", err)
+		}
+		return true, responses, nil
+	}
+}
+
+// writeGeneric writes the recorded chunks of the generic mock to the client, the messages pushed by the server
+// after their recorded delay
+func writeGeneric(ctx context.Context, logger *zap.Logger, clientConn net.Conn, responses []models.GenericPayload) error {
+	for _, response := range responses {
+		if response.Failure != "" {
+			logger.Debug("replaying the failure of the redis server", zap.Any("failure", response.Failure))
+			return pUtil.InjectFailure(ctx, clientConn, response.Failure)
+		}
+		if response.ReadDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(response.ReadDelay)):
+			}
+		}
+		if _, err := clientConn.Write(genericBytes([]models.GenericPayload{response})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// genericBytes joins the recorded chunks into the raw bytes which were sent
+func genericBytes(payloads []models.GenericPayload) []byte {
+	var buf []byte
+	for _, payload := range payloads {
+		if len(payload.Message) == 0 {
+			continue
+		}
+		data := []byte(payload.Message[0].Data)
+		if payload.Message[0].Type != models.String {
+			decoded, err := util.DecodeBase64(payload.Message[0].Data)
+			if err != nil {
+				return nil
+			}
+			data = decoded
+		}
+		buf = append(buf, data...)
+	}
+	return buf
+}
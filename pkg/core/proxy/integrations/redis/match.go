@@ -0,0 +1,204 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// match returns the replies of the mock recorded for the command. The mocks recorded during the test case are
+// consumed in order, the others are reused e.g. for the pings and the handshakes of the connection pools. The commands
+// matching no mock exactly get the replies of the mock of the same command on the same keys with the most equal
+// arguments, never those recorded for other keys.
+func match(ctx context.Context, logger *zap.Logger, mockDb integrations.MockMemDb, args []string) (bool, []models.RedisPayload, error) {
+	if len(args) == 0 {
+		return false, nil, nil
+	}
+	for {
+		if ctx.Err() != nil {
+			return false, nil, ctx.Err()
+		}
+		mocks, err := mockDb.GetUnFilteredMocks()
+		if err != nil {
+			return false, nil, fmt.Errorf("error while getting unfiltered mocks %v", err)
+		}
+
+		var filteredMocks []*models.Mock
+		var unfilteredMocks []*models.Mock
+		for _, mock := range mocks {
+			if mock.Kind != models.REDIS || len(mock.Spec.RedisRequests) == 0 {
+				continue
+			}
+			if mock.TestModeInfo.IsFiltered {
+				filteredMocks = append(filteredMocks, mock)
+			} else {
+				unfilteredMocks = append(unfilteredMocks, mock)
+			}
+		}
+
+		var matched *models.Mock
+		if idx := findExactMatch(filteredMocks, args); idx != -1 {
+			matched = filteredMocks[idx]
+		} else if idx := findExactMatch(unfilteredMocks, args); idx != -1 {
+			matched = unfilteredMocks[idx]
+		} else if idx := findClosestMatch(filteredMocks, args); idx != -1 {
+			matched = filteredMocks[idx]
+		} else if idx := findClosestMatch(unfilteredMocks, args); idx != -1 {
+			matched = unfilteredMocks[idx]
+		}
+		if matched == nil {
+			return false, nil, nil
+		}
+
+		logger.Debug("Matched mock", zap.String("mock", matched.Name))
+		replies := make([]models.RedisPayload, len(matched.Spec.RedisResponses))
+		copy(replies, matched.Spec.RedisResponses)
+		if matched.TestModeInfo.IsFiltered {
+			originalMatchedMock := *matched
+			matched.TestModeInfo.IsFiltered = false
+			matched.TestModeInfo.SortOrder = math.MaxInt64
+			if !mockDb.UpdateUnFilteredMock(&originalMatchedMock, matched) {
+				// the mock was consumed by another connection meanwhile
+				continue
+			}
+		} else if err := mockDb.FlagMockAsUsed(matched); err != nil {
+			logger.Error("failed to flag mock as used", zap.Error(err))
+		}
+		return true, replies, nil
+	}
+}
+
+// findExactMatch returns the index of the first mock recorded for the same command and arguments
+func findExactMatch(mocks []*models.Mock, args []string) int {
+	for idx, mock := range mocks {
+		recorded := commandArgs(mock.Spec.RedisRequests[0].Message)
+		if len(recorded) != len(args) || !strings.EqualFold(recorded[0], args[0]) {
+			continue
+		}
+		equal := true
+		for i := 1; i < len(args); i++ {
+			if recorded[i] != args[i] {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return idx
+		}
+	}
+	return -1
+}
+
+// findClosestMatch returns the index of the mock recorded for the same command on the same keys and the same number of
+// arguments with the most of them equal, e.g. a SET of a key with a generated id or a timestamp in its value
+func findClosestMatch(mocks []*models.Mock, args []string) int {
+	best, bestIdx := -1, -1
+	for idx, mock := range mocks {
+		recorded := commandArgs(mock.Spec.RedisRequests[0].Message)
+		if len(recorded) != len(args) || !strings.EqualFold(recorded[0], args[0]) || !sameKeys(recorded, args) {
+			continue
+		}
+		score := 0
+		for i := 1; i < len(args); i++ {
+			if recorded[i] == args[i] {
+				score++
+			}
+		}
+		if score > best {
+			best, bestIdx = score, idx
+		}
+	}
+	return bestIdx
+}
+
+// sameKeys reports whether the commands, of the same name and number of arguments, are on the same keys
+func sameKeys(a, b []string) bool {
+	for _, i := range keyPositions(a) {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyPositions returns the positions of the keys, and of the channels, among the arguments of the command. The
+// commands without a key, e.g. PING, have none, and most of the others take the key as their first argument.
+func keyPositions(args []string) []int {
+	var positions []int
+	switch strings.ToUpper(args[0]) {
+	case "PING", "ECHO", "HELLO", "AUTH", "SELECT", "CLIENT", "INFO", "TIME", "DBSIZE", "MULTI", "EXEC", "DISCARD",
+		"QUIT", "RESET", "SCAN", "KEYS", "FLUSHDB", "FLUSHALL", "CONFIG", "COMMAND", "SCRIPT", "READONLY", "READWRITE":
+		// the arguments aren't keys
+	case "MGET", "DEL", "UNLINK", "EXISTS", "TOUCH", "WATCH", "SINTER", "SUNION", "SDIFF", "PFCOUNT",
+		"SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE", "UNSUBSCRIBE", "PUNSUBSCRIBE", "SUNSUBSCRIBE":
+		for i := 1; i < len(args); i++ {
+			positions = append(positions, i)
+		}
+	case "EVAL", "EVALSHA", "EVAL_RO", "EVALSHA_RO", "FCALL", "FCALL_RO":
+		// the script and its keys, which follow their number
+		if len(args) > 1 {
+			positions = append(positions, 1)
+		}
+		if len(args) > 2 {
+			positions = append(positions, 2)
+			if n, err := strconv.Atoi(args[2]); err == nil {
+				for i := 3; i < len(args) && i < 3+n; i++ {
+					positions = append(positions, i)
+				}
+			}
+		}
+	case "MSET", "MSETNX":
+		for i := 1; i < len(args); i += 2 {
+			positions = append(positions, i)
+		}
+	default:
+		if len(args) > 1 {
+			positions = append(positions, 1)
+		}
+	}
+	return positions
+}
+
+// closestMocks compares the command which matched no mock with the redis mocks argument by argument, the command name
+// being compared regardless of its case
+func closestMocks(mocks []*models.Mock, args []string) []models.MockCandidate {
+	var candidates []models.MockCandidate
+	for _, mock := range mocks {
+		if mock.Kind != models.REDIS || len(mock.Spec.RedisRequests) == 0 {
+			continue
+		}
+		recorded := commandArgs(mock.Spec.RedisRequests[0].Message)
+		fields := max(len(recorded), len(args))
+		if fields == 0 {
+			continue
+		}
+		var mismatches []string
+		if len(recorded) != len(args) {
+			mismatches = append(mismatches, fmt.Sprintf("arguments: the mock has %d, the command %d", len(recorded)-1, len(args)-1))
+		}
+		equal := 0
+		for i := 0; i < min(len(recorded), len(args)); i++ {
+			switch {
+			case i == 0 && strings.EqualFold(recorded[0], args[0]), i > 0 && recorded[i] == args[i]:
+				equal++
+			case i == 0:
+				mismatches = append(mismatches, fmt.Sprintf("command: the mock has %s, the command %s", strings.ToUpper(recorded[0]), strings.ToUpper(args[0])))
+			default:
+				mismatches = append(mismatches, fmt.Sprintf("argument %d: the mock has %q, the command %q", i, util.Excerpt([]byte(recorded[i])), util.Excerpt([]byte(args[i]))))
+			}
+		}
+		candidates = append(candidates, models.MockCandidate{
+			Mock:       mock.Name,
+			Score:      util.Score(float64(equal), fields),
+			Mismatches: mismatches,
+		})
+	}
+	return candidates
+}
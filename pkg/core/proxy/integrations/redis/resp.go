@@ -0,0 +1,274 @@
+package redis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// the types of the RESP values, see https://redis.io/docs/latest/develop/reference/protocol-spec
+const (
+	typeSimpleString = "simple"
+	typeError        = "error"
+	typeInteger      = "integer"
+	typeBulkString   = "bulk"
+	typeArray        = "array"
+	typeNull         = "null"
+	typeBoolean      = "boolean"
+	typeDouble       = "double"
+	typeBigNumber    = "bignum"
+	typeBulkError    = "bulkError"
+	typeVerbatim     = "verbatim"
+	typeMap          = "map"
+	typeSet          = "set"
+	typePush         = "push"
+	typeAttribute    = "attribute"
+)
+
+var prefixes = map[byte]string{
+	'+': typeSimpleString,
+	'-': typeError,
+	':': typeInteger,
+	'$': typeBulkString,
+	'*': typeArray,
+	'_': typeNull,
+	'#': typeBoolean,
+	',': typeDouble,
+	'(': typeBigNumber,
+	'!': typeBulkError,
+	'=': typeVerbatim,
+	'%': typeMap,
+	'~': typeSet,
+	'>': typePush,
+	'|': typeAttribute,
+}
+
+// maxBulkLength is the largest string a redis server accepts, longer lengths are a corrupted frame
+const maxBulkLength = 512 << 20
+
+// errIncomplete is a buffer which ends before the value it starts
+var errIncomplete = errors.New("incomplete RESP value")
+
+// parseValue parses the RESP value at the start of the buffer and returns the number of bytes it spans
+func parseValue(buf []byte) (models.RedisValue, int, error) {
+	if len(buf) == 0 {
+		return models.RedisValue{}, 0, errIncomplete
+	}
+	typ, ok := prefixes[buf[0]]
+	if !ok {
+		return models.RedisValue{}, 0, fmt.Errorf("unknown RESP type %q", buf[0])
+	}
+	end := bytes.Index(buf, []byte("\r\n"))
+	if end == -1 {
+		return models.RedisValue{}, 0, errIncomplete
+	}
+	line := string(buf[1:end])
+	n := end + 2
+	value := models.RedisValue{Type: typ}
+
+	switch typ {
+	case typeSimpleString, typeError, typeInteger, typeNull, typeBoolean, typeDouble, typeBigNumber:
+		value.Value = line
+		return value, n, nil
+	case typeBulkString, typeBulkError, typeVerbatim:
+		length, err := strconv.Atoi(line)
+		if err != nil || length > maxBulkLength {
+			return models.RedisValue{}, 0, fmt.Errorf("invalid length %q of the RESP %s", line, typ)
+		}
+		if length < 0 {
+			// RESP2 nil bulk string
+			if length != -1 || typ != typeBulkString {
+				return models.RedisValue{}, 0, fmt.Errorf("invalid length %q of the RESP %s", line, typ)
+			}
+			value.Null = true
+			return value, n, nil
+		}
+		if len(buf) < n+length+2 {
+			return models.RedisValue{}, 0, errIncomplete
+		}
+		if !bytes.Equal(buf[n+length:n+length+2], []byte("\r\n")) {
+			return models.RedisValue{}, 0, fmt.Errorf("the RESP %s isn't terminated by CRLF", typ)
+		}
+		value.Value = string(buf[n : n+length])
+		return value, n + length + 2, nil
+	}
+
+	count, err := strconv.Atoi(line)
+	if err != nil {
+		return models.RedisValue{}, 0, fmt.Errorf("invalid size %q of the RESP %s", line, typ)
+	}
+	if count < 0 {
+		// RESP2 nil array
+		if count != -1 || typ != typeArray {
+			return models.RedisValue{}, 0, fmt.Errorf("invalid size %q of the RESP %s", line, typ)
+		}
+		value.Null = true
+		return value, n, nil
+	}
+	// the keys and the values of the maps and the attributes alternate
+	if typ == typeMap || typ == typeAttribute {
+		count *= 2
+	}
+	for i := 0; i < count; i++ {
+		element, size, err := parseValue(buf[n:])
+		if err != nil {
+			return models.RedisValue{}, 0, err
+		}
+		value.Elements = append(value.Elements, element)
+		n += size
+	}
+	return value, n, nil
+}
+
+// parseCommand parses the command at the start of the buffer, an array of bulk strings or an inline command
+// typed in e.g. by telnet
+func parseCommand(buf []byte) (models.RedisValue, int, error) {
+	if len(buf) > 0 && buf[0] == '*' {
+		return parseValue(buf)
+	}
+	end := bytes.IndexByte(buf, '\n')
+	if end == -1 {
+		return models.RedisValue{}, 0, errIncomplete
+	}
+	command := models.RedisValue{Type: typeArray}
+	for _, arg := range strings.Fields(string(buf[:end])) {
+		command.Elements = append(command.Elements, models.RedisValue{Type: typeBulkString, Value: arg})
+	}
+	return command, end + 1, nil
+}
+
+// parseFrames parses the complete values of the buffer, returning the bytes of the value which isn't complete yet
+func parseFrames(buf []byte, parse func([]byte) (models.RedisValue, int, error)) ([]models.RedisValue, []byte, error) {
+	var values []models.RedisValue
+	for len(buf) > 0 {
+		value, n, err := parse(buf)
+		if errors.Is(err, errIncomplete) {
+			break
+		}
+		if err != nil {
+			return values, buf, err
+		}
+		values = append(values, value)
+		buf = buf[n:]
+	}
+	return values, buf, nil
+}
+
+// encodeValue encodes the value as it was sent
+func encodeValue(value models.RedisValue) []byte {
+	var buf bytes.Buffer
+	writeValue(&buf, value)
+	return buf.Bytes()
+}
+
+func writeValue(buf *bytes.Buffer, value models.RedisValue) {
+	var prefix byte
+	for p, typ := range prefixes {
+		if typ == value.Type {
+			prefix = p
+			break
+		}
+	}
+	buf.WriteByte(prefix)
+	switch value.Type {
+	case typeBulkString, typeBulkError, typeVerbatim:
+		if value.Null {
+			buf.WriteString("-1\r\n")
+			return
+		}
+		buf.WriteString(strconv.Itoa(len(value.Value)) + "\r\n" + value.Value + "\r\n")
+	case typeArray, typeSet, typePush, typeMap, typeAttribute:
+		if value.Null {
+			buf.WriteString("-1\r\n")
+			return
+		}
+		count := len(value.Elements)
+		if value.Type == typeMap || value.Type == typeAttribute {
+			count /= 2
+		}
+		buf.WriteString(strconv.Itoa(count) + "\r\n")
+		for _, element := range value.Elements {
+			writeValue(buf, element)
+		}
+	default:
+		buf.WriteString(value.Value + "\r\n")
+	}
+}
+
+// isRESPCommand reports whether the buffer starts with a command of a redis client, an array of bulk strings
+func isRESPCommand(buf []byte) bool {
+	if len(buf) < 4 || buf[0] != '*' {
+		return false
+	}
+	end := bytes.Index(buf, []byte("\r\n"))
+	if end < 2 {
+		return false
+	}
+	if count, err := strconv.Atoi(string(buf[1:end])); err != nil || count < 1 {
+		return false
+	}
+	// the first bulk string may not have been read yet
+	return len(buf) == end+2 || buf[end+2] == '$'
+}
+
+// commandArgs returns the name and the arguments of the command, nil if it isn't an array of strings
+func commandArgs(command models.RedisValue) []string {
+	if command.Type != typeArray || len(command.Elements) == 0 {
+		return nil
+	}
+	args := make([]string, 0, len(command.Elements))
+	for _, element := range command.Elements {
+		if element.Type != typeBulkString && element.Type != typeSimpleString {
+			return nil
+		}
+		args = append(args, element.Value)
+	}
+	return args
+}
+
+// replyCount returns the number of the replies the server sends to the command
+func replyCount(args []string) int {
+	if len(args) == 0 {
+		return 1
+	}
+	switch strings.ToUpper(args[0]) {
+	case "SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE", "UNSUBSCRIBE", "PUNSUBSCRIBE", "SUNSUBSCRIBE":
+		// each channel is confirmed apart, unsubscribing from all of them being confirmed at least once
+		if len(args) > 1 {
+			return len(args) - 1
+		}
+	}
+	return 1
+}
+
+// isPushed reports whether the value is a message the server pushed without a command, e.g. to the subscribers of a
+// channel or the invalidation of a key tracked by the client. The RESP2 messages are arrays like the replies, so they
+// are only told apart on the subscribed connections.
+func isPushed(value models.RedisValue, subscribed bool) bool {
+	if value.Type != typePush && (value.Type != typeArray || !subscribed) || len(value.Elements) == 0 {
+		return false
+	}
+	switch strings.ToLower(value.Elements[0].Value) {
+	case "message", "pmessage", "smessage":
+		return true
+	case "invalidate":
+		return value.Type == typePush
+	}
+	return false
+}
+
+// isSubscription reports whether the command subscribes the connection to channels, patterns or shard channels
+func isSubscription(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch strings.ToUpper(args[0]) {
+	case "SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE":
+		return true
+	}
+	return false
+}
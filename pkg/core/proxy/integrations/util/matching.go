@@ -0,0 +1,100 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// MaxCandidates is the number of the closest mocks traced for an outgoing call which matched no mock
+const MaxCandidates = 3
+
+// maxExcerpt is the length of the excerpts of the requests written to the trace
+const maxExcerpt = 200
+
+// traceMu serializes the writes of the connections of every integration to the trace files
+var traceMu sync.Mutex
+
+// TraceUnmatched logs the mocks closest to an outgoing call which matched no mock, with the fields of the call they
+// differ in, and appends them to the matching file of the test run. Nothing is traced when opts is nil.
+func TraceUnmatched(logger *zap.Logger, opts *models.MatchDebugOptions, kind models.Kind, request string, candidates []models.MockCandidate) {
+	if opts == nil {
+		return
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > MaxCandidates {
+		candidates = candidates[:MaxCandidates]
+	}
+	call := models.UnmatchedCall{
+		Time:       time.Now(),
+		TestSet:    opts.TestSet,
+		Kind:       kind,
+		Request:    request,
+		Candidates: candidates,
+	}
+	logger.Info("no mock matched the outgoing call", zap.String("kind", string(kind)), zap.String("request", request), zap.Any("closestMocks", candidates))
+
+	data, err := yamlLib.Marshal(&call)
+	if err != nil {
+		logger.Error("failed to marshal the unmatched call", zap.Error(err))
+		return
+	}
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0777); err != nil {
+		logger.Error("failed to create the directory of the matching trace", zap.String("path", opts.Path), zap.Error(err))
+		return
+	}
+	file, err := os.OpenFile(opts.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.Error("failed to open the matching trace", zap.String("path", opts.Path), zap.Error(err))
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append([]byte("---\n"), data...)); err != nil {
+		logger.Error("failed to write the unmatched call to the matching trace", zap.String("path", opts.Path), zap.Error(err))
+	}
+}
+
+// Score returns the similarity of a mock whose equal fields out of the compared ones add up to equal, the partially
+// equal fields adding their own similarity
+func Score(equal float64, fields int) float64 {
+	if fields == 0 {
+		return 0
+	}
+	return math.Round(equal/float64(fields)*100) / 100
+}
+
+// Excerpt returns the start of a request as text, or its size when it's binary
+func Excerpt(data []byte) string {
+	s := string(data)
+	for _, r := range s {
+		if r > unicode.MaxASCII || (!unicode.IsPrint(r) && !unicode.IsSpace(r)) {
+			return fmt.Sprintf("%d bytes of binary data", len(data))
+		}
+	}
+	if len(s) > maxExcerpt {
+		return s[:maxExcerpt] + "..."
+	}
+	return s
+}
+
+// Similarity returns the similarity of the shingles of two payloads, 1 for equal payloads
+func Similarity(a, b []byte) float64 {
+	if string(a) == string(b) {
+		return 1
+	}
+	k := AdaptiveK(len(a), 3, 8, 5)
+	return JaccardSimilarity(CreateShingles(a, k), CreateShingles(b, k))
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	sqlStringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumberLiteral  = regexp.MustCompile(`\b\d+(?:\.\d+)?(?:[eE][-+]?\d+)?\b`)
+	sqlWhitespace     = regexp.MustCompile(`\s+`)
+	sqlValueList      = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	sqlValueListGroup = regexp.MustCompile(`\(\?\+\)(?:\s*,\s*\(\?\+\))+`)
+)
+
+// FingerprintSQL normalizes a query so that the queries which only differ in their literal values
+// and whitespace have the same fingerprint, e.g. "SELECT * FROM t WHERE id = 5 LIMIT 10" and
+// "select *  from t where id = 7 limit 20" both become "select * from t where id = ? limit ?".
+// The lists of values like IN (1, 2, 3) and the rows of a multi-row INSERT are collapsed too.
+func FingerprintSQL(query string) string {
+	fp := sqlStringLiteral.ReplaceAllString(query, "?")
+	fp = sqlNumberLiteral.ReplaceAllString(fp, "?")
+	fp = sqlWhitespace.ReplaceAllString(fp, " ")
+	fp = sqlValueList.ReplaceAllString(fp, "(?+)")
+	fp = sqlValueListGroup.ReplaceAllString(fp, "(?+)")
+	fp = strings.TrimSuffix(strings.TrimSpace(fp), ";")
+	return strings.ToLower(strings.TrimSpace(fp))
+}
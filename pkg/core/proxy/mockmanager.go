@@ -16,6 +16,9 @@ type MockManager struct {
 	unfiltered    *TreeDb
 	logger        *zap.Logger
 	consumedMocks sync.Map
+	// names of the filtered mocks in the order they were consumed, reset when the filtered mocks are set
+	consumedOrder []string
+	orderMu       sync.Mutex
 }
 
 func NewMockManager(filtered, unfiltered *TreeDb, logger *zap.Logger) *MockManager {
@@ -28,6 +31,10 @@ func NewMockManager(filtered, unfiltered *TreeDb, logger *zap.Logger) *MockManag
 }
 
 func (m *MockManager) SetFilteredMocks(mocks []*models.Mock) {
+	m.orderMu.Lock()
+	m.consumedOrder = nil
+	m.orderMu.Unlock()
+
 	m.filtered.deleteAll()
 	for index, mock := range mocks {
 		mock.TestModeInfo.SortOrder = index
@@ -95,6 +102,9 @@ func (m *MockManager) FlagMockAsUsed(mock *models.Mock) error {
 func (m *MockManager) DeleteFilteredMock(mock *models.Mock) bool {
 	isDeleted := m.filtered.delete(mock.TestModeInfo)
 	if isDeleted {
+		m.orderMu.Lock()
+		m.consumedOrder = append(m.consumedOrder, mock.Name)
+		m.orderMu.Unlock()
 		go func() {
 			if err := m.FlagMockAsUsed(mock); err != nil {
 				m.logger.Error("failed to flag mock as used", zap.Error(err))
@@ -132,3 +142,12 @@ func (m *MockManager) GetConsumedMocks() []string {
 	m.consumedMocks = sync.Map{}
 	return keys
 }
+
+// GetConsumedOrder returns the filtered mocks consumed since they were set, in the order of consumption
+func (m *MockManager) GetConsumedOrder() []string {
+	m.orderMu.Lock()
+	defer m.orderMu.Unlock()
+	order := m.consumedOrder
+	m.consumedOrder = nil
+	return order
+}
@@ -0,0 +1,236 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// isProcessExcluded reports whether the client connection belongs to a process which shouldn't be tracked
+// according to the configured process name patterns. The process is the one the hooks recorded for the call,
+// else the one owning the socket. Connections whose process can't be found, e.g. the ones coming from another
+// network namespace, are tracked as before.
+func (p *Proxy) isProcessExcluded(srcConn net.Conn, pid uint32) bool {
+	if len(p.processes.Include) == 0 && len(p.processes.Exclude) == 0 {
+		return false
+	}
+	remoteAddr, ok := srcConn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	var name string
+	var err error
+	if pid != 0 {
+		name, err = processName(strconv.FormatUint(uint64(pid), 10))
+	} else {
+		name, err = p.connOwner(uint16(remoteAddr.Port), uint16(p.Port))
+	}
+	if err != nil {
+		p.logger.Debug("failed to find the process of the client connection", zap.Int("source port", remoteAddr.Port), zap.Error(err))
+		return false
+	}
+
+	for _, pattern := range p.processes.Exclude {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			p.logger.Debug("passing through the call of an excluded process", zap.String("process", name))
+			return true
+		}
+	}
+	if len(p.processes.Include) == 0 {
+		return false
+	}
+	for _, pattern := range p.processes.Include {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return false
+		}
+	}
+	p.logger.Debug("passing through the call of a process which isn't included", zap.String("process", name))
+	return true
+}
+
+// connOwner returns the name of the process owning the client end of a connection redirected to the proxy. The
+// processes which owned the connections lately are searched first, as an app makes most of its calls from the
+// same processes, before all the processes are.
+func (p *Proxy) connOwner(srcPort, proxyPort uint16) (string, error) {
+	inode, err := socketInode(srcPort, proxyPort)
+	if err != nil {
+		return "", err
+	}
+	target := fmt.Sprintf("socket:[%s]", inode)
+
+	var owner string
+	p.owners.Range(func(key, _ any) bool {
+		pid := key.(string)
+		owns, err := ownsSocket(pid, target)
+		if err != nil {
+			// the process is gone
+			p.owners.Delete(pid)
+			return true
+		}
+		if owns {
+			owner = pid
+			return false
+		}
+		return true
+	})
+	if owner != "" {
+		return processName(owner)
+	}
+
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return "", err
+	}
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil {
+			continue
+		}
+		if owns, _ := ownsSocket(proc.Name(), target); owns {
+			p.owners.Store(proc.Name(), struct{}{})
+			return processName(proc.Name())
+		}
+	}
+	return "", fmt.Errorf("no process found for the socket %s", inode)
+}
+
+// ownsSocket reports whether one of the file descriptors of the process is the socket
+func ownsSocket(pid, target string) (bool, error) {
+	fdDir := filepath.Join("/proc", pid, "fd")
+	fds, err := os.ReadDir(fdDir)
+	if err != nil {
+		return false, err
+	}
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err == nil && link == target {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// processName returns the name of the process of the pid
+func processName(pid string) (string, error) {
+	comm, err := os.ReadFile(filepath.Join("/proc", pid, "comm"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(comm)), nil
+}
+
+// parentPid returns the pid of the parent of the process, 0 when it can't be read
+func parentPid(pid uint32) uint32 {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0
+	}
+	// example: 1234 (my app) S 1200 ..., the name of the process may contain spaces and parentheses
+	i := bytes.LastIndexByte(stat, ')')
+	if i == -1 {
+		return 0
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 2 {
+		return 0
+	}
+	ppid, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(ppid)
+}
+
+// socketInode finds the inode of the socket connected from srcPort to the proxy in the tcp tables
+func socketInode(srcPort, proxyPort uint16) (string, error) {
+	for _, table := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		inode, err := findInode(table, srcPort, proxyPort)
+		if err != nil {
+			return "", err
+		}
+		if inode != "" {
+			return inode, nil
+		}
+	}
+	return "", errors.New("socket not found in the tcp tables")
+}
+
+func findInode(table string, srcPort, proxyPort uint16) (string, error) {
+	f, err := os.Open(table)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	local := fmt.Sprintf(":%04X", srcPort)
+	remote := fmt.Sprintf(":%04X", proxyPort)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// example fields: 0: 0100007F:A1B2 0100007F:41D5 01 00000000:00000000 00:00000000 00000000 1000 0 123456 ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		if strings.HasSuffix(fields[1], local) && strings.HasSuffix(fields[2], remote) {
+			return fields[9], nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// tunnel forwards the connection to its destination as is, without recording or mocking it
+func (p *Proxy) tunnel(ctx context.Context, srcConn net.Conn, network, addr string) error {
+	dstConn, err := net.Dial(network, addr)
+	if err != nil {
+		utils.LogError(p.logger, err, "failed to dial the conn to destination server", zap.Any("server address", addr))
+		return err
+	}
+	defer func() {
+		if err := dstConn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			utils.LogError(p.logger, err, "failed to close the destination connection")
+		}
+	}()
+	p.pipe(ctx, srcConn, dstConn)
+	return nil
+}
+
+// pipe copies the data of the connections both ways until either side is done, the connections being closed by the caller
+func (p *Proxy) pipe(ctx context.Context, srcConn, dstConn net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		defer utils.Recover(p.logger)
+		_, _ = io.Copy(dstConn, srcConn)
+		done <- struct{}{}
+	}()
+	go func() {
+		defer utils.Recover(p.logger)
+		_, _ = io.Copy(srcConn, dstConn)
+		done <- struct{}{}
+	}()
+
+	// stop both directions once either side is done, the source connection is closed by the caller
+	select {
+	case <-ctx.Done():
+	case <-done:
+	}
+	_ = srcConn.SetReadDeadline(time.Now())
+	_ = dstConn.SetReadDeadline(time.Now())
+	<-done
+}
@@ -9,8 +9,10 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"runtime/pprof"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -45,26 +47,64 @@ type Proxy struct {
 	ipMutex   *sync.Mutex
 
 	clientConnections []net.Conn
+	// activeConns counts the client connections being handled, waited for when keploy drains
+	activeConns atomic.Int64
 
 	Listener net.Listener
 
 	UDPDNSServer *dns.Server
 	TCPDNSServer *dns.Server
+
+	unixSockets []config.UnixSocket
+	// udpDependencies are the udp dependencies whose datagrams the app sends to the proxy
+	udpDependencies []config.UDPDependency
+	// ipFamily is the address family preferred in the dns answers
+	ipFamily string
+	// processes picks the processes of the application whose calls are captured
+	processes config.ProcessFilter
+	// ignoreRules are the destinations whose calls are neither recorded nor mocked
+	ignoreRules []config.IgnoreRule
+	// dns customizes the answers of the dns server
+	dns config.DNS
+	// destRewrites send the calls to alternates of their destinations while recording
+	destRewrites []config.DestinationRewrite
+	// resolved are the ips of the hosts of the destination rewrites
+	resolved sync.Map
+	// egress counts the outgoing connections of the record sessions
+	egress egress
+	// genericFallback records the connections of the protocols no integration supports as byte-stream mocks,
+	// else they're passed through to their destination while recording
+	genericFallback bool
+	// appIPs are the apps of the client ips, for the apps run in parallel in their own network namespaces
+	appIPs sync.Map
+	// appPids are the pids of the apps, the connections over the unix sockets carrying only the pid of their peer
+	appPids sync.Map
+	// owners are the pids which owned the client connections lately, searched first for the owner of a connection
+	// whose pid the hooks didn't tell
+	owners sync.Map
 }
 
 func New(logger *zap.Logger, info core.DestInfo, opts config.Config) *Proxy {
 	return &Proxy{
-		logger:       logger,
-		Port:         opts.ProxyPort, // default: 16789
-		DNSPort:      opts.DNSPort,   // default: 26789
-		IP4:          "127.0.0.1",    // default: "127.0.0.1" <-> (2130706433)
-		IP6:          "::1",          //default: "::1" <-> ([4]uint32{0000, 0000, 0000, 0001})
-		ipMutex:      &sync.Mutex{},
-		connMutex:    &sync.Mutex{},
-		DestInfo:     info,
-		sessions:     core.NewSessions(),
-		MockManagers: sync.Map{},
-		Integrations: make(map[string]integrations.Integrations),
+		logger:          logger,
+		Port:            opts.ProxyPort, // default: 16789
+		DNSPort:         opts.DNSPort,   // default: 26789
+		IP4:             "127.0.0.1",    // default: "127.0.0.1" <-> (2130706433)
+		IP6:             "::1",          //default: "::1" <-> ([4]uint32{0000, 0000, 0000, 0001})
+		ipMutex:         &sync.Mutex{},
+		connMutex:       &sync.Mutex{},
+		DestInfo:        info,
+		sessions:        core.NewSessions(),
+		MockManagers:    sync.Map{},
+		Integrations:    make(map[string]integrations.Integrations),
+		unixSockets:     opts.UnixSockets,
+		udpDependencies: opts.UDPDependencies,
+		ipFamily:        opts.IPFamily,
+		processes:       opts.Processes,
+		ignoreRules:     opts.IgnoreRules,
+		destRewrites:    destRewrites(opts.Record.DestinationRewrites),
+		dns:             opts.DNS,
+		genericFallback: opts.Record.GenericFallback,
 	}
 }
 
@@ -79,6 +119,14 @@ func (p *Proxy) InitIntegrations(_ context.Context) error {
 
 func (p *Proxy) StartProxy(ctx context.Context, opts core.ProxyOptions) error {
 
+	// the ports may have been switched to free ones if the configured ports were taken
+	if opts.Port != 0 {
+		p.Port = opts.Port
+	}
+	if opts.DNSPort != 0 {
+		p.DNSPort = opts.DNSPort
+	}
+
 	//first initialize the integrations
 	err := p.InitIntegrations(ctx)
 	if err != nil {
@@ -96,10 +144,17 @@ func (p *Proxy) StartProxy(ctx context.Context, opts core.ProxyOptions) error {
 	if !ok {
 		return errors.New("failed to get the error group from the context")
 	}
+	// the goroutines of the proxy are labelled for the profiles
+	ctx = pprof.WithLabels(ctx, pprof.Labels("keploy", "proxy"))
+
+	// the outgoing calls in flight when keploy is stopped are recorded before the proxy is stopped
+	unregister := utils.RegisterDrain(p.drain)
 
 	// start the proxy server
 	g.Go(func() error {
 		utils.Recover(p.logger)
+		defer unregister()
+		pprof.SetGoroutineLabels(ctx)
 		err := p.start(ctx)
 		if err != nil {
 			utils.LogError(p.logger, err, "error while running the proxy server")
@@ -108,6 +163,13 @@ func (p *Proxy) StartProxy(ctx context.Context, opts core.ProxyOptions) error {
 		return nil
 	})
 
+	// start listening on the unix sockets of the outgoing calls which can't be redirected by the hooks
+	err = p.startUnixListeners(ctx, g)
+	if err != nil {
+		utils.LogError(p.logger, err, "failed to start the unix socket listeners")
+		return err
+	}
+
 	//change the ip4 and ip6 if provided in the opts in case of docker environment
 	if len(opts.DNSIPv4Addr) != 0 {
 		p.IP4 = opts.DNSIPv4Addr
@@ -121,6 +183,7 @@ func (p *Proxy) StartProxy(ctx context.Context, opts core.ProxyOptions) error {
 	p.logger.Debug("Starting Tcp Dns Server for handling Dns queries over TCP")
 	g.Go(func() error {
 		utils.Recover(p.logger)
+		pprof.SetGoroutineLabels(ctx)
 		errCh := make(chan error, 1)
 		go func(errCh chan error) {
 			err := p.startTCPDNSServer(ctx)
@@ -146,6 +209,7 @@ func (p *Proxy) StartProxy(ctx context.Context, opts core.ProxyOptions) error {
 	p.logger.Debug("Starting Udp Dns Server for handling Dns queries over UDP")
 	g.Go(func() error {
 		utils.Recover(p.logger)
+		pprof.SetGoroutineLabels(ctx)
 		errCh := make(chan error, 1)
 		go func(errCh chan error) {
 			err := p.startUDPDNSServer(ctx)
@@ -209,6 +273,12 @@ func (p *Proxy) start(ctx context.Context) error {
 		}
 	}()
 
+	// the datagrams of the udp dependencies are handled with the client connections, so their mocks are
+	// recorded before the mock channels are closed
+	if err := p.startUDPListeners(clientConnCtx, clientConnErrGrp); err != nil {
+		return err
+	}
+
 	for {
 		clientConnCh := make(chan net.Conn, 1)
 		errCh := make(chan error, 1)
@@ -232,7 +302,9 @@ func (p *Proxy) start(ctx context.Context) error {
 			return err
 		// handle the client connection
 		case clientConn := <-clientConnCh:
+			p.activeConns.Add(1)
 			clientConnErrGrp.Go(func() error {
+				defer p.activeConns.Add(-1)
 				defer utils.Recover(p.logger)
 				err := p.handleConnection(clientConnCtx, clientConn)
 				if err != nil && err != io.EOF {
@@ -244,6 +316,22 @@ func (p *Proxy) start(ctx context.Context) error {
 	}
 }
 
+// drain waits for the client connections being handled to finish
+func (p *Proxy) drain(ctx context.Context) {
+	for {
+		n := p.activeConns.Load()
+		if n == 0 {
+			return
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			p.logger.Warn("dropped the outgoing calls still in flight after draining", zap.Int64("connections", n))
+			return
+		}
+	}
+}
+
 // handleConnection function executes the actual outgoing network call and captures/forwards the request and response messages.
 func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 	//checking how much time proxy takes to execute the flow.
@@ -262,22 +350,34 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 	//Dialing for tls conn
 	destConnID := util.GetNextID()
 
-	remoteAddr := srcConn.RemoteAddr().(*net.TCPAddr)
-	sourcePort := remoteAddr.Port
+	var destInfo *core.NetworkAddress
+	var err error
+	if uc, ok := srcConn.(*unixConn); ok {
+		destInfo = uc.dest
+		p.logger.Debug("Inside handleConnection of proxyServer", zap.Any("unix socket", destInfo.UnixPath), zap.Any("Time", time.Now().Unix()))
+	} else {
+		remoteAddr := srcConn.RemoteAddr().(*net.TCPAddr)
+		sourcePort := remoteAddr.Port
 
-	p.logger.Debug("Inside handleConnection of proxyServer", zap.Any("source port", sourcePort), zap.Any("Time", time.Now().Unix()))
+		p.logger.Debug("Inside handleConnection of proxyServer", zap.Any("source port", sourcePort), zap.Any("Time", time.Now().Unix()))
 
-	destInfo, err := p.DestInfo.Get(ctx, uint16(sourcePort))
-	if err != nil {
-		utils.LogError(p.logger, err, "failed to fetch the destination info", zap.Any("Source port", sourcePort))
-		return err
-	}
+		destInfo, err = p.DestInfo.Get(ctx, uint16(sourcePort))
+		if err != nil {
+			utils.LogError(p.logger, err, "failed to fetch the destination info", zap.Any("Source port", sourcePort))
+			return err
+		}
 
-	// releases the occupied source port when done fetching the destination info
-	err = p.DestInfo.Delete(ctx, uint16(sourcePort))
-	if err != nil {
-		utils.LogError(p.logger, err, "failed to delete the destination info", zap.Any("Source port", sourcePort))
-		return err
+		// releases the occupied source port when done fetching the destination info
+		err = p.DestInfo.Delete(ctx, uint16(sourcePort))
+		if err != nil {
+			utils.LogError(p.logger, err, "failed to delete the destination info", zap.Any("Source port", sourcePort))
+			return err
+		}
+
+		// the hooks don't know which of the apps run in parallel made the call, their ip tells them apart
+		if id, ok := p.appIPs.Load(remoteAddr.IP.String()); ok {
+			destInfo.AppID = id.(uint64)
+		}
 	}
 
 	//get the session rule
@@ -288,8 +388,14 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 	}
 
 	var dstAddr string
-
-	if destInfo.Version == 4 {
+	// network of the destination, the calls over unix sockets are forwarded to the target socket
+	dstNetwork := "tcp"
+
+	if destInfo.UnixPath != "" {
+		dstNetwork = "unix"
+		dstAddr = destInfo.UnixPath
+		p.logger.Debug("", zap.Any("DestUnixSocket", destInfo.UnixPath), zap.Any("DestPort", destInfo.Port))
+	} else if destInfo.Version == 4 {
 		dstAddr = fmt.Sprintf("%v:%v", util.ToIP4AddressStr(destInfo.IPv4Addr), destInfo.Port)
 		p.logger.Debug("", zap.Any("DestIp4", destInfo.IPv4Addr), zap.Any("DestPort", destInfo.Port))
 	} else if destInfo.Version == 6 {
@@ -297,11 +403,34 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 		p.logger.Debug("", zap.Any("DestIp6", destInfo.IPv6Addr), zap.Any("DestPort", destInfo.Port))
 	}
 
+	// the calls are sent to the alternate of their destination while recording, matched by its ip here and by
+	// its server name or http host once the connection is read
+	rewritten := false
+	if rule.Mode != models.MODE_TEST && destInfo.UnixPath == "" && len(p.destRewrites) != 0 {
+		if host, _, err := net.SplitHostPort(dstAddr); err == nil {
+			dstAddr, rewritten = p.rewriteDestinationOr(dstAddr, []string{host}, destInfo.Port)
+		}
+	}
+
+	// calls of the processes which aren't tracked, e.g. build tools launched along with the app, go straight to the destination
+	if destInfo.UnixPath == "" && p.isProcessExcluded(srcConn, destInfo.Pid) {
+		if rule.Mode == models.MODE_RECORD {
+			p.egress.add(destInfo.AppID, egressHost("", "", dstAddr), destInfo.Port, "unknown", models.EgressPassThrough)
+		}
+		defer func() {
+			if err := srcConn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				utils.LogError(p.logger, err, "failed to close the source connection")
+			}
+		}()
+		return p.tunnel(ctx, srcConn, dstNetwork, dstAddr)
+	}
+
 	// This is used to handle the parser errors
 	parserErrGrp, parserCtx := errgroup.WithContext(ctx)
 	parserCtx = context.WithValue(parserCtx, models.ErrGroupKey, parserErrGrp)
 	parserCtx = context.WithValue(parserCtx, models.ClientConnectionIDKey, fmt.Sprint(clientConnID))
 	parserCtx = context.WithValue(parserCtx, models.DestConnectionIDKey, fmt.Sprint(destConnID))
+	parserCtx = context.WithValue(parserCtx, models.AppIDKey, destInfo.AppID)
 	parserCtx, parserCtxCancel := context.WithCancel(parserCtx)
 	defer func() {
 		parserCtxCancel()
@@ -334,11 +463,12 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 	if destInfo.Port == 3306 {
 		var dstConn net.Conn
 		if rule.Mode != models.MODE_TEST {
-			dstConn, err = net.Dial("tcp", dstAddr)
+			dstConn, err = net.Dial(dstNetwork, dstAddr)
 			if err != nil {
 				utils.LogError(p.logger, err, "failed to dial the conn to destination server", zap.Any("proxy port", p.Port), zap.Any("server address", dstAddr))
 				return err
 			}
+			p.egress.add(destInfo.AppID, egressHost("", "", dstAddr), destInfo.Port, "mysql", models.EgressRecorded)
 			// Record the outgoing message into a mock
 			err := p.Integrations["mysql"].RecordOutgoing(parserCtx, srcConn, dstConn, rule.MC, rule.OutgoingOptions)
 			if err != nil {
@@ -408,6 +538,35 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 
 	logger := p.logger.With(zap.Any("Client IP Address", srcConn.RemoteAddr().String()), zap.Any("Client ConnectionID", clientConnID), zap.Any("Destination IP Address", dstAddr), zap.Any("Destination ConnectionID", destConnID))
 
+	if len(p.ignoreRules) != 0 {
+		reqHost, isHTTP := httpHost(initialBuf)
+		var hosts []string
+		if isTLS {
+			hosts = append(hosts, dstURL)
+		}
+		hosts = append(hosts, reqHost, dstAddr)
+		if host, _, err := net.SplitHostPort(dstAddr); err == nil {
+			hosts = append(hosts, host)
+		}
+		if ignoreRule := p.matchIgnoreRule(logger, hosts, destInfo.Port); ignoreRule != nil {
+			if rule.Mode == models.MODE_RECORD {
+				protocol := "unknown"
+				if isHTTP {
+					protocol = "http"
+				}
+				p.egress.add(destInfo.AppID, egressHost(dstURL, reqHost, dstAddr), destInfo.Port, protocol, models.EgressIgnored)
+			}
+			return p.handleIgnored(logger, srcConn, ignoreRule, isHTTP)
+		}
+	}
+
+	// the dns queries sent over tls are answered by the dns server of the proxy, as the plain ones
+	if isTLS && p.dns.InterceptDoH {
+		if dot, doh := p.isEncryptedDNS(dstURL, dstAddr, destInfo.Port); dot || doh {
+			return p.serveEncryptedDNS(logger, srcConn, dot)
+		}
+	}
+
 	dstCfg := &integrations.ConditionalDstCfg{
 		Port: uint(destInfo.Port),
 	}
@@ -420,7 +579,19 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 			ServerName:         dstURL,
 		}
 
-		addr := fmt.Sprintf("%v:%v", dstURL, destInfo.Port)
+		// fallback to the destination ip when the client didn't send the server name
+		addr := dstAddr
+		if dstURL != "" && !rewritten {
+			addr = net.JoinHostPort(dstURL, fmt.Sprint(destInfo.Port))
+		}
+		if rule.Mode != models.MODE_TEST && !rewritten && len(p.destRewrites) != 0 {
+			addr, rewritten = p.rewriteDestinationOr(addr, []string{dstURL}, destInfo.Port)
+		}
+		if rewritten {
+			if host, _, err := net.SplitHostPort(addr); err == nil && net.ParseIP(host) == nil {
+				cfg.ServerName = host
+			}
+		}
 		if rule.Mode != models.MODE_TEST {
 			dialer := &net.Dialer{
 				Timeout: 4 * time.Second,
@@ -436,8 +607,13 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 		dstCfg.Addr = addr
 
 	} else {
+		if rule.Mode != models.MODE_TEST && !rewritten && len(p.destRewrites) != 0 {
+			if reqHost, isHTTP := httpHost(initialBuf); isHTTP {
+				dstAddr, rewritten = p.rewriteDestinationOr(dstAddr, []string{reqHost}, destInfo.Port)
+			}
+		}
 		if rule.Mode != models.MODE_TEST {
-			dstConn, err = net.Dial("tcp", dstAddr)
+			dstConn, err = net.Dial(dstNetwork, dstAddr)
 			if err != nil {
 				utils.LogError(logger, err, "failed to dial the conn to destination server", zap.Any("proxy port", p.Port), zap.Any("server address", dstAddr))
 				return err
@@ -455,10 +631,22 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 
 	generic := true
 
+	// the destination the connection is counted under while recording
+	var egressDst string
+	if rule.Mode == models.MODE_RECORD {
+		reqHost, _ := httpHost(initialBuf)
+		egressDst = egressHost(dstURL, reqHost, dstAddr)
+	}
+
 	//Checking for all the parsers.
-	for _, parser := range p.Integrations {
+	for name, parser := range p.Integrations {
 		if parser.MatchType(parserCtx, initialBuf) {
 			if rule.Mode == models.MODE_RECORD {
+				outcome := models.EgressRecorded
+				if name == "http" && isBypassed(rule.OutgoingOptions.Rules, initialBuf, destInfo.Port) {
+					outcome = models.EgressPassThrough
+				}
+				p.egress.add(destInfo.AppID, egressDst, destInfo.Port, name, outcome)
 				err := parser.RecordOutgoing(parserCtx, srcConn, dstConn, rule.MC, rule.OutgoingOptions)
 				if err != nil {
 					utils.LogError(logger, err, "failed to record the outgoing message")
@@ -477,7 +665,13 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 
 	if generic {
 		logger.Debug("The external dependency is not supported. Hence using generic parser")
+		if rule.Mode == models.MODE_RECORD && !p.genericFallback {
+			p.egress.add(destInfo.AppID, egressDst, destInfo.Port, "unknown", models.EgressPassThrough)
+			p.pipe(parserCtx, srcConn, dstConn)
+			return nil
+		}
 		if rule.Mode == models.MODE_RECORD {
+			p.egress.add(destInfo.AppID, egressDst, destInfo.Port, "generic", models.EgressUnparsed)
 			err := p.Integrations["generic"].RecordOutgoing(parserCtx, srcConn, dstConn, rule.MC, rule.OutgoingOptions)
 			if err != nil {
 				utils.LogError(logger, err, "failed to record the outgoing message")
@@ -544,6 +738,30 @@ func (p *Proxy) Record(_ context.Context, id uint64, mocks chan<- *models.Mock,
 	return nil
 }
 
+// SetBypassRules changes the outgoing calls of a session which are passed through, for the connections made after it
+func (p *Proxy) SetBypassRules(_ context.Context, id uint64, rules []config.BypassRule) error {
+	session, ok := p.sessions.Get(id)
+	if !ok {
+		return fmt.Errorf("session with id:%v not found", id)
+	}
+	updated := *session
+	updated.Rules = rules
+	p.sessions.Set(id, &updated)
+	return nil
+}
+
+// SetAppIP makes the connections from the ip belong to the app, which runs in its own network namespace
+func (p *Proxy) SetAppIP(_ context.Context, id uint64, ip string) error {
+	p.appIPs.Store(ip, id)
+	return nil
+}
+
+// SetAppPid makes the connections over the unix sockets from the process, or its descendants, belong to the app
+func (p *Proxy) SetAppPid(_ context.Context, id uint64, pid uint32) error {
+	p.appPids.Store(id, pid)
+	return nil
+}
+
 func (p *Proxy) Mock(_ context.Context, id uint64, opts models.OutgoingOptions) error {
 	p.sessions.Set(id, &core.Session{
 		ID:              id,
@@ -583,3 +801,12 @@ func (p *Proxy) GetConsumedMocks(_ context.Context, id uint64) ([]string, error)
 	}
 	return m.(*MockManager).GetConsumedMocks(), nil
 }
+
+// GetMockCallOrder returns the filtered mocks consumed by the current test case in the order of the outgoing calls
+func (p *Proxy) GetMockCallOrder(_ context.Context, id uint64) ([]string, error) {
+	m, ok := p.MockManagers.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("mock manager not found to get the order of the consumed mocks")
+	}
+	return m.(*MockManager).GetConsumedOrder(), nil
+}
@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.uber.org/zap"
+)
+
+// resolveTimeout bounds the lookup of the host of a destination rewrite
+const resolveTimeout = 2 * time.Second
+
+// rewriteDestination returns the address to dial instead of the destination while recording, when a rewrite's
+// From names one of the hosts or the port of the destination. The hosts are the ip, the server name or the http
+// host of the destination, and a From naming a host matches its ips too.
+func (p *Proxy) rewriteDestination(hosts []string, port uint32) (string, bool) {
+	for _, rewrite := range p.destRewrites {
+		fromHost, fromPort := splitHostPort(rewrite.From)
+		if fromPort != "" && fromPort != strconv.Itoa(int(port)) {
+			continue
+		}
+		if !p.matchesHost(fromHost, hosts) {
+			continue
+		}
+		toHost, toPort := splitHostPort(rewrite.To)
+		if toPort == "" {
+			toPort = strconv.Itoa(int(port))
+		}
+		addr := net.JoinHostPort(toHost, toPort)
+		p.logger.Debug("sending the call to the alternate of its destination", zap.String("from", rewrite.From), zap.String("to", addr))
+		return addr, true
+	}
+	return "", false
+}
+
+func (p *Proxy) matchesHost(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if h != "" && h == host {
+			return true
+		}
+	}
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	for _, ip := range p.resolve(host) {
+		for _, h := range hosts {
+			if h == ip {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolve returns the ips of the host, looked up once as keploy's own lookups aren't redirected to its dns server
+func (p *Proxy) resolve(host string) []string {
+	if ips, ok := p.resolved.Load(host); ok {
+		return ips.([]string)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		p.logger.Debug("failed to resolve the host of a destination rewrite", zap.String("host", host), zap.Error(err))
+		return nil
+	}
+	p.resolved.Store(host, ips)
+	return ips
+}
+
+// splitHostPort splits host:port, the port being empty when there is none
+func splitHostPort(addr string) (string, string) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, ""
+	}
+	return host, port
+}
+
+// destRewrites returns the rewrites of the config whose From and To are both set
+func destRewrites(rewrites []config.DestinationRewrite) []config.DestinationRewrite {
+	var valid []config.DestinationRewrite
+	for _, r := range rewrites {
+		if r.From != "" && r.To != "" {
+			valid = append(valid, r)
+		}
+	}
+	return valid
+}
+
+// rewriteDestinationOr returns the rewritten address of the destination, or addr when no rewrite matches it
+func (p *Proxy) rewriteDestinationOr(addr string, hosts []string, port uint32) (string, bool) {
+	if alt, ok := p.rewriteDestination(hosts, port); ok {
+		return alt, true
+	}
+	return addr, false
+}
@@ -0,0 +1,352 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// udpResponseWait is how long the responses to a datagram are collected into its mock before it's recorded
+	udpResponseWait = time.Second
+	// udpFlowIdle is how long the flow of a client address is kept without any datagram
+	udpFlowIdle = 30 * time.Second
+)
+
+// udpFlow is the exchange of a client address of the app with a udp dependency. Every datagram of the client starts
+// a new mock, which collects the datagrams the dependency answers with until the next one.
+type udpFlow struct {
+	appID uint64
+	rule  *core.Session
+	// upstream is the socket to the dependency, nil when the datagrams are answered from the mocks
+	upstream net.Conn
+
+	mu   sync.Mutex
+	mock *models.Mock
+	last time.Time
+}
+
+// startUDPListeners listens on the ports of the udp dependencies. The hooks only redirect the dns queries, so the
+// app sends the datagrams of its udp dependencies to the proxy itself. The ports are bound to the loopback, as the
+// proxy would relay the datagrams of anyone to the dependencies otherwise.
+func (p *Proxy) startUDPListeners(ctx context.Context, g *errgroup.Group) error {
+	for _, dep := range p.udpDependencies {
+		if dep.Port == 0 || dep.Target == "" {
+			continue
+		}
+		pc, err := net.ListenPacket("udp", "127.0.0.1:"+strconv.Itoa(int(dep.Port)))
+		if err != nil {
+			utils.LogError(p.logger, err, "failed to listen on the port of the udp dependency", zap.Uint32("port", dep.Port))
+			return err
+		}
+		p.logger.Debug("proxy is listening for the datagrams of the udp dependency", zap.Uint32("port", dep.Port), zap.String("target", dep.Target))
+
+		g.Go(func() error {
+			defer utils.Recover(p.logger)
+			<-ctx.Done()
+			if err := pc.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				utils.LogError(p.logger, err, "failed to close the udp listener")
+			}
+			return nil
+		})
+
+		dep := dep
+		g.Go(func() error {
+			defer utils.Recover(p.logger)
+			p.serveUDP(ctx, pc, dep)
+			return nil
+		})
+	}
+	return nil
+}
+
+func (p *Proxy) serveUDP(ctx context.Context, pc net.PacketConn, dep config.UDPDependency) {
+	flows := map[string]*udpFlow{}
+	defer func() {
+		for addr, flow := range flows {
+			p.closeUDPFlow(ctx, flow)
+			delete(flows, addr)
+		}
+	}()
+
+	buf := make([]byte, 64*1024)
+	for {
+		if err := pc.SetReadDeadline(time.Now().Add(udpResponseWait)); err != nil {
+			utils.LogError(p.logger, err, "failed to set the read deadline of the udp listener")
+			return
+		}
+		n, addr, err := pc.ReadFrom(buf)
+		now := time.Now()
+		var netErr net.Error
+		switch {
+		case errors.As(err, &netErr) && netErr.Timeout():
+		case err != nil:
+			if !errors.Is(err, net.ErrClosed) {
+				utils.LogError(p.logger, err, "failed to read the datagram of the udp dependency", zap.Uint32("port", dep.Port))
+			}
+			return
+		default:
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			flow, ok := flows[addr.String()]
+			if !ok {
+				flow, ok = p.newUDPFlow(ctx, pc, addr, dep)
+				if !ok {
+					continue
+				}
+				flows[addr.String()] = flow
+			}
+			p.handleDatagram(ctx, pc, addr, flow, dep, data)
+		}
+
+		// record the mocks whose responses are done and drop the idle flows
+		for key, flow := range flows {
+			flow.mu.Lock()
+			idle := now.Sub(flow.last) > udpFlowIdle
+			done := flow.mock != nil && now.Sub(flow.mock.Spec.ReqTimestampMock) > udpResponseWait
+			flow.mu.Unlock()
+			switch {
+			case idle:
+				p.closeUDPFlow(ctx, flow)
+				delete(flows, key)
+			case done:
+				p.flushUDPMock(ctx, flow)
+			}
+		}
+	}
+}
+
+// newUDPFlow ties the client address to its app and, unless its datagrams are answered from the mocks, opens its
+// socket to the dependency. The datagrams come over the loopback, which the apps run in parallel in their own
+// network namespaces can't reach, so they belong to the only app session.
+func (p *Proxy) newUDPFlow(ctx context.Context, pc net.PacketConn, addr net.Addr, dep config.UDPDependency) (*udpFlow, bool) {
+	ids := p.sessions.IDs()
+	if len(ids) != 1 {
+		utils.LogError(p.logger, nil, "failed to find the app of the udp datagram", zap.String("client", addr.String()), zap.Int("sessions", len(ids)))
+		return nil, false
+	}
+	appID := ids[0]
+	rule, ok := p.sessions.Get(appID)
+	if !ok {
+		utils.LogError(p.logger, nil, "failed to fetch the session rule of the udp datagram", zap.Uint64("AppID", appID))
+		return nil, false
+	}
+
+	flow := &udpFlow{appID: appID, rule: rule, last: time.Now()}
+	if rule.Mode == models.MODE_TEST && !dep.PassThrough {
+		return flow, true
+	}
+
+	upstream, err := net.Dial("udp", dep.Target)
+	if err != nil {
+		utils.LogError(p.logger, err, "failed to dial the udp dependency", zap.String("target", dep.Target))
+		return nil, false
+	}
+	flow.upstream = upstream
+	go func() {
+		defer utils.Recover(p.logger)
+		p.relayUDP(ctx, pc, addr, flow)
+	}()
+	return flow, true
+}
+
+// relayUDP sends the datagrams of the dependency back to the client, adding them to the mock of its last datagram
+func (p *Proxy) relayUDP(_ context.Context, pc net.PacketConn, addr net.Addr, flow *udpFlow) {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := flow.upstream.Read(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				p.logger.Debug("stopped reading the datagrams of the udp dependency", zap.Error(err))
+			}
+			return
+		}
+		if _, err := pc.WriteTo(buf[:n], addr); err != nil {
+			utils.LogError(p.logger, err, "failed to write the datagram of the udp dependency to the client")
+			continue
+		}
+		flow.mu.Lock()
+		if flow.mock != nil {
+			flow.mock.Spec.GenericResponses = append(flow.mock.Spec.GenericResponses, udpPayload(buf[:n], models.FromServer))
+			flow.mock.Spec.ResTimestampMock = time.Now()
+		}
+		flow.mu.Unlock()
+	}
+}
+
+func (p *Proxy) handleDatagram(ctx context.Context, pc net.PacketConn, addr net.Addr, flow *udpFlow, dep config.UDPDependency, data []byte) {
+	flow.mu.Lock()
+	flow.last = time.Now()
+	flow.mu.Unlock()
+
+	if flow.upstream == nil {
+		p.mockDatagram(pc, addr, flow, dep, data)
+		return
+	}
+
+	if flow.rule.Mode != models.MODE_TEST {
+		p.flushUDPMock(ctx, flow)
+		now := time.Now()
+		flow.mu.Lock()
+		flow.mock = &models.Mock{
+			Version: models.GetVersion(),
+			Name:    "mocks",
+			Kind:    models.UDP,
+			Spec: models.MockSpec{
+				Metadata: map[string]string{
+					"type":   "udp",
+					"target": dep.Target,
+					"port":   strconv.Itoa(int(dep.Port)),
+				},
+				GenericRequests:  []models.GenericPayload{udpPayload(data, models.FromClient)},
+				ReqTimestampMock: now,
+				ResTimestampMock: now,
+			},
+		}
+		flow.mu.Unlock()
+	}
+	if _, err := flow.upstream.Write(data); err != nil {
+		utils.LogError(p.logger, err, "failed to forward the datagram to the udp dependency", zap.String("target", dep.Target))
+	}
+}
+
+// flushUDPMock records the pending mock of the flow
+func (p *Proxy) flushUDPMock(ctx context.Context, flow *udpFlow) {
+	flow.mu.Lock()
+	mock := flow.mock
+	flow.mock = nil
+	flow.mu.Unlock()
+	if mock == nil || flow.rule.MC == nil {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case flow.rule.MC <- mock:
+		p.egress.add(flow.appID, mock.Spec.Metadata["target"], flowPort(mock), "udp", models.EgressRecorded)
+	}
+}
+
+func (p *Proxy) closeUDPFlow(ctx context.Context, flow *udpFlow) {
+	p.flushUDPMock(ctx, flow)
+	if flow.upstream != nil {
+		if err := flow.upstream.Close(); err != nil {
+			utils.LogError(p.logger, err, "failed to close the socket to the udp dependency")
+		}
+	}
+}
+
+// mockDatagram answers the datagram with the responses of the mock recorded for the same datagram to the dependency.
+// The mocks of the test case are consumed first, the mocks of the test set are answered with any number of times.
+func (p *Proxy) mockDatagram(pc net.PacketConn, addr net.Addr, flow *udpFlow, dep config.UDPDependency, data []byte) {
+	m, ok := p.MockManagers.Load(flow.appID)
+	if !ok {
+		utils.LogError(p.logger, nil, "failed to fetch the mock manager", zap.Uint64("AppID", flow.appID))
+		return
+	}
+	mgr := m.(*MockManager)
+
+	var responses []models.GenericPayload
+	matched := false
+	for !matched {
+		mocks, err := mgr.GetUnFilteredMocks()
+		if err != nil {
+			utils.LogError(p.logger, err, "failed to get the mocks of the udp dependency")
+			return
+		}
+		var filtered, unfiltered *models.Mock
+		for _, mock := range mocks {
+			if mock.Kind != models.UDP || mock.Spec.Metadata["target"] != dep.Target || !udpRequestEqual(mock, data) {
+				continue
+			}
+			if mock.TestModeInfo.IsFiltered && filtered == nil {
+				filtered = mock
+			}
+			if !mock.TestModeInfo.IsFiltered && mock.TestModeInfo.SortOrder != math.MaxInt64 && unfiltered == nil {
+				unfiltered = mock
+			}
+		}
+		switch {
+		case filtered != nil:
+			original := *filtered
+			updated := *filtered
+			updated.TestModeInfo.IsFiltered = false
+			updated.TestModeInfo.SortOrder = math.MaxInt64
+			// another datagram consumed the mock first, look for the next one
+			if !mgr.UpdateUnFilteredMock(&original, &updated) {
+				continue
+			}
+			responses, matched = filtered.Spec.GenericResponses, true
+		case unfiltered != nil:
+			if err := mgr.FlagMockAsUsed(unfiltered); err != nil {
+				utils.LogError(p.logger, err, "failed to flag the udp mock as used")
+			}
+			responses, matched = unfiltered.Spec.GenericResponses, true
+		default:
+			p.logger.Debug("no mock matched the datagram of the udp dependency", zap.String("target", dep.Target), zap.String("datagram", util.Excerpt(data)))
+			return
+		}
+	}
+
+	for _, resp := range responses {
+		if len(resp.Message) == 0 {
+			continue
+		}
+		payload, err := udpData(resp.Message[0])
+		if err != nil {
+			utils.LogError(p.logger, err, "failed to decode the recorded datagram of the udp dependency")
+			continue
+		}
+		if _, err := pc.WriteTo(payload, addr); err != nil {
+			utils.LogError(p.logger, err, "failed to write the mocked datagram to the client")
+			return
+		}
+	}
+}
+
+func udpPayload(data []byte, origin models.OriginType) models.GenericPayload {
+	bufStr := string(data)
+	dataType := models.String
+	if !util.IsASCIIPrintable(bufStr) {
+		bufStr = util.EncodeBase64(data)
+		dataType = "binary"
+	}
+	return models.GenericPayload{
+		Origin:  origin,
+		Message: []models.OutputBinary{{Type: dataType, Data: bufStr}},
+	}
+}
+
+func udpRequestEqual(mock *models.Mock, data []byte) bool {
+	if len(mock.Spec.GenericRequests) != 1 || len(mock.Spec.GenericRequests[0].Message) == 0 {
+		return false
+	}
+	recorded, err := udpData(mock.Spec.GenericRequests[0].Message[0])
+	return err == nil && bytes.Equal(recorded, data)
+}
+
+// udpData is the recorded datagram, the datagrams which aren't printable being kept in base64
+func udpData(msg models.OutputBinary) ([]byte, error) {
+	if msg.Type == models.String {
+		return []byte(msg.Data), nil
+	}
+	return util.DecodeBase64(msg.Data)
+}
+
+func flowPort(mock *models.Mock) uint32 {
+	port, _ := strconv.Atoi(mock.Spec.Metadata["port"])
+	return uint32(port)
+}
@@ -0,0 +1,188 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sys/unix"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// unixConn is a client connection accepted on a unix socket. The cgroup hooks only redirect inet sockets,
+// so its destination is known up front instead of being looked up from the eBPF maps.
+type unixConn struct {
+	net.Conn
+	dest *core.NetworkAddress
+}
+
+// startUnixListeners listens on the configured unix sockets and handles their connections like the redirected ones
+func (p *Proxy) startUnixListeners(ctx context.Context, g *errgroup.Group) error {
+	for _, sock := range p.unixSockets {
+		if sock.Path == "" {
+			continue
+		}
+		if filepath.Clean(sock.Path) == filepath.Clean(sock.Target) {
+			err := fmt.Errorf("the unix socket %s can't be its own target", sock.Path)
+			utils.LogError(p.logger, err, "invalid unix socket configuration")
+			return err
+		}
+		if err := removeStaleSocket(sock.Path); err != nil {
+			utils.LogError(p.logger, err, "failed to take over the unix socket", zap.String("path", sock.Path))
+			return err
+		}
+		listener, err := net.Listen("unix", sock.Path)
+		if err != nil {
+			utils.LogError(p.logger, err, "failed to listen on the unix socket", zap.String("path", sock.Path))
+			return err
+		}
+		p.logger.Debug("proxy is listening on the unix socket", zap.String("path", sock.Path), zap.String("target", sock.Target))
+
+		g.Go(func() error {
+			defer utils.Recover(p.logger)
+			<-ctx.Done()
+			if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				utils.LogError(p.logger, err, "failed to close the unix socket listener")
+			}
+			return nil
+		})
+
+		sock := sock
+		g.Go(func() error {
+			defer utils.Recover(p.logger)
+			p.acceptUnix(ctx, listener, sock)
+			return nil
+		})
+	}
+	return nil
+}
+
+func (p *Proxy) acceptUnix(ctx context.Context, listener net.Listener, sock config.UnixSocket) {
+	clientConnErrGrp, clientConnCtx := errgroup.WithContext(ctx)
+	defer func() {
+		err := clientConnErrGrp.Wait()
+		if err != nil {
+			p.logger.Debug("failed to handle the unix client connection", zap.Error(err))
+		}
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				utils.LogError(p.logger, err, "failed to accept connection on the unix socket", zap.String("path", sock.Path))
+			}
+			return
+		}
+
+		// calls over unix sockets aren't tied to an app by the hooks, the pid of the peer tells the app
+		appID, ok := p.unixConnApp(conn)
+		if !ok {
+			utils.LogError(p.logger, nil, "failed to find the app of the unix socket connection", zap.String("path", sock.Path))
+			if err := conn.Close(); err != nil {
+				utils.LogError(p.logger, err, "failed to close the unix client connection")
+			}
+			continue
+		}
+
+		clientConn := &unixConn{
+			Conn: conn,
+			dest: &core.NetworkAddress{
+				AppID:    appID,
+				Port:     sock.Port,
+				UnixPath: sock.Target,
+			},
+		}
+		clientConnErrGrp.Go(func() error {
+			defer utils.Recover(p.logger)
+			err := p.handleConnection(clientConnCtx, clientConn)
+			if err != nil && err != io.EOF {
+				utils.LogError(p.logger, err, "failed to handle the unix client connection")
+			}
+			return nil
+		})
+	}
+}
+
+// removeStaleSocket removes the socket file left by a previous run, refusing to take over a socket which is
+// still listened on or a file which isn't a socket
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and isn't a unix socket", path)
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("the unix socket %s is already listened on", path)
+	}
+	return os.Remove(path)
+}
+
+// unixConnApp finds the app whose process, or one of its ancestors, is the peer of the connection. A connection
+// whose peer belongs to no known app is attributed to the only session, if there's only one.
+func (p *Proxy) unixConnApp(conn net.Conn) (uint64, bool) {
+	if pid, err := peerPid(conn); err == nil {
+		for ; pid > 1; pid = parentPid(pid) {
+			var appID uint64
+			found := false
+			p.appPids.Range(func(key, value any) bool {
+				if value.(uint32) == pid {
+					appID, found = key.(uint64), true
+					return false
+				}
+				return true
+			})
+			if found {
+				return appID, true
+			}
+		}
+	} else {
+		p.logger.Debug("failed to get the peer of the unix socket connection", zap.Error(err))
+	}
+
+	ids := p.sessions.IDs()
+	if len(ids) != 1 {
+		return 0, false
+	}
+	return ids[0], true
+}
+
+// peerPid returns the pid of the process which connected to the unix socket
+func peerPid(conn net.Conn) (uint32, error) {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return 0, errors.New("not a unix socket connection")
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if credErr != nil {
+		return 0, credErr
+	}
+	return uint32(cred.Pid), nil
+}
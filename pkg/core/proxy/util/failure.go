@@ -0,0 +1,68 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// ConnFailureOf returns the failure of the connection which caused the read error, empty for the other errors
+func ConnFailureOf(err error) models.ConnFailure {
+	if err == nil || errors.Is(err, io.EOF) {
+		return ""
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return models.ConnReset
+	}
+	var netErr net.Error
+	if errors.Is(err, syscall.ETIMEDOUT) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return models.ConnTimeout
+	}
+	return ""
+}
+
+// InjectFailure replays the failure of the destination on the connection of the app. A reset closes the
+// connection with a RST, a timeout leaves the request unanswered until the app gives up and closes it.
+func InjectFailure(ctx context.Context, conn net.Conn, failure models.ConnFailure) error {
+	switch failure {
+	case models.ConnReset:
+		if tcpConn, ok := rawConn(conn).(*net.TCPConn); ok {
+			// a zero linger discards the unsent data and sends a RST on close
+			if err := tcpConn.SetLinger(0); err != nil {
+				return err
+			}
+		}
+		return conn.Close()
+	case models.ConnTimeout:
+		if err := conn.SetReadDeadline(time.Time{}); err != nil {
+			return err
+		}
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+		select {
+		case <-ctx.Done():
+		case <-closed:
+		}
+		return nil
+	}
+	return errors.New("unknown connection failure: " + string(failure))
+}
+
+// rawConn unwraps the connection of the app from the tls and the buffered connections of the proxy
+func rawConn(conn net.Conn) net.Conn {
+	for {
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return conn
+		}
+		conn = wrapper.NetConn()
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
 )
 
@@ -28,3 +29,18 @@ func (c *Core) GetOutgoing(ctx context.Context, id uint64, opts models.OutgoingO
 
 	return m, nil
 }
+
+// SetBypassRules replaces the bypass rules of a running session, in the kernel and in the proxy
+func (c *Core) SetBypassRules(ctx context.Context, id uint64, rules []config.BypassRule) error {
+	// the ports of the previous rules are cleared when the new ones have none
+	err := c.Hooks.PassThroughPortsInKernel(ctx, id, GetPortToSendToKernel(ctx, rules))
+	if err != nil {
+		return err
+	}
+	return c.Proxy.SetBypassRules(ctx, id, rules)
+}
+
+// GetEgressCalls returns the outgoing connections made by the app while recording, by destination, protocol and outcome
+func (c *Core) GetEgressCalls(ctx context.Context, id uint64) ([]models.EgressCalls, error) {
+	return c.Proxy.GetEgressCalls(ctx, id)
+}
@@ -0,0 +1,245 @@
+package reverseproxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// startEgress starts the explicit http proxy which hands the outgoing connections of the application over to the keploy proxy
+func (rp *ReverseProxy) startEgress(ctx context.Context) error {
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		return errors.New("failed to get the error group from the context")
+	}
+
+	// only the application reaches the proxy, which would relay the connections of anyone to any host otherwise
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", rp.cfg.EgressPort))
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to start the egress proxy", zap.Uint32("port", rp.cfg.EgressPort))
+		return err
+	}
+	listeners := []net.Listener{listener}
+	// the hosts without ipv6 have no ::1, the proxy env vars point the application at 127.0.0.1 anyway
+	if listener6, err := net.Listen("tcp", fmt.Sprintf("[::1]:%d", rp.cfg.EgressPort)); err == nil {
+		listeners = append(listeners, listener6)
+	} else {
+		rp.logger.Debug("the egress proxy isn't listening on ::1", zap.Error(err))
+	}
+	rp.logger.Info("egress proxy started", zap.Uint32("port", rp.cfg.EgressPort))
+
+	g.Go(func() error {
+		defer utils.Recover(rp.logger)
+		<-ctx.Done()
+		for _, listener := range listeners {
+			if err := listener.Close(); err != nil {
+				utils.LogError(rp.logger, err, "failed to close the egress proxy listener")
+			}
+		}
+		return nil
+	})
+
+	for _, listener := range listeners {
+		rp.acceptEgress(ctx, g, listener)
+	}
+	return nil
+}
+
+// acceptEgress hands the connections accepted by the listener to handleEgress till the context is done
+func (rp *ReverseProxy) acceptEgress(ctx context.Context, g *errgroup.Group, listener net.Listener) {
+	g.Go(func() error {
+		defer utils.Recover(rp.logger)
+		clientConnGrp, clientConnCtx := errgroup.WithContext(ctx)
+		defer func() {
+			err := clientConnGrp.Wait()
+			if err != nil {
+				utils.LogError(rp.logger, err, "failed to handle the egress connections")
+			}
+		}()
+		for {
+			clientConn, err := listener.Accept()
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return nil
+				default:
+				}
+				utils.LogError(rp.logger, err, "failed to accept the egress connection")
+				return nil
+			}
+			clientConnGrp.Go(func() error {
+				defer utils.Recover(rp.logger)
+				rp.handleEgress(clientConnCtx, clientConn)
+				return nil
+			})
+		}
+	})
+}
+
+// handleEgress reads the proxy request of the application and forwards the connection to the keploy proxy,
+// registering its original destination so that the proxy can pick the right integration.
+func (rp *ReverseProxy) handleEgress(ctx context.Context, clientConn net.Conn) {
+	defer func() {
+		if err := clientConn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			utils.LogError(rp.logger, err, "failed to close the egress client connection")
+		}
+	}()
+
+	reader := bufio.NewReader(clientConn)
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		if err != io.EOF {
+			utils.LogError(rp.logger, err, "failed to read the proxy request of the application")
+		}
+		return
+	}
+
+	isConnect := req.Method == http.MethodConnect
+	dstHost := req.Host
+	if !isConnect && req.URL.Host != "" {
+		dstHost = req.URL.Host
+	}
+	host, portStr, err := net.SplitHostPort(dstHost)
+	if err != nil {
+		// no port in the address of a plain http request
+		host, portStr = dstHost, "80"
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		utils.LogError(rp.logger, err, "invalid destination port in the proxy request", zap.String("host", dstHost))
+		return
+	}
+
+	var upstream net.Conn
+	if rp.isPassThrough(uint(port)) {
+		upstream, err = net.Dial("tcp", net.JoinHostPort(host, portStr))
+	} else {
+		upstream, err = rp.dialProxy(ctx, host, uint32(port))
+	}
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to connect to the destination of the outgoing call", zap.String("host", dstHost))
+		if isConnect {
+			_, _ = clientConn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+		}
+		return
+	}
+	defer func() {
+		if err := upstream.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			utils.LogError(rp.logger, err, "failed to close the egress upstream connection")
+		}
+	}()
+
+	if isConnect {
+		_, err = clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+		if err != nil {
+			utils.LogError(rp.logger, err, "failed to respond to the CONNECT request")
+			return
+		}
+	} else {
+		// forward the request in origin-form and close the connection after it, so that every request
+		// of the application gets its own destination.
+		req.Header.Del("Proxy-Connection")
+		req.Header.Del("Proxy-Authorization")
+		req.Close = true
+		err = req.Write(upstream)
+		if err != nil {
+			utils.LogError(rp.logger, err, "failed to forward the request to the destination")
+			return
+		}
+	}
+
+	pipe(rp.logger, &bufferedConn{Conn: clientConn, reader: reader}, upstream)
+}
+
+// bufferedConn reads from the buffered reader first since it may already hold the data sent by the client
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// pipe copies the data between the two connections in both directions until both sides are done
+func pipe(logger *zap.Logger, src, dst net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer utils.Recover(logger)
+		defer wg.Done()
+		_, _ = io.Copy(dst, src)
+		closeWrite(dst)
+	}()
+	go func() {
+		defer utils.Recover(logger)
+		defer wg.Done()
+		_, _ = io.Copy(src, dst)
+		closeWrite(src)
+	}()
+	wg.Wait()
+}
+
+func closeWrite(conn net.Conn) {
+	if c, ok := conn.(*bufferedConn); ok {
+		conn = c.Conn
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		_ = tcpConn.CloseWrite()
+	}
+}
+
+// dialProxy connects to the keploy proxy and registers the destination of the connection against its source port
+func (rp *ReverseProxy) dialProxy(ctx context.Context, host string, port uint32) (net.Conn, error) {
+	dest := &core.NetworkAddress{
+		Port: port,
+	}
+	rp.m.Lock()
+	dest.AppID = rp.appID
+	proxyPort := rp.proxyPort
+	rp.m.Unlock()
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			// the destination can't be resolved while mocking the outgoing calls, the proxy doesn't need it then
+			rp.logger.Debug("failed to resolve the destination of the outgoing call", zap.String("host", host), zap.Error(err))
+			ip = net.IPv4zero
+		} else {
+			ip = addrs[0].IP
+		}
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		dest.Version = 4
+		dest.IPv4Addr = binary.BigEndian.Uint32(ip4)
+	} else {
+		dest.Version = 6
+		ip16 := ip.To16()
+		for i := 0; i < 4; i++ {
+			dest.IPv6Addr[i] = binary.BigEndian.Uint32(ip16[i*4 : i*4+4])
+		}
+	}
+
+	conn, err := net.Dial("tcp", net.JoinHostPort(rp.proxyIP, strconv.Itoa(int(proxyPort))))
+	if err != nil {
+		return nil, err
+	}
+	srcPort := uint16(conn.LocalAddr().(*net.TCPAddr).Port)
+	rp.destinations.Store(srcPort, dest)
+	return conn, nil
+}
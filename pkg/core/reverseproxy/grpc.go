@@ -0,0 +1,124 @@
+package reverseproxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"golang.org/x/net/http2"
+)
+
+// grpcClient sends the grpc calls to the application over http2 without tls (h2c), as they are replayed
+func grpcClient() *http.Client {
+	return &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
+
+// forwardGrpc sends the incoming grpc call to the application and writes back its response with its trailers,
+// capturing the unary call as a grpc testcase
+func (rp *ReverseProxy) forwardGrpc(ctx context.Context, client *http.Client, appAddr string, t chan *models.TestCase, w http.ResponseWriter, r *http.Request) {
+	reqTimestamp := time.Now()
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to read the grpc request body")
+		http.Error(w, "failed to read the request body", http.StatusBadRequest)
+		return
+	}
+
+	appReq, err := http.NewRequestWithContext(r.Context(), r.Method, "http://"+appAddr+r.URL.RequestURI(), bytes.NewReader(reqBody))
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to create the grpc request for the application")
+		http.Error(w, "failed to create the request for the application", http.StatusInternalServerError)
+		return
+	}
+	appReq.Header = r.Header.Clone()
+
+	resp, err := client.Do(appReq)
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to send the grpc request to the application")
+		http.Error(w, "failed to reach the application", http.StatusBadGateway)
+		return
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogError(rp.logger, err, "failed to close the grpc response body")
+		}
+	}()
+	// the trailers are only set once the body is read till the end
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to read the grpc response body")
+		http.Error(w, "failed to read the response of the application", http.StatusBadGateway)
+		return
+	}
+	resTimestamp := time.Now()
+
+	for key, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	if _, err := w.Write(respBody); err != nil {
+		utils.LogError(rp.logger, err, "failed to write the grpc response of the application")
+	}
+	for key, values := range resp.Trailer {
+		for _, v := range values {
+			w.Header().Add(http.TrailerPrefix+key, v)
+		}
+	}
+
+	tc := &models.TestCase{
+		Version: models.GetVersion(),
+		Kind:    models.GRPC_EXPORT,
+		Created: time.Now().Unix(),
+		GrpcReq: models.GrpcReq{
+			Headers: models.GrpcHeaders{
+				PseudoHeaders: map[string]string{
+					":method": r.Method,
+					":path":   r.URL.Path,
+					// the testcases are replayed against the application directly
+					":authority": appAddr,
+					":scheme":    "http",
+				},
+				OrdinaryHeaders: pkg.ToGrpcHeaders(r.Header),
+			},
+			Body: pkg.GrpcLengthPrefixedMessage(reqBody),
+		},
+		GrpcResp: models.GrpcResp{
+			Headers: models.GrpcHeaders{
+				PseudoHeaders:   map[string]string{":status": strconv.Itoa(resp.StatusCode)},
+				OrdinaryHeaders: pkg.ToGrpcHeaders(resp.Header),
+			},
+			Body: pkg.GrpcLengthPrefixedMessage(respBody),
+			Trailers: models.GrpcHeaders{
+				PseudoHeaders:   map[string]string{},
+				OrdinaryHeaders: pkg.ToGrpcHeaders(resp.Trailer),
+			},
+		},
+		// the times of the call bound the window of its mocks
+		HTTPReq:  models.HTTPReq{Timestamp: reqTimestamp},
+		HTTPResp: models.HTTPResp{Timestamp: resTimestamp},
+		Noise:    map[string][]string{},
+	}
+
+	select {
+	case <-ctx.Done():
+	case t <- tc:
+	}
+}
@@ -0,0 +1,213 @@
+package reverseproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/sync/errgroup"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// ingressRoute maps a port on which keploy listens to the application port it forwards to
+type ingressRoute struct {
+	ingressPort uint32
+	appPort     uint32
+	protocol    string
+}
+
+// routes returns the ingress routes from the reverse proxy config and the declared application ports
+func (rp *ReverseProxy) routes() []ingressRoute {
+	var routes []ingressRoute
+	if rp.cfg.IngressPort != 0 && rp.cfg.AppPort != 0 {
+		routes = append(routes, ingressRoute{
+			ingressPort: rp.cfg.IngressPort,
+			appPort:     rp.cfg.AppPort,
+			protocol:    config.GetAppPortProtocol(&config.Config{ReverseProxy: rp.cfg}, rp.cfg.AppPort),
+		})
+	}
+	for _, appPort := range rp.cfg.AppPorts {
+		if appPort.IngressPort == 0 || appPort.Port == rp.cfg.AppPort {
+			continue
+		}
+		protocol := strings.ToLower(appPort.Protocol)
+		if protocol == "" {
+			protocol = config.ProtocolHTTP
+		}
+		routes = append(routes, ingressRoute{
+			ingressPort: appPort.IngressPort,
+			appPort:     appPort.Port,
+			protocol:    protocol,
+		})
+	}
+	return routes
+}
+
+// Record starts listening on the ingress ports and reverse-proxies the incoming requests to the application,
+// capturing every http request/response pair and every grpc call as a testcase.
+func (rp *ReverseProxy) Record(ctx context.Context, _ uint64) (<-chan *models.TestCase, error) {
+	routes := rp.routes()
+	if len(routes) == 0 {
+		return nil, errors.New("ingress port and app port are required for the reverse proxy mode")
+	}
+	for _, route := range routes {
+		if route.protocol != config.ProtocolHTTP && route.protocol != config.ProtocolGRPC {
+			return nil, fmt.Errorf("the protocol %s of the app port %d isn't supported, only %s and %s are", route.protocol, route.appPort, config.ProtocolHTTP, config.ProtocolGRPC)
+		}
+	}
+
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		return nil, errors.New("failed to get the error group from the context")
+	}
+
+	t := make(chan *models.TestCase, 500)
+	client := &http.Client{
+		// the proxy env vars point to the egress proxy, which is only meant for the application
+		Transport: &http.Transport{Proxy: nil},
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	grpc := grpcClient()
+
+	var servers []*http.Server
+	for _, route := range routes {
+		appAddr := fmt.Sprintf("localhost:%d", route.appPort)
+		var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rp.forward(ctx, client, appAddr, t, w, r)
+		})
+		if route.protocol == config.ProtocolGRPC {
+			// the grpc clients talk http2 without tls (h2c) to the ingress port
+			handler = h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rp.forwardGrpc(ctx, grpc, appAddr, t, w, r)
+			}), &http2.Server{})
+		}
+
+		srv := &http.Server{
+			Addr:    fmt.Sprintf(":%d", route.ingressPort),
+			Handler: handler,
+		}
+		servers = append(servers, srv)
+
+		ingressPort, protocol := route.ingressPort, route.protocol
+		g.Go(func() error {
+			defer utils.Recover(rp.logger)
+			rp.logger.Info("ingress reverse proxy started", zap.Uint32("port", ingressPort), zap.String("app", appAddr), zap.String("protocol", protocol))
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				utils.LogError(rp.logger, err, "failed to start the ingress reverse proxy", zap.Uint32("port", ingressPort))
+				return err
+			}
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		defer utils.Recover(rp.logger)
+		<-ctx.Done()
+		// shutdown waits for the in-flight requests, so the channel can be closed safely afterwards
+		for _, srv := range servers {
+			if err := srv.Shutdown(context.Background()); err != nil {
+				utils.LogError(rp.logger, err, "failed to stop the ingress reverse proxy")
+			}
+		}
+		close(t)
+		return nil
+	})
+
+	return t, nil
+}
+
+// forward sends the incoming request to the application and writes back its response
+func (rp *ReverseProxy) forward(ctx context.Context, client *http.Client, appAddr string, t chan *models.TestCase, w http.ResponseWriter, r *http.Request) {
+	reqTimestamp := time.Now()
+	reqBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to read the http request body")
+		http.Error(w, "failed to read the request body", http.StatusBadRequest)
+		return
+	}
+
+	appURL := fmt.Sprintf("http://%s%s", appAddr, r.URL.RequestURI())
+	appReq, err := http.NewRequestWithContext(r.Context(), r.Method, appURL, bytes.NewReader(reqBody))
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to create the request for the application")
+		http.Error(w, "failed to create the request for the application", http.StatusInternalServerError)
+		return
+	}
+	appReq.Header = r.Header.Clone()
+	appReq.Host = r.Host
+
+	resp, err := client.Do(appReq)
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to send the request to the application")
+		http.Error(w, "failed to reach the application", http.StatusBadGateway)
+		return
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogError(rp.logger, err, "failed to close the http response body")
+		}
+	}()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		utils.LogError(rp.logger, err, "failed to read the http response body")
+		http.Error(w, "failed to read the response of the application", http.StatusBadGateway)
+		return
+	}
+	resTimestamp := time.Now()
+
+	for key, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	if _, err := w.Write(respBody); err != nil {
+		utils.LogError(rp.logger, err, "failed to write the response of the application")
+	}
+
+	tc := &models.TestCase{
+		Version: models.GetVersion(),
+		Name:    pkg.ToYamlHTTPHeader(r.Header)["Keploy-Test-Name"],
+		Kind:    models.HTTP,
+		Created: time.Now().Unix(),
+		HTTPReq: models.HTTPReq{
+			Method:     models.Method(r.Method),
+			ProtoMajor: r.ProtoMajor,
+			ProtoMinor: r.ProtoMinor,
+			// the testcases are replayed against the application directly
+			URL:       appURL,
+			Header:    pkg.ToYamlHTTPHeader(r.Header),
+			Body:      string(reqBody),
+			URLParams: pkg.URLParams(r),
+			Timestamp: reqTimestamp,
+		},
+		HTTPResp: models.HTTPResp{
+			StatusCode:    resp.StatusCode,
+			Header:        pkg.ToYamlHTTPHeader(resp.Header),
+			Body:          string(respBody),
+			Timestamp:     resTimestamp,
+			StatusMessage: http.StatusText(resp.StatusCode),
+		},
+		Noise: map[string][]string{},
+	}
+
+	select {
+	case <-ctx.Done():
+	case t <- tc:
+	}
+}
@@ -0,0 +1,123 @@
+// Package reverseproxy provides a userspace alternative to the eBPF hooks for environments
+// where eBPF can't be loaded (e.g. AWS Fargate). Incoming traffic is captured by listening on a
+// port and reverse-proxying to the application, while outgoing traffic reaches the keploy proxy
+// through an explicit http(s) proxy which the application picks up from the proxy env vars.
+package reverseproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.uber.org/zap"
+)
+
+// destInfoTimeout is the maximum time to wait for the egress listener to register the destination
+// of a connection which has been accepted by the keploy proxy.
+const destInfoTimeout = 2 * time.Second
+
+type ReverseProxy struct {
+	logger    *zap.Logger
+	cfg       config.ReverseProxy
+	proxyIP   string
+	proxyPort uint32
+
+	m     sync.Mutex
+	appID uint64
+	// destinations of the egress connections forwarded to the keploy proxy, keyed by the source port
+	destinations     sync.Map
+	passThroughPorts map[uint]bool
+}
+
+func New(logger *zap.Logger, cfg config.Config) *ReverseProxy {
+	return &ReverseProxy{
+		logger:           logger,
+		cfg:              cfg.ReverseProxy,
+		proxyIP:          "127.0.0.1",
+		proxyPort:        cfg.ProxyPort,
+		m:                sync.Mutex{},
+		passThroughPorts: map[uint]bool{},
+	}
+}
+
+// Load starts the explicit proxy for the outgoing calls and points the proxy env vars of the application to it.
+func (rp *ReverseProxy) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
+	if rp.cfg.EgressPort == 0 {
+		return errors.New("egress port is required for the reverse proxy mode")
+	}
+
+	rp.m.Lock()
+	rp.appID = id
+	if opts.ProxyPort != 0 {
+		rp.proxyPort = opts.ProxyPort
+	}
+	rp.m.Unlock()
+
+	err := rp.startEgress(ctx)
+	if err != nil {
+		return err
+	}
+
+	proxyURL := fmt.Sprintf("http://127.0.0.1:%d", rp.cfg.EgressPort)
+	if opts.IsDocker {
+		rp.logger.Warn("the proxy env vars can't be set for a docker container, please pass them to the application container, which must use the host network to reach the egress proxy on the loopback", zap.String("HTTP_PROXY", proxyURL), zap.String("HTTPS_PROXY", proxyURL))
+		return nil
+	}
+	// the application started by keploy inherits the environment of the keploy process
+	for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		if err := os.Setenv(key, proxyURL); err != nil {
+			return fmt.Errorf("failed to set %s for the application: %w", key, err)
+		}
+	}
+	rp.logger.Debug("outgoing calls of the application are routed through the explicit proxy", zap.String("proxy", proxyURL))
+	return nil
+}
+
+// SendInode is a no-op since the network namespace of the application isn't tracked in the reverse proxy mode
+func (rp *ReverseProxy) SendInode(_ context.Context, _ uint64, _ uint64) error {
+	return nil
+}
+
+// PassThroughPortsInKernel stores the ports whose outgoing calls are tunneled directly to the destination
+func (rp *ReverseProxy) PassThroughPortsInKernel(_ context.Context, _ uint64, ports []uint) error {
+	rp.m.Lock()
+	defer rp.m.Unlock()
+	for _, port := range ports {
+		rp.passThroughPorts[port] = true
+	}
+	return nil
+}
+
+func (rp *ReverseProxy) isPassThrough(port uint) bool {
+	rp.m.Lock()
+	defer rp.m.Unlock()
+	return rp.passThroughPorts[port]
+}
+
+// Get returns the destination of the connection accepted by the keploy proxy.
+// The egress listener registers the destination right after dialing the proxy, so wait for it briefly.
+func (rp *ReverseProxy) Get(ctx context.Context, srcPort uint16) (*core.NetworkAddress, error) {
+	timeout := time.After(destInfoTimeout)
+	for {
+		if d, ok := rp.destinations.Load(srcPort); ok {
+			return d.(*core.NetworkAddress), nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return nil, fmt.Errorf("destination not found for the source port:%v", srcPort)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func (rp *ReverseProxy) Delete(_ context.Context, srcPort uint16) error {
+	rp.destinations.Delete(srcPort)
+	return nil
+}
@@ -2,8 +2,10 @@ package core
 
 import (
 	"context"
+	"sort"
 	"sync"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/core/app"
 	"go.keploy.io/server/v2/utils"
 
@@ -23,6 +25,9 @@ type HookCfg struct {
 	Pid        uint32
 	IsDocker   bool
 	KeployIPV4 string
+	// ProxyPort and DNSPort are the ports the proxy is started on, which may differ from the configured ones
+	ProxyPort uint32
+	DNSPort   uint32
 }
 
 type App interface {
@@ -39,6 +44,11 @@ type Proxy interface {
 	Mock(ctx context.Context, id uint64, opts models.OutgoingOptions) error
 	SetMocks(ctx context.Context, id uint64, filtered []*models.Mock, unFiltered []*models.Mock) error
 	GetConsumedMocks(ctx context.Context, id uint64) ([]string, error)
+	GetMockCallOrder(ctx context.Context, id uint64) ([]string, error)
+	SetBypassRules(ctx context.Context, id uint64, rules []config.BypassRule) error
+	GetEgressCalls(ctx context.Context, id uint64) ([]models.EgressCalls, error)
+	SetAppIP(ctx context.Context, id uint64, ip string) error
+	SetAppPid(ctx context.Context, id uint64, pid uint32) error
 }
 
 type ProxyOptions struct {
@@ -46,6 +56,10 @@ type ProxyOptions struct {
 	DNSIPv4Addr string
 	// DNSIPv6Addr is the proxy IP returned by the DNS server. default is loopback address
 	DNSIPv6Addr string
+	// Port is the port on which the proxy listens. default is the configured proxy port
+	Port uint32
+	// DNSPort is the port on which the DNS servers listen. default is the configured dns port
+	DNSPort uint32
 }
 
 type DestInfo interface {
@@ -67,6 +81,10 @@ type NetworkAddress struct {
 	IPv4Addr uint32
 	IPv6Addr [4]uint32
 	Port     uint32
+	// UnixPath is set when the destination is a unix socket instead of an ip address
+	UnixPath string
+	// Pid is the pid of the process which made the call as seen by the kernel, 0 when it isn't known
+	Pid uint32
 }
 
 type Sessions struct {
@@ -100,6 +118,16 @@ func (s *Sessions) getAll() map[uint64]*Session {
 	return sessions
 }
 
+// IDs returns the ids of all the sessions in ascending order
+func (s *Sessions) IDs() []uint64 {
+	var ids []uint64
+	for id := range s.getAll() {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
 func (s *Sessions) GetAllMC() []chan<- *models.Mock {
 	sessions := s.getAll()
 	var mc []chan<- *models.Mock
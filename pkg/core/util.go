@@ -2,6 +2,9 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 
 	"go.keploy.io/server/v2/config"
 )
@@ -18,3 +21,44 @@ func GetPortToSendToKernel(_ context.Context, rules []config.BypassRule) []uint
 	}
 	return ports
 }
+
+// getAvailablePort returns the given port if it can be bound, otherwise a free port picked by the kernel.
+// When udp is set, the port must be free for both tcp and udp (e.g. for the dns server).
+func getAvailablePort(port uint32, udp bool) (uint32, error) {
+	if isPortAvailable(port, udp) {
+		return port, nil
+	}
+	// the kernel picks a free tcp port, retry a few times in case it is taken for udp
+	for i := 0; i < 10; i++ {
+		listener, err := net.Listen("tcp", ":0")
+		if err != nil {
+			return 0, err
+		}
+		freePort := uint32(listener.Addr().(*net.TCPAddr).Port)
+		if err := listener.Close(); err != nil {
+			return 0, err
+		}
+		if isPortAvailable(freePort, udp) {
+			return freePort, nil
+		}
+	}
+	return 0, errors.New("failed to find a free port")
+}
+
+func isPortAvailable(port uint32, udp bool) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	if err := listener.Close(); err != nil {
+		return false
+	}
+	if !udp {
+		return true
+	}
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	return conn.Close() == nil
+}
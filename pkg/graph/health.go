@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.keploy.io/server/v2/utils"
+)
+
+// the states of the hooks and the proxy, which are started by the startHooks mutation
+const (
+	hooksNotStarted = "NOT_STARTED"
+	hooksRunning    = "RUNNING"
+	hooksStopped    = "STOPPED"
+)
+
+type readiness struct {
+	Ready    bool     `json:"ready"`
+	Hooks    string   `json:"hooks"`
+	TestSets []string `json:"testSets"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// healthz tells that the server is up, for the liveness probes
+func (g *Graph) healthz(w http.ResponseWriter, _ *http.Request) {
+	g.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz tells if keploy can run the test sets, with the state of the hooks and the test sets it found. It isn't
+// ready when the test sets can't be read or when the hooks which were started have stopped.
+func (g *Graph) readyz(w http.ResponseWriter, r *http.Request, p *project) {
+	res := readiness{Hooks: p.resolver.hooksState()}
+	testSets, err := p.replay.GetAllTestSetIDs(r.Context())
+	if err != nil {
+		res.Error = "failed to read the test sets: " + err.Error()
+	}
+	res.TestSets = append([]string{}, testSets...)
+	res.Ready = err == nil && res.Hooks != hooksStopped
+
+	status := http.StatusOK
+	if !res.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	g.writeJSON(w, status, res)
+}
+
+func (g *Graph) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		utils.LogError(g.logger, err, "failed to write the response")
+	}
+}
@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// maxIngestBodySize limits the size of a single request/response pair pushed to the ingest endpoint
+const maxIngestBodySize = 10 << 20
+
+// ingestPayload is a request/response pair pushed by custom middlewares or gateways
+type ingestPayload struct {
+	TestSetID string          `json:"testSetID"`
+	Name      string          `json:"name"`
+	Request   models.HTTPReq  `json:"req"`
+	Response  models.HTTPResp `json:"resp"`
+}
+
+type ingestResponse struct {
+	TestSetID string `json:"testSetID"`
+}
+
+// ingest converts the pushed request/response pair into a testcase, which allows
+// recording from environments where the ebpf hooks can't be loaded.
+func (g *Graph) ingest(w http.ResponseWriter, r *http.Request, p *project) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload ingestPayload
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxIngestBodySize))
+	if err := decoder.Decode(&payload); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode the request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if payload.Request.Method == "" || payload.Request.URL == "" {
+		http.Error(w, "req.method and req.url are required", http.StatusBadRequest)
+		return
+	}
+	if payload.Response.StatusCode == 0 {
+		http.Error(w, "resp.status_code is required", http.StatusBadRequest)
+		return
+	}
+	// the testset and the name are the directory and the file of the testcase, which must stay in the test directory
+	if payload.TestSetID != "" && !isPathElement(payload.TestSetID) {
+		http.Error(w, "invalid testSetID: it must be a single file name", http.StatusBadRequest)
+		return
+	}
+	if payload.Name != "" && !isPathElement(payload.Name) {
+		http.Error(w, "invalid name: it must be a single file name", http.StatusBadRequest)
+		return
+	}
+	parsedURL, err := url.Parse(payload.Request.URL)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid req.url: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if payload.Request.URLParams == nil {
+		payload.Request.URLParams = pkg.URLParams(&http.Request{URL: parsedURL})
+	}
+	if payload.Request.ProtoMajor == 0 {
+		payload.Request.ProtoMajor, payload.Request.ProtoMinor = 1, 1
+	}
+	if payload.Response.ProtoMajor == 0 {
+		payload.Response.ProtoMajor, payload.Response.ProtoMinor = 1, 1
+	}
+	if payload.Response.StatusMessage == "" {
+		payload.Response.StatusMessage = http.StatusText(payload.Response.StatusCode)
+	}
+	now := time.Now()
+	if payload.Request.Timestamp.IsZero() {
+		payload.Request.Timestamp = now
+	}
+	if payload.Response.Timestamp.IsZero() {
+		payload.Response.Timestamp = now
+	}
+
+	testSetID := payload.TestSetID
+	if testSetID == "" {
+		testSetID, err = g.getIngestTestSetID(r, p)
+		if err != nil {
+			utils.LogError(g.logger, err, "failed to get the testset for the ingested testcase")
+			http.Error(w, "failed to get the testset for the ingested testcase", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	tc := &models.TestCase{
+		Name:     payload.Name,
+		HTTPReq:  payload.Request,
+		HTTPResp: payload.Response,
+	}
+	err = p.replay.IngestTestCase(r.Context(), testSetID, tc)
+	if err != nil {
+		http.Error(w, "failed to store the ingested testcase", http.StatusInternalServerError)
+		return
+	}
+	g.logger.Debug("ingested testcase", zap.String("testSetID", testSetID), zap.String("url", payload.Request.URL), zap.String("project", p.id))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(ingestResponse{TestSetID: testSetID}); err != nil {
+		utils.LogError(g.logger, err, "failed to write the ingest response")
+	}
+}
+
+// isPathElement reports whether the name is a single clean element of a path, so that it can't point outside the
+// directory it's joined to
+func isPathElement(name string) bool {
+	if strings.ContainsAny(name, `/\`+"\x00") || strings.Contains(name, "..") {
+		return false
+	}
+	return name == filepath.Clean(name) && name == filepath.Base(name)
+}
+
+// getIngestTestSetID returns the testset in which the testcases without an explicit testset are stored.
+// A new testset is created once per serve session in each project.
+func (g *Graph) getIngestTestSetID(r *http.Request, p *project) (string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if p.ingestTestSetID != "" {
+		return p.ingestTestSetID, nil
+	}
+	testSetIDs, err := p.replay.GetAllTestSetIDs(r.Context())
+	if err != nil {
+		return "", err
+	}
+	p.ingestTestSetID = pkg.NewID(testSetIDs, models.TestSetPattern)
+	return p.ingestTestSetID, nil
+}
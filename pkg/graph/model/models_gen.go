@@ -2,12 +2,39 @@
 
 package model
 
+type FieldDiff struct {
+	Path     string `json:"path"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Noise    bool   `json:"noise"`
+}
+
+type HeaderInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
 type Mutation struct {
 }
 
 type Query struct {
 }
 
+type ResponseInput struct {
+	StatusCode int            `json:"statusCode"`
+	Headers    []*HeaderInput `json:"headers"`
+	Body       string         `json:"body"`
+}
+
+type TestCaseResult struct {
+	TestCaseID string       `json:"testCaseId"`
+	Kind       string       `json:"kind"`
+	Status     string       `json:"status"`
+	Started    int          `json:"started"`
+	Completed  int          `json:"completed"`
+	Diffs      []*FieldDiff `json:"diffs"`
+}
+
 type TestRunInfo struct {
 	AppID     int    `json:"appId"`
 	TestRunID string `json:"testRunId"`
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"go.keploy.io/server/v2/pkg/service/replay"
+)
+
+// projectParam is the query parameter selecting the project of a request, e.g. /query?project=payments. The
+// requests without it are served by the project of the config.
+const projectParam = "project"
+
+// project is a repo served by the graphql server, with its own test cases, mocks and reports. Each project has its
+// own resolver, so that the hooks and the app started by its mutations are tracked apart from the other projects.
+type project struct {
+	id       string
+	replay   replay.Service
+	resolver *Resolver
+	query    http.Handler
+	// ingestTestSetID is the testset used for the ingested testcases which don't specify one
+	ingestTestSetID string
+}
+
+func newProject(g *Graph, id string, replay replay.Service) *project {
+	resolver := &Resolver{
+		logger: g.logger,
+		replay: replay,
+	}
+	return &project{
+		id:       id,
+		replay:   replay,
+		resolver: resolver,
+		query: handler.NewDefaultServer(NewExecutableSchema(Config{
+			Resolvers: resolver,
+		})),
+	}
+}
+
+// AddProject serves another project besides the one of the config, selected by its id in the project parameter of
+// the requests. It has to be called before Serve.
+func (g *Graph) AddProject(id string, replay replay.Service) error {
+	if id == "" {
+		return errors.New("the id of the project is empty")
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if _, ok := g.projects[id]; ok {
+		return fmt.Errorf("the project %s is already served", id)
+	}
+	g.projects[id] = newProject(g, id, replay)
+	return nil
+}
+
+// projectOf returns the project selected by the request
+func (g *Graph) projectOf(r *http.Request) (*project, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	p, ok := g.projects[r.URL.Query().Get(projectParam)]
+	return p, ok
+}
+
+// projectIDs returns the ids of the served projects, the one of the config coming first as an empty id
+func (g *Graph) projectIDs() []string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	ids := make([]string, 0, len(g.projects))
+	for id := range g.projects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// withProject serves the request with the project it selects, failing when the project isn't served
+func (g *Graph) withProject(serve func(http.ResponseWriter, *http.Request, *project)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := g.projectOf(r)
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown project %q", r.URL.Query().Get(projectParam)), http.StatusNotFound)
+			return
+		}
+		serve(w, r, p)
+	}
+}
+
+// query serves the graphql queries and mutations of the project
+func (g *Graph) query(w http.ResponseWriter, r *http.Request, p *project) {
+	p.query.ServeHTTP(w, r)
+}
@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// downloadReport serves the reports of a test run as a zip archive, which lets CI systems attach
+// them as build artifacts without access to the filesystem of the runner.
+func (g *Graph) downloadReport(w http.ResponseWriter, r *http.Request, p *project) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	testRunID := r.URL.Query().Get("testRunId")
+	if testRunID == "" {
+		http.Error(w, "testRunId is required", http.StatusBadRequest)
+		return
+	}
+
+	// the archive is built in memory so that a failure can still be reported with a proper status
+	var archive bytes.Buffer
+	err := p.replay.ExportReports(r.Context(), testRunID, &archive)
+	if err != nil {
+		if errors.Is(err, replay.ErrTestRunNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		utils.LogError(g.logger, err, "failed to export the reports", zap.String("testRunId", testRunID), zap.String("project", p.id))
+		http.Error(w, "failed to export the reports", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", testRunID+"-report.zip"))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(archive.Bytes()); err != nil {
+		utils.LogError(g.logger, err, "failed to write the reports archive")
+	}
+}
@@ -27,6 +27,16 @@ func (r *Resolver) getHookCtxWithCancel() (context.Context, context.CancelFunc)
 	return r.hookCtx, r.hookCancel
 }
 
+func (r *Resolver) hooksState() string {
+	if r == nil || r.hookCtx == nil || r.hookCancel == nil {
+		return hooksNotStarted
+	}
+	if r.hookCtx.Err() != nil {
+		return hooksStopped
+	}
+	return hooksRunning
+}
+
 func (r *Resolver) getAppCtxWithCancel() (context.Context, context.CancelFunc) {
 	return r.appCtx, r.appCancel
 }
@@ -127,6 +127,43 @@ func (r *queryResolver) TestSetStatus(ctx context.Context, testRunID string, tes
 	}, nil
 }
 
+// TestCaseResults is the resolver for the testCaseResults field.
+func (r *queryResolver) TestCaseResults(ctx context.Context, testRunID string, testSet string) ([]*model.TestCaseResult, error) {
+	if r.Resolver == nil {
+		err := fmt.Errorf(utils.Emoji + "failed to get Resolver")
+		return nil, err
+	}
+
+	r.logger.Debug("getting test case results for", zap.String("testRunID", testRunID), zap.String("testSet", testSet))
+	ctx = context.WithoutCancel(ctx)
+	results, err := r.replay.GetTestCaseResults(ctx, testRunID, testSet)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get test case results")
+		return nil, errors.New("failed to get test case results")
+	}
+	testCaseResults := make([]*model.TestCaseResult, 0, len(results))
+	for _, result := range results {
+		diffs := make([]*model.FieldDiff, 0, len(result.Result.Diffs))
+		for _, diff := range result.Result.Diffs {
+			diffs = append(diffs, &model.FieldDiff{
+				Path:     diff.Path,
+				Expected: diff.Expected,
+				Actual:   diff.Actual,
+				Noise:    diff.Noise,
+			})
+		}
+		testCaseResults = append(testCaseResults, &model.TestCaseResult{
+			TestCaseID: result.TestCaseID,
+			Kind:       string(result.Kind),
+			Status:     string(result.Status),
+			Started:    int(result.Started),
+			Completed:  int(result.Completed),
+			Diffs:      diffs,
+		})
+	}
+	return testCaseResults, nil
+}
+
 // StopApp is the resolver for the stopApp field.
 func (r *mutationResolver) StopApp(_ context.Context, appId int) (bool, error) {
 	if r.Resolver == nil {
@@ -177,6 +214,78 @@ func (r *mutationResolver) StopHooks(context.Context) (bool, error) {
 	return true, nil
 }
 
+// UpdateTestCaseResponse is the resolver for the updateTestCaseResponse field.
+func (r *mutationResolver) UpdateTestCaseResponse(ctx context.Context, testSetID string, testCaseID string, response model.ResponseInput) (bool, error) {
+	if r.Resolver == nil {
+		err := fmt.Errorf(utils.Emoji + "failed to get Resolver")
+		return false, err
+	}
+
+	header := make(map[string]string, len(response.Headers))
+	for _, h := range response.Headers {
+		header[h.Name] = h.Value
+	}
+	ctx = context.WithoutCancel(ctx)
+	err := r.replay.UpdateTestCaseResponse(ctx, testSetID, testCaseID, models.HTTPResp{
+		StatusCode: response.StatusCode,
+		Header:     header,
+		Body:       response.Body,
+	})
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to update the response of the testcase")
+		return false, err
+	}
+	return true, nil
+}
+
+// AddNoise is the resolver for the addNoise field.
+func (r *mutationResolver) AddNoise(ctx context.Context, testSetID string, testCaseID string, fields []string) (bool, error) {
+	if r.Resolver == nil {
+		err := fmt.Errorf(utils.Emoji + "failed to get Resolver")
+		return false, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	err := r.replay.UpdateTestCaseNoise(ctx, testSetID, testCaseID, fields, nil)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to add the noise of the testcase")
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveNoise is the resolver for the removeNoise field.
+func (r *mutationResolver) RemoveNoise(ctx context.Context, testSetID string, testCaseID string, fields []string) (bool, error) {
+	if r.Resolver == nil {
+		err := fmt.Errorf(utils.Emoji + "failed to get Resolver")
+		return false, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	err := r.replay.UpdateTestCaseNoise(ctx, testSetID, testCaseID, nil, fields)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to remove the noise of the testcase")
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteTestCase is the resolver for the deleteTestCase field.
+func (r *mutationResolver) DeleteTestCase(ctx context.Context, testSetID string, testCaseID string) (bool, error) {
+	if r.Resolver == nil {
+		err := fmt.Errorf(utils.Emoji + "failed to get Resolver")
+		return false, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	err := r.replay.DeleteTestCase(ctx, testSetID, testCaseID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to delete the testcase")
+		return false, err
+	}
+	return true, nil
+}
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
@@ -9,7 +9,6 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
-	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
@@ -21,17 +20,20 @@ import (
 type Graph struct {
 	logger *zap.Logger
 	mutex  sync.Mutex
-	replay replay.Service
 	config config.Config
+	// projects are the served projects by their id, the one of the config having an empty id
+	projects map[string]*project
 }
 
 func NewGraph(logger *zap.Logger, replay replay.Service, config config.Config) *Graph {
-	return &Graph{
-		logger: logger,
-		mutex:  sync.Mutex{},
-		config: config,
-		replay: replay,
+	g := &Graph{
+		logger:   logger,
+		mutex:    sync.Mutex{},
+		config:   config,
+		projects: map[string]*project{},
 	}
+	g.projects[""] = newProject(g, "", replay)
+	return g
 }
 
 const defaultPort = 6789
@@ -44,38 +46,9 @@ func (g *Graph) Serve(ctx context.Context) error {
 
 	graphGrp, graphCtx := errgroup.WithContext(ctx)
 
-	resolver := &Resolver{
-		logger: g.logger,
-		replay: g.replay,
-	}
-
-	srv := handler.NewDefaultServer(NewExecutableSchema(Config{
-		Resolvers: resolver,
-	}))
-
 	defer func() {
-		// cancel the context of the hooks to stop proxy and ebpf hooks
-		hookCtx, hookCancel := resolver.getHookCtxWithCancel()
-		if hookCtx != nil && hookCancel != nil {
-			hookCancel()
-			hookErrGrp, ok := hookCtx.Value(models.ErrGroupKey).(*errgroup.Group)
-			if ok {
-				if err := hookErrGrp.Wait(); err != nil {
-					utils.LogError(g.logger, err, "failed to stop the hooks gracefully")
-				}
-			}
-		}
-
-		// cancel the context of the app in case of sudden stop if the app was started
-		appCtx, appCancel := resolver.getAppCtxWithCancel()
-		if appCtx != nil && appCancel != nil {
-			appCancel()
-			appErrGrp, ok := appCtx.Value(models.ErrGroupKey).(*errgroup.Group)
-			if ok {
-				if err := appErrGrp.Wait(); err != nil {
-					utils.LogError(g.logger, err, "failed to stop the application gracefully")
-				}
-			}
+		for _, id := range g.projectIDs() {
+			g.stopProject(g.projects[id])
 		}
 
 		err := graphGrp.Wait()
@@ -85,7 +58,11 @@ func (g *Graph) Serve(ctx context.Context) error {
 	}()
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	http.Handle("/query", g.withProject(g.query))
+	http.Handle("/ingest", g.withProject(g.ingest))
+	http.Handle("/report", g.withProject(g.downloadReport))
+	http.HandleFunc("/healthz", g.healthz)
+	http.Handle("/readyz", g.withProject(g.readyz))
 
 	// Create a new http.Server instance
 	httpSrv := &http.Server{
@@ -99,7 +76,7 @@ func (g *Graph) Serve(ctx context.Context) error {
 	})
 
 	g.logger.Debug(fmt.Sprintf("connect to http://localhost:%d/ for GraphQL playground", int(g.config.Port)))
-	g.logger.Info("Graphql server started", zap.Int("port", int(g.config.Port)))
+	g.logger.Info("Graphql server started", zap.Int("port", int(g.config.Port)), zap.Strings("projects", g.projectIDs()[1:]))
 	if err := httpSrv.ListenAndServe(); err != http.ErrServerClosed {
 		stopErr := utils.Stop(g.logger, "Graphql server failed to start")
 		if stopErr != nil {
@@ -111,6 +88,33 @@ func (g *Graph) Serve(ctx context.Context) error {
 	return nil
 }
 
+// stopProject stops the hooks and the app started by the mutations of the project
+func (g *Graph) stopProject(p *project) {
+	// cancel the context of the hooks to stop proxy and ebpf hooks
+	hookCtx, hookCancel := p.resolver.getHookCtxWithCancel()
+	if hookCtx != nil && hookCancel != nil {
+		hookCancel()
+		hookErrGrp, ok := hookCtx.Value(models.ErrGroupKey).(*errgroup.Group)
+		if ok {
+			if err := hookErrGrp.Wait(); err != nil {
+				utils.LogError(g.logger, err, "failed to stop the hooks gracefully", zap.String("project", p.id))
+			}
+		}
+	}
+
+	// cancel the context of the app in case of sudden stop if the app was started
+	appCtx, appCancel := p.resolver.getAppCtxWithCancel()
+	if appCtx != nil && appCancel != nil {
+		appCancel()
+		appErrGrp, ok := appCtx.Value(models.ErrGroupKey).(*errgroup.Group)
+		if ok {
+			if err := appErrGrp.Wait(); err != nil {
+				utils.LogError(g.logger, err, "failed to stop the application gracefully", zap.String("project", p.id))
+			}
+		}
+	}
+}
+
 // Gracefully shut down the HTTP server
 func (g *Graph) stopGraphqlServer(ctx context.Context, httpSrv *http.Server) error {
 	<-ctx.Done()
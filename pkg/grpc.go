@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocolbuffers/protoscope"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+)
+
+// GrpcLengthPrefixedMessage decodes the length prefixed grpc message of the payload with protoscope
+func GrpcLengthPrefixedMessage(data []byte) models.GrpcLengthPrefixedMessage {
+	msg := models.GrpcLengthPrefixedMessage{}
+
+	// If the body is not length prefixed, we return the default value.
+	if len(data) < 5 {
+		return msg
+	}
+
+	// The first byte is the compression flag.
+	msg.CompressionFlag = uint(data[0])
+
+	// The next 4 bytes are message length.
+	msg.MessageLength = binary.BigEndian.Uint32(data[1:5])
+
+	// Use protoscope to decode the message.
+	msg.DecodedData = protoscope.Write(data[5:], protoscope.WriterOptions{})
+
+	return msg
+}
+
+// GrpcPayload encodes the grpc message back into a length prefixed payload
+func GrpcPayload(msg models.GrpcLengthPrefixedMessage) ([]byte, error) {
+	scanner := protoscope.NewScanner(msg.DecodedData)
+	encodedData, err := scanner.Exec()
+	if err != nil {
+		return nil, fmt.Errorf("could not encode grpc msg using protoscope: %v", err)
+	}
+
+	// Note that the encoded length is present in the msg, but it is also equal to the len of encodedData.
+	// We should give the preference to the length of encodedData, since the mocks might have been altered.
+
+	// Reserve 1 byte for compression flag, 4 bytes for length capture.
+	payload := make([]byte, 1+4)
+	payload[0] = uint8(msg.CompressionFlag)
+	binary.BigEndian.PutUint32(payload[1:5], uint32(len(encodedData)))
+	payload = append(payload, encodedData...)
+
+	return payload, nil
+}
+
+// SimulateGrpc sends the grpc request of the testcase to the app over http2 without tls, to the address
+// of its :authority pseudo header, and returns the response along with its trailers.
+func SimulateGrpc(ctx context.Context, tc models.TestCase, testSet string, logger *zap.Logger, apiTimeout uint64) (*models.GrpcResp, error) {
+	logger.Info("starting test for of", zap.Any("test case", models.HighlightString(tc.Name)), zap.Any("test set", models.HighlightString(testSet)))
+
+	pseudo := tc.GrpcReq.Headers.PseudoHeaders
+	payload, err := GrpcPayload(tc.GrpcReq.Body)
+	if err != nil {
+		utils.LogError(logger, err, "failed to encode the grpc request from the yaml document")
+		return nil, err
+	}
+	url := "http://" + pseudo[":authority"] + pseudo[":path"]
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		utils.LogError(logger, err, "failed to create a grpc request from the yaml document")
+		return nil, err
+	}
+	for k, v := range tc.GrpcReq.Headers.OrdinaryHeaders {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("te", "trailers")
+	req.Header.Set("KEPLOY-TEST-ID", tc.Name)
+
+	logger.Debug(fmt.Sprintf("Sending grpc request to user app:%v", req))
+
+	client := &http.Client{
+		Timeout: time.Second * time.Duration(apiTimeout),
+		Transport: &http2.Transport{
+			// the apps serve grpc in plain text (h2c) in the test environment
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	httpResp, err := client.Do(req)
+	if err != nil {
+		utils.LogError(logger, err, "failed to send the grpc testcase request to app")
+		return nil, err
+	}
+	defer func() {
+		if err := httpResp.Body.Close(); err != nil {
+			utils.LogError(logger, err, "failed to close the grpc response body")
+		}
+	}()
+
+	// the trailers are only set once the body is read till the end
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		utils.LogError(logger, err, "failed reading grpc response body")
+		return nil, err
+	}
+
+	resp := &models.GrpcResp{
+		Headers: models.GrpcHeaders{
+			PseudoHeaders:   map[string]string{":status": strconv.Itoa(httpResp.StatusCode)},
+			OrdinaryHeaders: ToGrpcHeaders(httpResp.Header),
+		},
+		Body: GrpcLengthPrefixedMessage(respBody),
+		Trailers: models.GrpcHeaders{
+			PseudoHeaders:   map[string]string{},
+			OrdinaryHeaders: ToGrpcHeaders(httpResp.Trailer),
+		},
+	}
+	return resp, nil
+}
+
+// ToGrpcHeaders converts the headers to the lower case form in which they are recorded
+func ToGrpcHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for k, v := range header {
+		headers[strings.ToLower(k)] = strings.Join(v, ", ")
+	}
+	return headers
+}
@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// maskPrefix marks the values replaced by the masking policy
+const maskPrefix = "masked:"
+
+// Masker replaces the values of the JSON keys, headers and query params whose names match the
+// configured patterns (e.g. "password", "ssn", "card*") with deterministic hashes, so that the
+// recordings don't hold sensitive data while equal values still map to equal hashes.
+type Masker struct {
+	patterns []string
+}
+
+// NewMasker returns nil if no pattern is configured, the methods of a nil Masker are no-ops.
+func NewMasker(patterns []string) *Masker {
+	if len(patterns) == 0 {
+		return nil
+	}
+	m := &Masker{}
+	for _, p := range patterns {
+		m.patterns = append(m.patterns, strings.ToLower(p))
+	}
+	return m
+}
+
+func (m *Masker) MaskTestCase(tc *models.TestCase) {
+	if m == nil || tc == nil {
+		return
+	}
+	m.MaskHTTPReq(&tc.HTTPReq)
+	m.MaskHTTPResp(&tc.HTTPResp)
+}
+
+// MaskMock masks the http mocks, the payloads of the other protocols aren't keyed by field names
+func (m *Masker) MaskMock(mock *models.Mock) {
+	if m == nil || mock == nil {
+		return
+	}
+	if mock.Spec.HTTPReq != nil {
+		m.MaskHTTPReq(mock.Spec.HTTPReq)
+	}
+	if mock.Spec.HTTPResp != nil {
+		m.MaskHTTPResp(mock.Spec.HTTPResp)
+	}
+}
+
+func (m *Masker) MaskHTTPReq(req *models.HTTPReq) {
+	if m == nil {
+		return
+	}
+	m.maskHeader(req.Header)
+	for key, val := range req.URLParams {
+		if m.match(key) {
+			req.URLParams[key] = maskValue(val)
+		}
+	}
+	req.URL = m.maskURL(req.URL)
+	req.Body = m.maskBody(req.Body)
+}
+
+func (m *Masker) MaskHTTPResp(resp *models.HTTPResp) {
+	if m == nil {
+		return
+	}
+	m.maskHeader(resp.Header)
+	resp.Body = m.maskBody(resp.Body)
+}
+
+func (m *Masker) match(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range m.patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Masker) maskHeader(header map[string]string) {
+	for key, val := range header {
+		if m.match(key) {
+			header[key] = maskValue(val)
+		}
+	}
+}
+
+func (m *Masker) maskURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	query := u.Query()
+	masked := false
+	for key, vals := range query {
+		if !m.match(key) {
+			continue
+		}
+		for i, val := range vals {
+			vals[i] = maskValue(val)
+		}
+		masked = true
+	}
+	if !masked {
+		return rawURL
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// maskBody masks the matching keys at any depth of a json body, other bodies are returned as is
+func (m *Masker) maskBody(body string) string {
+	var data interface{}
+	if body == "" || json.Unmarshal([]byte(body), &data) != nil {
+		return body
+	}
+	if !m.maskJSON(data) {
+		return body
+	}
+	out, err := json.Marshal(data)
+	if err != nil {
+		return body
+	}
+	return string(out)
+}
+
+func (m *Masker) maskJSON(data interface{}) bool {
+	masked := false
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if m.match(key) {
+				str, ok := val.(string)
+				if !ok {
+					raw, _ := json.Marshal(val)
+					str = string(raw)
+				}
+				v[key] = maskValue(str)
+				masked = true
+				continue
+			}
+			masked = m.maskJSON(val) || masked
+		}
+	case []interface{}:
+		for _, val := range v {
+			masked = m.maskJSON(val) || masked
+		}
+	}
+	return masked
+}
+
+func maskValue(val string) string {
+	if strings.HasPrefix(val, maskPrefix) {
+		return val
+	}
+	sum := sha256.Sum256([]byte(val))
+	return maskPrefix + hex.EncodeToString(sum[:8])
+}
@@ -14,6 +14,8 @@ const (
 	TestSetPattern      string = "test-set-"
 	String              string = "string"
 	TestRunTemplateName string = "test-run-"
+	// GlobalMocksDir is the directory next to the test sets holding the mocks shared by them
+	GlobalMocksDir string = "global-mocks"
 )
 
 var (
@@ -191,3 +193,6 @@ type contextKey string
 const ErrGroupKey contextKey = "errGroup"
 const ClientConnectionIDKey contextKey = "clientConnectionId"
 const DestConnectionIDKey contextKey = "destConnectionId"
+
+// AppIDKey is the id of the app session the outgoing connection belongs to
+const AppIDKey contextKey = "appId"
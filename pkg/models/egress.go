@@ -0,0 +1,36 @@
+package models
+
+// outcomes of the outgoing connections of a record session
+const (
+	EgressRecorded    = "recorded"
+	EgressUnparsed    = "unparsed"
+	EgressPassThrough = "passthrough"
+	EgressIgnored     = "ignored"
+)
+
+// EgressCalls counts the outgoing connections to a destination by the protocol they were parsed as and what was
+// done with them: recorded, recorded by the generic parser as the protocol isn't supported (unparsed), passed
+// through to the destination or ignored
+type EgressCalls struct {
+	Host        string `json:"host" yaml:"host"`
+	Port        uint32 `json:"port" yaml:"port"`
+	Protocol    string `json:"protocol" yaml:"protocol"`
+	Outcome     string `json:"outcome" yaml:"outcome"`
+	Connections int    `json:"connections" yaml:"connections"`
+}
+
+// EgressEndpoint counts the mocks recorded for an endpoint of a dependency, like GET http://auth/token
+type EgressEndpoint struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	Mocks    int    `json:"mocks" yaml:"mocks"`
+}
+
+// EgressSummary tells what the outgoing calls of a record session were, so that the user knows right away
+// whether the dependencies were captured as expected
+type EgressSummary struct {
+	TestSet   string           `json:"testSet" yaml:"test_set"`
+	Mocks     map[string]int   `json:"mocks" yaml:"mocks"`
+	Endpoints []EgressEndpoint `json:"endpoints" yaml:"endpoints"`
+	Calls     []EgressCalls    `json:"calls" yaml:"calls"`
+}
@@ -9,6 +9,8 @@ type GrpcSpec struct {
 	GrpcResp         GrpcResp  `json:"grpcResp" yaml:"grpcResp"`
 	ReqTimestampMock time.Time `json:"reqTimestampMock" yaml:"reqTimestampMock,omitempty"`
 	ResTimestampMock time.Time `json:"resTimestampMock" yaml:"resTimestampMock,omitempty"`
+	// Assertions holds the noise of a grpc testcase, like the one of the http testcases
+	Assertions map[string]interface{} `json:"assertions,omitempty" yaml:"assertions,omitempty"`
 }
 
 type GrpcHeaders struct {
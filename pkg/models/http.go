@@ -25,6 +25,7 @@ type HTTPSchema struct {
 	Response         HTTPResp               `json:"resp" yaml:"resp"`
 	Objects          []*OutputBinary        `json:"objects" yaml:"objects"`
 	Assertions       map[string]interface{} `json:"assertions" yaml:"assertions,omitempty"`
+	Verify           []StateCheck           `json:"verify" yaml:"verify,omitempty"`
 	Created          int64                  `json:"created" yaml:"created,omitempty"`
 	ReqTimestampMock time.Time              `json:"reqTimestampMock" yaml:"reqTimestampMock,omitempty"`
 	ResTimestampMock time.Time              `json:"resTimestampMock" yaml:"resTimestampMock,omitempty"`
@@ -45,4 +46,10 @@ type HTTPResp struct {
 	ProtoMinor    int               `json:"proto_minor" yaml:"proto_minor"`
 	Binary        string            `json:"binary" yaml:"binary,omitempty"`
 	Timestamp     time.Time         `json:"timestamp" yaml:"timestamp"`
+	// BodyHash and BodySize are set when the body was truncated on recording, the hash of its full content
+	// being compared instead of the body
+	BodyHash string `json:"body_hash,omitempty" yaml:"body_hash,omitempty"`
+	BodySize int    `json:"body_size,omitempty" yaml:"body_size,omitempty"`
+	// Failure is set for the mocks of the requests whose connection failed instead of getting a response
+	Failure ConnFailure `json:"failure,omitempty" yaml:"failure,omitempty"`
 }
@@ -15,6 +15,19 @@ type OutgoingOptions struct {
 	MongoPassword string
 	// TODO: role of SQLDelay should be mentioned in the comments.
 	SQLDelay time.Duration // This is the same as Application delay.
+	// SQLFingerprint matches the sql queries on their text with the literals and whitespace normalized
+	SQLFingerprint bool
+	// GenericVerbatim serves the generic mocks of the destination in their recorded order to the requests which
+	// match none of them, instead of passing them through
+	GenericVerbatim bool
+	// MatchDebug traces the outgoing calls which match no mock with their closest mocks, nil when they aren't traced
+	MatchDebug *MatchDebugOptions
+}
+
+// MatchDebugOptions are the file and the test set the unmatched calls are traced for
+type MatchDebugOptions struct {
+	Path    string
+	TestSet string
 }
 
 type IncomingOptions struct {
@@ -25,8 +38,12 @@ type SetupOptions struct {
 	Container     string
 	DockerNetwork string
 	DockerDelay   time.Duration
+	// IsolateNetwork runs a native app inside a fresh network namespace for each test set
+	IsolateNetwork bool
 }
 
 type RunOptions struct {
 	//IgnoreErrors bool
+	// Env are the NAME=value variables added to the environment of the app for the run
+	Env []string
 }
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// JobSchema is the yaml doc of a test case recording a run of a scheduled job
+type JobSchema struct {
+	Job     Job          `json:"job" yaml:"job"`
+	Verify  []StateCheck `json:"verify" yaml:"verify,omitempty"`
+	Created int64        `json:"created" yaml:"created,omitempty"`
+}
+
+// Job is a run of a scheduled or async job, which has no incoming call triggering it. The outgoing calls made
+// between its start and its exit are the downstream calls verified when the job is run again during the test.
+type Job struct {
+	Name     string    `json:"name" yaml:"name"`
+	Command  string    `json:"command" yaml:"command"`
+	ExitCode int       `json:"exitCode" yaml:"exitCode"`
+	Started  time.Time `json:"started" yaml:"started"`
+	Finished time.Time `json:"finished" yaml:"finished"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// KafkaSchema is the yaml spec of a kafka mock, a request of the client with the response of the broker
+type KafkaSchema struct {
+	Metadata         map[string]string `json:"metadata" yaml:"metadata"`
+	KafkaRequests    []KafkaPayload    `json:"kafkaRequests" yaml:"kafkaRequests"`
+	KafkaResponses   []KafkaPayload    `json:"kafkaResponses" yaml:"kafkaResponses"`
+	ReqTimestampMock time.Time         `json:"reqTimestampMock,omitempty" yaml:"reqTimestampMock,omitempty"`
+	ResTimestampMock time.Time         `json:"resTimestampMock,omitempty" yaml:"resTimestampMock,omitempty"`
+}
+
+// KafkaPayload is a message of the kafka wire protocol. The header of the requests and the topics they're sent for
+// are kept readable, the whole message after its size being kept base64 encoded in Message.
+type KafkaPayload struct {
+	Origin        OriginType `json:"origin" yaml:"origin"`
+	APIKey        int16      `json:"api_key,omitempty" yaml:"api_key,omitempty"`
+	APIVersion    int16      `json:"api_version,omitempty" yaml:"api_version,omitempty"`
+	CorrelationID int32      `json:"correlation_id" yaml:"correlation_id"`
+	ClientID      string     `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	Topics        []string   `json:"topics,omitempty" yaml:"topics,omitempty"`
+	Message       string     `json:"message" yaml:"message"`
+	// Records are the records a fetch response delivered to the consumer, kept readable
+	Records []KafkaRecord `json:"records,omitempty" yaml:"records,omitempty"`
+}
+
+// KafkaRecord is a record of a topic partition delivered by a fetch response
+type KafkaRecord struct {
+	Topic     string            `json:"topic" yaml:"topic"`
+	Partition int32             `json:"partition" yaml:"partition"`
+	Offset    int64             `json:"offset" yaml:"offset"`
+	Key       string            `json:"key,omitempty" yaml:"key,omitempty"`
+	Value     string            `json:"value" yaml:"value"`
+	Headers   map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// UnmatchedCall is an outgoing call of the application which matched no mock in test mode, with the mocks which were
+// the closest to it. The unmatched calls of a test run are written to the matching.yaml of its reports.
+type UnmatchedCall struct {
+	Time       time.Time       `json:"time" yaml:"time"`
+	TestSet    string          `json:"testSet,omitempty" yaml:"testSet,omitempty"`
+	Kind       Kind            `json:"kind" yaml:"kind"`
+	Request    string          `json:"request" yaml:"request"`
+	Candidates []MockCandidate `json:"candidates" yaml:"candidates"`
+}
+
+// MockCandidate is a mock close to an unmatched call, with the fields which differ from the call. The score is the
+// similarity between the mock and the call, from 0 for no equal field to 1 for an exact match.
+type MockCandidate struct {
+	Mock       string   `json:"mock" yaml:"mock"`
+	Score      float64  `json:"score" yaml:"score"`
+	Mismatches []string `json:"mismatches" yaml:"mismatches"`
+}
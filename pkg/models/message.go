@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// brokers of the consumed messages
+const (
+	BrokerKafka = "kafka"
+	BrokerSQS   = "sqs"
+)
+
+// MessageSchema is the yaml doc of a test case triggered by a consumed message
+type MessageSchema struct {
+	Message Message      `json:"message" yaml:"message"`
+	Verify  []StateCheck `json:"verify" yaml:"verify,omitempty"`
+	Created int64        `json:"created" yaml:"created,omitempty"`
+}
+
+// Message is a message the app consumed from a broker, triggering a test case. The outgoing calls made
+// between its consumption and the end of its processing are the downstream calls verified during the test.
+type Message struct {
+	Broker    string            `json:"broker" yaml:"broker"`
+	Topic     string            `json:"topic" yaml:"topic"` // the topic or the queue the message was consumed from
+	Key       string            `json:"key,omitempty" yaml:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Value     string            `json:"value" yaml:"value"`
+	Consumed  time.Time         `json:"consumed" yaml:"consumed"`
+	Processed time.Time         `json:"processed" yaml:"processed"`
+	// Fetched tells the message was captured from a fetch of the app through the proxy, so the mocked
+	// broker delivers it again instead of the publish command
+	Fetched bool `json:"fetched,omitempty" yaml:"fetched,omitempty"`
+}
@@ -9,6 +9,8 @@ type Mock struct {
 	Spec         MockSpec     `json:"Spec,omitempty" bson:"Spec,omitempty"`
 	TestModeInfo TestModeInfo `json:"TestModeInfo,omitempty"  bson:"TestModeInfo,omitempty"` // Map for additional test mode information
 	ConnectionID string       `json:"ConnectionId,omitempty" bson:"ConnectionId,omitempty"`
+	// Tags like feature:payments-v2 load the mock only when one of them is active during the replay
+	Tags []string `json:"Tags,omitempty" bson:"Tags,omitempty"`
 }
 
 type TestModeInfo struct {
@@ -36,6 +38,10 @@ type MockSpec struct {
 	GRPCResp          *GrpcResp         `json:"grpcResponse,omitempty" bson:"grpc_resp,omitempty"`
 	MySQLRequests     []MySQLRequest    `json:"MySqlRequests,omitempty" bson:"my_sql_requests,omitempty"`
 	MySQLResponses    []MySQLResponse   `json:"MySqlResponses,omitempty" bson:"my_sql_responses,omitempty"`
+	RedisRequests     []RedisPayload    `json:"RedisRequests,omitempty" bson:"redis_requests,omitempty"`
+	RedisResponses    []RedisPayload    `json:"RedisResponses,omitempty" bson:"redis_responses,omitempty"`
+	KafkaRequests     []KafkaPayload    `json:"KafkaRequests,omitempty" bson:"kafka_requests,omitempty"`
+	KafkaResponses    []KafkaPayload    `json:"KafkaResponses,omitempty" bson:"kafka_responses,omitempty"`
 	ReqTimestampMock  time.Time         `json:"ReqTimestampMock,omitempty" bson:"req_timestamp_mock,omitempty"`
 	ResTimestampMock  time.Time         `json:"ResTimestampMock,omitempty" bson:"res_timestamp_mock,omitempty"`
 }
@@ -57,4 +63,18 @@ const (
 type GenericPayload struct {
 	Origin  OriginType     `json:"Origin,omitempty" yaml:"origin" bson:"origin,omitempty"`
 	Message []OutputBinary `json:"Message,omitempty" yaml:"message" bson:"message,omitempty"`
+	// ReadDelay is the wait before the messages pushed by the server without a request, e.g. redis pub/sub
+	ReadDelay int64 `json:"read_delay,omitempty" yaml:"read_delay,omitempty" bson:"read_delay,omitempty"`
+	// Failure ends the connection with the failure of the server instead of sending a message
+	Failure ConnFailure `json:"failure,omitempty" yaml:"failure,omitempty" bson:"failure,omitempty"`
 }
+
+// ConnFailure is a failure of the connection to the destination, recorded to replay the outages of the upstreams
+type ConnFailure string
+
+const (
+	// ConnReset is the connection reset by the destination
+	ConnReset ConnFailure = "reset"
+	// ConnTimeout is the destination which stopped answering until the client gave up
+	ConnTimeout ConnFailure = "timeout"
+)
@@ -104,12 +104,14 @@ type MySQLComStmtExecute struct {
 	NullBitmap     string           `json:"null_bitmap,omitempty" yaml:"null_bitmap,omitempty,flow" bson:"null_bitmap,omitempty"`
 	ParamCount     uint16           `json:"param_count,omitempty" yaml:"param_count,omitempty,flow" bson:"param_count,omitempty"`
 	Parameters     []BoundParameter `json:"parameters,omitempty" yaml:"parameters,omitempty,flow" bson:"parameters,omitempty"`
+	Query          string           `json:"query,omitempty" yaml:"query,omitempty" bson:"query,omitempty"`
 }
 
 type BoundParameter struct {
 	Type     byte   `json:"type,omitempty" yaml:"type,omitempty,flow" bson:"type,omitempty"`
 	Unsigned byte   `json:"unsigned,omitempty" yaml:"unsigned,omitempty,flow" bson:"unsigned,omitempty"`
 	Value    []byte `json:"value,omitempty" yaml:"value,omitempty,flow" bson:"value,omitempty"`
+	Decoded  string `json:"decoded,omitempty" yaml:"decoded,omitempty" bson:"decoded,omitempty"`
 }
 
 type MySQLStmtPrepareOk struct {
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// RedisSchema is the yaml spec of a redis mock, a command of the client with the replies of the server and the
+// messages it pushed afterwards to the subscriptions of the connection
+type RedisSchema struct {
+	Metadata         map[string]string `json:"metadata" yaml:"metadata"`
+	RedisRequests    []RedisPayload    `json:"redisRequests" yaml:"redisRequests"`
+	RedisResponses   []RedisPayload    `json:"redisResponses" yaml:"redisResponses"`
+	ReqTimestampMock time.Time         `json:"reqTimestampMock,omitempty" yaml:"reqTimestampMock,omitempty"`
+	ResTimestampMock time.Time         `json:"resTimestampMock,omitempty" yaml:"resTimestampMock,omitempty"`
+}
+
+// RedisPayload is a RESP frame sent on a redis connection
+type RedisPayload struct {
+	Origin  OriginType `json:"origin" yaml:"origin"`
+	Message RedisValue `json:"message" yaml:"message"`
+	// ReadDelay is the wait before the messages pushed by the server without a command, e.g. to the subscribers
+	ReadDelay int64 `json:"read_delay,omitempty" yaml:"read_delay,omitempty"`
+}
+
+// RedisValue is a RESP2 or RESP3 value. The strings, the errors and the numbers are kept in Value, the arrays, the
+// sets, the pushes, the maps and the attributes in Elements, the keys and the values of the maps alternating.
+type RedisValue struct {
+	Type     string       `json:"type" yaml:"type"`
+	Value    string       `json:"value,omitempty" yaml:"value,omitempty"`
+	Null     bool         `json:"null,omitempty" yaml:"null,omitempty"`
+	Elements []RedisValue `json:"elements,omitempty" yaml:"elements,omitempty"`
+}
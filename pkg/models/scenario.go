@@ -0,0 +1,43 @@
+package models
+
+// Scenario is a flow of test cases sent in the same session of a client, e.g. login, act and logout, which are
+// replayed in order as a unit rather than as isolated requests.
+type Scenario struct {
+	Name    string `json:"name" yaml:"name"`
+	Session string `json:"session" yaml:"session"`
+	// Steps are the test cases of the scenario in the order they were sent
+	Steps     []ScenarioStep     `json:"steps" yaml:"steps"`
+	Variables []ScenarioVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+}
+
+// ScenarioStep is a test case of a scenario. Assert are the fields of its response checked besides the
+// comparison with the recorded response.
+type ScenarioStep struct {
+	TestCase string          `json:"testCase" yaml:"testCase"`
+	Assert   []StepAssertion `json:"assert,omitempty" yaml:"assert,omitempty"`
+}
+
+// ScenarioVariable is a value returned by a step which the later steps send, e.g. a session cookie or a token.
+// Its Path in the response of the step is cookie.<name>, header.<name> or body.<json path>. The recorded Value
+// is replaced by the one returned during the test in the requests of the later steps.
+type ScenarioVariable struct {
+	Name  string `json:"name" yaml:"name"`
+	Step  int    `json:"step" yaml:"step"`
+	Path  string `json:"path" yaml:"path"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// StepAssertion checks that the field of the response at Path, status, header.<name> or body.<json path>,
+// equals the expected value
+type StepAssertion struct {
+	Path   string `json:"path" yaml:"path"`
+	Equals string `json:"equals" yaml:"equals"`
+}
+
+// AssertionResult is the outcome of an assertion of a scenario step
+type AssertionResult struct {
+	Normal   bool   `json:"normal" bson:"normal" yaml:"normal"`
+	Path     string `json:"path" bson:"path" yaml:"path"`
+	Expected string `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   string `json:"actual" bson:"actual" yaml:"actual"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// kinds of the verification queries
+const (
+	StateCheckSQL   = "sql"
+	StateCheckMongo = "mongo"
+)
+
+// StateCheck is a query run against a real test database once the test case is simulated, verifying the data it
+// persisted, e.g. SELECT status FROM orders WHERE id = 42 or db.orders.find({_id: 42}). The mocked datastores
+// can't be queried, as the mocks hold the calls of the app rather than its data. When the output of the query and
+// Expected are both json they are compared as json, their key and row orders ignored, and otherwise line by line,
+// the surrounding whitespace of the lines ignored.
+type StateCheck struct {
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+	Kind     string `json:"kind" yaml:"kind"`
+	Query    string `json:"query" yaml:"query"`
+	Expected string `json:"expected" yaml:"expected"`
+}
+
+// StateCheckResult is the outcome of a verification query of the test case
+type StateCheckResult struct {
+	Normal   bool   `json:"normal" bson:"normal" yaml:"normal"`
+	Name     string `json:"name,omitempty" bson:"name,omitempty" yaml:"name,omitempty"`
+	Query    string `json:"query" bson:"query" yaml:"query"`
+	Expected string `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   string `json:"actual" bson:"actual" yaml:"actual"`
+	// Error is set when the query couldn't be run
+	Error string `json:"error,omitempty" bson:"error,omitempty" yaml:"error,omitempty"`
+}
@@ -25,7 +25,13 @@ const (
 	SQL            Kind     = "SQL"
 	Postgres       Kind     = "Postgres"
 	GRPC_EXPORT    Kind     = "gRPC"
+	MESSAGE        Kind     = "Message"
+	JOB            Kind     = "Job"
 	Mongo          Kind     = "Mongo"
+	REDIS          Kind     = "Redis"
+	KAFKA          Kind     = "Kafka"
+	WEBSOCKET      Kind     = "WebSocket"
+	UDP            Kind     = "UDP"
 	BodyTypeUtf8   BodyType = "utf-8"
 	BodyTypeBinary BodyType = "binary"
 	BodyTypePlain  BodyType = "PLAIN"
@@ -45,11 +51,17 @@ type TestCase struct {
 	AllKeys  map[string][]string `json:"all_keys" bson:"all_keys"`
 	GrpcResp GrpcResp            `json:"grpcResp" bson:"grpcResp"`
 	GrpcReq  GrpcReq             `json:"grpcReq" bson:"grpcReq"`
+	Message  Message             `json:"message" bson:"message"`
+	Job      Job                 `json:"job" bson:"job"`
+	Frames   []WebSocketFrame    `json:"frames" bson:"frames"` // the messages of the websocket connection upgraded by HTTPReq
+	Verify   []StateCheck        `json:"verify" bson:"verify"`
 	Anchors  map[string][]string `json:"anchors" bson:"anchors"`
 	Noise    map[string][]string `json:"noise" bson:"noise"`
 	Mocks    []*Mock             `json:"mocks" bson:"mocks"`
 	Type     string              `json:"type" bson:"type"`
 	Curl     string              `json:"curl" bson:"curl"`
+	Skip     bool                `json:"skip" bson:"skip"`   // the test case isn't run
+	Xfail    string              `json:"xfail" bson:"xfail"` // the known issue, e.g. JIRA-123, for which the test case is expected to fail
 }
 
 func (tc *TestCase) GetKind() string {
@@ -13,12 +13,149 @@ type TestReport struct {
 	Total   int          `json:"total" yaml:"total"`
 	Tests   []TestResult `json:"tests" yaml:"tests,omitempty"`
 	TestSet string       `json:"testSet" yaml:"test_set"`
+	// Skipped are the test cases marked with skip, which aren't run
+	Skipped int `json:"skipped" yaml:"skipped"`
+	// ExpectedFailures are the test cases marked with xfail which failed, they don't fail the test set
+	ExpectedFailures int `json:"expectedFailures" yaml:"expected_failures"`
+	// UnexpectedPasses are the test cases marked with xfail which passed, whose known issue is likely fixed
+	UnexpectedPasses int `json:"unexpectedPasses" yaml:"unexpected_passes"`
+	// CrashBundle is the directory of the failure bundle collected when the app exited during the test set
+	CrashBundle string `json:"crashBundle,omitempty" yaml:"crash_bundle,omitempty"`
+	// EnvSet is the env set of the matrix the test set was run under
+	EnvSet string `json:"envSet,omitempty" yaml:"env_set,omitempty"`
+	// ErrorType is the cause of the failure of the test set, with the Error, empty for the passing test sets
+	ErrorType ReplayErrorType `json:"errorType,omitempty" yaml:"error_type,omitempty"`
+	Error     string          `json:"error,omitempty" yaml:"error,omitempty"`
+	// MockMismatches are the failing test cases which didn't consume the mocks recorded for them
+	MockMismatches []string `json:"mockMismatches,omitempty" yaml:"mock_mismatches,omitempty"`
+	// Environment is the build and the machine the test set was run on, that of its test run
+	Environment *RunEnvironment `json:"environment,omitempty" yaml:"environment,omitempty"`
+}
+
+// ReplayErrorType classifies the cause of the failure of a test set or a test run, telling the failures of the
+// infrastructure apart from the failing test cases
+type ReplayErrorType string
+
+const (
+	ReplaySetupError   ReplayErrorType = "SETUP_ERROR"
+	ReplayAppCrash     ReplayErrorType = "APP_CRASH"
+	ReplayMockMismatch ReplayErrorType = "MOCK_MISMATCH"
+	ReplayStorageError ReplayErrorType = "STORAGE_ERROR"
+)
+
+// CrashBundle describes how the app exited during a test set, with the test case in flight at that time
+type CrashBundle struct {
+	TestSet   string   `json:"testSet" yaml:"test_set"`
+	TestCase  string   `json:"testCase,omitempty" yaml:"test_case,omitempty"`
+	ErrorType string   `json:"errorType" yaml:"error_type"`
+	Error     string   `json:"error,omitempty" yaml:"error,omitempty"`
+	ExitCode  int      `json:"exitCode" yaml:"exit_code"`
+	CoreDump  string   `json:"coreDump,omitempty" yaml:"core_dump,omitempty"`
+	Stderr    []string `json:"stderr,omitempty" yaml:"stderr,omitempty"` // the last lines of the stderr of the app
+	Time      int64    `json:"time" yaml:"time"`
 }
 
 func (tr *TestReport) GetKind() string {
 	return "TestReport"
 }
 
+// TestRunReport aggregates the reports of the test sets of a test run
+type TestRunReport struct {
+	Version          Version          `json:"version" yaml:"version"`
+	Name             string           `json:"name" yaml:"name"`
+	Status           string           `json:"status" yaml:"status"`
+	Started          int64            `json:"started" yaml:"started"`
+	Completed        int64            `json:"completed" yaml:"completed"`
+	Duration         string           `json:"duration" yaml:"duration"`
+	Total            int              `json:"total" yaml:"total"`
+	Success          int              `json:"success" yaml:"success"`
+	Failure          int              `json:"failure" yaml:"failure"`
+	Skipped          int              `json:"skipped" yaml:"skipped"`
+	UnexpectedPasses int              `json:"unexpectedPasses" yaml:"unexpected_passes"`
+	AbortReason      string           `json:"abortReason,omitempty" yaml:"abort_reason,omitempty"`
+	ErrorType        ReplayErrorType  `json:"errorType,omitempty" yaml:"error_type,omitempty"`
+	Slowest          []SlowTest       `json:"slowest,omitempty" yaml:"slowest,omitempty"`
+	APICoverage      *APICoverage     `json:"apiCoverage,omitempty" yaml:"api_coverage,omitempty"`
+	EnvMatrix        []EnvMatrixRow   `json:"envMatrix,omitempty" yaml:"env_matrix,omitempty"`
+	TestSets         []TestSetVerdict `json:"testSets" yaml:"test_sets"`
+	Environment      RunEnvironment   `json:"environment" yaml:"environment"`
+}
+
+// TestSetVerdict is the outcome of a test set in the test run
+type TestSetVerdict struct {
+	TestSet          string `json:"testSet" yaml:"test_set"`
+	Status           string `json:"status" yaml:"status"`
+	Total            int    `json:"total" yaml:"total"`
+	Success          int    `json:"success" yaml:"success"`
+	Failure          int    `json:"failure" yaml:"failure"`
+	Skipped          int    `json:"skipped" yaml:"skipped"`
+	UnexpectedPasses int    `json:"unexpectedPasses" yaml:"unexpected_passes"`
+	EnvSet           string `json:"envSet,omitempty" yaml:"env_set,omitempty"`
+	// ErrorType, Error and MockMismatches are those of the report of the test set
+	ErrorType      ReplayErrorType `json:"errorType,omitempty" yaml:"error_type,omitempty"`
+	Error          string          `json:"error,omitempty" yaml:"error,omitempty"`
+	MockMismatches []string        `json:"mockMismatches,omitempty" yaml:"mock_mismatches,omitempty"`
+}
+
+// EnvMatrixRow is the status of a test set under each env set of the matrix, by the name of the env set
+type EnvMatrixRow struct {
+	TestSet  string            `json:"testSet" yaml:"test_set"`
+	Statuses map[string]string `json:"statuses" yaml:"statuses"`
+}
+
+// APICoverage tells which of the endpoints and status codes recorded across all the test sets were exercised by
+// the test run, the ids in their paths being replaced by {id}
+type APICoverage struct {
+	Total      int                `json:"total" yaml:"total"`
+	Covered    int                `json:"covered" yaml:"covered"`
+	Percentage float64            `json:"percentage" yaml:"percentage"`
+	Endpoints  []EndpointCoverage `json:"endpoints" yaml:"endpoints"`
+}
+
+type EndpointCoverage struct {
+	Method     string `json:"method" yaml:"method"`
+	Path       string `json:"path" yaml:"path"`
+	StatusCode int    `json:"statusCode" yaml:"status_code"`
+	Covered    bool   `json:"covered" yaml:"covered"`
+	TestCases  int    `json:"testCases" yaml:"test_cases"` // the test cases of the endpoint run in the test run
+}
+
+// SlowTest is a test case among the slowest of the test run, by the time taken to simulate it
+type SlowTest struct {
+	TestSet    string `json:"testSet" yaml:"test_set"`
+	TestCaseID string `json:"testCaseID" yaml:"test_case_id"`
+	Duration   int64  `json:"duration" yaml:"duration"` // in milliseconds
+}
+
+// RunEnvironment describes where the test run was executed
+type RunEnvironment struct {
+	KeployVersion string `json:"keployVersion" yaml:"keploy_version"`
+	OS            string `json:"os" yaml:"os"`
+	Kernel        string `json:"kernel,omitempty" yaml:"kernel,omitempty"`
+	Arch          string `json:"arch" yaml:"arch"`
+	Hostname      string `json:"hostname" yaml:"hostname"`
+	Language      string `json:"language,omitempty" yaml:"language,omitempty"`
+	Command       string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Git is the commit the test run was run against, nil outside of a git repository
+	Git *GitInfo `json:"git,omitempty" yaml:"git,omitempty"`
+}
+
+// GitInfo is the commit checked out in the working directory, Dirty telling it had uncommitted changes
+type GitInfo struct {
+	Commit string `json:"commit" yaml:"commit"`
+	Branch string `json:"branch" yaml:"branch"`
+	Dirty  bool   `json:"dirty" yaml:"dirty"`
+}
+
+// constants for the test run status
+const (
+	TestRunStatusPassed  = "PASSED"
+	TestRunStatusFailed  = "FAILED"
+	TestRunStatusAborted = "ABORTED"
+	// TestRunStatusTimedOut is the status of a test run aborted once it exceeded the runTimeout
+	TestRunStatusTimedOut = "TIMED_OUT"
+)
+
 type TestResult struct {
 	Kind         Kind       `json:"kind" yaml:"kind"`
 	Name         string     `json:"name" yaml:"name"`
@@ -32,6 +169,17 @@ type TestResult struct {
 	Res          HTTPResp   `json:"resp" yaml:"resp,omitempty"`
 	Noise        Noise      `json:"noise" yaml:"noise,omitempty"`
 	Result       Result     `json:"result" yaml:"result"`
+	Xfail        string     `json:"xfail,omitempty" yaml:"xfail,omitempty"` // the known issue of a test case expected to fail
+	Duration     int64      `json:"duration" yaml:"duration"`               // milliseconds taken to simulate the test case
+	// AppOutput are the lines printed by the app while a failing test case ran
+	AppOutput []AppOutputLine `json:"appOutput,omitempty" yaml:"app_output,omitempty"`
+}
+
+// AppOutputLine is a line printed by the app on its stdout or stderr, at the time in unix milliseconds
+type AppOutputLine struct {
+	Time   int64  `json:"time" yaml:"time"`
+	Stream string `json:"stream" yaml:"stream"`
+	Line   string `json:"line" yaml:"line"`
 }
 
 func (tr *TestResult) GetKind() string {
@@ -42,13 +190,15 @@ type TestSetStatus string
 
 // constants for testSet status
 const (
-	TestSetStatusRunning      TestSetStatus = "RUNNING"
-	TestSetStatusFailed       TestSetStatus = "FAILED"
-	TestSetStatusPassed       TestSetStatus = "PASSED"
-	TestSetStatusAppHalted    TestSetStatus = "APP_HALTED"
-	TestSetStatusUserAbort    TestSetStatus = "USER_ABORT"
-	TestSetStatusFaultUserApp TestSetStatus = "APP_FAULT"
-	TestSetStatusInternalErr  TestSetStatus = "INTERNAL_ERR"
+	TestSetStatusRunning            TestSetStatus = "RUNNING"
+	TestSetStatusFailed             TestSetStatus = "FAILED"
+	TestSetStatusPassed             TestSetStatus = "PASSED"
+	TestSetStatusAppHalted          TestSetStatus = "APP_HALTED"
+	TestSetStatusUserAbort          TestSetStatus = "USER_ABORT"
+	TestSetStatusFaultUserApp       TestSetStatus = "APP_FAULT"
+	TestSetStatusInternalErr        TestSetStatus = "INTERNAL_ERR"
+	TestSetStatusTimedOut           TestSetStatus = "TIMED_OUT"
+	TestSetStatusPreconditionFailed TestSetStatus = "PRECONDITION_FAILED"
 )
 
 func StringToTestSetStatus(s string) (TestSetStatus, error) {
@@ -67,6 +217,10 @@ func StringToTestSetStatus(s string) (TestSetStatus, error) {
 		return TestSetStatusFaultUserApp, nil
 	case "INTERNAL_ERR":
 		return TestSetStatusInternalErr, nil
+	case "TIMED_OUT":
+		return TestSetStatusTimedOut, nil
+	case "PRECONDITION_FAILED":
+		return TestSetStatusPreconditionFailed, nil
 	default:
 		return "", errors.New("invalid TestSetStatus value")
 	}
@@ -77,6 +231,73 @@ type Result struct {
 	HeadersResult []HeaderResult `json:"headers_result" bson:"headers_result" yaml:"headers_result"`
 	BodyResult    []BodyResult   `json:"body_result" bson:"body_result" yaml:"body_result"`
 	DepResult     []DepResult    `json:"dep_result" bson:"dep_result" yaml:"dep_result"`
+	// MockOrder is set when the order of the outgoing calls is verified
+	MockOrder *MockOrderResult `json:"mock_order,omitempty" bson:"mock_order,omitempty" yaml:"mock_order,omitempty"`
+	// Downstream is set for the test cases triggered by a message or a job, whose outgoing calls are verified
+	Downstream *DownstreamResult `json:"downstream,omitempty" bson:"downstream,omitempty" yaml:"downstream,omitempty"`
+	// ExitCode is set for the test cases of a job, whose exit code is compared with the recorded one
+	ExitCode *IntResult `json:"exit_code,omitempty" bson:"exit_code,omitempty" yaml:"exit_code,omitempty"`
+	// Assertions are the outcomes of the assertions of the test case when it's a step of a scenario
+	Assertions []AssertionResult `json:"assertions,omitempty" bson:"assertions,omitempty" yaml:"assertions,omitempty"`
+	// StateChecks are the outcomes of the verification queries run against the datastore after the test case
+	StateChecks []StateCheckResult `json:"state_checks,omitempty" bson:"state_checks,omitempty" yaml:"state_checks,omitempty"`
+	// Diffs are the fields of the response which differ from the recorded one, including the ones ignored as noise
+	Diffs []FieldDiff `json:"diffs,omitempty" bson:"diffs,omitempty" yaml:"diffs,omitempty"`
+	// GrpcStatus is set for the grpc test cases, whose grpc-status is compared with the recorded one
+	GrpcStatus *IntResult `json:"grpc_status,omitempty" bson:"grpc_status,omitempty" yaml:"grpc_status,omitempty"`
+	// TrailersResult compares the grpc-message and the selected trailers of the grpc responses
+	TrailersResult []HeaderResult `json:"trailers_result,omitempty" bson:"trailers_result,omitempty" yaml:"trailers_result,omitempty"`
+	// Idempotency is set when the test case is simulated twice, comparing the second response with the first
+	Idempotency *IdempotencyResult `json:"idempotency,omitempty" bson:"idempotency,omitempty" yaml:"idempotency,omitempty"`
+	// SchemaDrift are the fields of the json response whose type or presence differs from the schema inferred
+	// from the responses recorded for the endpoint
+	SchemaDrift []SchemaDrift `json:"schema_drift,omitempty" bson:"schema_drift,omitempty" yaml:"schema_drift,omitempty"`
+	// WebSocket is set for the websocket test cases, whose server messages are compared with the recorded ones
+	WebSocket *WebSocketResult `json:"websocket,omitempty" bson:"websocket,omitempty" yaml:"websocket,omitempty"`
+}
+
+// IdempotencyResult compares the responses of a request sent twice back to back, Diffs being the fields of the
+// second response which differ from the first beyond the noise
+type IdempotencyResult struct {
+	Normal bool        `json:"normal" bson:"normal" yaml:"normal"`
+	Diffs  []FieldDiff `json:"diffs,omitempty" bson:"diffs,omitempty" yaml:"diffs,omitempty"`
+}
+
+// SchemaDrift is a field of the response which has a type never recorded for it, or is missing while it was in
+// all the recorded responses, in which case Actual is empty
+type SchemaDrift struct {
+	Path     string   `json:"path" bson:"path" yaml:"path"`
+	Expected []string `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   []string `json:"actual,omitempty" bson:"actual,omitempty" yaml:"actual,omitempty"`
+}
+
+// FieldDiff is a field of the response whose actual value differs from the expected one. The path is
+// status_code, header.<name> or body.<json path>, the json values are encoded as json and missing ones are empty.
+type FieldDiff struct {
+	Path     string `json:"path" bson:"path" yaml:"path"`
+	Expected string `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   string `json:"actual" bson:"actual" yaml:"actual"`
+	Noise    bool   `json:"noise" bson:"noise" yaml:"noise"`
+}
+
+// MockOrderResult compares the recorded order of the outgoing calls of a test case with the order during the test
+type MockOrderResult struct {
+	Normal   bool     `json:"normal" bson:"normal" yaml:"normal"`
+	Expected []string `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   []string `json:"actual" bson:"actual" yaml:"actual"`
+	// Missing are the recorded calls which weren't made during the test
+	Missing []string `json:"missing,omitempty" bson:"missing,omitempty" yaml:"missing,omitempty"`
+}
+
+// DownstreamResult compares the outgoing calls recorded while a consumed message was processed or a job ran,
+// by the names of their mocks, with the calls made when the message is published or the job is run again
+// during the test. Missing are the recorded
+// calls which weren't made.
+type DownstreamResult struct {
+	Normal   bool     `json:"normal" bson:"normal" yaml:"normal"`
+	Expected []string `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   []string `json:"actual" bson:"actual" yaml:"actual"`
+	Missing  []string `json:"missing,omitempty" bson:"missing,omitempty" yaml:"missing,omitempty"`
 }
 
 type DepResult struct {
@@ -124,4 +345,9 @@ const (
 	TestStatusRunning TestStatus = "RUNNING"
 	TestStatusFailed  TestStatus = "FAILED"
 	TestStatusPassed  TestStatus = "PASSED"
+	TestStatusSkipped TestStatus = "SKIPPED"
+	// TestStatusXFailed is the status of a test case marked with xfail which failed as expected
+	TestStatusXFailed TestStatus = "XFAIL"
+	// TestStatusXPassed is the status of a test case marked with xfail which passed unexpectedly
+	TestStatusXPassed TestStatus = "XPASS"
 )
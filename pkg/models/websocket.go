@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// the types of the websocket messages, by their opcodes
+const (
+	WebSocketText   = "text"
+	WebSocketBinary = "binary"
+	WebSocketClose  = "close"
+	WebSocketPing   = "ping"
+	WebSocketPong   = "pong"
+)
+
+// WebSocketSchema is the yaml doc of a test case of a websocket connection, the http upgrade request and its 101
+// response followed by the messages exchanged on the connection in their order
+type WebSocketSchema struct {
+	Request    HTTPReq                `json:"req" yaml:"req"`
+	Response   HTTPResp               `json:"resp" yaml:"resp"`
+	Frames     []WebSocketFrame       `json:"frames" yaml:"frames"`
+	Verify     []StateCheck           `json:"verify" yaml:"verify,omitempty"`
+	Created    int64                  `json:"created" yaml:"created,omitempty"`
+	Assertions map[string]interface{} `json:"assertions" yaml:"assertions,omitempty"`
+}
+
+// WebSocketFrame is a message sent on a websocket connection, its fragments joined. The data of the binary messages
+// is base64 encoded, the close messages hold the close code followed by the reason.
+type WebSocketFrame struct {
+	Origin    OriginType `json:"origin" yaml:"origin"`
+	Type      string     `json:"type" yaml:"type"`
+	Data      string     `json:"data" yaml:"data"`
+	Timestamp time.Time  `json:"timestamp" yaml:"timestamp"`
+}
+
+// WebSocketResult compares the messages the server sent during the test with the recorded ones, in their order
+type WebSocketResult struct {
+	Normal bool                   `json:"normal" bson:"normal" yaml:"normal"`
+	Frames []WebSocketFrameResult `json:"frames" bson:"frames" yaml:"frames"`
+}
+
+// WebSocketFrameResult is a message of the server, Expected or Actual being empty when it is missing or unexpected
+type WebSocketFrameResult struct {
+	Normal   bool   `json:"normal" bson:"normal" yaml:"normal"`
+	Type     string `json:"type" bson:"type" yaml:"type"`
+	Expected string `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   string `json:"actual" bson:"actual" yaml:"actual"`
+}
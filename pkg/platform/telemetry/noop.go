@@ -0,0 +1,14 @@
+package telemetry
+
+// Noop is used in place of the telemetry service when telemetry is disabled, so that nothing
+// is constructed or sent at all.
+type Noop struct{}
+
+func (Noop) Ping()                                               {}
+func (Noop) TestSetRun(_ int, _ int, _ string, _ string)         {}
+func (Noop) TestRun(_ int, _ int, _ int, _ string)               {}
+func (Noop) MockTestRun(_ int)                                   {}
+func (Noop) RecordedTestSuite(_ string, _ int, _ map[string]int) {}
+func (Noop) RecordedTestAndMocks()                               {}
+func (Noop) RecordedMocks(_ map[string]int)                      {}
+func (Noop) RecordedTestCaseMock(_ string)                       {}
@@ -3,6 +3,7 @@ package telemetry
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"runtime"
 	"time"
@@ -21,6 +22,7 @@ type Telemetry struct {
 	KeployVersion  string
 	GlobalMap      map[string]interface{}
 	client         *http.Client
+	url            string
 }
 
 type Options struct {
@@ -28,11 +30,18 @@ type Options struct {
 	Version        string
 	GlobalMap      map[string]interface{}
 	InstallationID string
+	// URL of the telemetry sink, defaults to the keploy telemetry server
+	URL string
 }
 
 func NewTelemetry(logger *zap.Logger, opt Options) *Telemetry {
+	url := opt.URL
+	if url == "" {
+		url = teleURL
+	}
 	return &Telemetry{
 		Enabled:        opt.Enabled,
+		url:            url,
 		logger:         logger,
 		KeployVersion:  opt.Version,
 		GlobalMap:      opt.GlobalMap,
@@ -54,62 +63,87 @@ func (tel *Telemetry) Ping() {
 }
 
 func (tel *Telemetry) TestSetRun(success int, failure int, testSet string, runStatus string) {
-	go tel.SendTelemetry("TestSetRun", map[string]interface{}{"Passed-Tests": success, "Failed-Tests": failure, "Test-Set": testSet, "Run-Status": runStatus})
+	go tel.SendTelemetry("TestSetRun", testSetRunMeta(success, failure, testSet, runStatus))
 }
 
 func (tel *Telemetry) TestRun(success int, failure int, testSets int, runStatus string) {
-	go tel.SendTelemetry("TestRun", map[string]interface{}{"Passed-Tests": success, "Failed-Tests": failure, "Test-Sets": testSets, "Run-Status": runStatus})
+	go tel.SendTelemetry("TestRun", testRunMeta(success, failure, testSets, runStatus))
 }
 
 // MockTestRun is Telemetry event for the Mocking feature test run
 func (tel *Telemetry) MockTestRun(utilizedMocks int) {
-	go tel.SendTelemetry("MockTestRun", map[string]interface{}{"Utilized-Mocks": utilizedMocks})
+	go tel.SendTelemetry("MockTestRun", mockTestRunMeta(utilizedMocks))
 }
 
 // RecordedTestSuite is Telemetry event for the tests and mocks that are recorded
 func (tel *Telemetry) RecordedTestSuite(testSet string, testsTotal int, mockTotal map[string]int) {
-	go tel.SendTelemetry("RecordedTestSuite", map[string]interface{}{"test-set": testSet, "tests": testsTotal, "mocks": mockTotal})
+	go tel.SendTelemetry("RecordedTestSuite", recordedTestSuiteMeta(testSet, testsTotal, mockTotal))
 }
 
 func (tel *Telemetry) RecordedTestAndMocks() {
-	go tel.SendTelemetry("RecordedTestAndMocks", map[string]interface{}{"mocks": make(map[string]int)})
+	go tel.SendTelemetry("RecordedTestAndMocks", recordedMocksMeta(make(map[string]int)))
 }
 
 // RecordedMocks is Telemetry event for the mocks that are recorded in the mocking feature
 func (tel *Telemetry) RecordedMocks(mockTotal map[string]int) {
-	go tel.SendTelemetry("RecordedMocks", map[string]interface{}{"mocks": mockTotal})
+	go tel.SendTelemetry("RecordedMocks", recordedMocksMeta(mockTotal))
 }
 
 func (tel *Telemetry) RecordedTestCaseMock(mockType string) {
-	go tel.SendTelemetry("RecordedTestCaseMock", map[string]interface{}{"mock": mockType})
+	go tel.SendTelemetry("RecordedTestCaseMock", recordedTestCaseMockMeta(mockType))
 }
 
-func (tel *Telemetry) SendTelemetry(eventType string, output ...map[string]interface{}) {
-	if tel.Enabled {
-		event := models.TeleEvent{
-			EventType: eventType,
-			CreatedAt: time.Now().Unix(),
-		}
-		event.Meta = make(map[string]interface{})
-		if len(output) != 0 {
-			event.Meta = output[0]
-		}
+// Preview returns the url and the payloads of all the events exactly as they would be sent, with sample values
+func (tel *Telemetry) Preview() ([]byte, error) {
+	events := []models.TeleEvent{
+		tel.event("Ping"),
+		tel.event("TestSetRun", testSetRunMeta(4, 1, "test-set-0", "FAILED")),
+		tel.event("TestRun", testRunMeta(4, 1, 1, "fail")),
+		tel.event("MockTestRun", mockTestRunMeta(3)),
+		tel.event("RecordedTestSuite", recordedTestSuiteMeta("test-set-0", 5, map[string]int{"Http": 3})),
+		tel.event("RecordedTestAndMocks", recordedMocksMeta(make(map[string]int))),
+		tel.event("RecordedMocks", recordedMocksMeta(map[string]int{"Http": 3})),
+		tel.event("RecordedTestCaseMock", recordedTestCaseMockMeta("Http")),
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"url":     tel.url,
+		"enabled": tel.Enabled,
+		"events":  events,
+	}, "", "  ")
+}
 
-		if tel.GlobalMap != nil {
-			event.Meta["global-map"] = tel.GlobalMap
-		}
+// event builds the payload of a telemetry event along with the details common to all the events
+func (tel *Telemetry) event(eventType string, output ...map[string]interface{}) models.TeleEvent {
+	event := models.TeleEvent{
+		EventType: eventType,
+		CreatedAt: time.Now().Unix(),
+	}
+	event.Meta = make(map[string]interface{})
+	if len(output) != 0 {
+		event.Meta = output[0]
+	}
+
+	if tel.GlobalMap != nil {
+		event.Meta["global-map"] = tel.GlobalMap
+	}
 
-		event.InstallationID = tel.InstallationID
-		event.OS = runtime.GOOS
-		event.KeployVersion = tel.KeployVersion
-		event.Arch = runtime.GOARCH
+	event.InstallationID = tel.InstallationID
+	event.OS = runtime.GOOS
+	event.KeployVersion = tel.KeployVersion
+	event.Arch = runtime.GOARCH
+	return event
+}
+
+func (tel *Telemetry) SendTelemetry(eventType string, output ...map[string]interface{}) {
+	if tel.Enabled {
+		event := tel.event(eventType, output...)
 		bin, err := marshalEvent(event, tel.logger)
 		if err != nil {
 			tel.logger.Debug("failed to marshal event", zap.Error(err))
 			return
 		}
 
-		req, err := http.NewRequest(http.MethodPost, teleURL, bytes.NewBuffer(bin))
+		req, err := http.NewRequest(http.MethodPost, tel.url, bytes.NewBuffer(bin))
 		if err != nil {
 			tel.logger.Debug("failed to create request for analytics", zap.Error(err))
 			return
@@ -50,3 +50,27 @@ func unmarshalResp(resp *http.Response, log *zap.Logger) (id string, err error)
 	}
 	return
 }
+
+func testSetRunMeta(success int, failure int, testSet string, runStatus string) map[string]interface{} {
+	return map[string]interface{}{"Passed-Tests": success, "Failed-Tests": failure, "Test-Set": testSet, "Run-Status": runStatus}
+}
+
+func testRunMeta(success int, failure int, testSets int, runStatus string) map[string]interface{} {
+	return map[string]interface{}{"Passed-Tests": success, "Failed-Tests": failure, "Test-Sets": testSets, "Run-Status": runStatus}
+}
+
+func mockTestRunMeta(utilizedMocks int) map[string]interface{} {
+	return map[string]interface{}{"Utilized-Mocks": utilizedMocks}
+}
+
+func recordedTestSuiteMeta(testSet string, testsTotal int, mockTotal map[string]int) map[string]interface{} {
+	return map[string]interface{}{"test-set": testSet, "tests": testsTotal, "mocks": mockTotal}
+}
+
+func recordedMocksMeta(mockTotal map[string]int) map[string]interface{} {
+	return map[string]interface{}{"mocks": mockTotal}
+}
+
+func recordedTestCaseMockMeta(mockType string) map[string]interface{} {
+	return map[string]interface{}{"mock": mockType}
+}
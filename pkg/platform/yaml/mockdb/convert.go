@@ -0,0 +1,78 @@
+package mockdb
+
+import (
+	"strings"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.uber.org/zap"
+)
+
+// enterpriseVersionPrefix is the version prefix of the mocks recorded by keploy enterprise
+const enterpriseVersionPrefix = "api.keploy-enterprise.io/"
+
+// Converter translates an enterprise mock into an open source one
+type Converter func(doc *yaml.NetworkTrafficDoc) (*yaml.NetworkTrafficDoc, error)
+
+var (
+	convertersMu sync.RWMutex
+	converters   = map[models.Kind]Converter{}
+	// unsupportedKinds are warned about once
+	unsupportedKinds sync.Map
+)
+
+// RegisterConverter sets the converter of the enterprise mocks of the kind
+func RegisterConverter(kind models.Kind, converter Converter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[kind] = converter
+}
+
+func isEnterpriseMock(doc *yaml.NetworkTrafficDoc) bool {
+	return strings.HasPrefix(string(doc.Version), enterpriseVersionPrefix) || strings.Contains(string(doc.Kind), "-")
+}
+
+// convertMock translates the enterprise mocks with the converter of their kind. The kinds without a converter
+// whose base kind (e.g. Http of Http-v2) is supported are read as their base kind, the others are skipped.
+func convertMock(doc *yaml.NetworkTrafficDoc, logger *zap.Logger) (*yaml.NetworkTrafficDoc, bool) {
+	if !isEnterpriseMock(doc) {
+		return doc, true
+	}
+
+	convertersMu.RLock()
+	converter, ok := converters[doc.Kind]
+	convertersMu.RUnlock()
+	if !ok {
+		converter, ok = baseKindConverter(doc.Kind)
+	}
+	if !ok {
+		if _, warned := unsupportedKinds.LoadOrStore(doc.Kind, true); !warned {
+			logger.Warn("mocks of this kind are recorded by keploy enterprise and aren't supported by the open source version, they are skipped", zap.String("kind", string(doc.Kind)))
+		}
+		return nil, false
+	}
+
+	converted, err := converter(doc)
+	if err != nil {
+		logger.Warn("failed to convert the enterprise mock, it's skipped", zap.String("mock", doc.Name), zap.String("kind", string(doc.Kind)), zap.Error(err))
+		return nil, false
+	}
+	logger.Debug("converted the enterprise mock", zap.String("mock", doc.Name), zap.String("from", string(doc.Kind)), zap.String("to", string(converted.Kind)))
+	return converted, true
+}
+
+func baseKindConverter(kind models.Kind) (Converter, bool) {
+	base := models.Kind(strings.SplitN(string(kind), "-", 2)[0])
+	switch base {
+	case models.HTTP, models.GENERIC, models.SQL, models.Postgres, models.GRPC_EXPORT, models.Mongo:
+	default:
+		return nil, false
+	}
+	return func(doc *yaml.NetworkTrafficDoc) (*yaml.NetworkTrafficDoc, error) {
+		converted := *doc
+		converted.Kind = base
+		converted.Version = models.Version("api.keploy.io/" + strings.TrimPrefix(string(doc.Version), enterpriseVersionPrefix))
+		return &converted, nil
+	}, true
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -25,14 +26,23 @@ type MockYaml struct {
 	MockName  string
 	Logger    *zap.Logger
 	idCounter int64
+	// GlobalMocks are the test sets using the shared mocks, all of them when empty
+	GlobalMocks []string
+	// Tags are the active tags, the tagged mocks are loaded only when one of their tags is active
+	Tags []string
+	// indexes are the mocks of the test sets indexed by their timestamps
+	indexes map[string]*mockIndex
+	indexMu sync.Mutex
 }
 
-func New(Logger *zap.Logger, mockPath string, mockName string) *MockYaml {
+func New(Logger *zap.Logger, mockPath string, mockName string, globalMocks []string, tags []string) *MockYaml {
 	return &MockYaml{
-		MockPath:  mockPath,
-		MockName:  mockName,
-		Logger:    Logger,
-		idCounter: -1,
+		MockPath:    mockPath,
+		MockName:    mockName,
+		Logger:      Logger,
+		idCounter:   -1,
+		GlobalMocks: globalMocks,
+		Tags:        tags,
 	}
 }
 
@@ -84,6 +94,11 @@ func (ys *MockYaml) UpdateMocks(ctx context.Context, testSetID string, mockNames
 	}
 	var newMocks []*models.Mock
 	for _, mock := range mocks {
+		// the mocks of the inactive tags weren't loaded, so they're kept for the replays activating them
+		if !ys.isActive(mock) {
+			newMocks = append(newMocks, mock)
+			continue
+		}
 		if _, ok := mockNames[mock.Name]; ok {
 			newMocks = append(newMocks, mock)
 			continue
@@ -141,6 +156,10 @@ func (ys *MockYaml) InsertMock(ctx context.Context, mock *models.Mock, testSetID
 }
 
 func (ys *MockYaml) GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
+	// the mocks of a test case are looked up in the index of the test set
+	if afterTime != (time.Time{}) && beforeTime != (time.Time{}) {
+		return ys.filteredMocks(ctx, testSetID, afterTime, beforeTime)
+	}
 
 	var tcsMocks = make([]*models.Mock, 0)
 	var filteredTcsMocks = make([]*models.Mock, 0)
@@ -182,7 +201,10 @@ func (ys *MockYaml) GetFilteredMocks(ctx context.Context, testSetID string, afte
 		}
 
 		for _, mock := range mocks {
-			if mock.Spec.Metadata["type"] != "config" && mock.Kind != "Generic" && mock.Kind != "Postgres" {
+			if !ys.isActive(mock) {
+				continue
+			}
+			if mock.Spec.Metadata["type"] != "config" && mock.Kind != "Generic" && mock.Kind != "Postgres" && mock.Kind != "Redis" && mock.Kind != "Kafka" && mock.Kind != "UDP" {
 				tcsMocks = append(tcsMocks, mock)
 			}
 		}
@@ -194,10 +216,18 @@ func (ys *MockYaml) GetFilteredMocks(ctx context.Context, testSetID string, afte
 		return filteredTcsMocks[i].Spec.ReqTimestampMock.Before(filteredTcsMocks[j].Spec.ReqTimestampMock)
 	})
 
-	return filteredTcsMocks, nil
+	globalMocks, err := ys.getGlobalMocks(ctx, testSetID, tcsMocks, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(filteredTcsMocks, globalMocks...), nil
 }
 
 func (ys *MockYaml) GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
+	if afterTime != (time.Time{}) && beforeTime != (time.Time{}) {
+		return ys.unfilteredMocks(ctx, testSetID, afterTime, beforeTime)
+	}
 
 	var configMocks = make([]*models.Mock, 0)
 
@@ -238,7 +268,10 @@ func (ys *MockYaml) GetUnFilteredMocks(ctx context.Context, testSetID string, af
 			return nil, err
 		}
 		for _, mock := range mocks {
-			if mock.Spec.Metadata["type"] == "config" || mock.Kind == "Postgres" || mock.Kind == "Generic" {
+			if !ys.isActive(mock) {
+				continue
+			}
+			if mock.Spec.Metadata["type"] == "config" || mock.Kind == "Postgres" || mock.Kind == "Generic" || mock.Kind == "Redis" || mock.Kind == "Kafka" || mock.Kind == "UDP" {
 				configMocks = append(configMocks, mock)
 			}
 		}
@@ -260,7 +293,12 @@ func (ys *MockYaml) GetUnFilteredMocks(ctx context.Context, testSetID string, af
 
 	mocks := append(filteredMocks, unfilteredMocks...)
 
-	return mocks, nil
+	globalMocks, err := ys.getGlobalMocks(ctx, testSetID, configMocks, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(mocks, globalMocks...), nil
 }
 
 func (ys *MockYaml) getNextID() int64 {
@@ -307,3 +345,45 @@ func (ys *MockYaml) filterByTimeStamp(_ context.Context, m []*models.Mock, after
 	}
 	return filteredMocks, unfilteredMocks
 }
+
+// InsertMappings writes the names of the mocks captured for each test case of the test set
+func (ys *MockYaml) InsertMappings(ctx context.Context, testSetID string, mappings map[string][]string) error {
+	data, err := yamlLib.Marshal(mappings)
+	if err != nil {
+		return err
+	}
+	return yaml.WriteFile(ctx, ys.Logger, filepath.Join(ys.MockPath, testSetID), "mappings", data, false)
+}
+
+// InsertEgressSummary writes the summary of the outgoing calls recorded in the test set
+func (ys *MockYaml) InsertEgressSummary(ctx context.Context, testSetID string, summary *models.EgressSummary) error {
+	data, err := yamlLib.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return yaml.WriteFile(ctx, ys.Logger, filepath.Join(ys.MockPath, testSetID), "egress", data, false)
+}
+
+// GetMappings returns the names of the mocks captured for each test case of the test set,
+// it's empty for the test sets recorded without a mapping.
+func (ys *MockYaml) GetMappings(ctx context.Context, testSetID string) (map[string][]string, error) {
+	mappings := map[string][]string{}
+	path := filepath.Join(ys.MockPath, testSetID)
+	mappingPath, err := yaml.ValidatePath(filepath.Join(path, "mappings.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(mappingPath); err != nil {
+		return mappings, nil
+	}
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, "mappings")
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to read the mock mappings", zap.Any("session", testSetID))
+		return nil, err
+	}
+	err = yamlLib.Unmarshal(data, &mappings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the mock mappings. error: %v", err.Error())
+	}
+	return mappings, nil
+}
@@ -0,0 +1,137 @@
+package mockdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// The mocks of the common dependencies, like an auth or a config service, can be kept once in the global-mocks
+// directory next to the test sets instead of in every test set. They're served after the mocks of the test set,
+// and a mock of the test set recording the same request as a shared one overrides it.
+
+// getGlobalMocks returns the shared mocks used by the test set which aren't overridden by its own mocks, the config
+// mocks or the test case ones
+func (ys *MockYaml) getGlobalMocks(ctx context.Context, testSetID string, own []*models.Mock, config bool) ([]*models.Mock, error) {
+	if testSetID == models.GlobalMocksDir || !ys.usesGlobalMocks(testSetID) {
+		return nil, nil
+	}
+	mocks, err := ys.readGlobalMocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	overridden := map[string]bool{}
+	for _, mock := range own {
+		overridden[requestKey(mock)] = true
+	}
+	var global []*models.Mock
+	for _, mock := range mocks {
+		if !ys.isActive(mock) || isConfigMock(mock) != config || overridden[requestKey(mock)] {
+			continue
+		}
+		mock.TestModeInfo.IsFiltered = false
+		global = append(global, mock)
+	}
+	return global, nil
+}
+
+func (ys *MockYaml) usesGlobalMocks(testSetID string) bool {
+	if len(ys.GlobalMocks) == 0 {
+		return true
+	}
+	for _, id := range ys.GlobalMocks {
+		if id == testSetID {
+			return true
+		}
+	}
+	return false
+}
+
+func (ys *MockYaml) readGlobalMocks(ctx context.Context) ([]*models.Mock, error) {
+	mockFileName := "mocks"
+	if ys.MockName != "" {
+		mockFileName = ys.MockName
+	}
+	path := filepath.Join(ys.MockPath, models.GlobalMocksDir)
+	mockPath, err := yaml.ValidatePath(filepath.Join(path, mockFileName+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(mockPath); err != nil {
+		return nil, nil
+	}
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to read the global mocks", zap.Any("at path", mockPath))
+		return nil, err
+	}
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	var mockYamls []*yaml.NetworkTrafficDoc
+	for {
+		var doc *yaml.NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the global mocks. error: %v", err.Error())
+		}
+		mockYamls = append(mockYamls, doc)
+	}
+	mocks, err := decodeMocks(mockYamls, ys.Logger)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to decode the global mocks from yaml docs")
+		return nil, err
+	}
+	return mocks, nil
+}
+
+// isConfigMock tells if the mock is served by GetUnFilteredMocks rather than GetFilteredMocks
+func isConfigMock(mock *models.Mock) bool {
+	return mock.Spec.Metadata["type"] == "config" || mock.Kind == "Postgres" || mock.Kind == "Generic" || mock.Kind == "Redis" || mock.Kind == "Kafka" || mock.Kind == "UDP"
+}
+
+// requestKey identifies the request recorded by the mock, regardless of when it was recorded
+func requestKey(mock *models.Mock) string {
+	spec := mock.Spec
+	req := struct {
+		Kind     models.Kind
+		HTTP     []string                `json:",omitempty"`
+		Generic  []models.GenericPayload `json:",omitempty"`
+		Mongo    []models.MongoRequest   `json:",omitempty"`
+		Postgres []models.Backend        `json:",omitempty"`
+		MySQL    []models.MySQLRequest   `json:",omitempty"`
+		Redis    []models.RedisPayload   `json:",omitempty"`
+		Kafka    []models.KafkaPayload   `json:",omitempty"`
+		GRPC     *models.GrpcReq         `json:",omitempty"`
+	}{
+		Kind:     mock.Kind,
+		Generic:  spec.GenericRequests,
+		Mongo:    spec.MongoRequests,
+		Postgres: spec.PostgresRequests,
+		MySQL:    spec.MySQLRequests,
+		Redis:    spec.RedisRequests,
+		Kafka:    spec.KafkaRequests,
+		GRPC:     spec.GRPCReq,
+	}
+	if spec.HTTPReq != nil {
+		req.HTTP = []string{string(spec.HTTPReq.Method), spec.HTTPReq.URL, spec.HTTPReq.Body}
+	}
+	key, err := json.Marshal(req)
+	if err != nil {
+		return mock.Name
+	}
+	return string(key)
+}
@@ -0,0 +1,301 @@
+package mockdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// The mocks of a test set are read and indexed by their timestamps once, the mocks of each test case being looked up
+// in the index instead of reading and scanning the mock file again for every test case. Only the looked up mocks are
+// decoded from the yaml docs, so that the proxy gets mocks of its own to consume. The index is built again when the
+// mock file of the test set or the global one changes.
+
+// indexedDoc is a mock doc with the timestamps of its request and response
+type indexedDoc struct {
+	doc *yaml.NetworkTrafficDoc
+	req time.Time
+	res time.Time
+}
+
+// fileStamp tells if a mock file changed since it was indexed
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// mockIndex is the index of the mocks of a test set
+type mockIndex struct {
+	stamp       fileStamp
+	globalStamp fileStamp
+	// the test case and the config mocks with timestamps, sorted by the timestamps of their requests
+	tcs    []indexedDoc
+	config []indexedDoc
+	// the mocks without timestamps, which are served to every test case, in the order of the mock file
+	untimedTcs    []indexedDoc
+	untimedConfig []indexedDoc
+	// the shared mocks of the global mock file which aren't overridden by the test set
+	globalTcs    []*yaml.NetworkTrafficDoc
+	globalConfig []*yaml.NetworkTrafficDoc
+}
+
+// window returns the indexed mocks whose requests were sent after afterTime and whose responses came before
+// beforeTime, in the order of their requests
+func window(docs []indexedDoc, afterTime, beforeTime time.Time) []indexedDoc {
+	start := sort.Search(len(docs), func(i int) bool {
+		return docs[i].req.After(afterTime)
+	})
+	var inWindow []indexedDoc
+	for _, d := range docs[start:] {
+		// the responses come after the requests, so no later request is in the window
+		if !d.req.Before(beforeTime) {
+			break
+		}
+		if d.res.Before(beforeTime) {
+			inWindow = append(inWindow, d)
+		}
+	}
+	return inWindow
+}
+
+// byRequest returns the docs of the mocks without timestamps and of the window, sorted by the timestamps of their
+// requests as the mocks filtered by filterByTimeStamp are
+func byRequest(untimed, inWindow []indexedDoc) []*yaml.NetworkTrafficDoc {
+	sorted := append(append([]indexedDoc{}, untimed...), inWindow...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].req.Before(sorted[j].req)
+	})
+	docs := make([]*yaml.NetworkTrafficDoc, len(sorted))
+	for i, d := range sorted {
+		docs[i] = d.doc
+	}
+	return docs
+}
+
+// filteredMocks returns the test case mocks of the window followed by the global ones, as GetFilteredMocks does
+func (ys *MockYaml) filteredMocks(ctx context.Context, testSetID string, afterTime, beforeTime time.Time) ([]*models.Mock, error) {
+	index, err := ys.mockIndex(ctx, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	mocks, err := decodeMocks(byRequest(index.untimedTcs, window(index.tcs, afterTime, beforeTime)), ys.Logger)
+	if err != nil {
+		return nil, err
+	}
+	for _, mock := range mocks {
+		mock.TestModeInfo.IsFiltered = true
+	}
+	globalMocks, err := decodeMocks(index.globalTcs, ys.Logger)
+	if err != nil {
+		return nil, err
+	}
+	return append(mocks, globalMocks...), nil
+}
+
+// unfilteredMocks returns the config mocks of the window flagged as filtered, followed by the rest of the config
+// mocks and the global ones, as GetUnFilteredMocks does
+func (ys *MockYaml) unfilteredMocks(ctx context.Context, testSetID string, afterTime, beforeTime time.Time) ([]*models.Mock, error) {
+	index, err := ys.mockIndex(ctx, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	inWindow := window(index.config, afterTime, beforeTime)
+	docs := byRequest(index.untimedConfig, inWindow)
+	filtered := make(map[*yaml.NetworkTrafficDoc]bool, len(docs))
+	for _, doc := range docs {
+		filtered[doc] = true
+	}
+	for _, d := range index.config {
+		if !filtered[d.doc] {
+			docs = append(docs, d.doc)
+		}
+	}
+	mocks, err := decodeMocks(docs, ys.Logger)
+	if err != nil {
+		return nil, err
+	}
+	for i, mock := range mocks {
+		mock.TestModeInfo.IsFiltered = filtered[docs[i]]
+	}
+	globalMocks, err := decodeMocks(index.globalConfig, ys.Logger)
+	if err != nil {
+		return nil, err
+	}
+	return append(mocks, globalMocks...), nil
+}
+
+// mockIndex returns the index of the mocks of the test set, building it when the mock files changed
+func (ys *MockYaml) mockIndex(ctx context.Context, testSetID string) (*mockIndex, error) {
+	stamp, err := ys.stampOf(testSetID)
+	if err != nil {
+		return nil, err
+	}
+	globalStamp := fileStamp{}
+	if testSetID != models.GlobalMocksDir && ys.usesGlobalMocks(testSetID) {
+		if globalStamp, err = ys.stampOf(models.GlobalMocksDir); err != nil {
+			return nil, err
+		}
+	}
+
+	ys.indexMu.Lock()
+	defer ys.indexMu.Unlock()
+	if index, ok := ys.indexes[testSetID]; ok && index.stamp == stamp && index.globalStamp == globalStamp {
+		return index, nil
+	}
+	index, err := ys.buildIndex(ctx, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	index.stamp, index.globalStamp = stamp, globalStamp
+	if ys.indexes == nil {
+		ys.indexes = map[string]*mockIndex{}
+	}
+	ys.indexes[testSetID] = index
+	return index, nil
+}
+
+func (ys *MockYaml) buildIndex(ctx context.Context, testSetID string) (*mockIndex, error) {
+	docs, mocks, err := ys.readMockDocs(ctx, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	index := &mockIndex{}
+	var tcsMocks, configMocks []*models.Mock
+	isNonKeploy := false
+	for i, mock := range mocks {
+		if !ys.isActive(mock) {
+			continue
+		}
+		config := isConfigMock(mock)
+		if config {
+			configMocks = append(configMocks, mock)
+		} else {
+			tcsMocks = append(tcsMocks, mock)
+		}
+		if mock.Version != "api.keploy.io/v1beta1" && mock.Version != "api.keploy.io/v1beta2" {
+			isNonKeploy = true
+			continue
+		}
+		d := indexedDoc{doc: docs[i], req: mock.Spec.ReqTimestampMock, res: mock.Spec.ResTimestampMock}
+		if mock.Spec.ReqTimestampMock == (time.Time{}) || mock.Spec.ResTimestampMock == (time.Time{}) {
+			if config {
+				index.untimedConfig = append(index.untimedConfig, d)
+			} else {
+				index.untimedTcs = append(index.untimedTcs, d)
+			}
+			continue
+		}
+		if config {
+			index.config = append(index.config, d)
+		} else {
+			index.tcs = append(index.tcs, d)
+		}
+	}
+	if isNonKeploy {
+		ys.Logger.Warn("Few mocks in the mock File are not recorded by keploy ignoring them")
+	}
+	for _, docs := range [][]indexedDoc{index.tcs, index.config} {
+		sort.SliceStable(docs, func(i, j int) bool {
+			return docs[i].req.Before(docs[j].req)
+		})
+	}
+
+	if testSetID == models.GlobalMocksDir || !ys.usesGlobalMocks(testSetID) {
+		return index, nil
+	}
+	globalDocs, globalMocks, err := ys.readMockDocs(ctx, models.GlobalMocksDir)
+	if err != nil {
+		return nil, err
+	}
+	// the mocks of the test set override the global ones of the same request and the same kind of mocks
+	overridden := map[bool]map[string]bool{false: {}, true: {}}
+	for _, mock := range tcsMocks {
+		overridden[false][requestKey(mock)] = true
+	}
+	for _, mock := range configMocks {
+		overridden[true][requestKey(mock)] = true
+	}
+	for i, mock := range globalMocks {
+		config := isConfigMock(mock)
+		if !ys.isActive(mock) || overridden[config][requestKey(mock)] {
+			continue
+		}
+		if config {
+			index.globalConfig = append(index.globalConfig, globalDocs[i])
+		} else {
+			index.globalTcs = append(index.globalTcs, globalDocs[i])
+		}
+	}
+	return index, nil
+}
+
+// readMockDocs reads the mock docs of the test set with the mocks they decode into, the docs which can't be
+// converted into a mock being left out
+func (ys *MockYaml) readMockDocs(ctx context.Context, testSetID string) ([]*yaml.NetworkTrafficDoc, []*models.Mock, error) {
+	path := filepath.Join(ys.MockPath, testSetID)
+	mockPath, err := yaml.ValidatePath(filepath.Join(path, ys.MockFileName()+".yaml"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(mockPath); err != nil {
+		return nil, nil, nil
+	}
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, ys.MockFileName())
+	if err != nil {
+		return nil, nil, err
+	}
+	var docs []*yaml.NetworkTrafficDoc
+	var mocks []*models.Mock
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc *yaml.NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
+		}
+		decoded, err := decodeMocks([]*yaml.NetworkTrafficDoc{doc}, ys.Logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(decoded) == 0 {
+			continue
+		}
+		docs = append(docs, doc)
+		mocks = append(mocks, decoded[0])
+	}
+	return docs, mocks, nil
+}
+
+// stampOf returns the stamp of the mock file of the test set, the zero stamp when it doesn't exist
+func (ys *MockYaml) stampOf(testSetID string) (fileStamp, error) {
+	mockPath, err := yaml.ValidatePath(filepath.Join(ys.MockPath, testSetID, ys.MockFileName()+".yaml"))
+	if err != nil {
+		return fileStamp{}, err
+	}
+	info, err := os.Stat(mockPath)
+	if err != nil {
+		return fileStamp{}, nil
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// MockFileName is the name of the mock files of the test sets, without the extension
+func (ys *MockYaml) MockFileName() string {
+	if ys.MockName != "" {
+		return ys.MockName
+	}
+	return "mocks"
+}
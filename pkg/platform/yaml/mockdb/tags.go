@@ -0,0 +1,18 @@
+package mockdb
+
+import "go.keploy.io/server/v2/pkg/models"
+
+// isActive tells if the mock is loaded, which it is when it has no tags or one of its tags is active
+func (ys *MockYaml) isActive(mock *models.Mock) bool {
+	if len(mock.Tags) == 0 {
+		return true
+	}
+	for _, tag := range mock.Tags {
+		for _, active := range ys.Tags {
+			if tag == active {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -2,7 +2,7 @@ package mockdb
 
 import (
 	"errors"
-	"strings"
+	"fmt"
 
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/pkg/platform/yaml"
@@ -17,6 +17,7 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*yaml.NetworkTrafficDoc,
 		Kind:         mock.Kind,
 		Name:         mock.Name,
 		ConnectionID: mock.ConnectionID,
+		Tags:         mock.Tags,
 	}
 	switch mock.Kind {
 	case models.Mongo:
@@ -75,7 +76,8 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*yaml.NetworkTrafficDoc,
 			utils.LogError(logger, err, "failed to marshal the http input-output as yaml")
 			return nil, err
 		}
-	case models.GENERIC:
+	case models.GENERIC, models.UDP:
+		// the datagrams of the udp dependencies are kept like the byte streams of the generic mocks
 		genericSpec := models.GenericSchema{
 			Metadata:         mock.Spec.Metadata,
 			GenericRequests:  mock.Spec.GenericRequests,
@@ -88,6 +90,32 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*yaml.NetworkTrafficDoc,
 			utils.LogError(logger, err, "failed to marshal the generic input-output as yaml")
 			return nil, err
 		}
+	case models.REDIS:
+		redisSpec := models.RedisSchema{
+			Metadata:         mock.Spec.Metadata,
+			RedisRequests:    mock.Spec.RedisRequests,
+			RedisResponses:   mock.Spec.RedisResponses,
+			ReqTimestampMock: mock.Spec.ReqTimestampMock,
+			ResTimestampMock: mock.Spec.ResTimestampMock,
+		}
+		err := yamlDoc.Spec.Encode(redisSpec)
+		if err != nil {
+			utils.LogError(logger, err, "failed to marshal the redis input-output as yaml")
+			return nil, err
+		}
+	case models.KAFKA:
+		kafkaSpec := models.KafkaSchema{
+			Metadata:         mock.Spec.Metadata,
+			KafkaRequests:    mock.Spec.KafkaRequests,
+			KafkaResponses:   mock.Spec.KafkaResponses,
+			ReqTimestampMock: mock.Spec.ReqTimestampMock,
+			ResTimestampMock: mock.Spec.ResTimestampMock,
+		}
+		err := yamlDoc.Spec.Encode(kafkaSpec)
+		if err != nil {
+			utils.LogError(logger, err, "failed to marshal the kafka input-output as yaml")
+			return nil, err
+		}
 	case models.Postgres:
 		// case models.PostgresV2:
 
@@ -164,20 +192,32 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*yaml.NetworkTrafficDoc,
 	return &yamlDoc, nil
 }
 
+// Decode converts a yaml doc into a mock, it fails for the kinds of mocks which aren't supported
+func Decode(yamlMock *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.Mock, error) {
+	mocks, err := decodeMocks([]*yaml.NetworkTrafficDoc{yamlMock}, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(mocks) == 0 {
+		return nil, fmt.Errorf("mock of unknown kind %q", yamlMock.Kind)
+	}
+	return mocks[0], nil
+}
+
 func decodeMocks(yamlMocks []*yaml.NetworkTrafficDoc, logger *zap.Logger) ([]*models.Mock, error) {
 	mocks := []*models.Mock{}
 
 	for _, m := range yamlMocks {
+		m, ok := convertMock(m, logger)
+		if !ok {
+			continue
+		}
 		mock := models.Mock{
 			Version:      m.Version,
 			Name:         m.Name,
 			Kind:         m.Kind,
 			ConnectionID: m.ConnectionID,
-		}
-		mockCheck := strings.Split(string(m.Kind), "-")
-		if len(mockCheck) > 1 {
-			logger.Debug("This dependency does not belong to open source version, will be skipped", zap.String("mock kind:", string(m.Kind)))
-			continue
+			Tags:         m.Tags,
 		}
 		switch m.Kind {
 		case models.HTTP:
@@ -222,7 +262,7 @@ func decodeMocks(yamlMocks []*yaml.NetworkTrafficDoc, logger *zap.Logger) ([]*mo
 				ReqTimestampMock: grpcSpec.ReqTimestampMock,
 				ResTimestampMock: grpcSpec.ResTimestampMock,
 			}
-		case models.GENERIC:
+		case models.GENERIC, models.UDP:
 			genericSpec := models.GenericSchema{}
 			err := m.Spec.Decode(&genericSpec)
 			if err != nil {
@@ -236,6 +276,34 @@ func decodeMocks(yamlMocks []*yaml.NetworkTrafficDoc, logger *zap.Logger) ([]*mo
 				ReqTimestampMock: genericSpec.ReqTimestampMock,
 				ResTimestampMock: genericSpec.ResTimestampMock,
 			}
+		case models.REDIS:
+			redisSpec := models.RedisSchema{}
+			err := m.Spec.Decode(&redisSpec)
+			if err != nil {
+				utils.LogError(logger, err, "failed to unmarshal a yaml doc into redis mock", zap.Any("mock name", m.Name))
+				return nil, err
+			}
+			mock.Spec = models.MockSpec{
+				Metadata:         redisSpec.Metadata,
+				RedisRequests:    redisSpec.RedisRequests,
+				RedisResponses:   redisSpec.RedisResponses,
+				ReqTimestampMock: redisSpec.ReqTimestampMock,
+				ResTimestampMock: redisSpec.ResTimestampMock,
+			}
+		case models.KAFKA:
+			kafkaSpec := models.KafkaSchema{}
+			err := m.Spec.Decode(&kafkaSpec)
+			if err != nil {
+				utils.LogError(logger, err, "failed to unmarshal a yaml doc into kafka mock", zap.Any("mock name", m.Name))
+				return nil, err
+			}
+			mock.Spec = models.MockSpec{
+				Metadata:         kafkaSpec.Metadata,
+				KafkaRequests:    kafkaSpec.KafkaRequests,
+				KafkaResponses:   kafkaSpec.KafkaResponses,
+				ReqTimestampMock: kafkaSpec.ReqTimestampMock,
+				ResTimestampMock: kafkaSpec.ResTimestampMock,
+			}
 
 		case models.Postgres:
 			// case models.PostgresV2:
@@ -2,10 +2,16 @@
 package reportdb
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"go.keploy.io/server/v2/pkg/models"
@@ -107,3 +113,112 @@ func (fe *TestReport) InsertReport(ctx context.Context, testRunID string, testSe
 	}
 	return nil
 }
+
+// InsertRunReport writes the report of the whole test run as yaml and json next to the reports of its test sets
+func (fe *TestReport) InsertRunReport(ctx context.Context, testRunID string, report *models.TestRunReport) error {
+	reportPath := filepath.Join(fe.Path, testRunID)
+	data, err := yamlLib.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("%s failed to marshal document to yaml. error: %s", utils.Emoji, err.Error())
+	}
+	err = yaml.WriteFile(ctx, fe.Logger, reportPath, "run-report", data, false)
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to write the run report to yaml", zap.Any("testRun", testRunID))
+		return err
+	}
+	data, err = json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s failed to marshal document to json. error: %s", utils.Emoji, err.Error())
+	}
+	err = os.WriteFile(filepath.Join(reportPath, "run-report.json"), data, 0644)
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to write the run report to json", zap.Any("testRun", testRunID))
+		return err
+	}
+	return nil
+}
+
+// InsertCrashBundle writes the crash bundle of the test set in a directory of the test run, along with the
+// stderr of the app as a log, and returns the directory
+func (fe *TestReport) InsertCrashBundle(ctx context.Context, testRunID string, testSetID string, bundle *models.CrashBundle) (string, error) {
+	bundlePath := filepath.Join(fe.Path, testRunID, testSetID+"-crash")
+	data, err := yamlLib.Marshal(bundle)
+	if err != nil {
+		return "", fmt.Errorf("%s failed to marshal document to yaml. error: %s", utils.Emoji, err.Error())
+	}
+	err = yaml.WriteFile(ctx, fe.Logger, bundlePath, "crash", data, false)
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to write the crash bundle to yaml", zap.Any("testSet", testSetID))
+		return "", err
+	}
+	if len(bundle.Stderr) > 0 {
+		err = os.WriteFile(filepath.Join(bundlePath, "stderr.log"), []byte(strings.Join(bundle.Stderr, "\n")+"\n"), 0644)
+		if err != nil {
+			utils.LogError(fe.Logger, err, "failed to write the stderr of the app", zap.Any("testSet", testSetID))
+			return "", err
+		}
+	}
+	return bundlePath, nil
+}
+
+// InsertHTMLReport writes the html report of the test run next to its reports and returns its path
+func (fe *TestReport) InsertHTMLReport(_ context.Context, testRunID string, html []byte) (string, error) {
+	reportPath, err := yaml.ValidatePath(filepath.Join(fe.Path, testRunID))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(reportPath, fs.ModePerm); err != nil {
+		return "", fmt.Errorf("%s failed to create the directory of the test run. error: %s", utils.Emoji, err.Error())
+	}
+	file := filepath.Join(reportPath, "report.html")
+	err = os.WriteFile(file, html, 0644)
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to write the html report", zap.Any("testRun", testRunID))
+		return "", err
+	}
+	return file, nil
+}
+
+// ExportReports writes a zip archive of the report files of the test run, in every format they were written in
+func (fe *TestReport) ExportReports(ctx context.Context, testRunID string, w io.Writer) error {
+	reportPath, err := yaml.ValidatePath(filepath.Join(fe.Path, testRunID))
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	err = filepath.WalkDir(reportPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name, err := filepath.Rel(reportPath, path)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				utils.LogError(fe.Logger, err, "failed to close the report file", zap.String("file", path))
+			}
+		}()
+		entry, err := zw.Create(filepath.ToSlash(filepath.Join(testRunID, name)))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, file)
+		return err
+	})
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to archive the reports", zap.String("testRun", testRunID))
+		return err
+	}
+	return zw.Close()
+}
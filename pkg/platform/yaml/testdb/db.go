@@ -41,6 +41,7 @@ func (ts *TestYaml) InsertTestCase(ctx context.Context, tc *models.TestCase, tes
 	} else {
 		tcsName = tc.Name
 	}
+	tc.Name = tcsName
 	yamlTc, err := EncodeTestcase(*tc, ts.logger)
 	if err != nil {
 		return err
@@ -59,6 +60,39 @@ func (ts *TestYaml) InsertTestCase(ctx context.Context, tc *models.TestCase, tes
 	return nil
 }
 
+// UpdateTestCase overwrites the test case of the test set which has the same name
+func (ts *TestYaml) UpdateTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error {
+	tcsPath := filepath.Join(ts.TcsPath, testSetID, "tests")
+	yamlTc, err := EncodeTestcase(*tc, ts.logger)
+	if err != nil {
+		return err
+	}
+	data, err := yamlLib.Marshal(&yamlTc)
+	if err != nil {
+		return err
+	}
+	err = yaml.WriteFile(ctx, ts.logger, tcsPath, tc.Name, data, false)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to write testcase yaml file")
+		return err
+	}
+	return nil
+}
+
+// DeleteTestCase removes the test case from the test set
+func (ts *TestYaml) DeleteTestCase(_ context.Context, testSetID string, name string) error {
+	path, err := yaml.ValidatePath(filepath.Join(ts.TcsPath, testSetID, "tests", name+".yaml"))
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to delete the testcase yaml file", zap.String("path", path))
+		return err
+	}
+	return nil
+}
+
 func (ts *TestYaml) GetAllTestSetIDs(ctx context.Context) ([]string, error) {
 	return yaml.ReadSessionIndices(ctx, ts.TcsPath, ts.logger)
 }
@@ -112,7 +146,98 @@ func (ts *TestYaml) GetTestCases(ctx context.Context, testSetID string) ([]*mode
 		tcs = append(tcs, tc)
 	}
 	sort.SliceStable(tcs, func(i, j int) bool {
-		return tcs[i].HTTPReq.Timestamp.Before(tcs[j].HTTPReq.Timestamp)
+		return startedAt(tcs[i]).Before(startedAt(tcs[j]))
 	})
+
+	// the test cases named after their content are ordered by the index of the test set
+	index, err := ts.getIndex(ctx, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	if len(index) > 0 {
+		position := func(name string) int {
+			if p, ok := index[name]; ok {
+				return p
+			}
+			return len(index)
+		}
+		sort.SliceStable(tcs, func(i, j int) bool {
+			return position(tcs[i].Name) < position(tcs[j].Name)
+		})
+	}
 	return tcs, nil
 }
+
+// InsertIndex writes the names of the test cases of the test set in the order they were captured
+func (ts *TestYaml) InsertIndex(ctx context.Context, testSetID string, names []string) error {
+	data, err := yamlLib.Marshal(names)
+	if err != nil {
+		return err
+	}
+	return yaml.WriteFile(ctx, ts.logger, filepath.Join(ts.TcsPath, testSetID), "index", data, false)
+}
+
+// Sync flushes the test cases of the test set to the disk
+func (ts *TestYaml) Sync(ctx context.Context, testSetID string, names []string) error {
+	return yaml.SyncFiles(ctx, ts.logger, filepath.Join(ts.TcsPath, testSetID, "tests"), names)
+}
+
+// getIndex returns the position of each test case in the index of the test set, if it has one
+func (ts *TestYaml) getIndex(ctx context.Context, testSetID string) (map[string]int, error) {
+	path := filepath.Join(ts.TcsPath, testSetID)
+	indexPath, err := yaml.ValidatePath(filepath.Join(path, "index.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(indexPath); err != nil {
+		return nil, nil
+	}
+	data, err := yaml.ReadFile(ctx, ts.logger, path, "index")
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to read the test case index", zap.String("testSetID", testSetID))
+		return nil, err
+	}
+	var names []string
+	err = yamlLib.Unmarshal(data, &names)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to unmarshall the test case index")
+		return nil, err
+	}
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	return index, nil
+}
+
+// InsertScenarios writes the scenarios of the test set
+func (ts *TestYaml) InsertScenarios(ctx context.Context, testSetID string, scenarios []models.Scenario) error {
+	data, err := yamlLib.Marshal(scenarios)
+	if err != nil {
+		return err
+	}
+	return yaml.WriteFile(ctx, ts.logger, filepath.Join(ts.TcsPath, testSetID), "scenarios", data, false)
+}
+
+// GetScenarios returns the scenarios of the test set, it's empty for the test sets recorded without sessions
+func (ts *TestYaml) GetScenarios(ctx context.Context, testSetID string) ([]models.Scenario, error) {
+	path := filepath.Join(ts.TcsPath, testSetID)
+	scenariosPath, err := yaml.ValidatePath(filepath.Join(path, "scenarios.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(scenariosPath); err != nil {
+		return nil, nil
+	}
+	data, err := yaml.ReadFile(ctx, ts.logger, path, "scenarios")
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to read the scenarios", zap.String("testSetID", testSetID))
+		return nil, err
+	}
+	var scenarios []models.Scenario
+	err = yamlLib.Unmarshal(data, &scenarios)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the scenarios. error: %v", err.Error())
+	}
+	return scenarios, nil
+}
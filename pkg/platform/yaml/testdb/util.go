@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
@@ -27,6 +28,8 @@ func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*yaml.NetworkTraffi
 		Kind:    tc.Kind,
 		Name:    tc.Name,
 		Curl:    curl,
+		Skip:    tc.Skip,
+		Xfail:   tc.Xfail,
 	}
 	// find noisy fields
 	m, err := FlattenHTTPResponse(pkg.ToHTTPHeader(tc.HTTPResp.Header), tc.HTTPResp.Body)
@@ -57,6 +60,7 @@ func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*yaml.NetworkTraffi
 		err := doc.Spec.Encode(models.HTTPSchema{
 			Request:  tc.HTTPReq,
 			Response: tc.HTTPResp,
+			Verify:   tc.Verify,
 			Created:  tc.Created,
 			Assertions: map[string]interface{}{
 				"noise": noise,
@@ -66,6 +70,61 @@ func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*yaml.NetworkTraffi
 			utils.LogError(logger, err, "failed to encode testcase into a yaml doc")
 			return nil, err
 		}
+	case models.WEBSOCKET:
+		doc.Curl = ""
+		err := doc.Spec.Encode(models.WebSocketSchema{
+			Request:  tc.HTTPReq,
+			Response: tc.HTTPResp,
+			Frames:   tc.Frames,
+			Verify:   tc.Verify,
+			Created:  tc.Created,
+			Assertions: map[string]interface{}{
+				"noise": tc.Noise,
+			},
+		})
+		if err != nil {
+			utils.LogError(logger, err, "failed to encode the websocket testcase into a yaml doc")
+			return nil, err
+		}
+	case models.MESSAGE:
+		doc.Curl = ""
+		err := doc.Spec.Encode(models.MessageSchema{
+			Message: tc.Message,
+			Verify:  tc.Verify,
+			Created: tc.Created,
+		})
+		if err != nil {
+			utils.LogError(logger, err, "failed to encode the message testcase into a yaml doc")
+			return nil, err
+		}
+	case models.JOB:
+		doc.Curl = ""
+		err := doc.Spec.Encode(models.JobSchema{
+			Job:     tc.Job,
+			Verify:  tc.Verify,
+			Created: tc.Created,
+		})
+		if err != nil {
+			utils.LogError(logger, err, "failed to encode the job testcase into a yaml doc")
+			return nil, err
+		}
+	case models.GRPC_EXPORT:
+		doc.Curl = ""
+		err := doc.Spec.Encode(models.GrpcSpec{
+			GrpcReq:  tc.GrpcReq,
+			GrpcResp: tc.GrpcResp,
+			// the grpc testcases keep the times of the call in the ones of the http request and response, which
+			// bound the window of their mocks
+			ReqTimestampMock: tc.HTTPReq.Timestamp,
+			ResTimestampMock: tc.HTTPResp.Timestamp,
+			Assertions: map[string]interface{}{
+				"noise": tc.Noise,
+			},
+		})
+		if err != nil {
+			utils.LogError(logger, err, "failed to encode the grpc testcase into a yaml doc")
+			return nil, err
+		}
 	default:
 		utils.LogError(logger, nil, "failed to marshal the testcase into yaml due to invalid kind of testcase")
 		return nil, errors.New("type of testcases is invalid")
@@ -230,6 +289,8 @@ func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.T
 		Kind:    yamlTestcase.Kind,
 		Name:    yamlTestcase.Name,
 		Curl:    yamlTestcase.Curl,
+		Skip:    yamlTestcase.Skip,
+		Xfail:   yamlTestcase.Xfail,
 	}
 	switch tc.Kind {
 	case models.HTTP:
@@ -242,20 +303,8 @@ func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.T
 		tc.Created = httpSpec.Created
 		tc.HTTPReq = httpSpec.Request
 		tc.HTTPResp = httpSpec.Response
-		tc.Noise = map[string][]string{}
-		switch reflect.ValueOf(httpSpec.Assertions["noise"]).Kind() {
-		case reflect.Map:
-			for k, v := range httpSpec.Assertions["noise"].(map[string]interface{}) {
-				tc.Noise[k] = []string{}
-				for _, val := range v.([]interface{}) {
-					tc.Noise[k] = append(tc.Noise[k], val.(string))
-				}
-			}
-		case reflect.Slice:
-			for _, v := range httpSpec.Assertions["noise"].([]interface{}) {
-				tc.Noise[v.(string)] = []string{}
-			}
-		}
+		tc.Verify = httpSpec.Verify
+		tc.Noise = decodeNoise(httpSpec.Assertions)
 	// unmarshal its mocks from yaml docs to go struct
 	case models.GRPC_EXPORT:
 		grpcSpec := models.GrpcSpec{}
@@ -266,9 +315,79 @@ func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.T
 		}
 		tc.GrpcReq = grpcSpec.GrpcReq
 		tc.GrpcResp = grpcSpec.GrpcResp
+		tc.HTTPReq.Timestamp = grpcSpec.ReqTimestampMock
+		tc.HTTPResp.Timestamp = grpcSpec.ResTimestampMock
+		tc.Noise = decodeNoise(grpcSpec.Assertions)
+	case models.WEBSOCKET:
+		wsSpec := models.WebSocketSchema{}
+		err := yamlTestcase.Spec.Decode(&wsSpec)
+		if err != nil {
+			utils.LogError(logger, err, "failed to unmarshal a yaml doc into the websocket testcase")
+			return nil, err
+		}
+		tc.Created = wsSpec.Created
+		tc.HTTPReq = wsSpec.Request
+		tc.HTTPResp = wsSpec.Response
+		tc.Frames = wsSpec.Frames
+		tc.Verify = wsSpec.Verify
+		tc.Noise = decodeNoise(wsSpec.Assertions)
+	case models.MESSAGE:
+		messageSpec := models.MessageSchema{}
+		err := yamlTestcase.Spec.Decode(&messageSpec)
+		if err != nil {
+			utils.LogError(logger, err, "failed to unmarshal a yaml doc into the message testcase")
+			return nil, err
+		}
+		tc.Created = messageSpec.Created
+		tc.Message = messageSpec.Message
+		tc.Verify = messageSpec.Verify
+		tc.Noise = map[string][]string{}
+	case models.JOB:
+		jobSpec := models.JobSchema{}
+		err := yamlTestcase.Spec.Decode(&jobSpec)
+		if err != nil {
+			utils.LogError(logger, err, "failed to unmarshal a yaml doc into the job testcase")
+			return nil, err
+		}
+		tc.Created = jobSpec.Created
+		tc.Job = jobSpec.Job
+		tc.Verify = jobSpec.Verify
+		tc.Noise = map[string][]string{}
 	default:
 		utils.LogError(logger, nil, "failed to unmarshal yaml doc of unknown type", zap.Any("type of yaml doc", tc.Kind))
 		return nil, errors.New("yaml doc of unknown type")
 	}
 	return &tc, nil
 }
+
+// startedAt returns when the test case was triggered, by its request, by the message consumed or by the job
+func startedAt(tc *models.TestCase) time.Time {
+	switch tc.Kind {
+	case models.MESSAGE:
+		return tc.Message.Consumed
+	case models.JOB:
+		return tc.Job.Started
+	default:
+		return tc.HTTPReq.Timestamp
+	}
+}
+
+// decodeNoise reads the noise of the assertions of a testcase, either a list of fields or a map of the fields to
+// the regular expressions of their noisy values
+func decodeNoise(assertions map[string]interface{}) map[string][]string {
+	noise := map[string][]string{}
+	switch reflect.ValueOf(assertions["noise"]).Kind() {
+	case reflect.Map:
+		for k, v := range assertions["noise"].(map[string]interface{}) {
+			noise[k] = []string{}
+			for _, val := range v.([]interface{}) {
+				noise[k] = append(noise[k], val.(string))
+			}
+		}
+	case reflect.Slice:
+		for _, v := range assertions["noise"].([]interface{}) {
+			noise[v.(string)] = []string{}
+		}
+	}
+	return noise
+}
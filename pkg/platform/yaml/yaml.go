@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -22,6 +23,9 @@ type NetworkTrafficDoc struct {
 	Spec         yamlLib.Node   `json:"spec" yaml:"spec"`
 	Curl         string         `json:"curl" yaml:"curl,omitempty"`
 	ConnectionID string         `json:"connectionId" yaml:"connectionId,omitempty"`
+	Skip         bool           `json:"skip" yaml:"skip,omitempty"`
+	Xfail        string         `json:"xfail" yaml:"xfail,omitempty"`
+	Tags         []string       `json:"tags" yaml:"tags,omitempty"`
 }
 
 // ctxReader wraps an io.Reader with a context for cancellation support
@@ -100,6 +104,33 @@ func WriteFile(ctx context.Context, logger *zap.Logger, path, fileName string, d
 	return nil
 }
 
+// SyncFiles flushes the yaml files of the directory, and the directory entries, to the disk
+func SyncFiles(ctx context.Context, logger *zap.Logger, path string, names []string) error {
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := syncFile(logger, filepath.Join(path, name+".yaml"))
+		if err != nil {
+			return err
+		}
+	}
+	return syncFile(logger, path)
+}
+
+func syncFile(logger *zap.Logger, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			utils.LogError(logger, err, "failed to close file", zap.String("file", path))
+		}
+	}()
+	return file.Sync()
+}
+
 func ReadFile(ctx context.Context, logger *zap.Logger, path, name string) ([]byte, error) {
 	filePath := filepath.Join(path, name+".yaml")
 	file, err := os.Open(filePath)
@@ -172,7 +203,8 @@ func ReadSessionIndices(_ context.Context, path string, Logger *zap.Logger) ([]s
 	}
 
 	for _, v := range files {
-		if v.Name() != "reports" && v.Name() != "testReports" {
+		// the hidden directories aren't test sets, e.g. the backup of the migrated v1 recordings
+		if v.Name() != "reports" && v.Name() != "testReports" && v.Name() != models.GlobalMocksDir && !strings.HasPrefix(v.Name(), ".") {
 			indices = append(indices, v.Name())
 		}
 	}
@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// CookieValue returns the value of the named cookie in a Cookie or a Set-Cookie header, whose values are
+// joined by commas once recorded
+func CookieValue(header, name string) string {
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ';' || r == ',' }) {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && k == name {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+// HeaderValue returns the value of the header, looked up case insensitively
+func HeaderValue(header map[string]string, name string) string {
+	for k, v := range header {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// ResponseField returns the field of the response at the path: status, header.<name>, cookie.<name> set by
+// the response or body.<path> of a json body, whose keys are separated by dots and array indices are numbers
+func ResponseField(resp *models.HTTPResp, path string) (string, bool) {
+	if path == "status" {
+		return strconv.Itoa(resp.StatusCode), true
+	}
+	kind, rest, ok := strings.Cut(path, ".")
+	if !ok {
+		return "", false
+	}
+	switch kind {
+	case "header":
+		v := HeaderValue(resp.Header, rest)
+		return v, v != ""
+	case "cookie":
+		v := CookieValue(HeaderValue(resp.Header, "Set-Cookie"), rest)
+		return v, v != ""
+	case "body":
+		var body interface{}
+		if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+			return "", false
+		}
+		for _, key := range strings.Split(rest, ".") {
+			switch v := body.(type) {
+			case map[string]interface{}:
+				if body, ok = v[key]; !ok {
+					return "", false
+				}
+			case []interface{}:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(v) {
+					return "", false
+				}
+				body = v[i]
+			default:
+				return "", false
+			}
+		}
+		if s, ok := body.(string); ok {
+			return s, true
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+	return "", false
+}
+
+// JSONStrings returns the string values of the json body by their paths, in the format of ResponseField
+// without the body prefix
+func JSONStrings(body string) map[string]string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return nil
+	}
+	values := map[string]string{}
+	collectJSONStrings("", v, values)
+	return values
+}
+
+func collectJSONStrings(path string, v interface{}, values map[string]string) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			collectJSONStrings(join(k), child, values)
+		}
+	case []interface{}:
+		for i, child := range v {
+			collectJSONStrings(join(strconv.Itoa(i)), child, values)
+		}
+	case string:
+		if path != "" {
+			values[path] = v
+		}
+	}
+}
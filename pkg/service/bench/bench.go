@@ -0,0 +1,290 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/core/proxy"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// ids of the proxy sessions of the benchmark
+const (
+	recordSession uint64 = 1
+	mockSession   uint64 = 2
+)
+
+type bencher struct {
+	logger *zap.Logger
+	cfg    config.Config
+}
+
+func New(logger *zap.Logger, cfg config.Config) Service {
+	return &bencher{
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// Proxy sends the traffic directly to a synthetic backend, then through the proxy while recording it
+// and finally through the proxy mocking it from the recorded mocks with the backend stopped.
+func (b *bencher) Proxy(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Requests <= 0 || opts.Concurrency <= 0 {
+		return nil, errors.New("the number of requests and the concurrency must be positive")
+	}
+	var backend backend
+	switch opts.Protocol {
+	case ProtocolHTTP:
+		backend = newHTTPBackend(b.logger, opts.PayloadSize)
+	case ProtocolMongo:
+		backend = newMongoBackend(b.logger, opts.PayloadSize)
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q, supported: %s, %s", opts.Protocol, ProtocolHTTP, ProtocolMongo)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		err := g.Wait()
+		if err != nil {
+			utils.LogError(b.logger, err, "failed to stop the benchmark")
+		}
+	}()
+
+	backendAddr, err := backend.start(ctx)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to start the synthetic backend")
+		return nil, err
+	}
+
+	report := &Report{
+		Protocol:    opts.Protocol,
+		Requests:    opts.Requests,
+		Concurrency: opts.Concurrency,
+		PayloadSize: opts.PayloadSize,
+	}
+
+	b.logger.Info("sending the traffic directly to the backend", zap.String("protocol", opts.Protocol), zap.Int("requests", opts.Requests))
+	direct := b.load(ctx, ModeDirect, backend, backendAddr, opts)
+	report.Modes = append(report.Modes, direct)
+
+	dest, err := newDestination(backendAddr)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to resolve the address of the backend")
+		return nil, err
+	}
+	proxyAddr, prx, err := b.startProxy(ctx, dest)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to start the proxy")
+		return nil, err
+	}
+
+	// record the traffic, the mocks are sent by the proxy after the responses so they are collected alongside
+	mockCh := make(chan *models.Mock, opts.Requests)
+	var mocks []*models.Mock
+	var mocksMu sync.Mutex
+	g.Go(func() error {
+		defer utils.Recover(b.logger)
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case mock, ok := <-mockCh:
+				if !ok {
+					return nil
+				}
+				mocksMu.Lock()
+				mock.Name = fmt.Sprintf("mock-%d", len(mocks))
+				mocks = append(mocks, mock)
+				mocksMu.Unlock()
+			}
+		}
+	})
+	err = prx.Record(ctx, recordSession, mockCh, models.OutgoingOptions{})
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to start recording the outgoing calls")
+		return nil, err
+	}
+	dest.setSession(recordSession)
+	b.logger.Info("sending the traffic through the proxy in record mode")
+	recorded := b.load(ctx, ModeRecord, backend, proxyAddr, opts)
+	report.Modes = append(report.Modes, recorded)
+
+	recordedMocks := b.waitForMocks(ctx, &mocksMu, &mocks, recorded.Requests-recorded.Errors)
+
+	// the backend is stopped so that the mocked calls can only be served by the proxy
+	backend.stop()
+	err = prx.Mock(ctx, mockSession, models.OutgoingOptions{})
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to start mocking the outgoing calls")
+		return nil, err
+	}
+	err = prx.SetMocks(ctx, mockSession, recordedMocks, nil)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to set the recorded mocks")
+		return nil, err
+	}
+	dest.setSession(mockSession)
+	b.logger.Info("sending the traffic through the proxy in mock mode", zap.Int("mocks", len(recordedMocks)))
+	mocked := b.load(ctx, ModeMock, backend, proxyAddr, opts)
+	report.Modes = append(report.Modes, mocked)
+
+	for i := range report.Modes {
+		if report.Modes[i].Mode != ModeDirect {
+			report.Modes[i].AddedLatency = report.Modes[i].P50 - direct.P50
+		}
+	}
+	return report, ctx.Err()
+}
+
+// startProxy starts the keploy proxy on free ports with the outgoing calls routed to the destination
+func (b *bencher) startProxy(ctx context.Context, dest *destination) (string, *proxy.Proxy, error) {
+	proxyPort, err := freePort()
+	if err != nil {
+		return "", nil, err
+	}
+	dnsPort, err := freePort()
+	if err != nil {
+		return "", nil, err
+	}
+	cfg := b.cfg
+	cfg.ProxyPort = proxyPort
+	cfg.DNSPort = dnsPort
+	cfg.UnixSockets = nil
+	cfg.IgnoreRules = nil
+	cfg.Processes = config.ProcessFilter{}
+	prx := proxy.New(b.logger, dest, cfg)
+	err = prx.StartProxy(ctx, core.ProxyOptions{Port: proxyPort, DNSPort: dnsPort})
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the proxy listens in the background, wait for it to accept the connections
+	addr := fmt.Sprintf("127.0.0.1:%d", proxyPort)
+	for i := 0; i < 50; i++ {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			if err := conn.Close(); err != nil {
+				b.logger.Debug("failed to close the probe connection to the proxy", zap.Error(err))
+			}
+			return addr, prx, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return "", nil, fmt.Errorf("proxy didn't start listening on %s", addr)
+}
+
+// waitForMocks waits for the mocks of the recorded calls and returns a copy of them
+func (b *bencher) waitForMocks(ctx context.Context, mu *sync.Mutex, mocks *[]*models.Mock, expected int) []*models.Mock {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		count := len(*mocks)
+		mu.Unlock()
+		if count >= expected || time.Now().After(deadline) || ctx.Err() != nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(*mocks) < expected {
+		b.logger.Warn("not all the calls were recorded as mocks", zap.Int("expected", expected), zap.Int("recorded", len(*mocks)))
+	}
+	return append([]*models.Mock{}, *mocks...)
+}
+
+// load sends the requests to the address from the concurrent clients and measures their latencies
+func (b *bencher) load(ctx context.Context, mode string, backend backend, addr string, opts Options) ModeResult {
+	var next int64
+	var errCount int64
+	latencies := make([]time.Duration, opts.Requests)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer utils.Recover(b.logger)
+			defer wg.Done()
+			client := backend.client(addr)
+			defer client.close()
+			for {
+				i := atomic.AddInt64(&next, 1) - 1
+				if i >= int64(opts.Requests) || ctx.Err() != nil {
+					return
+				}
+				reqStart := time.Now()
+				err := client.do(ctx)
+				latencies[i] = time.Since(reqStart)
+				if err != nil {
+					atomic.AddInt64(&errCount, 1)
+					latencies[i] = -1
+					b.logger.Debug("benchmark request failed", zap.String("mode", mode), zap.Error(err))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result := ModeResult{
+		Mode:     mode,
+		Requests: opts.Requests,
+		Errors:   int(errCount),
+	}
+	succeeded := make([]time.Duration, 0, len(latencies))
+	var total time.Duration
+	for _, l := range latencies {
+		if l >= 0 {
+			succeeded = append(succeeded, l)
+			total += l
+		}
+	}
+	if len(succeeded) == 0 {
+		return result
+	}
+	sort.Slice(succeeded, func(i, j int) bool { return succeeded[i] < succeeded[j] })
+	result.Throughput = float64(len(succeeded)) / elapsed.Seconds()
+	result.Mean = total / time.Duration(len(succeeded))
+	result.P50 = percentile(succeeded, 50)
+	result.P99 = percentile(succeeded, 99)
+	return result
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, err
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if err := listener.Close(); err != nil {
+		return 0, err
+	}
+	return uint32(port), nil
+}
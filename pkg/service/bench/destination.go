@@ -0,0 +1,55 @@
+package bench
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	"go.keploy.io/server/v2/pkg/core"
+)
+
+// destination routes all the connections of the proxy to the backend, in place of the eBPF hooks
+type destination struct {
+	ip4     uint32
+	port    uint32
+	session atomic.Uint64
+}
+
+func newDestination(addr string) (*destination, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ip4 := net.ParseIP(host).To4()
+	if ip4 == nil {
+		return nil, errors.New("the backend must listen on an ipv4 address")
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, err
+	}
+	return &destination{
+		ip4:  binary.BigEndian.Uint32(ip4),
+		port: uint32(port),
+	}, nil
+}
+
+func (d *destination) setSession(id uint64) {
+	d.session.Store(id)
+}
+
+func (d *destination) Get(_ context.Context, _ uint16) (*core.NetworkAddress, error) {
+	return &core.NetworkAddress{
+		AppID:    d.session.Load(),
+		Version:  4,
+		IPv4Addr: d.ip4,
+		Port:     d.port,
+	}, nil
+}
+
+func (d *destination) Delete(_ context.Context, _ uint16) error {
+	return nil
+}
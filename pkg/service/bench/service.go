@@ -0,0 +1,53 @@
+// Package bench measures the overhead added by the keploy proxy to the outgoing calls of an application,
+// by driving synthetic traffic to a local backend directly and through the proxy in record and mock modes.
+package bench
+
+import (
+	"context"
+	"time"
+)
+
+type Service interface {
+	Proxy(ctx context.Context, opts Options) (*Report, error)
+}
+
+const (
+	ProtocolHTTP  = "http"
+	ProtocolMongo = "mongo"
+)
+
+type Options struct {
+	// Protocol of the synthetic traffic: http or mongo
+	Protocol    string
+	Requests    int
+	Concurrency int
+	// PayloadSize is the size in bytes of the body of the requests and responses
+	PayloadSize int
+}
+
+type Report struct {
+	Protocol    string       `json:"protocol"`
+	Requests    int          `json:"requests"`
+	Concurrency int          `json:"concurrency"`
+	PayloadSize int          `json:"payloadSize"`
+	Modes       []ModeResult `json:"modes"`
+}
+
+// ModeResult is the outcome of the traffic sent directly to the backend or through the proxy in a mode
+type ModeResult struct {
+	Mode       string        `json:"mode"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	Throughput float64       `json:"throughput"` // successful requests per second
+	Mean       time.Duration `json:"mean"`
+	P50        time.Duration `json:"p50"`
+	P99        time.Duration `json:"p99"`
+	// AddedLatency is the median latency added over the direct calls
+	AddedLatency time.Duration `json:"addedLatency"`
+}
+
+const (
+	ModeDirect = "direct"
+	ModeRecord = "record"
+	ModeMock   = "mock"
+)
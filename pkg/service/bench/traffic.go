@@ -0,0 +1,284 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+	"go.uber.org/zap"
+
+	"go.keploy.io/server/v2/utils"
+)
+
+// backend is the synthetic dependency the traffic of the benchmark is sent to
+type backend interface {
+	start(ctx context.Context) (string, error)
+	stop()
+	client(addr string) client
+}
+
+type client interface {
+	do(ctx context.Context) error
+	close()
+}
+
+func payload(size int) string {
+	return strings.Repeat("k", size)
+}
+
+// httpBackend echoes the json body of the requests
+type httpBackend struct {
+	logger *zap.Logger
+	body   []byte
+	server *http.Server
+}
+
+func newHTTPBackend(logger *zap.Logger, payloadSize int) *httpBackend {
+	return &httpBackend{
+		logger: logger,
+		body:   []byte(fmt.Sprintf(`{"data":%q}`, payload(payloadSize))),
+	}
+}
+
+func (h *httpBackend) start(ctx context.Context) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	h.server = &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body)
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		defer utils.Recover(h.logger)
+		err := h.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			utils.LogError(h.logger, err, "synthetic http backend stopped")
+		}
+	}()
+	go func() {
+		defer utils.Recover(h.logger)
+		<-ctx.Done()
+		h.stop()
+	}()
+	return listener.Addr().String(), nil
+}
+
+func (h *httpBackend) stop() {
+	if err := h.server.Close(); err != nil {
+		h.logger.Debug("failed to stop the synthetic http backend", zap.Error(err))
+	}
+}
+
+func (h *httpBackend) client(addr string) client {
+	return &httpClient{
+		url:  "http://" + addr + "/bench",
+		body: h.body,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// the proxy env vars must not route the benchmark elsewhere
+			Transport: &http.Transport{Proxy: nil, MaxIdleConnsPerHost: 1},
+		},
+	}
+}
+
+type httpClient struct {
+	url    string
+	body   []byte
+	client *http.Client
+}
+
+func (c *httpClient) do(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(c.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if closeErr := resp.Body.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, c.body) {
+		return fmt.Errorf("unexpected response with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *httpClient) close() {
+	c.client.CloseIdleConnections()
+}
+
+// mongoBackend answers the OP_MSG commands with an ok document holding the payload
+type mongoBackend struct {
+	logger   *zap.Logger
+	payload  string
+	listener net.Listener
+}
+
+func newMongoBackend(logger *zap.Logger, payloadSize int) *mongoBackend {
+	return &mongoBackend{
+		logger:  logger,
+		payload: payload(payloadSize),
+	}
+}
+
+func (m *mongoBackend) start(ctx context.Context) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	m.listener = listener
+	go func() {
+		defer utils.Recover(m.logger)
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer utils.Recover(m.logger)
+				m.serve(conn)
+			}()
+		}
+	}()
+	go func() {
+		defer utils.Recover(m.logger)
+		<-ctx.Done()
+		m.stop()
+	}()
+	return listener.Addr().String(), nil
+}
+
+func (m *mongoBackend) serve(conn net.Conn) {
+	defer func() {
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			m.logger.Debug("failed to close the synthetic mongo connection", zap.Error(err))
+		}
+	}()
+	reply, err := bson.Marshal(bson.D{{Key: "ok", Value: 1.0}, {Key: "payload", Value: m.payload}})
+	if err != nil {
+		utils.LogError(m.logger, err, "failed to encode the synthetic mongo reply")
+		return
+	}
+	for {
+		requestID, _, err := readOpMsg(conn)
+		if err != nil {
+			return
+		}
+		_, err = conn.Write(opMsg(wiremessage.NextRequestID(), requestID, reply))
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (m *mongoBackend) stop() {
+	if err := m.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		m.logger.Debug("failed to stop the synthetic mongo backend", zap.Error(err))
+	}
+}
+
+func (m *mongoBackend) client(addr string) client {
+	return &mongoClient{logger: m.logger, addr: addr, payload: m.payload}
+}
+
+type mongoClient struct {
+	logger  *zap.Logger
+	addr    string
+	payload string
+	conn    net.Conn
+}
+
+func (c *mongoClient) do(_ context.Context) error {
+	if c.conn == nil {
+		conn, err := net.DialTimeout("tcp", c.addr, 10*time.Second)
+		if err != nil {
+			return err
+		}
+		c.conn = conn
+	}
+	cmd, err := bson.Marshal(bson.D{{Key: "ping", Value: 1}, {Key: "payload", Value: c.payload}, {Key: "$db", Value: "bench"}})
+	if err != nil {
+		return err
+	}
+	err = c.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if err != nil {
+		return err
+	}
+	requestID := wiremessage.NextRequestID()
+	_, err = c.conn.Write(opMsg(requestID, 0, cmd))
+	if err == nil {
+		var responseTo int32
+		_, responseTo, err = readOpMsg(c.conn)
+		if err == nil && responseTo != requestID {
+			err = fmt.Errorf("reply to the request %d received for the request %d", responseTo, requestID)
+		}
+	}
+	if err != nil {
+		// the connection can't be reused after a failed exchange
+		c.close()
+	}
+	return err
+}
+
+func (c *mongoClient) close() {
+	if c.conn == nil {
+		return
+	}
+	if err := c.conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		c.logger.Debug("failed to close the mongo connection", zap.Error(err))
+	}
+	c.conn = nil
+}
+
+// opMsg builds an OP_MSG wire message with the document as its body section
+func opMsg(requestID, responseTo int32, doc []byte) []byte {
+	idx, msg := wiremessage.AppendHeaderStart(nil, requestID, responseTo, wiremessage.OpMsg)
+	msg = wiremessage.AppendMsgFlags(msg, 0)
+	msg = wiremessage.AppendMsgSectionType(msg, wiremessage.SingleDocument)
+	msg = append(msg, doc...)
+	binary.LittleEndian.PutUint32(msg[idx:], uint32(len(msg)))
+	return msg
+}
+
+// readOpMsg reads a wire message and returns the request id and the response to of its header
+func readOpMsg(r io.Reader) (int32, int32, error) {
+	header := make([]byte, 16)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return 0, 0, err
+	}
+	length, requestID, responseTo, _, _, ok := wiremessage.ReadHeader(header)
+	if !ok || length < 16 {
+		return 0, 0, errors.New("malformed wire message header")
+	}
+	_, err = io.CopyN(io.Discard, r, int64(length-16))
+	if err != nil {
+		return 0, 0, err
+	}
+	return requestID, responseTo, nil
+}
@@ -0,0 +1,230 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+type differ struct {
+	logger *zap.Logger
+	path   string
+}
+
+func New(logger *zap.Logger, path string) Service {
+	return &differ{
+		logger: logger,
+		path:   path,
+	}
+}
+
+// testSetFiles holds the recorded test cases and mocks of a test set, by name
+type testSetFiles struct {
+	tests map[string][]byte
+	mocks map[string][]byte
+}
+
+func (d *differ) Diff(ctx context.Context, base string) (*Report, error) {
+	if _, err := os.Stat(d.path); err != nil {
+		utils.LogError(d.logger, err, "failed to find the keploy directory", zap.String("path", d.path))
+		return nil, err
+	}
+
+	current := map[string][]byte{}
+	err := filepath.WalkDir(d.path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(d.path, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !isRecorded(rel) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		current[rel] = data
+		return nil
+	})
+	if err != nil {
+		utils.LogError(d.logger, err, "failed to read the test sets of the working tree")
+		return nil, err
+	}
+
+	previous, err := d.readRef(ctx, base)
+	if err != nil {
+		utils.LogError(d.logger, err, "failed to read the test sets of the git ref", zap.String("ref", base))
+		return nil, err
+	}
+
+	currentSets, err := groupByTestSet(current)
+	if err != nil {
+		return nil, err
+	}
+	previousSets, err := groupByTestSet(previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the mocks of %s: %v", base, err)
+	}
+
+	ids := map[string]bool{}
+	for id := range currentSets {
+		ids[id] = true
+	}
+	for id := range previousSets {
+		ids[id] = true
+	}
+
+	report := &Report{Base: base, TestSets: []TestSetDiff{}}
+	for id := range ids {
+		cur, prev := currentSets[id], previousSets[id]
+		if cur == nil {
+			cur = &testSetFiles{}
+		}
+		if prev == nil {
+			prev = &testSetFiles{}
+		}
+		setDiff := TestSetDiff{
+			ID:    id,
+			Tests: compare(prev.tests, cur.tests),
+			Mocks: compare(prev.mocks, cur.mocks),
+		}
+		if setDiff.Tests.empty() && setDiff.Mocks.empty() {
+			continue
+		}
+		report.TestSets = append(report.TestSets, setDiff)
+	}
+	sort.Slice(report.TestSets, func(i, j int) bool {
+		return report.TestSets[i].ID < report.TestSets[j].ID
+	})
+	return report, nil
+}
+
+// readRef returns the recorded files of the keploy directory at the git ref
+func (d *differ) readRef(ctx context.Context, ref string) (map[string][]byte, error) {
+	out, err := d.git(ctx, "ls-tree", "-r", "-z", "--name-only", ref, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, rel := range strings.Split(string(out), "\x00") {
+		if rel == "" || !isRecorded(rel) {
+			continue
+		}
+		data, err := d.git(ctx, "show", ref+":./"+rel)
+		if err != nil {
+			return nil, err
+		}
+		files[rel] = data
+	}
+	return files, nil
+}
+
+func (d *differ) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = d.path
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// isRecorded reports whether the path relative to the keploy directory is a test case or a mock file
+func isRecorded(rel string) bool {
+	parts := strings.Split(rel, "/")
+	if strings.HasPrefix(parts[0], ".") {
+		return false
+	}
+	switch {
+	case len(parts) == 3 && parts[1] == "tests":
+		return strings.HasSuffix(parts[2], ".yaml")
+	case len(parts) == 2:
+		return parts[1] == "mocks.yaml"
+	}
+	return false
+}
+
+func groupByTestSet(files map[string][]byte) (map[string]*testSetFiles, error) {
+	sets := map[string]*testSetFiles{}
+	for rel, data := range files {
+		parts := strings.Split(rel, "/")
+		set, ok := sets[parts[0]]
+		if !ok {
+			set = &testSetFiles{tests: map[string][]byte{}, mocks: map[string][]byte{}}
+			sets[parts[0]] = set
+		}
+		if len(parts) == 3 {
+			set.tests[strings.TrimSuffix(parts[2], ".yaml")] = data
+			continue
+		}
+		mocks, err := splitMocks(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", rel, err)
+		}
+		set.mocks = mocks
+	}
+	return sets, nil
+}
+
+// splitMocks returns the documents of a mock file by the name of the mock
+func splitMocks(data []byte) (map[string][]byte, error) {
+	mocks := map[string][]byte{}
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := yamlLib.Marshal(&doc)
+		if err != nil {
+			return nil, err
+		}
+		mocks[doc.Name] = encoded
+	}
+	return mocks, nil
+}
+
+func compare(previous, current map[string][]byte) Changes {
+	var changes Changes
+	for name, data := range current {
+		prev, ok := previous[name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, name)
+		case !bytes.Equal(prev, data):
+			changes.Modified = append(changes.Modified, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Modified)
+	return changes
+}
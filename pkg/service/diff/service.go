@@ -0,0 +1,35 @@
+// Package diff compares the recorded test sets with the ones of a git ref.
+package diff
+
+import "context"
+
+type Service interface {
+	Diff(ctx context.Context, base string) (*Report, error)
+}
+
+// Report lists the test cases and mocks changed since the base ref, by test set
+type Report struct {
+	Base     string        `json:"base"`
+	TestSets []TestSetDiff `json:"testSets"`
+}
+
+type TestSetDiff struct {
+	ID    string  `json:"id"`
+	Tests Changes `json:"tests"`
+	Mocks Changes `json:"mocks"`
+}
+
+type Changes struct {
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+}
+
+func (c Changes) empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// HasChanges reports whether any test case or mock differs from the base ref
+func (r *Report) HasChanges() bool {
+	return len(r.TestSets) > 0
+}
@@ -0,0 +1,243 @@
+package gaps
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// methods are the keys of a path item of the spec which are operations, the others being its parameters,
+// summary and the like
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// pathParam matches the templated parameters of the paths of the spec, like {userId}
+var pathParam = regexp.MustCompile(`\{[^/}]+\}`)
+
+type analyzer struct {
+	logger *zap.Logger
+	testDB TestDB
+}
+
+func New(logger *zap.Logger, testDB TestDB) Service {
+	return &analyzer{
+		logger: logger,
+		testDB: testDB,
+	}
+}
+
+// spec holds the parts of an OpenAPI 3 or Swagger 2 document telling its operations, either in yaml or json
+type spec struct {
+	BasePath string `yaml:"basePath"`
+	Servers  []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths map[string]map[string]yamlLib.Node `yaml:"paths"`
+}
+
+type operation struct {
+	OperationID string `yaml:"operationId"`
+	RequestBody *struct {
+		Content map[string]yamlLib.Node `yaml:"content"`
+	} `yaml:"requestBody"`
+}
+
+// route is an operation of the spec with the pattern of the paths calling it
+type route struct {
+	op          *Operation
+	pattern     *regexp.Regexp
+	base        string // the path of the server the skeleton test case calls
+	contentType string
+}
+
+func (a *analyzer) Analyze(ctx context.Context, specPath string, scaffold bool, appURL string) (*Report, error) {
+	routes, err := a.readSpec(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	testSetIDs, err := a.testDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get the test sets")
+		return nil, err
+	}
+	for _, testSetID := range testSetIDs {
+		testCases, err := a.testDB.GetTestCases(ctx, testSetID)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to get the test cases", zap.String("testSet", testSetID))
+			return nil, err
+		}
+		for _, tc := range testCases {
+			if tc.Kind != models.HTTP {
+				continue
+			}
+			path := tc.HTTPReq.URL
+			if u, err := url.Parse(tc.HTTPReq.URL); err == nil {
+				path = u.Path
+			}
+			for _, r := range routes {
+				if strings.EqualFold(r.op.Method, string(tc.HTTPReq.Method)) && r.pattern.MatchString(path) {
+					r.op.TestCases++
+					break
+				}
+			}
+		}
+	}
+
+	report := &Report{Spec: specPath}
+	for _, r := range routes {
+		report.Operations = append(report.Operations, *r.op)
+		if r.op.TestCases > 0 {
+			report.Covered++
+		}
+	}
+	report.Total = len(report.Operations)
+
+	if scaffold && report.Covered < report.Total {
+		report.Scaffolded, err = a.scaffold(ctx, testSetIDs, routes, appURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func (a *analyzer) readSpec(specPath string) ([]route, error) {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to read the openapi spec", zap.String("spec", specPath))
+		return nil, err
+	}
+	// yaml is a superset of json, so both the formats of the spec are read alike
+	var doc spec
+	if err := yamlLib.Unmarshal(data, &doc); err != nil {
+		utils.LogError(a.logger, err, "failed to parse the openapi spec", zap.String("spec", specPath))
+		return nil, err
+	}
+	if len(doc.Paths) == 0 {
+		return nil, fmt.Errorf("found no paths in the openapi spec %s", specPath)
+	}
+
+	// the paths of the spec are relative to the path of its server, like /v1 of https://api.example.com/v1
+	base := strings.TrimSuffix(doc.BasePath, "/")
+	bases := map[string]bool{base: true}
+	for i, server := range doc.Servers {
+		if u, err := url.Parse(server.URL); err == nil {
+			bases[strings.TrimSuffix(u.Path, "/")] = true
+			if i == 0 && base == "" {
+				base = strings.TrimSuffix(u.Path, "/")
+			}
+		}
+	}
+	var prefixes []string
+	for base := range bases {
+		prefixes = append(prefixes, regexp.QuoteMeta(base))
+	}
+	sort.Strings(prefixes)
+	prefix := "(" + strings.Join(prefixes, "|") + ")"
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var routes []route
+	for _, path := range paths {
+		item := doc.Paths[path]
+		re, err := regexp.Compile("^" + prefix + pathPattern(path) + "/?$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s in the openapi spec: %w", path, err)
+		}
+		for _, method := range methods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op operation
+			if err := node.Decode(&op); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s in the openapi spec: %w", strings.ToUpper(method), path, err)
+			}
+			r := route{
+				op:      &Operation{Method: strings.ToUpper(method), Path: path, OperationID: op.OperationID},
+				pattern: re,
+				base:    base,
+			}
+			if op.RequestBody != nil {
+				for contentType := range op.RequestBody.Content {
+					if r.contentType == "" || contentType == "application/json" {
+						r.contentType = contentType
+					}
+				}
+			}
+			routes = append(routes, r)
+		}
+	}
+	return routes, nil
+}
+
+// pathPattern returns the pattern of the paths of a templated path of the spec, a parameter matching a segment
+func pathPattern(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	var sb strings.Builder
+	last := 0
+	for _, loc := range pathParam.FindAllStringIndex(path, -1) {
+		sb.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		sb.WriteString("[^/]+")
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(path[last:]))
+	return sb.String()
+}
+
+// scaffold writes a skeleton test case for each operation without one to a new test set. They are marked to be
+// skipped, as they have no recorded response, until their request and response are filled in.
+func (a *analyzer) scaffold(ctx context.Context, testSetIDs []string, routes []route, appURL string) (string, error) {
+	testSetID := pkg.NewID(testSetIDs, models.TestSetPattern)
+	now := time.Now()
+	for _, r := range routes {
+		if r.op.TestCases > 0 {
+			continue
+		}
+		header := map[string]string{}
+		if r.contentType != "" {
+			header["Content-Type"] = r.contentType
+		}
+		tc := &models.TestCase{
+			Version: models.GetVersion(),
+			Kind:    models.HTTP,
+			Created: now.Unix(),
+			HTTPReq: models.HTTPReq{
+				Method:     models.Method(r.op.Method),
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				URL:        strings.TrimSuffix(appURL, "/") + r.base + r.op.Path,
+				Header:     header,
+				Timestamp:  now,
+			},
+			HTTPResp: models.HTTPResp{
+				StatusCode: 200,
+				Header:     map[string]string{},
+				Timestamp:  now,
+			},
+			Noise: map[string][]string{},
+			Skip:  true,
+		}
+		if err := a.testDB.InsertTestCase(ctx, tc, testSetID); err != nil {
+			utils.LogError(a.logger, err, "failed to write the skeleton test case", zap.String("operation", r.op.Method+" "+r.op.Path))
+			return "", err
+		}
+	}
+	a.logger.Info("wrote the skeleton test cases of the operations without any, fill in their request and response and remove their skip marker", zap.String("testSet", testSetID))
+	return testSetID, nil
+}
@@ -0,0 +1,48 @@
+// Package gaps finds the operations of an OpenAPI spec which have no recorded test cases.
+package gaps
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Analyze matches the recorded test cases to the operations of the spec. With a scaffold, a skeleton test
+	// case of each operation without one is written to a new test set, calling the app at the appURL.
+	Analyze(ctx context.Context, specPath string, scaffold bool, appURL string) (*Report, error)
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+	InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error
+}
+
+// Report lists the operations of the spec with the number of recorded test cases calling them
+type Report struct {
+	Spec       string      `json:"spec"`
+	Total      int         `json:"total"`
+	Covered    int         `json:"covered"`
+	Operations []Operation `json:"operations"`
+	// Scaffolded is the test set holding the skeleton test cases, empty when none was written
+	Scaffolded string `json:"scaffolded,omitempty"`
+}
+
+type Operation struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	OperationID string `json:"operationId,omitempty"`
+	TestCases   int    `json:"testCases"`
+}
+
+// Missing returns the operations without any recorded test case
+func (r *Report) Missing() []Operation {
+	var missing []Operation
+	for _, op := range r.Operations {
+		if op.TestCases == 0 {
+			missing = append(missing, op)
+		}
+	}
+	return missing
+}
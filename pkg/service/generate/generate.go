@@ -0,0 +1,232 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// the kinds of the variants, a negative one expecting the invalid status
+const (
+	kindMissing   = "missing"
+	kindType      = "type"
+	kindZero      = "zero"
+	kindNegative  = "negative"
+	kindOverflow  = "overflow"
+	kindEmpty     = "empty"
+	kindLong      = "long"
+	longStringLen = 4096
+)
+
+// unsafeName matches the characters which can't be a part of the name of a test case file
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type generator struct {
+	logger *zap.Logger
+	testDB TestDB
+}
+
+func New(logger *zap.Logger, testDB TestDB) Service {
+	return &generator{
+		logger: logger,
+		testDB: testDB,
+	}
+}
+
+// mutation changes the value of a field of the body, returning false when it removed the field
+type mutation struct {
+	kind     string
+	negative bool
+	value    func(v interface{}) (interface{}, bool)
+}
+
+func (g *generator) Generate(ctx context.Context, testSetID string, testCaseIDs []string, opts Options) ([]Variant, error) {
+	testCases, err := g.testDB.GetTestCases(ctx, testSetID)
+	if err != nil {
+		utils.LogError(g.logger, err, "failed to get the test cases", zap.String("testSet", testSetID))
+		return nil, err
+	}
+	selected := map[string]bool{}
+	for _, id := range testCaseIDs {
+		selected[id] = true
+	}
+	names := map[string]bool{}
+	for _, tc := range testCases {
+		names[tc.Name] = true
+	}
+
+	var variants []Variant
+	for _, tc := range testCases {
+		if len(selected) != 0 && !selected[tc.Name] {
+			continue
+		}
+		delete(selected, tc.Name)
+		// the variants generated before aren't varied again
+		if isVariant(tc.Name, names) {
+			continue
+		}
+		var body interface{}
+		if tc.Kind != models.HTTP || json.Unmarshal([]byte(tc.HTTPReq.Body), &body) != nil {
+			if len(testCaseIDs) != 0 {
+				g.logger.Warn("the test case has no json request body to derive variants from", zap.String("testcase", tc.Name))
+			}
+			continue
+		}
+		if _, ok := body.(map[string]interface{}); !ok {
+			continue
+		}
+		for _, path := range fieldPaths(body, nil) {
+			for _, m := range mutations(valueAt(body, path)) {
+				variant, err := g.write(ctx, testSetID, tc, path, m, opts)
+				if err != nil {
+					return variants, err
+				}
+				variants = append(variants, variant)
+			}
+		}
+	}
+	for id := range selected {
+		g.logger.Warn("the test case isn't found in the test set", zap.String("testcase", id), zap.String("testSet", testSetID))
+	}
+	return variants, nil
+}
+
+// write derives the variant of the test case by mutating the field and writes it next to the test case
+func (g *generator) write(ctx context.Context, testSetID string, tc *models.TestCase, path []string, m mutation, opts Options) (Variant, error) {
+	var body interface{}
+	// the body is decoded again so that every variant changes a single field of the recorded body
+	if err := json.Unmarshal([]byte(tc.HTTPReq.Body), &body); err != nil {
+		return Variant{}, err
+	}
+	setAt(body, path, m.value)
+	data, err := json.Marshal(body)
+	if err != nil {
+		return Variant{}, err
+	}
+
+	field := strings.Join(path, ".")
+	variant := *tc
+	variant.Name = unsafeName.ReplaceAllString(fmt.Sprintf("%s-%s-%s", tc.Name, m.kind, field), "_")
+	variant.HTTPReq.Body = string(data)
+	variant.HTTPReq.Binary = ""
+	variant.Curl = ""
+	variant.Skip, variant.Xfail = false, ""
+	// only the status is asserted, as the response to the changed request wasn't recorded
+	variant.HTTPResp.Body, variant.HTTPResp.Binary = "", ""
+	variant.HTTPResp.BodyHash, variant.HTTPResp.BodySize = "", 0
+	variant.Noise = map[string][]string{"body": {}}
+	for k := range tc.HTTPResp.Header {
+		variant.Noise["header."+k] = []string{}
+	}
+	if m.negative {
+		variant.HTTPResp.StatusCode = opts.InvalidStatus
+		variant.HTTPResp.StatusMessage = ""
+	}
+
+	if err := g.testDB.InsertTestCase(ctx, &variant, testSetID); err != nil {
+		utils.LogError(g.logger, err, "failed to write the variant of the test case", zap.String("testcase", variant.Name))
+		return Variant{}, err
+	}
+	return Variant{TestCase: variant.Name, Source: tc.Name, Kind: m.kind, Field: field, Status: variant.HTTPResp.StatusCode}, nil
+}
+
+// isVariant tells if the test case was derived from one of the named test cases
+func isVariant(name string, names map[string]bool) bool {
+	for _, kind := range []string{kindMissing, kindType, kindZero, kindNegative, kindOverflow, kindEmpty, kindLong} {
+		if i := strings.Index(name, "-"+kind+"-"); i > 0 && names[name[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPaths returns the paths of the fields of the objects of the body, nested objects included
+func fieldPaths(v interface{}, prefix []string) [][]string {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var paths [][]string
+	for _, k := range keys {
+		path := append(append([]string{}, prefix...), k)
+		paths = append(paths, path)
+		paths = append(paths, fieldPaths(obj[k], path)...)
+	}
+	return paths
+}
+
+func valueAt(v interface{}, path []string) interface{} {
+	for _, k := range path {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = obj[k]
+	}
+	return v
+}
+
+// setAt replaces the value of the field at the path by the mutated one, or removes the field
+func setAt(v interface{}, path []string, mutate func(interface{}) (interface{}, bool)) {
+	for i, k := range path {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if i < len(path)-1 {
+			v = obj[k]
+			continue
+		}
+		if value, keep := mutate(obj[k]); keep {
+			obj[k] = value
+		} else {
+			delete(obj, k)
+		}
+	}
+}
+
+// mutations returns the changes of a field: its removal, a value of the wrong type and the boundary values of
+// its type
+func mutations(v interface{}) []mutation {
+	constant := func(c interface{}) func(interface{}) (interface{}, bool) {
+		return func(interface{}) (interface{}, bool) { return c, true }
+	}
+	ms := []mutation{{kind: kindMissing, negative: true, value: func(interface{}) (interface{}, bool) { return nil, false }}}
+	switch v.(type) {
+	case float64:
+		ms = append(ms,
+			mutation{kind: kindType, negative: true, value: constant("not-a-number")},
+			mutation{kind: kindZero, value: constant(0)},
+			mutation{kind: kindNegative, value: constant(-1)},
+			mutation{kind: kindOverflow, value: constant(int64(1) << 53)},
+		)
+	case string:
+		ms = append(ms,
+			mutation{kind: kindType, negative: true, value: constant(12345)},
+			mutation{kind: kindEmpty, value: constant("")},
+			mutation{kind: kindLong, value: constant(strings.Repeat("a", longStringLen))},
+		)
+	case bool:
+		ms = append(ms, mutation{kind: kindType, negative: true, value: constant("not-a-boolean")})
+	case map[string]interface{}:
+		ms = append(ms, mutation{kind: kindType, negative: true, value: constant("not-an-object")})
+	case []interface{}:
+		ms = append(ms,
+			mutation{kind: kindType, negative: true, value: constant("not-an-array")},
+			mutation{kind: kindEmpty, value: constant([]interface{}{})},
+		)
+	}
+	return ms
+}
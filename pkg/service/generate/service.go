@@ -0,0 +1,34 @@
+// Package generate derives negative and boundary test cases from the recorded ones.
+package generate
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Generate writes the variants of the json test cases of the test set, all of them when no test case is
+	// given, next to their recordings so that they are replayed with the same mocks
+	Generate(ctx context.Context, testSetID string, testCaseIDs []string, opts Options) ([]Variant, error)
+}
+
+type TestDB interface {
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+	InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error
+}
+
+// Options sets the status the variants are expected to respond with. A request with a missing field or a
+// field of the wrong type expects InvalidStatus, a boundary value the status which was recorded.
+type Options struct {
+	InvalidStatus int
+}
+
+// Variant is a test case derived from a recorded one by changing a field of its request body
+type Variant struct {
+	TestCase string `json:"testCase"`
+	Source   string `json:"source"`
+	Kind     string `json:"kind"`
+	Field    string `json:"field"`
+	Status   int    `json:"status"`
+}
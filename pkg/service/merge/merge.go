@@ -0,0 +1,246 @@
+package merge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+const driverName = "keploy"
+
+type merger struct {
+	logger *zap.Logger
+	path   string
+}
+
+func New(logger *zap.Logger, path string) Service {
+	return &merger{
+		logger: logger,
+		path:   path,
+	}
+}
+
+// document is a test case or a mock of a yaml file
+type document struct {
+	doc  *yaml.NetworkTrafficDoc
+	data []byte
+}
+
+func (d document) key() string {
+	return string(d.doc.Kind) + "/" + d.doc.Name
+}
+
+func (m *merger) Merge(_ context.Context, base, ours, theirs string) (int, error) {
+	var files [3][]document
+	for i, path := range []string{base, ours, theirs} {
+		docs, err := readDocuments(path)
+		if err != nil {
+			utils.LogError(m.logger, err, "failed to read the yaml documents", zap.String("file", path))
+			return 0, err
+		}
+		files[i] = docs
+	}
+
+	merged, conflicts := mergeDocuments(files[0], files[1], files[2])
+	err := os.WriteFile(ours, merged, 0644)
+	if err != nil {
+		utils.LogError(m.logger, err, "failed to write the merged yaml documents", zap.String("file", ours))
+		return 0, err
+	}
+	return conflicts, nil
+}
+
+func (m *merger) Install(ctx context.Context) error {
+	root, err := m.git(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		utils.LogError(m.logger, err, "failed to find the git repository of the keploy directory", zap.String("path", m.path))
+		return err
+	}
+	root = strings.TrimSpace(root)
+	rel, err := filepath.Rel(root, m.path)
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, args := range [][]string{
+		{"config", "merge." + driverName + ".name", "keploy test cases and mocks merge driver"},
+		{"config", "merge." + driverName + ".driver", "keploy merge %O %A %B"},
+	} {
+		if _, err := m.git(ctx, args...); err != nil {
+			utils.LogError(m.logger, err, "failed to register the merge driver")
+			return err
+		}
+	}
+
+	attributesPath := filepath.Join(root, ".gitattributes")
+	existing, err := os.ReadFile(attributesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var lines []string
+	for _, pattern := range []string{rel + "/*/mocks.yaml", rel + "/*/tests/*.yaml"} {
+		line := pattern + " merge=" + driverName
+		if !strings.Contains(string(existing), line) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		existing = append(existing, '\n')
+	}
+	existing = append(existing, []byte(strings.Join(lines, "\n")+"\n")...)
+	err = os.WriteFile(attributesPath, existing, 0644)
+	if err != nil {
+		utils.LogError(m.logger, err, "failed to write the git attributes", zap.String("file", attributesPath))
+		return err
+	}
+	m.logger.Info("registered keploy as the merge driver of the test cases and mocks", zap.String("attributes", attributesPath))
+	return nil
+}
+
+func (m *merger) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = m.path
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+func readDocuments(path string) ([]document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var docs []document
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc *yaml.NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := yamlLib.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, document{doc: doc, data: encoded})
+	}
+	return docs, nil
+}
+
+// mergeDocuments merges the documents three-way by their kind and name. The mocks added on both sides
+// under the same name are both kept by renaming theirs, since concurrent recordings number them alike.
+func mergeDocuments(base, ours, theirs []document) ([]byte, int) {
+	baseDocs, oursDocs, theirsDocs := index(base), index(ours), index(theirs)
+	lastMock := 0
+	for _, docs := range [][]document{base, ours, theirs} {
+		for _, d := range docs {
+			if n, ok := mockNumber(d.doc.Name); ok && n > lastMock {
+				lastMock = n
+			}
+		}
+	}
+
+	var chunks []string
+	conflicts := 0
+	conflict := func(o, t []byte) {
+		conflicts++
+		chunks = append(chunks, "<<<<<<< ours\n"+string(o)+"=======\n"+string(t)+">>>>>>> theirs\n")
+	}
+
+	for _, o := range ours {
+		b, inBase := baseDocs[o.key()]
+		t, inTheirs := theirsDocs[o.key()]
+		switch {
+		case inBase && inTheirs:
+			switch {
+			case bytes.Equal(o.data, t.data) || bytes.Equal(t.data, b.data):
+				chunks = append(chunks, string(o.data))
+			case bytes.Equal(o.data, b.data):
+				chunks = append(chunks, string(t.data))
+			default:
+				conflict(o.data, t.data)
+			}
+		case inBase:
+			// deleted by theirs
+			if !bytes.Equal(o.data, b.data) {
+				conflict(o.data, nil)
+			}
+		case inTheirs && !bytes.Equal(o.data, t.data):
+			// added on both sides, theirs is renamed below if it's a mock
+			if _, ok := mockNumber(o.doc.Name); !ok {
+				conflict(o.data, t.data)
+				continue
+			}
+			chunks = append(chunks, string(o.data))
+		default:
+			chunks = append(chunks, string(o.data))
+		}
+	}
+
+	for _, t := range theirs {
+		b, inBase := baseDocs[t.key()]
+		o, inOurs := oursDocs[t.key()]
+		switch {
+		case inBase && !inOurs:
+			// deleted by ours
+			if !bytes.Equal(t.data, b.data) {
+				conflict(nil, t.data)
+			}
+		case !inBase && !inOurs:
+			chunks = append(chunks, string(t.data))
+		case !inBase && inOurs && !bytes.Equal(o.data, t.data):
+			if _, ok := mockNumber(t.doc.Name); !ok {
+				continue
+			}
+			lastMock++
+			renamed := *t.doc
+			renamed.Name = "mock-" + strconv.Itoa(lastMock)
+			data, err := yamlLib.Marshal(&renamed)
+			if err != nil {
+				conflict(o.data, t.data)
+				continue
+			}
+			chunks = append(chunks, string(data))
+		}
+	}
+	return []byte(strings.Join(chunks, "---\n")), conflicts
+}
+
+func index(docs []document) map[string]document {
+	m := make(map[string]document, len(docs))
+	for _, d := range docs {
+		m[d.key()] = d
+	}
+	return m
+}
+
+func mockNumber(name string) (int, bool) {
+	if !strings.HasPrefix(name, "mock-") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "mock-"))
+	return n, err == nil
+}
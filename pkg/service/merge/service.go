@@ -0,0 +1,11 @@
+// Package merge merges the recorded test cases and mocks by their name, it's used as a git merge driver.
+package merge
+
+import "context"
+
+type Service interface {
+	// Merge merges the changes of the base to theirs file into ours, and returns the number of conflicts
+	Merge(ctx context.Context, base, ours, theirs string) (int, error)
+	// Install registers keploy as the merge driver of the test cases and mocks of the git repository
+	Install(ctx context.Context) error
+}
@@ -0,0 +1,405 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
+	"go.keploy.io/server/v2/pkg/platform/yaml/testdb"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// backupDir keeps the v1 directories once they are migrated
+const backupDir = ".v1-backup"
+
+type migrator struct {
+	logger *zap.Logger
+}
+
+func New(logger *zap.Logger) Service {
+	return &migrator{
+		logger: logger,
+	}
+}
+
+func (m *migrator) Migrate(ctx context.Context, path string, opts Options) (*Summary, error) {
+	if _, err := os.Stat(path); err != nil {
+		utils.LogError(m.logger, err, "failed to find the keploy directory", zap.String("path", path))
+		return nil, err
+	}
+	summary := &Summary{}
+
+	// v1 kept all the test cases in a single directory and the mocks of each test case in its own file
+	if isDir(filepath.Join(path, "tests")) {
+		err := m.migrateFlatLayout(ctx, path, opts, summary)
+		if err != nil {
+			utils.LogError(m.logger, err, "failed to migrate the v1 test cases", zap.String("path", path))
+			return nil, err
+		}
+	}
+
+	testSetIDs, err := yaml.ReadSessionIndices(ctx, path, m.logger)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range testSetIDs {
+		if !isDir(filepath.Join(path, id)) || id == backupDir || id == "tests" || id == "mocks" || contains(summary.TestSets, id) {
+			continue
+		}
+		err := m.migrateTestSet(ctx, filepath.Join(path, id), opts, summary)
+		if err != nil {
+			utils.LogError(m.logger, err, "failed to migrate the test set", zap.String("testSet", id))
+			return nil, err
+		}
+	}
+
+	if isDir(filepath.Join(path, "testReports")) {
+		err := m.migrateReports(ctx, path, opts, summary)
+		if err != nil {
+			utils.LogError(m.logger, err, "failed to migrate the v1 test reports", zap.String("path", path))
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// migrateFlatLayout moves the v1 tests and mocks directories into a new test set, the mocks of a
+// test case are mapped to it instead of being filtered by their timestamps
+func (m *migrator) migrateFlatLayout(ctx context.Context, path string, opts Options, summary *Summary) error {
+	testSetIDs, err := yaml.ReadSessionIndices(ctx, path, m.logger)
+	if err != nil {
+		return err
+	}
+	testSetID := pkg.NewID(testSetIDs, models.TestSetPattern)
+	testSetPath := filepath.Join(path, testSetID)
+	summary.TestSets = append(summary.TestSets, testSetID)
+
+	files, err := yamlFiles(filepath.Join(path, "tests"))
+	if err != nil {
+		return err
+	}
+	var mocks [][]byte
+	mappings := map[string][]string{}
+	for i, file := range files {
+		docs, err := readDocs(file)
+		if err != nil || len(docs) != 1 {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s: not a single yaml document", file))
+			continue
+		}
+		doc := docs[0]
+		oldName := doc.Name
+		doc.Name = fmt.Sprintf("test-%d", i+1)
+		if err := upgradeDoc(doc, true, opts.AppURL); err != nil {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s: %v", file, err))
+			continue
+		}
+		data, err := yamlLib.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		if !opts.DryRun {
+			err = yaml.WriteFile(ctx, m.logger, filepath.Join(testSetPath, "tests"), doc.Name, data, false)
+			if err != nil {
+				return err
+			}
+		}
+		summary.Tests++
+
+		mockFile := filepath.Join(path, "mocks", oldName+".yaml")
+		if _, err := os.Stat(mockFile); err != nil {
+			continue
+		}
+		mockDocs, err := readDocs(mockFile)
+		if err != nil {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s: %v", mockFile, err))
+			continue
+		}
+		mappings[doc.Name] = []string{}
+		for _, mockDoc := range mockDocs {
+			mockDoc.Name = fmt.Sprintf("mock-%d", len(mocks)+1)
+			if err := upgradeDoc(mockDoc, false, opts.AppURL); err != nil {
+				summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s: %v", mockFile, err))
+				continue
+			}
+			data, err := yamlLib.Marshal(mockDoc)
+			if err != nil {
+				return err
+			}
+			mocks = append(mocks, data)
+			mappings[doc.Name] = append(mappings[doc.Name], mockDoc.Name)
+		}
+	}
+	summary.Mocks += len(mocks)
+	if opts.DryRun {
+		return nil
+	}
+
+	if len(mocks) > 0 {
+		err = yaml.WriteFile(ctx, m.logger, testSetPath, "mocks", bytes.Join(mocks, []byte("---\n")), false)
+		if err != nil {
+			return err
+		}
+		err = mockdb.New(m.logger, path, "", nil, nil).InsertMappings(ctx, testSetID, mappings)
+		if err != nil {
+			return err
+		}
+	}
+	for _, dir := range []string{"tests", "mocks"} {
+		if err := backup(path, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateTestSet upgrades the documents of a test set in place
+func (m *migrator) migrateTestSet(ctx context.Context, testSetPath string, opts Options, summary *Summary) error {
+	files, err := yamlFiles(filepath.Join(testSetPath, "tests"))
+	if err != nil {
+		return err
+	}
+	mockFile := filepath.Join(testSetPath, "mocks.yaml")
+	if _, err := os.Stat(mockFile); err == nil {
+		files = append(files, mockFile)
+	}
+
+	for _, file := range files {
+		isTestCase := file != mockFile
+		original, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		docs, err := readDocs(file)
+		if err != nil {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s: %v", file, err))
+			continue
+		}
+		var out [][]byte
+		for _, doc := range docs {
+			// the documents which can't be upgraded are kept as they are
+			if err := upgradeDoc(doc, isTestCase, opts.AppURL); err != nil {
+				summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s (%s): %v", file, doc.Name, err))
+			} else if isTestCase {
+				summary.Tests++
+			} else {
+				summary.Mocks++
+			}
+			data, err := yamlLib.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			out = append(out, data)
+		}
+		migrated := bytes.Join(out, []byte("---\n"))
+		if opts.DryRun || bytes.Equal(migrated, original) {
+			continue
+		}
+		err = yaml.WriteFile(ctx, m.logger, filepath.Dir(file), strings.TrimSuffix(filepath.Base(file), ".yaml"), migrated, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateReports moves the v1 testReports into the reports directory, named after their test set
+func (m *migrator) migrateReports(ctx context.Context, path string, opts Options, summary *Summary) error {
+	runs, err := os.ReadDir(filepath.Join(path, "testReports"))
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if !run.IsDir() {
+			continue
+		}
+		files, err := yamlFiles(filepath.Join(path, "testReports", run.Name()))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			var report models.TestReport
+			if err := yamlLib.Unmarshal(data, &report); err != nil {
+				summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s: %v", file, err))
+				continue
+			}
+			report.Version = models.GetVersion()
+			report.Name = strings.TrimSuffix(filepath.Base(file), ".yaml")
+			if report.TestSet != "" {
+				report.Name = report.TestSet + "-report"
+			}
+			out, err := yamlLib.Marshal(&report)
+			if err != nil {
+				return err
+			}
+			summary.Reports++
+			if opts.DryRun {
+				continue
+			}
+			err = yaml.WriteFile(ctx, m.logger, filepath.Join(path, "reports", run.Name()), report.Name, out, false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if opts.DryRun {
+		return nil
+	}
+	return backup(path, "testReports")
+}
+
+// upgradeDoc rewrites the v1 fields of the document and checks that v2 can decode it
+func upgradeDoc(doc *yaml.NetworkTrafficDoc, isTestCase bool, appURL string) error {
+	doc.Version = models.GetVersion()
+	if doc.Kind == models.HTTP && doc.Spec.Kind == yamlLib.MappingNode {
+		// v1 named the response of the http mocks res and listed the mocks of the test cases
+		renameKey(&doc.Spec, "res", "resp")
+		deleteKey(&doc.Spec, "mocks")
+		for _, key := range []string{"req", "resp"} {
+			msg := mappingValue(&doc.Spec, key)
+			if msg == nil {
+				continue
+			}
+			if header := mappingValue(msg, "header"); header != nil {
+				joinHeaderValues(header)
+			}
+			if url := mappingValue(msg, "url"); url != nil && strings.HasPrefix(url.Value, "/") && appURL != "" {
+				url.Value = strings.TrimSuffix(appURL, "/") + url.Value
+			}
+		}
+	}
+
+	var err error
+	if isTestCase {
+		_, err = testdb.Decode(doc, zap.NewNop())
+	} else {
+		_, err = mockdb.Decode(doc, zap.NewNop())
+	}
+	return err
+}
+
+func mappingValue(node *yamlLib.Node, key string) *yamlLib.Node {
+	if node.Kind != yamlLib.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func renameKey(node *yamlLib.Node, from, to string) {
+	if mappingValue(node, to) != nil {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == from {
+			node.Content[i].Value = to
+		}
+	}
+}
+
+func deleteKey(node *yamlLib.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// joinHeaderValues converts the v1 headers with multiple values into the comma separated v2 ones
+func joinHeaderValues(header *yamlLib.Node) {
+	for i := 1; i < len(header.Content); i += 2 {
+		value := header.Content[i]
+		if value.Kind != yamlLib.SequenceNode {
+			continue
+		}
+		var values []string
+		for _, v := range value.Content {
+			values = append(values, v.Value)
+		}
+		header.Content[i] = &yamlLib.Node{Kind: yamlLib.ScalarNode, Tag: "!!str", Value: strings.Join(values, ", ")}
+	}
+}
+
+func readDocs(file string) ([]*yaml.NetworkTrafficDoc, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*yaml.NetworkTrafficDoc
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc *yaml.NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func yamlFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".yaml" {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func backup(path, dir string) error {
+	src := filepath.Join(path, dir)
+	if !isDir(src) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Join(path, backupDir), 0777); err != nil {
+		return err
+	}
+	return os.Rename(src, filepath.Join(path, backupDir, dir))
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+// Package migrate upgrades the test cases, mocks and reports recorded by keploy v1 to the v2 format.
+package migrate
+
+import "context"
+
+type Service interface {
+	Migrate(ctx context.Context, path string, opts Options) (*Summary, error)
+}
+
+type Options struct {
+	// AppURL prefixes the v1 test cases which only recorded the path of the request
+	AppURL string
+	// DryRun reports the changes without writing them
+	DryRun bool
+}
+
+// Summary counts the migrated documents and lists the ones which couldn't be
+type Summary struct {
+	TestSets []string
+	Tests    int
+	Mocks    int
+	Reports  int
+	Skipped  []string
+}
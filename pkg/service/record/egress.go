@@ -0,0 +1,121 @@
+package record
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// endpoints counts the recorded mocks by the endpoint of the dependency they were recorded for
+type endpoints struct {
+	mu     sync.Mutex
+	counts map[models.EgressEndpoint]int
+}
+
+func newEndpoints() *endpoints {
+	return &endpoints{counts: map[models.EgressEndpoint]int{}}
+}
+
+// add counts the mock under its endpoint, the http and grpc ones only as the others have none
+func (e *endpoints) add(mock *models.Mock) {
+	var endpoint string
+	switch {
+	case mock.Kind == models.HTTP && mock.Spec.HTTPReq != nil:
+		u, err := url.Parse(mock.Spec.HTTPReq.URL)
+		if err != nil {
+			return
+		}
+		endpoint = string(mock.Spec.HTTPReq.Method) + " " + u.Scheme + "://" + u.Host + u.Path
+	case mock.Kind == models.GRPC_EXPORT && mock.Spec.GRPCReq != nil:
+		headers := mock.Spec.GRPCReq.Headers.PseudoHeaders
+		endpoint = headers[":authority"] + headers[":path"]
+	default:
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.counts[models.EgressEndpoint{Kind: mock.GetKind(), Endpoint: endpoint}]++
+}
+
+func (e *endpoints) list() []models.EgressEndpoint {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	list := make([]models.EgressEndpoint, 0, len(e.counts))
+	for ep, n := range e.counts {
+		ep.Mocks = n
+		list = append(list, ep)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Endpoint < list[j].Endpoint
+	})
+	return list
+}
+
+// egressSummary prints and writes the summary of the outgoing calls of the record session, so that the user
+// knows right away which dependencies were captured, passed through or not understood
+func (r *recorder) egressSummary(ctx context.Context, appID uint64, testSetID string, eps *endpoints, mocks map[string]int) {
+	calls, err := r.instrumentation.GetEgressCalls(ctx, appID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the outgoing calls of the record session")
+		return
+	}
+	summary := &models.EgressSummary{
+		TestSet:   testSetID,
+		Mocks:     mocks,
+		Endpoints: eps.list(),
+		Calls:     calls,
+	}
+	if len(summary.Endpoints) == 0 && len(summary.Calls) == 0 {
+		return
+	}
+
+	err = r.mockDB.InsertEgressSummary(ctx, testSetID, summary)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to write the egress summary", zap.String("testSet", testSetID))
+	}
+
+	pp.SetColorScheme(models.PassingColorScheme)
+	if _, err := pp.Printf("\n <=========================================> \n  EGRESS SUMMARY. For test-set: %s\n", testSetID); err != nil {
+		utils.LogError(r.logger, err, "failed to print the egress summary")
+		return
+	}
+	if len(summary.Endpoints) > 0 {
+		if _, err := pp.Printf("\n\tEndpoint\t\tKind\t\tMocks\n"); err != nil {
+			utils.LogError(r.logger, err, "failed to print the egress summary")
+			return
+		}
+		for _, ep := range summary.Endpoints {
+			if _, err := pp.Printf("\t%s\t\t%s\t\t%s\n", ep.Endpoint, ep.Kind, ep.Mocks); err != nil {
+				utils.LogError(r.logger, err, "failed to print the egress summary")
+				return
+			}
+		}
+	}
+	if len(summary.Calls) > 0 {
+		if _, err := pp.Printf("\n\tDestination\t\tProtocol\t\tOutcome\t\tConnections\n"); err != nil {
+			utils.LogError(r.logger, err, "failed to print the egress summary")
+			return
+		}
+		for _, c := range summary.Calls {
+			// the calls of the unsupported protocols are recorded as raw bytes, which likely won't replay well
+			if c.Outcome == models.EgressUnparsed {
+				pp.SetColorScheme(models.FailingColorScheme)
+			} else {
+				pp.SetColorScheme(models.PassingColorScheme)
+			}
+			if _, err := pp.Printf("\t%s:%s\t\t%s\t\t%s\t\t%s\n", c.Host, c.Port, c.Protocol, c.Outcome, c.Connections); err != nil {
+				utils.LogError(r.logger, err, "failed to print the egress summary")
+				return
+			}
+		}
+	}
+	if _, err := pp.Printf(" <=========================================> \n\n"); err != nil {
+		utils.LogError(r.logger, err, "failed to print separator")
+	}
+}
@@ -0,0 +1,88 @@
+package record
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// isFiltered tells if the test case matches one of the filters, in which case it isn't recorded.
+// A filter matches when its host and path patterns, its port, its methods and its header patterns all do.
+func isFiltered(logger *zap.Logger, tc *models.TestCase, filters []config.Filter) bool {
+	// the filters match http requests, the consumed messages and the jobs are always recorded
+	if len(filters) == 0 || tc.Kind == models.MESSAGE || tc.Kind == models.JOB {
+		return false
+	}
+	u, err := url.Parse(tc.HTTPReq.URL)
+	if err != nil {
+		utils.LogError(logger, err, "failed to parse the url of the test case", zap.String("url", tc.HTTPReq.URL))
+		return false
+	}
+	for _, filter := range filters {
+		if filterMatches(logger, tc, u, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterMatches(logger *zap.Logger, tc *models.TestCase, u *url.URL, filter config.Filter) bool {
+	if filter.Host != "" && !matchPattern(logger, filter.Host, u.Hostname()) {
+		return false
+	}
+	if filter.Path != "" && !matchPattern(logger, filter.Path, u.RequestURI()) {
+		return false
+	}
+	if filter.Port != 0 {
+		port, err := strconv.Atoi(u.Port())
+		if err != nil {
+			port = 80
+		}
+		if uint(port) != filter.Port {
+			return false
+		}
+	}
+	if len(filter.URLMethods) > 0 {
+		found := false
+		for _, method := range filter.URLMethods {
+			if strings.EqualFold(method, string(tc.HTTPReq.Method)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for name, pattern := range filter.Headers {
+		value, ok := headerValue(tc.HTTPReq.Header, name)
+		if !ok || !matchPattern(logger, pattern, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func headerValue(headers map[string]string, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func matchPattern(logger *zap.Logger, pattern, value string) bool {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		utils.LogError(logger, err, "failed to compile the filter regex", zap.String("pattern", pattern))
+		return false
+	}
+	return regex.MatchString(value)
+}
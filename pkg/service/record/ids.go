@@ -0,0 +1,55 @@
+package record
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// testCaseIDs names the test cases after a hash of their request, so that re-recording keeps the names
+// of the unchanged test cases. The order in which they're captured is kept separately as an index.
+type testCaseIDs struct {
+	seen  map[string]int
+	order []string
+}
+
+func newTestCaseIDs() *testCaseIDs {
+	return &testCaseIDs{
+		seen: map[string]int{},
+	}
+}
+
+// next returns the name of the test case, the repeated requests get a numbered suffix
+func (ids *testCaseIDs) next(tc *models.TestCase) string {
+	var content string
+	switch tc.Kind {
+	case models.GRPC_EXPORT:
+		content = strings.Join([]string{string(tc.Kind), tc.GrpcReq.Headers.PseudoHeaders[":path"], tc.GrpcReq.Body.DecodedData}, "\n")
+	case models.MESSAGE:
+		content = strings.Join([]string{string(tc.Kind), tc.Message.Topic, tc.Message.Key, tc.Message.Value}, "\n")
+	case models.WEBSOCKET:
+		parts := []string{string(tc.Kind), tc.HTTPReq.URL}
+		for _, frame := range tc.Frames {
+			if frame.Origin == models.FromClient {
+				parts = append(parts, frame.Type, frame.Data)
+			}
+		}
+		content = strings.Join(parts, "\n")
+	case models.JOB:
+		content = strings.Join([]string{string(tc.Kind), tc.Job.Name, tc.Job.Command}, "\n")
+	default:
+		content = strings.Join([]string{string(tc.Kind), string(tc.HTTPReq.Method), tc.HTTPReq.URL, tc.HTTPReq.Body}, "\n")
+	}
+	sum := sha256.Sum256([]byte(content))
+	name := "test-" + hex.EncodeToString(sum[:])[:12]
+
+	ids.seen[name]++
+	if n := ids.seen[name]; n > 1 {
+		name = fmt.Sprintf("%s-%d", name, n)
+	}
+	ids.order = append(ids.order, name)
+	return name
+}
@@ -0,0 +1,38 @@
+package record
+
+import (
+	"errors"
+	"os/exec"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// jobTestCase returns the test case of the job run by the command, which started at the given time and
+// exited with the app error. Nil is returned when the job didn't run to its exit.
+func (r *recorder) jobTestCase(started time.Time, appErr models.AppError) *models.TestCase {
+	job := models.Job{
+		Name:     r.config.Record.Job,
+		Command:  r.config.Command,
+		Started:  started,
+		Finished: time.Now(),
+	}
+	switch appErr.AppErrorType {
+	case models.ErrAppStopped:
+	case models.ErrUnExpected:
+		var exitErr *exec.ExitError
+		if !errors.As(appErr.Err, &exitErr) {
+			return nil
+		}
+		job.ExitCode = exitErr.ExitCode()
+	default:
+		return nil
+	}
+	return &models.TestCase{
+		Version: models.GetVersion(),
+		Kind:    models.JOB,
+		Created: job.Finished.Unix(),
+		Job:     job,
+		Noise:   map[string][]string{},
+	}
+}
@@ -0,0 +1,77 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// limits stops recording once the session reaches one of the caps of the config. Recording is stopped as it is
+// by the user, so the captures already queued are still written before keploy exits.
+type limits struct {
+	logger    *zap.Logger
+	cfg       config.Record
+	testCases atomic.Int64
+	mockBytes atomic.Int64
+	once      sync.Once
+}
+
+func newLimits(logger *zap.Logger, cfg config.Record) *limits {
+	return &limits{logger: logger, cfg: cfg}
+}
+
+// addTestCase counts a written test case
+func (l *limits) addTestCase() {
+	n := l.testCases.Add(1)
+	if l.cfg.MaxTestCases > 0 && n >= int64(l.cfg.MaxTestCases) {
+		l.stop(fmt.Sprintf("recorded %d test cases, the maxTestCases limit", n))
+	}
+}
+
+// addMock counts the size of a written mock, its json encoding being close to the size it takes on the disk
+func (l *limits) addMock(mock *models.Mock) {
+	if l.cfg.MaxMockBytes <= 0 {
+		return
+	}
+	data, err := json.Marshal(mock)
+	if err != nil {
+		return
+	}
+	n := l.mockBytes.Add(int64(len(data)))
+	if n >= l.cfg.MaxMockBytes {
+		l.stop(fmt.Sprintf("recorded %d bytes of mocks, the maxMockBytes limit", n))
+	}
+}
+
+// watchDuration stops recording once it has run for the maxDuration
+func (l *limits) watchDuration(ctx context.Context) error {
+	if l.cfg.MaxDuration <= 0 {
+		return nil
+	}
+	l.logger.Info("recording stops after the maxDuration", zap.Duration("maxDuration", l.cfg.MaxDuration))
+	timer := time.NewTimer(l.cfg.MaxDuration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		l.stop("recorded for " + l.cfg.MaxDuration.String() + ", the maxDuration limit")
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (l *limits) stop(reason string) {
+	l.once.Do(func() {
+		l.logger.Warn("reached a limit of the recording session, stopping keploy", zap.String("reason", reason))
+		if err := utils.Stop(l.logger, reason); err != nil {
+			utils.LogError(l.logger, err, "failed to stop recording")
+		}
+	})
+}
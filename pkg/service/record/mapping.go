@@ -0,0 +1,137 @@
+package record
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// mockMapper maps the mocks to the test cases whose request and response enclose them. The test cases whose
+// windows overlap, e.g. the requests served concurrently, can't tell their mocks apart and are left unmapped, so
+// that their mocks are picked by their timestamps during the tests. The mocks are known by their capture sequence
+// until they are written and named.
+type mockMapper struct {
+	mu    sync.Mutex
+	next  int
+	mocks map[int]*mappedMock
+	cases map[string]window
+
+	// writeMu orders the writes of the mappings so that the last one holds the latest state
+	writeMu sync.Mutex
+}
+
+// window is the time from the request to the response of a test case or a mock
+type window struct {
+	start time.Time
+	end   time.Time
+}
+
+func (w window) valid() bool {
+	return !w.start.IsZero() && !w.end.IsZero()
+}
+
+func (w window) overlaps(o window) bool {
+	return w.start.Before(o.end) && o.start.Before(w.end)
+}
+
+// encloses reports whether the mock was made while the test case was served
+func (w window) encloses(o window) bool {
+	return o.start.After(w.start) && o.end.Before(w.end)
+}
+
+type mappedMock struct {
+	window
+	name string
+}
+
+func newMockMapper() *mockMapper {
+	return &mockMapper{
+		mocks: map[int]*mappedMock{},
+		cases: map[string]window{},
+	}
+}
+
+// addMock keeps the times of the captured mock and returns its capture sequence
+func (m *mockMapper) addMock(mock *models.Mock) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seq := m.next
+	m.next++
+	m.mocks[seq] = &mappedMock{window: window{start: mock.Spec.ReqTimestampMock, end: mock.Spec.ResTimestampMock}}
+	return seq
+}
+
+// nameMock records the name of the mock once it is written
+func (m *mockMapper) nameMock(seq int, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mock, ok := m.mocks[seq]; ok {
+		mock.name = name
+	}
+}
+
+// assign keeps the window of the test case once it is named
+func (m *mockMapper) assign(tc *models.TestCase) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cases[tc.Name] = caseWindow(tc)
+}
+
+// snapshot returns the names of the written mocks of every test case which doesn't overlap another one
+func (m *mockMapper) snapshot() map[string][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seqs := make([]int, 0, len(m.mocks))
+	for seq := range m.mocks {
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+
+	mappings := make(map[string][]string, len(m.cases))
+	for tc, w := range m.cases {
+		if !w.valid() || m.overlapping(tc, w) {
+			continue
+		}
+		names := []string{}
+		for _, seq := range seqs {
+			mock := m.mocks[seq]
+			if mock.name != "" && mock.valid() && w.encloses(mock.window) {
+				names = append(names, mock.name)
+			}
+		}
+		mappings[tc] = names
+	}
+	return mappings
+}
+
+func (m *mockMapper) overlapping(name string, w window) bool {
+	for other, ow := range m.cases {
+		if other != name && ow.valid() && w.overlaps(ow) {
+			return true
+		}
+	}
+	return false
+}
+
+// persist writes the current mappings of the test set
+func (m *mockMapper) persist(ctx context.Context, mockDB MockDB, testSetID string) error {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	return mockDB.InsertMappings(ctx, testSetID, m.snapshot())
+}
+
+// caseWindow is the time the test case was served in, its mocks being made within it
+func caseWindow(tc *models.TestCase) window {
+	switch tc.Kind {
+	case models.MESSAGE:
+		return window{start: tc.Message.Consumed, end: tc.Message.Processed}
+	case models.JOB:
+		return window{start: tc.Job.Started, end: tc.Job.Finished}
+	default:
+		return window{start: tc.HTTPReq.Timestamp, end: tc.HTTPResp.Timestamp}
+	}
+}
@@ -0,0 +1,224 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxMessageSize limits the size of a single consumed message reported by the app
+const maxMessageSize = 10 << 20
+
+// listenMessages serves the endpoint on which the consumers of the app report the messages once processed,
+// sending each as a test case until the context is done. The mocks captured while the message was
+// processed are mapped to it like to any other test case.
+func (r *recorder) listenMessages(ctx context.Context, port uint32) (<-chan *models.TestCase, error) {
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		return nil, errors.New("failed to get the error group from the context")
+	}
+
+	t := make(chan *models.TestCase, 100)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/message", func(w http.ResponseWriter, req *http.Request) {
+		r.captureMessage(ctx, t, w, req)
+	})
+	srv := &http.Server{
+		// the endpoint is unauthenticated, so only the processes of the host can report messages
+		Addr:    fmt.Sprintf("127.0.0.1:%d", port),
+		Handler: mux,
+	}
+
+	g.Go(func() error {
+		defer utils.Recover(r.logger)
+		r.logger.Info("listening for the consumed messages", zap.Uint32("port", port))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			utils.LogError(r.logger, err, "failed to listen for the consumed messages", zap.Uint32("port", port))
+			return err
+		}
+		return nil
+	})
+	g.Go(func() error {
+		defer utils.Recover(r.logger)
+		<-ctx.Done()
+		// shutdown waits for the handlers, so the channel can be closed safely afterwards
+		if err := srv.Shutdown(context.Background()); err != nil {
+			utils.LogError(r.logger, err, "failed to stop listening for the consumed messages")
+		}
+		close(t)
+		return nil
+	})
+	return t, nil
+}
+
+func (r *recorder) captureMessage(ctx context.Context, t chan<- *models.TestCase, w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var msg models.Message
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxMessageSize))
+	if err := decoder.Decode(&msg); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode the message: %v", err), http.StatusBadRequest)
+		return
+	}
+	if msg.Topic == "" {
+		http.Error(w, "topic is required", http.StatusBadRequest)
+		return
+	}
+	switch msg.Broker {
+	case "":
+		msg.Broker = models.BrokerKafka
+	case models.BrokerKafka, models.BrokerSQS:
+	default:
+		http.Error(w, fmt.Sprintf("unsupported broker %q, supported brokers are kafka and sqs", msg.Broker), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	if msg.Processed.IsZero() {
+		msg.Processed = now
+	}
+	if msg.Consumed.IsZero() {
+		msg.Consumed = msg.Processed
+	}
+
+	tc := &models.TestCase{
+		Version: models.GetVersion(),
+		Kind:    models.MESSAGE,
+		Created: now.Unix(),
+		Message: msg,
+		Noise:   map[string][]string{},
+	}
+	select {
+	case t <- tc:
+	case <-ctx.Done():
+		http.Error(w, "recording is stopped", http.StatusServiceUnavailable)
+		return
+	}
+	r.logger.Debug("captured a consumed message", zap.String("broker", msg.Broker), zap.String("topic", msg.Topic))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// mergeTestCases sends the test cases of both channels until both are closed
+func mergeTestCases(logger *zap.Logger, a, b <-chan *models.TestCase) <-chan *models.TestCase {
+	merged := make(chan *models.TestCase)
+	var wg sync.WaitGroup
+	for _, ch := range []<-chan *models.TestCase{a, b} {
+		wg.Add(1)
+		go func(ch <-chan *models.TestCase) {
+			defer wg.Done()
+			defer utils.Recover(logger)
+			for tc := range ch {
+				merged <- tc
+			}
+		}(ch)
+	}
+	go func() {
+		defer utils.Recover(logger)
+		wg.Wait()
+		close(merged)
+	}()
+	return merged
+}
+
+// kafkaFetch is the operation of the mocks of the kafka fetches
+const kafkaFetch = "Fetch"
+
+// fetchCapture records the kafka messages the app fetches through the proxy as test cases. The consumer processes
+// the records of a fetch before fetching their topics again, so the test case of a fetch spans from it to the next
+// fetch of the same topics, enclosing the fetch and the outgoing calls made while processing its records. The
+// records delivered by the same fetch are processed in one test case, as the mocked fetch delivers them together.
+type fetchCapture struct {
+	logger  *zap.Logger
+	mu      sync.Mutex
+	t       chan *models.TestCase
+	closed  bool
+	pending map[string]*models.TestCase // by the topics of the fetch
+}
+
+func newFetchCapture(logger *zap.Logger) *fetchCapture {
+	return &fetchCapture{
+		logger:  logger,
+		t:       make(chan *models.TestCase, 100),
+		pending: map[string]*models.TestCase{},
+	}
+}
+
+// observe records the messages of the kafka fetch mock, marking the mock so that it's only delivered during their
+// test case, and sends the test case of the previous fetch of its topics which the consumer is done with
+func (f *fetchCapture) observe(mock *models.Mock) {
+	if mock.Kind != models.KAFKA || mock.Spec.Metadata["operation"] != kafkaFetch || len(mock.Spec.KafkaResponses) == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	topics := mock.Spec.Metadata["topics"]
+	if tc, ok := f.pending[topics]; ok {
+		tc.Message.Processed = mock.Spec.ReqTimestampMock
+		if !tc.Message.Processed.After(tc.Message.Consumed) {
+			tc.Message.Processed = tc.Message.Consumed.Add(time.Millisecond)
+		}
+		delete(f.pending, topics)
+		f.t <- tc
+	}
+
+	records := mock.Spec.KafkaResponses[0].Records
+	if len(records) == 0 {
+		return
+	}
+	first := records[0]
+	mock.Spec.Metadata["message"] = first.Topic
+	f.pending[topics] = &models.TestCase{
+		Version: models.GetVersion(),
+		Kind:    models.MESSAGE,
+		Created: time.Now().Unix(),
+		Message: models.Message{
+			Broker:  models.BrokerKafka,
+			Topic:   first.Topic,
+			Key:     first.Key,
+			Headers: first.Headers,
+			Value:   first.Value,
+			// the window starts before the fetch so that it encloses it
+			Consumed: mock.Spec.ReqTimestampMock.Add(-time.Millisecond),
+			// the end of the processing is unknown until the next fetch
+			Processed: mock.Spec.ResTimestampMock.Add(time.Millisecond),
+			Fetched:   true,
+		},
+		Noise: map[string][]string{},
+	}
+	if len(records) > 1 {
+		f.logger.Debug("the records of the kafka fetch are recorded as one test case", zap.String("topics", topics), zap.Int("records", len(records)))
+	}
+}
+
+// close sends the test cases of the fetches whose topics weren't fetched again, till now, and closes the channel
+func (f *fetchCapture) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	f.closed = true
+	now := time.Now()
+	for _, tc := range f.pending {
+		if now.After(tc.Message.Processed) {
+			tc.Message.Processed = now
+		}
+		f.t <- tc
+	}
+	f.pending = nil
+	close(f.t)
+}
@@ -0,0 +1,279 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+const defaultMockQueueSize = 500
+
+// queuedMock is a captured mock along with its capture sequence
+type queuedMock struct {
+	seq  int
+	mock *models.Mock
+}
+
+// mockQueueStats tells how the queue of the captured mocks coped with the load
+type mockQueueStats struct {
+	Depth    int
+	MaxDepth int
+	Dropped  int
+	Spilled  int
+}
+
+// mockQueue holds the captured mocks until they are written, in capture order. Past its size the
+// overflow policy applies: the capture waits for room (block), the mock is dropped (drop) or it is
+// written to a spill segment on the disk to be read back once the queue has drained (spill).
+type mockQueue struct {
+	logger *zap.Logger
+	opts   config.MockQueue
+
+	mu       sync.Mutex
+	items    []queuedMock
+	segments []*spillSegment
+	depth    int
+	closed   bool
+	stats    mockQueueStats
+	warned   bool
+
+	ready chan struct{}
+	room  chan struct{}
+}
+
+func newMockQueue(logger *zap.Logger, opts config.MockQueue) *mockQueue {
+	if opts.Size <= 0 {
+		opts.Size = defaultMockQueueSize
+	}
+	if opts.Overflow == "" {
+		opts.Overflow = config.OverflowBlock
+	}
+	if opts.SpillDir == "" {
+		opts.SpillDir = os.TempDir()
+	}
+	return &mockQueue{
+		logger: logger,
+		opts:   opts,
+		ready:  make(chan struct{}, 1),
+		room:   make(chan struct{}, 1),
+	}
+}
+
+// push queues the mock, applying the overflow policy when the queue is full
+func (q *mockQueue) push(ctx context.Context, qm queuedMock) {
+	for {
+		q.mu.Lock()
+		if len(q.segments) == 0 && len(q.items) < q.opts.Size {
+			q.items = append(q.items, qm)
+			q.added()
+			q.mu.Unlock()
+			return
+		}
+		q.overflowed()
+		switch q.opts.Overflow {
+		case config.OverflowDrop:
+			q.stats.Dropped++
+			q.mu.Unlock()
+			q.logger.Debug("dropped a captured mock as the queue is full", zap.String("kind", string(q.kindOf(qm))))
+			return
+		case config.OverflowSpill:
+			err := q.spill(qm)
+			if err == nil {
+				q.added()
+				q.mu.Unlock()
+				return
+			}
+			q.stats.Dropped++
+			q.mu.Unlock()
+			utils.LogError(q.logger, err, "failed to spill the captured mock, dropping it", zap.String("dir", q.opts.SpillDir))
+			return
+		}
+		q.mu.Unlock()
+
+		// block until a mock is taken off the queue
+		select {
+		case <-q.room:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (q *mockQueue) kindOf(qm queuedMock) models.Kind {
+	if qm.mock == nil {
+		return ""
+	}
+	return qm.mock.Kind
+}
+
+// added accounts a queued mock and wakes the consumer, the lock must be held
+func (q *mockQueue) added() {
+	q.depth++
+	if q.depth > q.stats.MaxDepth {
+		q.stats.MaxDepth = q.depth
+	}
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// overflowed warns the first time the queue is full, the lock must be held
+func (q *mockQueue) overflowed() {
+	if q.warned {
+		return
+	}
+	q.warned = true
+	q.logger.Warn("the mocks are captured faster than they are written", zap.Int("queueSize", q.opts.Size), zap.String("overflow", q.opts.Overflow))
+}
+
+// pop returns the next mock in capture order, waiting for one until the queue is closed and drained
+func (q *mockQueue) pop() (queuedMock, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.items) == 0 && len(q.segments) > 0 {
+			q.loadSegment()
+		}
+		if len(q.items) > 0 {
+			qm := q.items[0]
+			q.items[0] = queuedMock{}
+			q.items = q.items[1:]
+			q.depth--
+			q.mu.Unlock()
+			select {
+			case q.room <- struct{}{}:
+			default:
+			}
+			return qm, true
+		}
+		if q.closed && len(q.segments) == 0 {
+			q.mu.Unlock()
+			return queuedMock{}, false
+		}
+		q.mu.Unlock()
+		<-q.ready
+	}
+}
+
+// close stops taking mocks, the queued ones can still be popped
+func (q *mockQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// metrics returns the current and maximum depth of the queue and the number of mocks dropped and spilled
+func (q *mockQueue) metrics() mockQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := q.stats
+	stats.Depth = q.depth
+	return stats
+}
+
+// spillSegment holds at most a queue size of spilled mocks in a file
+type spillSegment struct {
+	file  *os.File
+	count int
+}
+
+// spilledMock is the document of a mock in a spill segment
+type spilledMock struct {
+	Seq int                     `yaml:"seq"`
+	Doc *yaml.NetworkTrafficDoc `yaml:"doc"`
+}
+
+// spill appends the mock to the last segment, the lock must be held
+func (q *mockQueue) spill(qm queuedMock) error {
+	doc, err := mockdb.EncodeMock(qm.mock, q.logger)
+	if err != nil {
+		return err
+	}
+	data, err := yamlLib.Marshal(&spilledMock{Seq: qm.seq, Doc: doc})
+	if err != nil {
+		return err
+	}
+
+	var segment *spillSegment
+	if n := len(q.segments); n > 0 && q.segments[n-1].file != nil && q.segments[n-1].count < q.opts.Size {
+		segment = q.segments[n-1]
+	} else {
+		file, err := os.CreateTemp(q.opts.SpillDir, "keploy-mocks-*.yaml")
+		if err != nil {
+			return err
+		}
+		segment = &spillSegment{file: file}
+		q.segments = append(q.segments, segment)
+	}
+	_, err = segment.file.Write(append([]byte("---\n"), data...))
+	if err != nil {
+		return err
+	}
+	segment.count++
+	q.stats.Spilled++
+	return nil
+}
+
+// loadSegment reads the oldest segment back into the queue, the lock must be held
+func (q *mockQueue) loadSegment() {
+	segment := q.segments[0]
+	q.segments = q.segments[1:]
+	defer q.removeSegment(segment)
+
+	_, err := segment.file.Seek(0, io.SeekStart)
+	if err != nil {
+		utils.LogError(q.logger, err, "failed to read the spilled mocks", zap.String("file", segment.file.Name()))
+		q.depth -= segment.count
+		q.stats.Dropped += segment.count
+		return
+	}
+	dec := yamlLib.NewDecoder(segment.file)
+	loaded := 0
+	for {
+		var sm spilledMock
+		err := dec.Decode(&sm)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			utils.LogError(q.logger, err, "failed to decode the spilled mocks", zap.String("file", segment.file.Name()))
+			break
+		}
+		mock, err := mockdb.Decode(sm.Doc, q.logger)
+		if err != nil {
+			utils.LogError(q.logger, err, "failed to decode a spilled mock", zap.String("file", segment.file.Name()))
+			continue
+		}
+		q.items = append(q.items, queuedMock{seq: sm.Seq, mock: mock})
+		loaded++
+	}
+	if loaded < segment.count {
+		q.depth -= segment.count - loaded
+		q.stats.Dropped += segment.count - loaded
+	}
+}
+
+func (q *mockQueue) removeSegment(segment *spillSegment) {
+	name := segment.file.Name()
+	if err := segment.file.Close(); err != nil {
+		utils.LogError(q.logger, err, "failed to close the spilled mocks", zap.String("file", name))
+	}
+	segment.file = nil
+	if err := os.Remove(name); err != nil {
+		utils.LogError(q.logger, err, "failed to remove the spilled mocks", zap.String("file", name))
+	}
+}
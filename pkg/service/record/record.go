@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime/pprof"
+	"sync"
 	"time"
 
 	"go.keploy.io/server/v2/config"
@@ -22,6 +24,10 @@ type recorder struct {
 	telemetry       Telemetry
 	instrumentation Instrumentation
 	config          config.Config
+
+	// reloadMu guards the parts of the config which are reloaded while recording and the app being recorded
+	reloadMu sync.RWMutex
+	appID    uint64
 }
 
 func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, telemetry Telemetry, instrumentation Instrumentation, config config.Config) Service {
@@ -40,6 +46,8 @@ func (r *recorder) Start(ctx context.Context) error {
 	// creating error group to manage proper shutdown of all the go routines and to propagate the error to the caller
 	errGrp, _ := errgroup.WithContext(ctx)
 	ctx = context.WithValue(ctx, models.ErrGroupKey, errGrp)
+	ctx = pprof.WithLabels(ctx, pprof.Labels("keploy", "record"))
+	pprof.SetGoroutineLabels(ctx)
 
 	runAppErrGrp, _ := errgroup.WithContext(ctx)
 	runAppCtx := context.WithoutCancel(ctx)
@@ -57,12 +65,12 @@ func (r *recorder) Start(ctx context.Context) error {
 	var appErrChan = make(chan models.AppError, 1)
 	var incomingChan <-chan *models.TestCase
 	var outgoingChan <-chan *models.Mock
-	var insertTestErrChan = make(chan error, 10)
 	var insertMockErrChan = make(chan error, 10)
 	var appID uint64
 	var newTestSetID string
 	var testCount = 0
 	var mockCountMap = make(map[string]int)
+	var egressEndpoints = newEndpoints()
 
 	// defering the stop function to stop keploy in case of any error in record or in case of context cancellation
 	defer func() {
@@ -80,6 +88,8 @@ func (r *recorder) Start(ctx context.Context) error {
 		if err != nil {
 			utils.LogError(r.logger, err, "failed to stop application")
 		}
+		// the calls captured until the application stopped are completed before the hooks and proxy go
+		utils.Drain(r.logger, r.config.Record.DrainTimeout)
 		hookCtxCancel()
 		err = hookErrGrp.Wait()
 		if err != nil {
@@ -89,11 +99,12 @@ func (r *recorder) Start(ctx context.Context) error {
 		if err != nil {
 			utils.LogError(r.logger, err, "failed to stop recording")
 		}
+		if newTestSetID != "" && appID != 0 {
+			r.egressSummary(context.WithoutCancel(ctx), appID, newTestSetID, egressEndpoints, mockCountMap)
+		}
 	}()
 
 	defer close(appErrChan)
-	defer close(insertTestErrChan)
-	defer close(insertMockErrChan)
 
 	testSetIDs, err := r.testDB.GetAllTestSetIDs(ctx)
 	if err != nil {
@@ -111,6 +122,9 @@ func (r *recorder) Start(ctx context.Context) error {
 		utils.LogError(r.logger, err, stopReason)
 		return fmt.Errorf(stopReason)
 	}
+	r.reloadMu.Lock()
+	r.appID = appID
+	r.reloadMu.Unlock()
 
 	// checking for context cancellation as we don't want to start the hooks and proxy if the context is cancelled
 	select {
@@ -130,7 +144,8 @@ func (r *recorder) Start(ctx context.Context) error {
 	}
 
 	// fetching test cases and mocks from the application and inserting them into the database
-	incomingChan, err = r.instrumentation.GetIncoming(ctx, appID, models.IncomingOptions{})
+	// the incoming calls are captured until the hooks are stopped, after draining
+	incomingChan, err = r.instrumentation.GetIncoming(hookCtx, appID, models.IncomingOptions{})
 	if err != nil {
 		stopReason = "failed to get incoming frames"
 		utils.LogError(r.logger, err, stopReason)
@@ -140,23 +155,85 @@ func (r *recorder) Start(ctx context.Context) error {
 		return fmt.Errorf(stopReason)
 	}
 
+	// the messages consumed by the app are recorded as test cases too, when its consumers report them
+	if r.config.Record.MessagePort != 0 {
+		messageChan, err := r.listenMessages(hookCtx, r.config.Record.MessagePort)
+		if err != nil {
+			stopReason = "failed to listen for the consumed messages"
+			utils.LogError(r.logger, err, stopReason)
+			return fmt.Errorf(stopReason)
+		}
+		incomingChan = mergeTestCases(r.logger, incomingChan, messageChan)
+	}
+	// or when they are captured from the fetches of the app through the proxy
+	var fetches *fetchCapture
+	if r.config.Record.CaptureMessages {
+		fetches = newFetchCapture(r.logger)
+		incomingChan = mergeTestCases(r.logger, incomingChan, fetches.t)
+		// the capture is closed once the outgoing calls end, or when recording fails before they start
+		defer fetches.close()
+	}
+
+	// a job has no incoming call, so its run is recorded as a test case once it exits
+	var jobChan chan *models.TestCase
+	var jobRunning bool
+	if r.config.Record.Job != "" {
+		jobChan = make(chan *models.TestCase, 1)
+		incomingChan = mergeTestCases(r.logger, incomingChan, jobChan)
+		// the job closes the channel once it exits, unless recording stops before it runs
+		defer func() {
+			if !jobRunning {
+				close(jobChan)
+			}
+		}()
+	}
+
+	// sensitive fields are masked before the test cases and mocks are written
+	masker := pkg.NewMasker(r.config.Masking.Fields)
+	mapper := newMockMapper()
+	var ids *testCaseIDs
+	if r.config.Record.StableTestIDs {
+		ids = newTestCaseIDs()
+	}
+
+	limits := newLimits(r.logger, r.config.Record)
 	errGrp.Go(func() error {
+		defer utils.Recover(r.logger)
+		return limits.watchDuration(ctx)
+	})
+
+	// the test cases are written in batches by the writer so that capturing isn't held up by the disk
+	writer := newCaseWriter(r.logger, r.testDB, r.mockDB, r.config.Record.Persistence, newTestSetID, mapper, ids, newScenarioRecorder(r.config.Record.Session), func(_ *models.TestCase) {
+		testCount++
+		r.telemetry.RecordedTestAndMocks()
+		limits.addTestCase()
+	})
+	errGrp.Go(func() error {
+		defer utils.Recover(r.logger)
+		return writer.run(ctx)
+	})
+	// the captures are queued even after recording is stopped, the writer draining the queues till they are closed
+	drainCtx := context.WithoutCancel(ctx)
+	errGrp.Go(func() error {
+		defer writer.close()
 		for testCase := range incomingChan {
-			err := r.testDB.InsertTestCase(ctx, testCase, newTestSetID)
-			if err != nil {
-				if err == context.Canceled {
-					continue
-				}
-				insertTestErrChan <- err
-			} else {
-				testCount++
-				r.telemetry.RecordedTestAndMocks()
+			if isFiltered(r.logger, testCase, r.filters()) {
+				r.logger.Debug("skipped recording the test case matching a filter", zap.String("url", testCase.HTTPReq.URL))
+				continue
+			}
+			masker.MaskTestCase(testCase)
+			if pkg.TruncateBody(&testCase.HTTPResp, r.config.Record.MaxBodySize) {
+				r.logger.Debug("truncated the body of the response above the maxBodySize", zap.String("url", testCase.HTTPReq.URL), zap.Int("size", testCase.HTTPResp.BodySize))
 			}
+			if ids != nil {
+				testCase.Name = ids.next(testCase)
+			}
+			writer.add(drainCtx, testCase)
 		}
 		return nil
 	})
 
-	outgoingChan, err = r.instrumentation.GetOutgoing(ctx, appID, models.OutgoingOptions{})
+	outgoingChan, err = r.instrumentation.GetOutgoing(ctx, appID, models.OutgoingOptions{Rules: r.bypassRules()})
 	if err != nil {
 		stopReason = "failed to get outgoing frames"
 		utils.LogError(r.logger, err, stopReason)
@@ -165,25 +242,87 @@ func (r *recorder) Start(ctx context.Context) error {
 		}
 		return fmt.Errorf(stopReason)
 	}
+
+	// the captured mocks are queued so that a slow disk doesn't hold up the proxy
+	queue := newMockQueue(r.logger, r.config.Record.MockQueue)
 	errGrp.Go(func() error {
+		defer queue.close()
+		if fetches != nil {
+			defer fetches.close()
+		}
 		for mock := range outgoingChan {
-			err := r.mockDB.InsertMock(ctx, mock, newTestSetID)
+			if fetches != nil {
+				fetches.observe(mock)
+			}
+			queue.push(drainCtx, queuedMock{seq: mapper.addMock(mock), mock: mock})
+		}
+		return nil
+	})
+	errGrp.Go(func() error {
+		defer utils.Recover(r.logger)
+		// the queued mocks are written even when recording is stopped
+		writeCtx := context.WithoutCancel(ctx)
+		for {
+			qm, ok := queue.pop()
+			if !ok {
+				break
+			}
+			masker.MaskMock(qm.mock)
+			qm.mock.Tags = append(qm.mock.Tags, r.config.Record.MockTags...)
+			err := r.mockDB.InsertMock(writeCtx, qm.mock, newTestSetID)
 			if err != nil {
-				if err == context.Canceled {
-					continue
+				select {
+				case insertMockErrChan <- err:
+				default:
+					utils.LogError(r.logger, err, "failed to insert the mock", zap.String("testSet", newTestSetID))
 				}
-				insertMockErrChan <- err
-			} else {
-				mockCountMap[mock.GetKind()]++
-				r.telemetry.RecordedTestCaseMock(mock.GetKind())
+				continue
 			}
+			mapper.nameMock(qm.seq, qm.mock.Name)
+			mockCountMap[qm.mock.GetKind()]++
+			egressEndpoints.add(qm.mock)
+			r.telemetry.RecordedTestCaseMock(qm.mock.GetKind())
+			limits.addMock(qm.mock)
+		}
+		// the mocks written after the last test case are mapped too
+		err := mapper.persist(writeCtx, r.mockDB, newTestSetID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to insert the mock mappings", zap.String("testSet", newTestSetID))
+		}
+		if stats := queue.metrics(); stats.Dropped > 0 || stats.Spilled > 0 {
+			r.logger.Warn("the mock queue overflowed while recording", zap.Int("maxDepth", stats.MaxDepth), zap.Int("dropped", stats.Dropped), zap.Int("spilled", stats.Spilled))
 		}
 		return nil
 	})
+	errGrp.Go(func() error {
+		defer utils.Recover(r.logger)
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if stats := queue.metrics(); stats.Depth > 0 {
+					r.logger.Debug("mock queue", zap.Int("depth", stats.Depth), zap.Int("maxDepth", stats.MaxDepth), zap.Int("dropped", stats.Dropped), zap.Int("spilled", stats.Spilled))
+				}
+			}
+		}
+	})
 
 	// running the user application
+	jobRunning = jobChan != nil
 	runAppErrGrp.Go(func() error {
+		started := time.Now()
 		runAppError = r.instrumentation.Run(runAppCtx, appID, models.RunOptions{})
+		if jobChan != nil {
+			if tc := r.jobTestCase(started, runAppError); tc != nil {
+				jobChan <- tc
+			} else {
+				r.logger.Warn("the job was stopped before it exited, hence it isn't recorded", zap.String("job", r.config.Record.Job))
+			}
+			close(jobChan)
+		}
 		if runAppError.AppErrorType == models.ErrCtxCanceled {
 			return nil
 		}
@@ -214,6 +353,11 @@ func (r *recorder) Start(ctx context.Context) error {
 	// Waiting for the error to occur in any of the go routines
 	select {
 	case appErr := <-appErrChan:
+		// the job exiting ends its recording, whatever its exit code
+		if r.config.Record.Job != "" && (appErr.AppErrorType == models.ErrAppStopped || appErr.AppErrorType == models.ErrUnExpected) {
+			r.logger.Info("the job exited, hence stopping keploy", zap.String("job", r.config.Record.Job))
+			return nil
+		}
 		switch appErr.AppErrorType {
 		case models.ErrCommandError:
 			stopReason = "error in running the user application, hence stopping keploy"
@@ -231,7 +375,7 @@ func (r *recorder) Start(ctx context.Context) error {
 			stopReason = "unknown error recieved from application, hence stopping keploy"
 		}
 
-	case err = <-insertTestErrChan:
+	case err = <-writer.errCh:
 		stopReason = "error while inserting test case into db, hence stopping keploy"
 	case err = <-insertMockErrChan:
 		stopReason = "error while inserting mock into db, hence stopping keploy"
@@ -242,6 +386,38 @@ func (r *recorder) Start(ctx context.Context) error {
 	return fmt.Errorf(stopReason)
 }
 
+// Reload applies the filters and the bypass rules of the reloaded config to the running recording
+func (r *recorder) Reload(ctx context.Context, cfg config.Config) error {
+	r.reloadMu.Lock()
+	r.config.Record.Filters = cfg.Record.Filters
+	r.config.BypassRules = cfg.BypassRules
+	appID := r.appID
+	r.reloadMu.Unlock()
+
+	if appID == 0 {
+		return nil
+	}
+	err := r.instrumentation.SetBypassRules(ctx, appID, cfg.BypassRules)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to apply the reloaded bypass rules")
+		return err
+	}
+	r.logger.Info("reloaded the config", zap.Int("filters", len(cfg.Record.Filters)), zap.Int("bypassRules", len(cfg.BypassRules)))
+	return nil
+}
+
+func (r *recorder) filters() []config.Filter {
+	r.reloadMu.RLock()
+	defer r.reloadMu.RUnlock()
+	return r.config.Record.Filters
+}
+
+func (r *recorder) bypassRules() []config.BypassRule {
+	r.reloadMu.RLock()
+	defer r.reloadMu.RUnlock()
+	return r.config.BypassRules
+}
+
 func (r *recorder) StartMock(ctx context.Context) error {
 	g, ctx := errgroup.WithContext(ctx)
 	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
@@ -286,9 +462,12 @@ func (r *recorder) StartMock(ctx context.Context) error {
 		}
 		return fmt.Errorf(stopReason)
 	}
+	masker := pkg.NewMasker(r.config.Masking.Fields)
 	g.Go(func() error {
 		for mock := range outgoingChan {
 			mock := mock // capture range variable
+			masker.MaskMock(mock)
+			mock.Tags = append(mock.Tags, r.config.Record.MockTags...)
 			g.Go(func() error {
 				err := r.mockDB.InsertMock(ctx, mock, "")
 				if err != nil {
@@ -0,0 +1,182 @@
+package record
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// minVariableLength is the length below which the values returned by a step aren't taken as the variables
+// of the later steps, as short values are sent by chance
+const minVariableLength = 8
+
+// scenarioRecorder groups the written test cases sent in the same session of a client into scenarios, finding
+// the values returned by a step which the later steps send. It's only used by the writer.
+type scenarioRecorder struct {
+	session  config.Session
+	sessions map[string]*sessionScenario
+	order    []string
+	changed  bool
+}
+
+type sessionScenario struct {
+	scenario models.Scenario
+	// returned are the values returned by the steps so far which no later step has sent yet
+	returned map[string]returnedValue
+}
+
+type returnedValue struct {
+	step int
+	path string
+}
+
+func newScenarioRecorder(session config.Session) *scenarioRecorder {
+	if session.Cookie == "" && session.Header == "" {
+		return nil
+	}
+	return &scenarioRecorder{
+		session:  session,
+		sessions: map[string]*sessionScenario{},
+	}
+}
+
+// add appends the test case to the scenario of its session, if it was sent in one
+func (s *scenarioRecorder) add(tc *models.TestCase) {
+	id := s.sessionOf(tc)
+	if id == "" {
+		return
+	}
+	ss, ok := s.sessions[id]
+	if !ok {
+		ss = &sessionScenario{
+			scenario: models.Scenario{Session: id},
+			returned: map[string]returnedValue{},
+		}
+		s.sessions[id] = ss
+		s.order = append(s.order, id)
+	}
+
+	request := strings.Join([]string{tc.HTTPReq.URL, tc.HTTPReq.Body, strings.Join(headerValues(tc.HTTPReq.Header), "\n")}, "\n")
+	var sent []string
+	for value := range ss.returned {
+		if strings.Contains(request, value) {
+			sent = append(sent, value)
+		}
+	}
+	sort.Strings(sent)
+	for _, value := range sent {
+		rv := ss.returned[value]
+		ss.scenario.Variables = append(ss.scenario.Variables, models.ScenarioVariable{
+			Name:  variableName(ss.scenario.Variables, rv.path),
+			Step:  rv.step,
+			Path:  rv.path,
+			Value: value,
+		})
+		delete(ss.returned, value)
+	}
+
+	step := len(ss.scenario.Steps)
+	ss.scenario.Steps = append(ss.scenario.Steps, models.ScenarioStep{TestCase: tc.Name})
+	values := responseValues(&tc.HTTPResp)
+	paths := make([]string, 0, len(values))
+	for p := range values {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		value := values[p]
+		if _, ok := ss.returned[value]; !ok && len(value) >= minVariableLength {
+			ss.returned[value] = returnedValue{step: step, path: p}
+		}
+	}
+	s.changed = true
+}
+
+// scenarios returns the sessions of more than one test case as scenarios, in the order they started
+func (s *scenarioRecorder) scenarios() []models.Scenario {
+	var scenarios []models.Scenario
+	for _, id := range s.order {
+		sc := s.sessions[id].scenario
+		if len(sc.Steps) < 2 {
+			continue
+		}
+		sc.Name = fmt.Sprintf("scenario-%d", len(scenarios)+1)
+		scenarios = append(scenarios, sc)
+	}
+	return scenarios
+}
+
+// sessionOf returns the session in which the test case was sent, by its cookie or, for the first request of
+// a session like a login, the cookie set by its response. Otherwise, it's the value of the session header.
+func (s *scenarioRecorder) sessionOf(tc *models.TestCase) string {
+	if tc.Kind != models.HTTP {
+		return ""
+	}
+	if s.session.Cookie != "" {
+		if id := pkg.CookieValue(pkg.HeaderValue(tc.HTTPReq.Header, "Cookie"), s.session.Cookie); id != "" {
+			return id
+		}
+		if id := pkg.CookieValue(pkg.HeaderValue(tc.HTTPResp.Header, "Set-Cookie"), s.session.Cookie); id != "" {
+			return id
+		}
+	}
+	if s.session.Header == "" {
+		return ""
+	}
+	id := pkg.HeaderValue(tc.HTTPReq.Header, s.session.Header)
+	// the parent id of traceparent changes with every request, only its trace id identifies the session
+	if strings.EqualFold(s.session.Header, "traceparent") {
+		if parts := strings.Split(id, "-"); len(parts) == 4 {
+			return parts[1]
+		}
+	}
+	return id
+}
+
+// responseValues returns the cookies set by the response and the string values of its json body by their
+// paths, which are the values the later steps can send
+func responseValues(resp *models.HTTPResp) map[string]string {
+	values := map[string]string{}
+	for _, part := range strings.Split(pkg.HeaderValue(resp.Header, "Set-Cookie"), ",") {
+		cookie, _, _ := strings.Cut(part, ";")
+		name, value, ok := strings.Cut(strings.TrimSpace(cookie), "=")
+		if ok && name != "" && value != "" {
+			values["cookie."+name] = value
+		}
+	}
+	for p, value := range pkg.JSONStrings(resp.Body) {
+		values["body."+p] = value
+	}
+	return values
+}
+
+func headerValues(header map[string]string) []string {
+	values := make([]string, 0, len(header))
+	for _, v := range header {
+		values = append(values, v)
+	}
+	return values
+}
+
+// variableName names the variable after the last key of its path, numbered if another variable has that name
+func variableName(variables []models.ScenarioVariable, p string) string {
+	base := p[strings.LastIndex(p, ".")+1:]
+	name := base
+	for i := 2; ; i++ {
+		taken := false
+		for _, v := range variables {
+			if v.Name == name {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+}
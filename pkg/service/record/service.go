@@ -3,6 +3,7 @@ package record
 import (
 	"context"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
 )
 
@@ -15,20 +16,34 @@ type Instrumentation interface {
 	GetOutgoing(ctx context.Context, id uint64, opts models.OutgoingOptions) (<-chan *models.Mock, error)
 	// Run is blocking call and will execute until error
 	Run(ctx context.Context, id uint64, opts models.RunOptions) models.AppError
+	// SetBypassRules changes the outgoing calls of a running app which are passed through to their destination
+	SetBypassRules(ctx context.Context, id uint64, rules []config.BypassRule) error
+	// GetEgressCalls returns the outgoing connections made by the app while recording, by destination, protocol and outcome
+	GetEgressCalls(ctx context.Context, id uint64) ([]models.EgressCalls, error)
 }
 
 type Service interface {
 	Start(ctx context.Context) error
 	StartMock(ctx context.Context) error
+	// Reload applies the reloadable parts of the config, the filters and the bypass rules, without restarting
+	Reload(ctx context.Context, cfg config.Config) error
 }
 
 type TestDB interface {
 	GetAllTestSetIDs(ctx context.Context) ([]string, error)
 	InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error
+	InsertIndex(ctx context.Context, testSetID string, names []string) error
+	// InsertScenarios writes the flows of test cases sent in the same session of a client
+	InsertScenarios(ctx context.Context, testSetID string, scenarios []models.Scenario) error
+	// Sync flushes the written test cases of the test set to the disk
+	Sync(ctx context.Context, testSetID string, names []string) error
 }
 
 type MockDB interface {
 	InsertMock(ctx context.Context, mock *models.Mock, testSetID string) error
+	InsertMappings(ctx context.Context, testSetID string, mappings map[string][]string) error
+	// InsertEgressSummary writes the summary of the outgoing calls recorded in the test set
+	InsertEgressSummary(ctx context.Context, testSetID string, summary *models.EgressSummary) error
 }
 
 type Telemetry interface {
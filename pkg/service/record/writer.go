@@ -0,0 +1,179 @@
+package record
+
+import (
+	"context"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// Defaults of the test case persistence
+const (
+	defaultQueueSize     = 1000
+	defaultBatchSize     = 32
+	defaultFlushInterval = time.Second
+)
+
+// pendingCase is a captured test case waiting to be written along with the mocks captured before it
+type pendingCase struct {
+	tc *models.TestCase
+}
+
+// caseWriter writes the captured test cases in batches off the capture path, so that the latency
+// of the disk doesn't hold up the captures.
+type caseWriter struct {
+	logger    *zap.Logger
+	testDB    TestDB
+	mockDB    MockDB
+	opts      config.Persistence
+	testSetID string
+	mapper    *mockMapper
+	ids       *testCaseIDs
+	scenarios *scenarioRecorder
+	// written is called for every test case once it is persisted
+	written func(tc *models.TestCase)
+
+	queue chan pendingCase
+	errCh chan error
+	full  bool
+}
+
+func newCaseWriter(logger *zap.Logger, testDB TestDB, mockDB MockDB, opts config.Persistence, testSetID string, mapper *mockMapper, ids *testCaseIDs, scenarios *scenarioRecorder, written func(tc *models.TestCase)) *caseWriter {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	return &caseWriter{
+		logger:    logger,
+		testDB:    testDB,
+		mockDB:    mockDB,
+		opts:      opts,
+		testSetID: testSetID,
+		mapper:    mapper,
+		ids:       ids,
+		scenarios: scenarios,
+		written:   written,
+		queue:     make(chan pendingCase, opts.QueueSize),
+		errCh:     make(chan error, 1),
+	}
+}
+
+// add queues the test case, blocking while the queue is full
+func (w *caseWriter) add(ctx context.Context, tc *models.TestCase) {
+	pc := pendingCase{tc: tc}
+	select {
+	case w.queue <- pc:
+		return
+	default:
+	}
+	if !w.full {
+		w.full = true
+		w.logger.Warn("the test cases are captured faster than they are written, capturing is slowed down to keep them", zap.Int("queueSize", w.opts.QueueSize))
+	}
+	select {
+	case w.queue <- pc:
+	case <-ctx.Done():
+	}
+}
+
+// close stops taking test cases, the queued ones are still written
+func (w *caseWriter) close() {
+	close(w.queue)
+}
+
+// run writes the queued test cases until the queue is closed
+func (w *caseWriter) run(ctx context.Context) error {
+	// the captured test cases are written even when recording is stopped
+	ctx = context.WithoutCancel(ctx)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]pendingCase, 0, w.opts.BatchSize)
+	for {
+		select {
+		case pc, ok := <-w.queue:
+			if !ok {
+				w.flush(ctx, batch)
+				return nil
+			}
+			batch = append(batch, pc)
+			if len(batch) < w.opts.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		w.flush(ctx, batch)
+		batch = batch[:0]
+	}
+}
+
+func (w *caseWriter) flush(ctx context.Context, batch []pendingCase) {
+	if len(batch) == 0 {
+		return
+	}
+	var names []string
+	for _, pc := range batch {
+		err := w.testDB.InsertTestCase(ctx, pc.tc, w.testSetID)
+		if err != nil {
+			w.fail(err)
+			continue
+		}
+		if w.opts.Fsync == config.FsyncAlways {
+			w.sync(ctx, []string{pc.tc.Name})
+		}
+		names = append(names, pc.tc.Name)
+		w.mapper.assign(pc.tc)
+		if w.scenarios != nil {
+			w.scenarios.add(pc.tc)
+		}
+		w.written(pc.tc)
+	}
+	if len(names) == 0 {
+		return
+	}
+	if w.opts.Fsync == config.FsyncBatch {
+		w.sync(ctx, names)
+	}
+
+	err := w.mapper.persist(ctx, w.mockDB, w.testSetID)
+	if err != nil {
+		utils.LogError(w.logger, err, "failed to insert the mock mappings", zap.String("testSet", w.testSetID))
+	}
+	if w.ids != nil {
+		err = w.testDB.InsertIndex(ctx, w.testSetID, w.ids.order)
+		if err != nil {
+			utils.LogError(w.logger, err, "failed to insert the test case index", zap.String("testSet", w.testSetID))
+		}
+	}
+	if w.scenarios != nil && w.scenarios.changed {
+		w.scenarios.changed = false
+		err = w.testDB.InsertScenarios(ctx, w.testSetID, w.scenarios.scenarios())
+		if err != nil {
+			utils.LogError(w.logger, err, "failed to insert the scenarios", zap.String("testSet", w.testSetID))
+		}
+	}
+}
+
+func (w *caseWriter) sync(ctx context.Context, names []string) {
+	err := w.testDB.Sync(ctx, w.testSetID, names)
+	if err != nil {
+		utils.LogError(w.logger, err, "failed to sync the test cases to the disk", zap.String("testSet", w.testSetID))
+	}
+}
+
+// fail reports the first failed write to the recorder, the next ones are only logged
+func (w *caseWriter) fail(err error) {
+	select {
+	case w.errCh <- err:
+	default:
+		utils.LogError(w.logger, err, "failed to insert the test case", zap.String("testSet", w.testSetID))
+	}
+}
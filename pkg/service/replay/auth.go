@@ -0,0 +1,187 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// the types of the auth providers
+const (
+	authOAuth2 = "oauth2"
+	authLogin  = "login"
+	authScript = "script"
+)
+
+// authTimeout bounds the time taken to obtain a token
+const authTimeout = 30 * time.Second
+
+// authHeader returns the value of the auth header with a fresh token from the provider of the config, empty when
+// no provider is set
+func (r *replayer) authHeader(ctx context.Context) (string, error) {
+	auth := r.config.Test.Auth
+	if auth.Type == "" {
+		return "", nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, authTimeout)
+	defer cancel()
+
+	var token string
+	var err error
+	switch auth.Type {
+	case authOAuth2:
+		token, err = oauth2Token(ctx, auth.OAuth2)
+	case authLogin:
+		token, err = loginToken(ctx, auth.Login)
+	case authScript:
+		token, err = scriptToken(ctx, auth.Script)
+	default:
+		return "", fmt.Errorf("unknown auth type %q, supported types are oauth2, login and script", auth.Type)
+	}
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", fmt.Errorf("the %s auth provider returned an empty token", auth.Type)
+	}
+	if auth.Scheme == "" {
+		return token, nil
+	}
+	return auth.Scheme + " " + token, nil
+}
+
+// withAuth returns the test case with the recorded value of the auth header replaced, the recorded test case
+// being left as is for the report
+func withAuth(tc *models.TestCase, header, value string) *models.TestCase {
+	if value == "" {
+		return tc
+	}
+	if header == "" {
+		header = "Authorization"
+	}
+	var key string
+	for k := range tc.HTTPReq.Header {
+		if strings.EqualFold(k, header) {
+			key = k
+		}
+	}
+	if key == "" {
+		return tc
+	}
+	authed := *tc
+	authed.HTTPReq.Header = make(map[string]string, len(tc.HTTPReq.Header))
+	for k, v := range tc.HTTPReq.Header {
+		authed.HTTPReq.Header[k] = v
+	}
+	authed.HTTPReq.Header[key] = value
+	return &authed
+}
+
+// oauth2Token obtains a token with the client credentials grant
+func oauth2Token(ctx context.Context, cfg config.AuthOAuth2) (string, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	body, err := sendAuthRequest(req)
+	if err != nil {
+		return "", err
+	}
+	return tokenAt(body, "access_token")
+}
+
+// loginToken sends the login request and reads the token from its json response
+func loginToken(ctx context.Context, cfg config.AuthLogin) (string, error) {
+	method := cfg.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), cfg.URL, strings.NewReader(cfg.Body))
+	if err != nil {
+		return "", err
+	}
+	for k, v := range cfg.Header {
+		req.Header.Set(k, v)
+	}
+	if req.Header.Get("Content-Type") == "" && json.Valid([]byte(cfg.Body)) {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	body, err := sendAuthRequest(req)
+	if err != nil {
+		return "", err
+	}
+	path := cfg.TokenPath
+	if path == "" {
+		path = "access_token"
+	}
+	return tokenAt(body, path)
+}
+
+// scriptToken runs the script and returns the token it printed
+func scriptToken(ctx context.Context, script string) (string, error) {
+	if script == "" {
+		return "", errors.New("no script is set to obtain the token")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", script)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run the auth script: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func sendAuthRequest(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("the auth request to %s responded with %s", req.URL.Redacted(), resp.Status)
+	}
+	return body, nil
+}
+
+// tokenAt reads the string at the dot separated path of the json body, like data.token
+func tokenAt(body []byte, path string) (string, error) {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "", fmt.Errorf("the auth response isn't json: %w", err)
+	}
+	for _, k := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("found no token at %s of the auth response", path)
+		}
+		v = obj[k]
+	}
+	token, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("found no token at %s of the auth response", path)
+	}
+	return token, nil
+}
@@ -0,0 +1,90 @@
+package replay
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"golang.org/x/text/unicode/norm"
+)
+
+// jsonCompareOptions returns the canonical comparison options of the test set, which replace the global ones
+func jsonCompareOptions(cfg config.JSONCompare, testSetID string) config.JSONCompareOptions {
+	if opts, ok := cfg.Testsets[testSetID]; ok {
+		return opts
+	}
+	return cfg.Global
+}
+
+func isCanonical(opts config.JSONCompareOptions) bool {
+	return opts.TrailingZeros || opts.NumericStrings || opts.NullAsAbsent || opts.UnicodeNormalization
+}
+
+// canonicalJSON rewrites the json body in the canonical form of the options so that equivalent values of the
+// recorded and the actual responses compare equal. A body which isn't json is returned as is.
+func canonicalJSON(body string, opts config.JSONCompareOptions) string {
+	if !isCanonical(opts) {
+		return body
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+	out, err := json.Marshal(canonicalValue(v, opts))
+	if err != nil {
+		return body
+	}
+	return string(out)
+}
+
+func canonicalValue(v interface{}, opts config.JSONCompareOptions) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			if e == nil && opts.NullAsAbsent {
+				continue
+			}
+			if opts.UnicodeNormalization {
+				k = norm.NFC.String(k)
+			}
+			m[k] = canonicalValue(e, opts)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, e := range val {
+			s[i] = canonicalValue(e, opts)
+		}
+		return s
+	case string:
+		if opts.UnicodeNormalization {
+			val = norm.NFC.String(val)
+		}
+		if opts.NumericStrings {
+			// a number sent as a string, like "5" for 5
+			if f, err := strconv.ParseFloat(val, 64); err == nil {
+				return f
+			}
+		}
+		if opts.TrailingZeros {
+			return trimTrailingZeros(val)
+		}
+		return val
+	default:
+		// the json numbers are decoded as float64, for which 1.50 and 1.5 are the same already
+		return val
+	}
+}
+
+// trimTrailingZeros drops the trailing zeros of the fraction of a decimal string, like 1.50 to 1.5 and 2.00 to 2
+func trimTrailingZeros(s string) string {
+	if !strings.Contains(s, ".") || strings.ContainsAny(s, "eE") {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
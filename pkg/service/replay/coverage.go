@@ -0,0 +1,106 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// idSegment matches the segments of a path which are ids rather than a part of the route, like 42, a uuid or a
+// long hex digest, so that /users/42 and /users/7 are the same endpoint
+var idSegment = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{24,})$`)
+
+// endpointOf returns the method and the route of the request, its ids replaced by {id}
+func endpointOf(req models.HTTPReq) (string, string) {
+	path := req.URL
+	if u, err := url.Parse(req.URL); err == nil {
+		path = u.Path
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if idSegment.MatchString(s) {
+			segments[i] = "{id}"
+		}
+	}
+	path = strings.Join(segments, "/")
+	if path == "" {
+		path = "/"
+	}
+	return strings.ToUpper(string(req.Method)), path
+}
+
+// apiCoverage sets the endpoints and status codes exercised by the test run among the ones recorded across all
+// the test sets. It needs no tooling of the language of the app, as it's told from the test cases alone.
+func (r *replayer) apiCoverage(ctx context.Context, testRunID string, testSetIDs []string) {
+	endpoints := map[string]*models.EndpointCoverage{}
+	for _, testSetID := range testSetIDs {
+		testCases, err := r.testDB.GetTestCases(ctx, testSetID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the test cases for the api coverage", zap.String("testSet", testSetID))
+			return
+		}
+		// the test cases of the test set run in the test run, skipped ones aside
+		exercised := map[string]bool{}
+		for _, reportID := range r.reportIDs(testSetID) {
+			results, err := r.reportDB.GetTestCaseResults(ctx, testRunID, reportID)
+			if err != nil {
+				continue
+			}
+			for _, res := range results {
+				if res.Status != models.TestStatusSkipped {
+					exercised[res.TestCaseID] = true
+				}
+			}
+		}
+		for _, tc := range testCases {
+			if tc.Kind != models.HTTP {
+				continue
+			}
+			method, path := endpointOf(tc.HTTPReq)
+			key := fmt.Sprintf("%s %s %d", method, path, tc.HTTPResp.StatusCode)
+			e, ok := endpoints[key]
+			if !ok {
+				e = &models.EndpointCoverage{Method: method, Path: path, StatusCode: tc.HTTPResp.StatusCode}
+				endpoints[key] = e
+			}
+			if exercised[tc.Name] {
+				e.Covered = true
+				e.TestCases++
+			}
+		}
+	}
+
+	coverage := &models.APICoverage{Total: len(endpoints)}
+	for _, e := range endpoints {
+		if e.Covered {
+			coverage.Covered++
+		}
+		coverage.Endpoints = append(coverage.Endpoints, *e)
+	}
+	sort.Slice(coverage.Endpoints, func(i, j int) bool {
+		a, b := coverage.Endpoints[i], coverage.Endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.StatusCode < b.StatusCode
+	})
+	if coverage.Total > 0 {
+		coverage.Percentage = float64(coverage.Covered) * 100 / float64(coverage.Total)
+	}
+
+	r.reportMu.Lock()
+	defer r.reportMu.Unlock()
+	if report, ok := r.runReports[testRunID]; ok {
+		report.APICoverage = coverage
+	}
+}
@@ -0,0 +1,107 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// coreFile matches the names of the core files, core or core followed by the pid e.g. core.4242 or core.app.4242
+var coreFile = regexp.MustCompile(`^core(\..*[0-9].*)?$`)
+
+// crashStderrLines is the number of the last lines of the stderr of the app kept in a crash bundle
+const crashStderrLines = 50
+
+// collectCrash writes the failure bundle of the app which exited during the test set and returns its directory
+func (r *replayer) collectCrash(ctx context.Context, appID uint64, testRunID, testSetID, inFlight string, appErr models.AppError, since time.Time) string {
+	bundle := &models.CrashBundle{
+		TestSet:   testSetID,
+		TestCase:  inFlight,
+		ErrorType: string(appErr.AppErrorType),
+		ExitCode:  exitCodeOf(appErr),
+		CoreDump:  findCoreDump(since),
+		Time:      time.Now().Unix(),
+	}
+	if appErr.Err != nil {
+		bundle.Error = appErr.Err.Error()
+	}
+
+	output, err := r.instrumentation.GetAppOutput(ctx, appID, since, time.Now())
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the output of the app for the crash bundle")
+	}
+	for _, line := range output {
+		if line.Stream == "stderr" {
+			bundle.Stderr = append(bundle.Stderr, line.Line)
+		}
+	}
+	if len(bundle.Stderr) > crashStderrLines {
+		bundle.Stderr = bundle.Stderr[len(bundle.Stderr)-crashStderrLines:]
+	}
+
+	dir, err := r.reportDB.InsertCrashBundle(ctx, testRunID, testSetID, bundle)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to write the crash bundle", zap.String("testSet", testSetID))
+		return ""
+	}
+	r.logger.Info("the app exited during the test set, collected its crash bundle", zap.String("bundle", dir), zap.String("testcase", inFlight), zap.Int("exitCode", bundle.ExitCode))
+	return dir
+}
+
+// exitCodeOf returns the exit code of the app, -1 when it's unknown
+func exitCodeOf(appErr models.AppError) int {
+	if appErr.AppErrorType == models.ErrAppStopped {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(appErr.Err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// findCoreDump returns the newest core file written since the time, looked up in the working directory and in
+// the directory of the core_pattern of the kernel, empty when there is none
+func findCoreDump(since time.Time) string {
+	dirs := []string{"."}
+	if pattern, err := os.ReadFile("/proc/sys/kernel/core_pattern"); err == nil {
+		// a pattern starting with | pipes the core to a program like systemd-coredump
+		if p := strings.TrimSpace(string(pattern)); filepath.IsAbs(p) {
+			dirs = append(dirs, filepath.Dir(p))
+		}
+	}
+	var newest string
+	var newestMod time.Time
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !coreFile.MatchString(e.Name()) {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil || info.ModTime().Before(since) || !info.ModTime().After(newestMod) {
+				continue
+			}
+			newest, newestMod = filepath.Join(dir, e.Name()), info.ModTime()
+		}
+	}
+	if newest == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(newest); err == nil {
+		return abs
+	}
+	return newest
+}
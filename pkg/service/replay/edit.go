@@ -0,0 +1,104 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func (r *replayer) UpdateTestCaseResponse(ctx context.Context, testSetID string, testCaseID string, resp models.HTTPResp) error {
+	tc, err := r.getTestCase(ctx, testSetID, testCaseID)
+	if err != nil {
+		return err
+	}
+	if tc.Kind != models.HTTP {
+		return fmt.Errorf("testcase %s is of kind %s, only the response of http testcases can be updated", testCaseID, tc.Kind)
+	}
+	if http.StatusText(resp.StatusCode) == "" {
+		return fmt.Errorf("invalid status code %d", resp.StatusCode)
+	}
+	tc.HTTPResp.StatusCode = resp.StatusCode
+	tc.HTTPResp.StatusMessage = http.StatusText(resp.StatusCode)
+	tc.HTTPResp.Header = resp.Header
+	tc.HTTPResp.Body = resp.Body
+	// the binary body and the hash of a truncated body were recorded for the previous response
+	tc.HTTPResp.Binary = ""
+	tc.HTTPResp.BodyHash, tc.HTTPResp.BodySize = "", 0
+	err = r.testDB.UpdateTestCase(ctx, tc, testSetID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to update the response of the testcase", zap.String("testcase", testCaseID), zap.String("testSetID", testSetID))
+		return err
+	}
+	r.logger.Info("updated the expected response of the testcase", zap.String("testcase", testCaseID), zap.String("testSetID", testSetID))
+	return nil
+}
+
+func (r *replayer) UpdateTestCaseNoise(ctx context.Context, testSetID string, testCaseID string, add []string, remove []string) error {
+	for _, field := range append(append([]string{}, add...), remove...) {
+		if !isNoiseField(field) {
+			return fmt.Errorf("invalid noise field %q, it should be body, header or start with body., header., trailer., data. or errors.", field)
+		}
+	}
+	tc, err := r.getTestCase(ctx, testSetID, testCaseID)
+	if err != nil {
+		return err
+	}
+	if tc.Noise == nil {
+		tc.Noise = map[string][]string{}
+	}
+	for _, field := range add {
+		if _, ok := tc.Noise[field]; !ok {
+			tc.Noise[field] = []string{}
+		}
+	}
+	for _, field := range remove {
+		delete(tc.Noise, field)
+	}
+	err = r.testDB.UpdateTestCase(ctx, tc, testSetID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to update the noise of the testcase", zap.String("testcase", testCaseID), zap.String("testSetID", testSetID))
+		return err
+	}
+	r.logger.Info("updated the noise of the testcase", zap.String("testcase", testCaseID), zap.String("testSetID", testSetID), zap.Strings("added", add), zap.Strings("removed", remove))
+	return nil
+}
+
+func (r *replayer) DeleteTestCase(ctx context.Context, testSetID string, testCaseID string) error {
+	// make sure it's a testcase of the test set before removing the file
+	if _, err := r.getTestCase(ctx, testSetID, testCaseID); err != nil {
+		return err
+	}
+	err := r.testDB.DeleteTestCase(ctx, testSetID, testCaseID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to delete the testcase", zap.String("testcase", testCaseID), zap.String("testSetID", testSetID))
+		return err
+	}
+	r.logger.Info("deleted the testcase", zap.String("testcase", testCaseID), zap.String("testSetID", testSetID))
+	return nil
+}
+
+func (r *replayer) getTestCase(ctx context.Context, testSetID string, testCaseID string) (*models.TestCase, error) {
+	testCases, err := r.testDB.GetTestCases(ctx, testSetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the testcases of the test set %s: %w", testSetID, err)
+	}
+	for _, tc := range testCases {
+		if tc.Name == testCaseID {
+			return tc, nil
+		}
+	}
+	return nil, fmt.Errorf("testcase %s not found in the test set %s", testCaseID, testSetID)
+}
+
+// isNoiseField tells if the field is one the comparison of the response understands as noise
+func isNoiseField(field string) bool {
+	return field == "body" || field == "header" || isGraphQLNoise(field) ||
+		(strings.HasPrefix(field, "body.") && len(field) > len("body.")) ||
+		(strings.HasPrefix(field, "header.") && len(field) > len("header.")) ||
+		(strings.HasPrefix(field, "trailer.") && len(field) > len("trailer."))
+}
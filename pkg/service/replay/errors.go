@@ -0,0 +1,184 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// The failures of a test run are typed by their cause, which is written in the reports and sets the exit code of
+// keploy test, so that the CI tells the failures of the infrastructure apart from the failing test cases.
+
+// exit codes of keploy test by the cause of the failure of the test run
+const (
+	ExitTestsFailed  = 1
+	ExitSetupError   = 2
+	ExitAppCrash     = 3
+	ExitMockMismatch = 4
+	ExitStorageError = 5
+	ExitTimedOut     = 6
+)
+
+// ErrTestsFailed is a test run whose test cases failed for none of the other causes, e.g. a changed response
+var ErrTestsFailed = errors.New("some test cases failed")
+
+// ErrRunTimedOut is a test run aborted once it ran for the runTimeout
+var ErrRunTimedOut = errors.New("the test run exceeded the runTimeout")
+
+// SetupError is a failure to set up the hooks, the proxy, the mocks or the app for the test cases
+type SetupError struct {
+	Err error
+}
+
+func (e *SetupError) Error() string {
+	return "setup error: " + e.Err.Error()
+}
+
+func (e *SetupError) Unwrap() error {
+	return e.Err
+}
+
+// AppCrash is the app which exited or couldn't be started while the test set was run
+type AppCrash struct {
+	TestSet string
+	Err     error
+}
+
+func (e *AppCrash) Error() string {
+	return fmt.Sprintf("the app crashed during %s: %v", e.TestSet, e.Err)
+}
+
+func (e *AppCrash) Unwrap() error {
+	return e.Err
+}
+
+// MockMismatch is a test run whose failing test cases didn't consume the mocks recorded for them, the outgoing calls
+// of the app matching none of them. TestCases are the test cases of each test set.
+type MockMismatch struct {
+	TestCases map[string][]string
+}
+
+func (e *MockMismatch) Error() string {
+	var testSets []string
+	for testSet, testCases := range e.TestCases {
+		testSets = append(testSets, fmt.Sprintf("%s (%s)", testSet, strings.Join(testCases, ", ")))
+	}
+	sort.Strings(testSets)
+	return "the outgoing calls of the failing test cases matched none of their mocks in " + strings.Join(testSets, ", ")
+}
+
+// StorageError is a failure to read or write the test cases, the mocks or the reports
+type StorageError struct {
+	Err error
+}
+
+func (e *StorageError) Error() string {
+	return "storage error: " + e.Err.Error()
+}
+
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+// errorType returns the type of the error written in the reports, empty for the errors which aren't typed
+func errorType(err error) models.ReplayErrorType {
+	var setupErr *SetupError
+	var appCrash *AppCrash
+	var mismatch *MockMismatch
+	var storageErr *StorageError
+	switch {
+	case errors.As(err, &setupErr):
+		return models.ReplaySetupError
+	case errors.As(err, &appCrash):
+		return models.ReplayAppCrash
+	case errors.As(err, &mismatch):
+		return models.ReplayMockMismatch
+	case errors.As(err, &storageErr):
+		return models.ReplayStorageError
+	}
+	return ""
+}
+
+// ExitCode returns the exit code of keploy test for the error of the test run
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, ErrRunTimedOut) {
+		return ExitTimedOut
+	}
+	switch errorType(err) {
+	case models.ReplaySetupError:
+		return ExitSetupError
+	case models.ReplayAppCrash:
+		return ExitAppCrash
+	case models.ReplayMockMismatch:
+		return ExitMockMismatch
+	case models.ReplayStorageError:
+		return ExitStorageError
+	}
+	return ExitTestsFailed
+}
+
+// isTestFailure reports whether the test run completed with failing test cases, rather than being stopped
+func isTestFailure(err error) bool {
+	return errors.Is(err, ErrTestsFailed) || errorType(err) == models.ReplayMockMismatch
+}
+
+// runError returns the error of the completed test run from the verdicts of its test sets, the failures of the
+// infrastructure coming before the failing test cases
+func runError(verdicts []models.TestSetVerdict) error {
+	failed := false
+	mismatches := map[string][]string{}
+	for _, v := range verdicts {
+		switch v.ErrorType {
+		case models.ReplaySetupError:
+			return &SetupError{Err: errors.New(v.Error)}
+		case models.ReplayStorageError:
+			return &StorageError{Err: errors.New(v.Error)}
+		case models.ReplayAppCrash:
+			return &AppCrash{TestSet: v.TestSet, Err: errors.New(v.Error)}
+		case models.ReplayMockMismatch:
+			mismatches[v.TestSet] = append(mismatches[v.TestSet], v.MockMismatches...)
+		}
+		if v.Status != string(models.TestSetStatusPassed) {
+			failed = true
+		}
+	}
+	if len(mismatches) > 0 {
+		return &MockMismatch{TestCases: mismatches}
+	}
+	if failed {
+		return ErrTestsFailed
+	}
+	return nil
+}
+
+// setErrorType writes the cause of the failure of the test set in its report. The errors of the test cases which
+// couldn't be run come first, then the failures of the app and lastly the mocks the failing test cases didn't consume.
+func setErrorType(report *models.TestReport, status models.TestSetStatus, loopErr error, appErr *models.AppError, mismatched []string) {
+	switch {
+	case loopErr != nil && !errors.Is(loopErr, context.Canceled):
+		report.ErrorType, report.Error = errorType(loopErr), loopErr.Error()
+		// the test cases which couldn't be simulated leave the test set as unfinished as a failed setup
+		if report.ErrorType == "" {
+			report.ErrorType = models.ReplaySetupError
+		}
+	case status == models.TestSetStatusAppHalted || status == models.TestSetStatusFaultUserApp:
+		report.ErrorType, report.Error = models.ReplayAppCrash, string(status)
+		if appErr != nil {
+			report.Error = appErr.Error()
+		}
+	case status == models.TestSetStatusInternalErr:
+		report.ErrorType, report.Error = models.ReplaySetupError, string(status)
+		if appErr != nil {
+			report.Error = appErr.Error()
+		}
+	case status == models.TestSetStatusFailed && len(mismatched) > 0:
+		report.ErrorType, report.MockMismatches = models.ReplayMockMismatch, mismatched
+	}
+}
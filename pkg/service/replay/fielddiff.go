@@ -0,0 +1,167 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// fieldDiffs lists the fields of the actual response which differ from the expected one, and whether the noise ignores them
+func fieldDiffs(expectedResponse, actualResponse *models.HTTPResp, bodyNoise, headerNoise map[string][]string, bodyNoisy bool) []models.FieldDiff {
+	var diffs []models.FieldDiff
+	if expectedResponse.StatusCode != actualResponse.StatusCode {
+		diffs = append(diffs, models.FieldDiff{
+			Path:     "status_code",
+			Expected: fmt.Sprint(expectedResponse.StatusCode),
+			Actual:   fmt.Sprint(actualResponse.StatusCode),
+		})
+	}
+
+	diffs = append(diffs, headerDiffs(pkg.ToHTTPHeader(expectedResponse.Header), pkg.ToHTTPHeader(actualResponse.Header), headerNoise)...)
+
+	var expected, actual interface{}
+	if json.Unmarshal([]byte(expectedResponse.Body), &expected) == nil && json.Unmarshal([]byte(actualResponse.Body), &actual) == nil {
+		diffs = append(diffs, jsonDiffs("body", "", expected, actual, bodyNoise, bodyNoisy)...)
+	} else if expectedResponse.Body != actualResponse.Body {
+		diffs = append(diffs, models.FieldDiff{
+			Path:     "body",
+			Expected: expectedResponse.Body,
+			Actual:   actualResponse.Body,
+			Noise:    bodyNoisy,
+		})
+	}
+	return diffs
+}
+
+func headerDiffs(expected, actual http.Header, noise map[string][]string) []models.FieldDiff {
+	keys := map[string]bool{}
+	for k := range expected {
+		keys[k] = true
+	}
+	for k := range actual {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	_, allNoisy := noise["header"]
+	var diffs []models.FieldDiff
+	for _, k := range sorted {
+		exp, act := strings.Join(expected[k], ", "), strings.Join(actual[k], ", ")
+		if exp == act {
+			continue
+		}
+		regexArr, isNoisy := CheckStringExist(k, noise)
+		if isNoisy && len(regexArr) != 0 {
+			isNoisy, _ = MatchesAnyRegex(exp, regexArr)
+		}
+		diffs = append(diffs, models.FieldDiff{
+			Path:     "header." + k,
+			Expected: exp,
+			Actual:   act,
+			Noise:    isNoisy || allNoisy,
+		})
+	}
+	return diffs
+}
+
+// jsonDiffs compares the json values, the noise key of the array elements is the key of their array like while matching
+func jsonDiffs(path, key string, expected, actual interface{}, noise map[string][]string, noisy bool) []models.FieldDiff {
+	if regexArr, isNoisy := CheckStringExist(key, noise); key != "" && isNoisy {
+		if len(regexArr) == 0 {
+			noisy = true
+		} else if matched, _ := MatchesAnyRegex(InterfaceToString(expected), regexArr); matched {
+			noisy = true
+		}
+	}
+	expMap, expIsMap := expected.(map[string]interface{})
+	actMap, actIsMap := actual.(map[string]interface{})
+	if expIsMap && actIsMap {
+		keys := map[string]bool{}
+		for k := range expMap {
+			keys[k] = true
+		}
+		for k := range actMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		var diffs []models.FieldDiff
+		for _, k := range sorted {
+			childKey := k
+			if key != "" {
+				childKey = key + "." + k
+			}
+			exp, inExp := expMap[k]
+			act, inAct := actMap[k]
+			if !inExp || !inAct {
+				diffs = append(diffs, leafDiff(path+"."+k, childKey, exp, act, inExp, inAct, noise, noisy))
+				continue
+			}
+			diffs = append(diffs, jsonDiffs(path+"."+k, childKey, exp, act, noise, noisy)...)
+		}
+		return diffs
+	}
+
+	expSlice, expIsSlice := expected.([]interface{})
+	actSlice, actIsSlice := actual.([]interface{})
+	if expIsSlice && actIsSlice {
+		var diffs []models.FieldDiff
+		for i := 0; i < len(expSlice) || i < len(actSlice); i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(expSlice) || i >= len(actSlice) {
+				var exp, act interface{}
+				if i < len(expSlice) {
+					exp = expSlice[i]
+				}
+				if i < len(actSlice) {
+					act = actSlice[i]
+				}
+				diffs = append(diffs, leafDiff(elemPath, key, exp, act, i < len(expSlice), i < len(actSlice), noise, noisy))
+				continue
+			}
+			diffs = append(diffs, jsonDiffs(elemPath, key, expSlice[i], actSlice[i], noise, noisy)...)
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+	return []models.FieldDiff{leafDiff(path, key, expected, actual, true, true, noise, noisy)}
+}
+
+func leafDiff(path, key string, expected, actual interface{}, hasExpected, hasActual bool, noise map[string][]string, noisy bool) models.FieldDiff {
+	diff := models.FieldDiff{Path: path, Noise: noisy}
+	if !noisy && key != "" {
+		_, diff.Noise = CheckStringExist(key, noise)
+	}
+	if hasExpected {
+		diff.Expected = encodeJSONValue(expected)
+	}
+	if hasActual {
+		diff.Actual = encodeJSONValue(actual)
+	}
+	return diff
+}
+
+func encodeJSONValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,83 @@
+package replay
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// graphQLRequest is a graphql operation sent over http
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// parseGraphQLRequest returns the graphql operation of the request, when it's one posted either as json or as application/graphql
+func parseGraphQLRequest(req models.HTTPReq) (*graphQLRequest, bool) {
+	if req.Method != models.Method(http.MethodPost) {
+		return nil, false
+	}
+	var gqlReq graphQLRequest
+	if strings.HasPrefix(pkg.ToHTTPHeader(req.Header).Get("Content-Type"), "application/graphql") {
+		gqlReq.Query = req.Body
+	} else if err := json.Unmarshal([]byte(req.Body), &gqlReq); err != nil {
+		return nil, false
+	}
+	if strings.TrimSpace(gqlReq.Query) == "" {
+		return nil, false
+	}
+	doc, err := parser.ParseQuery(&ast.Source{Input: gqlReq.Query})
+	if err != nil {
+		return nil, false
+	}
+	if gqlReq.OperationName == "" && len(doc.Operations) == 1 {
+		gqlReq.OperationName = doc.Operations[0].Name
+	}
+	return &gqlReq, true
+}
+
+// graphQLResponse keeps the data and errors of the graphql response, the extensions like tracing or cost vary
+// between the runs. The errors are sorted since their order isn't meaningful. A body which isn't a graphql
+// response is returned as is.
+func graphQLResponse(body string) string {
+	var resp map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return body
+	}
+	data, hasData := resp["data"]
+	errs, hasErrors := resp["errors"].([]interface{})
+	if !hasData && !hasErrors {
+		return body
+	}
+	normalized := map[string]interface{}{}
+	if hasData {
+		normalized["data"] = data
+	}
+	if hasErrors {
+		sorted := make([]interface{}, len(errs))
+		copy(sorted, errs)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, _ := json.Marshal(sorted[i])
+			b, _ := json.Marshal(sorted[j])
+			return string(a) < string(b)
+		})
+		normalized["errors"] = sorted
+	}
+	out, err := json.Marshal(normalized)
+	if err != nil {
+		return body
+	}
+	return string(out)
+}
+
+// isGraphQLNoise tells if the noise field is a path of the graphql response, like data.user.updatedAt
+func isGraphQLNoise(field string) bool {
+	return strings.HasPrefix(field, "data.") || strings.HasPrefix(field, "errors.")
+}
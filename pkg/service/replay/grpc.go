@@ -0,0 +1,155 @@
+package replay
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// simulateGrpc sends the grpc request of the testcase to the app, to the same address as the http ones
+func (r *replayer) simulateGrpc(ctx context.Context, appID uint64, tc *models.TestCase, testSetID string) (*models.GrpcResp, error) {
+	simulated := *tc
+	pseudo := make(map[string]string, len(tc.GrpcReq.Headers.PseudoHeaders))
+	for k, v := range tc.GrpcReq.Headers.PseudoHeaders {
+		pseudo[k] = v
+	}
+	simulated.GrpcReq.Headers.PseudoHeaders = pseudo
+
+	authority := "http://" + pseudo[":authority"]
+	var err error
+	cmdType := utils.FindDockerCmd(r.config.Command)
+	if r.isK8sTarget() {
+		authority, err = r.replaceHostToK8sTarget(authority)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to replace host to the kubernetes port-forward address")
+			return nil, err
+		}
+	} else if cmdType == utils.Docker || cmdType == utils.DockerCompose || r.config.Test.IsolateNetwork {
+		userIP, err := r.instrumentation.GetAppIP(ctx, appID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the app ip")
+			return nil, err
+		}
+		authority, err = replaceHostToIP(authority, userIP)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to replace host to docker container's IP")
+			return nil, err
+		}
+	}
+	pseudo[":authority"] = strings.TrimPrefix(authority, "http://")
+	r.logger.Debug("the address of the grpc testcase", zap.String("authority", pseudo[":authority"]), zap.String("path", pseudo[":path"]))
+
+	return pkg.SimulateGrpc(ctx, simulated, testSetID, r.logger, r.config.Test.APITimeout)
+}
+
+func (r *replayer) compareGrpc(tc *models.TestCase, actualResponse *models.GrpcResp, testSetID string) (bool, *models.Result) {
+	r.reloadMu.RLock()
+	globalNoise := r.config.Test.GlobalNoise
+	trailers := r.config.Test.GrpcTrailers
+	r.reloadMu.RUnlock()
+
+	noiseConfig := globalNoise.Global
+	if tsNoise, ok := globalNoise.Testsets[testSetID]; ok {
+		noiseConfig = LeftJoinNoise(globalNoise.Global, tsNoise)
+	}
+	return matchGrpc(tc, actualResponse, noiseConfig, trailers, r.logger)
+}
+
+// matchGrpc compares the grpc-status, the grpc-message and the selected trailers of the responses besides their
+// messages, so that a failed call doesn't pass because its payload happens to be the recorded one. A trailer is
+// ignored with the trailer.<name> noise of the testcase or the trailer noise of the config, the message with the
+// body noise of the testcase.
+func matchGrpc(tc *models.TestCase, actualResponse *models.GrpcResp, noiseConfig map[string]map[string][]string, trailers []string, logger *zap.Logger) (bool, *models.Result) {
+	trailerNoise := map[string][]string{}
+	for k, v := range noiseConfig["trailer"] {
+		trailerNoise[strings.ToLower(k)] = v
+	}
+	_, bodyNoisy := tc.Noise["body"]
+	for field, regexArr := range tc.Noise {
+		if name, ok := strings.CutPrefix(field, "trailer."); ok {
+			trailerNoise[strings.ToLower(name)] = regexArr
+		}
+	}
+
+	if actualResponse == nil {
+		actualResponse = &models.GrpcResp{}
+	}
+	pass := true
+	res := &models.Result{
+		GrpcStatus: &models.IntResult{
+			Expected: grpcStatus(&tc.GrpcResp),
+			Actual:   grpcStatus(actualResponse),
+		},
+		BodyResult: []models.BodyResult{{
+			Type:     models.BodyTypePlain,
+			Expected: tc.GrpcResp.Body.DecodedData,
+			Actual:   actualResponse.Body.DecodedData,
+		}},
+	}
+
+	res.GrpcStatus.Normal = res.GrpcStatus.Expected == res.GrpcStatus.Actual || isNoisyTrailer("grpc-status", grpcTrailer(&tc.GrpcResp, "grpc-status"), trailerNoise)
+	if !res.GrpcStatus.Normal {
+		pass = false
+	}
+
+	res.BodyResult[0].Normal = bodyNoisy || tc.GrpcResp.Body.DecodedData == actualResponse.Body.DecodedData
+	if !res.BodyResult[0].Normal {
+		pass = false
+	}
+
+	for _, name := range append([]string{"grpc-message"}, trailers...) {
+		name = strings.ToLower(name)
+		expected, actual := grpcTrailer(&tc.GrpcResp, name), grpcTrailer(actualResponse, name)
+		normal := expected == actual || isNoisyTrailer(name, expected, trailerNoise)
+		res.TrailersResult = append(res.TrailersResult, models.HeaderResult{
+			Normal:   normal,
+			Expected: models.Header{Key: name, Value: []string{expected}},
+			Actual:   models.Header{Key: name, Value: []string{actual}},
+		})
+		if !normal {
+			pass = false
+		}
+	}
+
+	if !pass {
+		logger.Info("the grpc response differs from the recorded one", zap.String("testcase", tc.Name),
+			zap.Int("expected grpc-status", res.GrpcStatus.Expected), zap.Int("actual grpc-status", res.GrpcStatus.Actual),
+			zap.Any("trailers", res.TrailersResult), zap.Bool("message matched", res.BodyResult[0].Normal))
+	}
+	return pass, res
+}
+
+// grpcTrailer returns the value of the trailer of the response, which is sent with the headers when the call fails
+// without a message (trailers-only response)
+func grpcTrailer(resp *models.GrpcResp, name string) string {
+	if v, ok := resp.Trailers.OrdinaryHeaders[name]; ok {
+		return v
+	}
+	return resp.Headers.OrdinaryHeaders[name]
+}
+
+// grpcStatus returns the status code of the call, -1 when the response has none
+func grpcStatus(resp *models.GrpcResp) int {
+	code, err := strconv.Atoi(grpcTrailer(resp, "grpc-status"))
+	if err != nil {
+		return -1
+	}
+	return code
+}
+
+func isNoisyTrailer(name, value string, noise map[string][]string) bool {
+	regexArr, ok := noise[name]
+	if !ok {
+		return false
+	}
+	if len(regexArr) == 0 {
+		return true
+	}
+	matched, _ := MatchesAnyRegex(value, regexArr)
+	return matched
+}
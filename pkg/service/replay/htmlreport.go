@@ -0,0 +1,149 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// htmlReport is the test run rendered in the html report, with the results of the test cases of each test set
+type htmlReport struct {
+	Report    models.TestRunReport
+	Passed    bool
+	Generated string
+	TestSets  []htmlTestSet
+}
+
+type htmlTestSet struct {
+	models.TestSetVerdict
+	Name  string
+	Tests []models.TestResult
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"failed": func(status string) bool {
+		return status != string(models.TestStatusPassed) && status != string(models.TestStatusSkipped) && status != string(models.TestStatusXFailed)
+	},
+	"pretty": prettyBody,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Keploy test run {{.Report.Name}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { font-size: 1.5em; }
+table { border-collapse: collapse; margin: .5em 0; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+pre { margin: 0; white-space: pre-wrap; word-break: break-all; font-size: .85em; }
+details { margin: .5em 0; border: 1px solid #d0d7de; border-radius: 6px; padding: .5em 1em; }
+summary { cursor: pointer; font-weight: 600; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; }
+.noise { color: #6e7781; }
+tr.mismatch td { background: #ffebe9; }
+tr.noise td { background: #f6f8fa; }
+.status { float: right; }
+</style>
+</head>
+<body>
+<h1>Test run {{.Report.Name}}</h1>
+<p>Generated at {{.Generated}} by keploy {{.Report.Environment.KeployVersion}} on {{.Report.Environment.OS}} {{.Report.Environment.Kernel}}{{with .Report.Environment.Git}}, commit {{.Commit}} of {{.Branch}}{{if .Dirty}} with uncommitted changes{{end}}{{end}}</p>
+<table>
+<tr><th>Status</th><th>Total</th><th>Passed</th><th>Failed</th><th>Skipped</th><th>Xpassed</th></tr>
+<tr>{{if .Passed}}<td class="passed">PASSED</td>{{else}}<td class="failed">FAILED</td>{{end}}<td>{{.Report.Total}}</td><td>{{.Report.Success}}</td><td>{{.Report.Failure}}</td><td>{{.Report.Skipped}}</td><td>{{.Report.UnexpectedPasses}}</td></tr>
+</table>
+{{range .TestSets}}
+<details{{if failed .Status}} open{{end}}>
+<summary>{{.Name}} <span class="status {{if failed .Status}}failed{{else}}passed{{end}}">{{.Status}} &middot; {{.Success}}/{{.Total}} passed</span></summary>
+{{if .Error}}<p class="failed">{{.ErrorType}}: {{.Error}}</p>{{end}}
+{{range .Tests}}
+<details{{if failed (print .Status)}} open{{end}}>
+<summary>{{.TestCaseID}} {{.Req.Method}} {{.Req.URL}} <span class="status {{if failed (print .Status)}}failed{{else}}passed{{end}}">{{.Status}}{{if .Xfail}} ({{.Xfail}}){{end}}</span></summary>
+{{with .Result}}
+<table>
+<tr><th>Status code</th><th>Expected</th><th>Actual</th></tr>
+<tr{{if not .StatusCode.Normal}} class="mismatch"{{end}}><td></td><td>{{.StatusCode.Expected}}</td><td>{{.StatusCode.Actual}}</td></tr>
+</table>
+{{if .HeadersResult}}
+<details{{range .HeadersResult}}{{if not .Normal}} open{{break}}{{end}}{{end}}>
+<summary>Headers</summary>
+<table>
+<tr><th>Header</th><th>Expected</th><th>Actual</th></tr>
+{{range .HeadersResult}}<tr{{if not .Normal}} class="mismatch"{{end}}><td>{{.Expected.Key}}{{if not .Expected.Key}}{{.Actual.Key}}{{end}}</td><td><pre>{{range .Expected.Value}}{{.}}
+{{end}}</pre></td><td><pre>{{range .Actual.Value}}{{.}}
+{{end}}</pre></td></tr>
+{{end}}
+</table>
+</details>
+{{end}}
+{{if .Diffs}}
+<table>
+<tr><th>Field</th><th>Expected</th><th>Actual</th></tr>
+{{range .Diffs}}<tr class="{{if .Noise}}noise{{else}}mismatch{{end}}"><td>{{.Path}}{{if .Noise}} <span class="noise">(noise)</span>{{end}}</td><td><pre>{{.Expected}}</pre></td><td><pre>{{.Actual}}</pre></td></tr>
+{{end}}
+</table>
+{{end}}
+{{range .BodyResult}}
+<details{{if not .Normal}} open{{end}}>
+<summary>Body <span class="{{if .Normal}}passed{{else}}failed{{end}}">{{if .Normal}}matched{{else}}mismatched{{end}}</span></summary>
+<table>
+<tr><th>Expected</th><th>Actual</th></tr>
+<tr{{if not .Normal}} class="mismatch"{{end}}><td><pre>{{pretty .Expected}}</pre></td><td><pre>{{pretty .Actual}}</pre></td></tr>
+</table>
+</details>
+{{end}}
+{{end}}
+</details>
+{{end}}
+</details>
+{{end}}
+</body>
+</html>
+`))
+
+// writeHTMLReport writes a self-contained html report of the test run, with the differences between the recorded
+// and the actual responses of each test case, so that the failures can be shared without the logs
+func (r *replayer) writeHTMLReport(ctx context.Context, testRunID string, passed bool) {
+	report := r.runReportOf(testRunID)
+	data := htmlReport{Report: report, Passed: passed, Generated: time.Now().Format(time.RFC1123)}
+	for _, verdict := range report.TestSets {
+		name := verdict.TestSet
+		if verdict.EnvSet != "" {
+			name += "@" + verdict.EnvSet
+		}
+		results, err := r.reportDB.GetTestCaseResults(ctx, testRunID, name)
+		if err != nil {
+			r.logger.Debug("failed to get the test case results for the html report", zap.String("testSet", name), zap.Error(err))
+		}
+		data.TestSets = append(data.TestSets, htmlTestSet{TestSetVerdict: verdict, Name: name, Tests: results})
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, data); err != nil {
+		utils.LogError(r.logger, err, "failed to render the html report", zap.String("testRun", testRunID))
+		return
+	}
+	path, err := r.reportDB.InsertHTMLReport(ctx, testRunID, buf.Bytes())
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to write the html report", zap.String("testRun", testRunID))
+		return
+	}
+	r.logger.Info("the html report of the test run is written", zap.String("path", path))
+}
+
+// prettyBody indents the json bodies, leaving the others as they are
+func prettyBody(body string) string {
+	var buf bytes.Buffer
+	if json.Indent(&buf, []byte(body), "", "  ") != nil {
+		return body
+	}
+	return buf.String()
+}
@@ -0,0 +1,54 @@
+package replay
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// isIdempotent tells if the requests of the method are meant to respond alike when sent again
+func isIdempotent(method models.Method) bool {
+	switch strings.ToUpper(string(method)) {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// simulateAgain sends the request of the test case a second time, the mocks consumed by the first being set again
+func (r *replayer) simulateAgain(ctx context.Context, appID uint64, tc *models.TestCase, testSetID string, filteredMocks, unfilteredMocks []*models.Mock, mocksEnabled bool) (*models.HTTPResp, error) {
+	if mocksEnabled {
+		if err := r.instrumentation.SetMocks(ctx, appID, filteredMocks, unfilteredMocks); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := r.SimulateRequest(ctx, appID, tc, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	if mocksEnabled {
+		// the mocks consumed by the second call were consumed by the first already
+		if _, err := r.instrumentation.GetConsumedMocks(ctx, appID); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// compareRepeated compares the second response of the test case with the first, with the noise of the test case
+func (r *replayer) compareRepeated(tc *models.TestCase, first, second *models.HTTPResp, testSetID string) *models.IdempotencyResult {
+	expected := *tc
+	expected.HTTPResp = *first
+	pass, res := r.compareResp(&expected, second, testSetID)
+	result := &models.IdempotencyResult{Normal: pass}
+	if res != nil {
+		for _, diff := range res.Diffs {
+			if !diff.Noise {
+				result.Diffs = append(result.Diffs, diff)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,209 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// impactedTestSets keeps the test sets calling an endpoint affected by the files changed since the
+// impact base, along with the ones whose test cases or mocks changed. All the test sets are kept when
+// the impact can't be told, e.g. when git fails or a changed file isn't mapped to any endpoint.
+func (r *replayer) impactedTestSets(ctx context.Context, testSetIDs []string) []string {
+	base := r.config.Test.Impact.Base
+	changed, err := changedFiles(ctx, base)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the changed files, running all the test sets", zap.String("base", base))
+		return testSetIDs
+	}
+	if len(changed) == 0 {
+		r.logger.Info("no file changed, no test set to run", zap.String("base", base))
+		return nil
+	}
+
+	changedSets := map[string]bool{}
+	var endpoints []endpointPattern
+	for _, file := range changed {
+		if testSetID, ok := r.testSetOfFile(file); ok {
+			changedSets[testSetID] = true
+			continue
+		}
+		if matchesAny(r.config.Test.Impact.Ignore, file) {
+			continue
+		}
+		mapped := false
+		for _, route := range r.config.Test.Impact.Routes {
+			if !matchesAny(route.Files, file) {
+				continue
+			}
+			mapped = true
+			for _, endpoint := range route.Endpoints {
+				endpoints = append(endpoints, parseEndpoint(endpoint))
+			}
+		}
+		if !mapped {
+			r.logger.Info("the changed file isn't mapped to any endpoint, running all the test sets", zap.String("file", file))
+			return testSetIDs
+		}
+	}
+
+	var impacted []string
+	for _, testSetID := range testSetIDs {
+		if changedSets[testSetID] {
+			impacted = append(impacted, testSetID)
+			continue
+		}
+		if len(endpoints) == 0 {
+			continue
+		}
+		testCases, err := r.testDB.GetTestCases(ctx, testSetID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the test cases, running the test set", zap.String("testSet", testSetID))
+			impacted = append(impacted, testSetID)
+			continue
+		}
+		if callsAny(testCases, endpoints) {
+			impacted = append(impacted, testSetID)
+		}
+	}
+	r.logger.Info("running the test sets impacted by the changed files", zap.String("base", base), zap.Int("changedFiles", len(changed)), zap.Strings("testSets", impacted), zap.Int("skipped", len(testSetIDs)-len(impacted)))
+	return impacted
+}
+
+// changedFiles lists the files, relative to the working directory, changed since the git ref
+// including the uncommitted and the untracked ones
+func changedFiles(ctx context.Context, base string) ([]string, error) {
+	diff, err := git(ctx, "diff", "--name-only", "--relative", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(ctx, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var files []string
+	for _, file := range append(diff, untracked...) {
+		if file == "" || seen[file] {
+			continue
+		}
+		seen[file] = true
+		files = append(files, filepath.ToSlash(file))
+	}
+	return files, nil
+}
+
+func git(ctx context.Context, args ...string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n"), nil
+}
+
+// testSetOfFile tells the test set the changed file belongs to, if it is one of its test cases or mocks
+func (r *replayer) testSetOfFile(file string) (string, bool) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(r.config.Path, abs)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	testSetID, _, found := strings.Cut(filepath.ToSlash(rel), "/")
+	return testSetID, found
+}
+
+// endpointPattern is an endpoint of an impact route, the method being empty for any
+type endpointPattern struct {
+	method string
+	path   string
+}
+
+func parseEndpoint(endpoint string) endpointPattern {
+	endpoint = strings.TrimSpace(endpoint)
+	if method, path, found := strings.Cut(endpoint, " "); found {
+		return endpointPattern{method: strings.ToUpper(method), path: strings.TrimSpace(path)}
+	}
+	return endpointPattern{path: endpoint}
+}
+
+// callsAny tells if one of the test cases calls one of the endpoints
+func callsAny(testCases []*models.TestCase, endpoints []endpointPattern) bool {
+	for _, tc := range testCases {
+		method, path := testCaseEndpoint(tc)
+		for _, endpoint := range endpoints {
+			if endpoint.method != "" && endpoint.method != method {
+				continue
+			}
+			if matchGlob(endpoint.path, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func testCaseEndpoint(tc *models.TestCase) (string, string) {
+	if tc.Kind == models.GRPC_EXPORT {
+		return "POST", tc.GrpcReq.Headers.PseudoHeaders[":path"]
+	}
+	u, err := url.Parse(tc.HTTPReq.URL)
+	if err != nil {
+		return string(tc.HTTPReq.Method), ""
+	}
+	return strings.ToUpper(string(tc.HTTPReq.Method)), u.Path
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches the slash separated name against the pattern, where a * matches within a segment
+// and a ** across segments
+func matchGlob(pattern, name string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				// a **/ also matches no directory at all
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					expr.WriteString("(.*/)?")
+				} else {
+					expr.WriteString(".*")
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	ok, err := regexp.MatchString(expr.String(), name)
+	return err == nil && ok
+}
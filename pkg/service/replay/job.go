@@ -0,0 +1,60 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// isJobTestSet tells if the test set records the runs of a job, in which case the app is run once for each of
+// its test cases instead of being kept running while they are simulated
+func isJobTestSet(testCases []*models.TestCase) bool {
+	for _, tc := range testCases {
+		if tc.Kind != models.JOB {
+			return false
+		}
+	}
+	return len(testCases) > 0
+}
+
+// runJob runs the job of the test case with the command of the app until it exits and returns its exit code
+// along with the names of the mocks of the outgoing calls it made
+func (r *replayer) runJob(ctx context.Context, appID uint64, tc *models.TestCase) (int, []string, error) {
+	r.logger.Debug("running the job", zap.String("testcase", tc.Name), zap.String("job", tc.Job.Name))
+	exitCode := 0
+	appErr := r.RunApplication(ctx, appID, r.runOptions())
+	switch appErr.AppErrorType {
+	case models.ErrAppStopped:
+	case models.ErrUnExpected:
+		var exitErr *exec.ExitError
+		if !errors.As(appErr.Err, &exitErr) {
+			return 0, nil, appErr
+		}
+		exitCode = exitErr.ExitCode()
+	case models.ErrCtxCanceled:
+		return 0, nil, context.Canceled
+	default:
+		return 0, nil, appErr
+	}
+	consumed, err := r.instrumentation.GetConsumedMocks(ctx, appID)
+	if err != nil {
+		return exitCode, nil, fmt.Errorf("failed to get the consumed mocks of the job: %w", err)
+	}
+	return exitCode, consumed, nil
+}
+
+// compareJob passes the test case of a job when it exits with the recorded exit code after making all the
+// outgoing calls it made when recorded
+func compareJob(tc *models.TestCase, exitCode int, expected, actual []string) (bool, *models.Result) {
+	pass, result := compareDownstream(expected, actual, true)
+	result.ExitCode = &models.IntResult{
+		Normal:   exitCode == tc.Job.ExitCode,
+		Expected: tc.Job.ExitCode,
+		Actual:   exitCode,
+	}
+	return pass && result.ExitCode.Normal, result
+}
@@ -0,0 +1,109 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"time"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// portForwardTimeout is the maximum time to wait for the kubectl port-forward to start accepting connections
+const portForwardTimeout = 30 * time.Second
+
+// isK8sTarget returns true if the test cases should be replayed against a deployed kubernetes workload
+func (r *replayer) isK8sTarget() bool {
+	return r.config.Test.K8s.Target != ""
+}
+
+// startPortForward establishes a port-forward to the configured kubernetes service/pod using kubectl
+// and returns a cancel function which tears the port-forward down.
+func (r *replayer) startPortForward(ctx context.Context) (context.CancelFunc, error) {
+	k8s := r.config.Test.K8s
+	if k8s.Port == 0 {
+		return nil, errors.New("port of the kubernetes target is required for port-forwarding")
+	}
+	localPort := k8s.LocalPort
+	if localPort == 0 {
+		localPort = k8s.Port
+	}
+
+	args := []string{"port-forward"}
+	if k8s.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", k8s.Kubeconfig)
+	}
+	if k8s.Context != "" {
+		args = append(args, "--context", k8s.Context)
+	}
+	if k8s.Namespace != "" {
+		args = append(args, "--namespace", k8s.Namespace)
+	}
+	args = append(args, k8s.Target, fmt.Sprintf("%d:%d", localPort, k8s.Port))
+
+	pfCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	cmd := exec.CommandContext(pfCtx, "kubectl", args...)
+	cmd.Stderr = os.Stderr
+
+	r.logger.Debug("starting kubectl port-forward", zap.Any("cmd", cmd.String()))
+	err := cmd.Start()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start kubectl port-forward: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		defer utils.Recover(r.logger)
+		exited <- cmd.Wait()
+	}()
+
+	addr := fmt.Sprintf("127.0.0.1:%d", localPort)
+	timeout := time.After(portForwardTimeout)
+	for {
+		conn, dialErr := net.DialTimeout("tcp", addr, time.Second)
+		if dialErr == nil {
+			if err := conn.Close(); err != nil {
+				utils.LogError(r.logger, err, "failed to close the probe connection to port-forward")
+			}
+			break
+		}
+		select {
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		case err := <-exited:
+			cancel()
+			return nil, fmt.Errorf("kubectl port-forward exited unexpectedly: %v", err)
+		case <-timeout:
+			cancel()
+			return nil, fmt.Errorf("timed out waiting for port-forward to %s on %s", k8s.Target, addr)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	r.logger.Info("port-forward to kubernetes target established", zap.String("target", k8s.Target), zap.String("address", addr))
+	return cancel, nil
+}
+
+// replaceHostToK8sTarget points the test case url to the local end of the port-forward
+func (r *replayer) replaceHostToK8sTarget(currentURL string) (string, error) {
+	parsedURL, err := url.Parse(currentURL)
+	if err != nil {
+		return currentURL, err
+	}
+	localPort := r.config.Test.K8s.LocalPort
+	if localPort == 0 {
+		localPort = r.config.Test.K8s.Port
+	}
+	parsedURL.Host = fmt.Sprintf("127.0.0.1:%d", localPort)
+	if parsedURL.Scheme == "" {
+		parsedURL.Scheme = "http"
+	}
+	return parsedURL.String(), nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/wI2L/jsondiff"
 	"github.com/yudai/gojsondiff"
 	"github.com/yudai/gojsondiff/formatter"
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -39,9 +40,11 @@ type JSONComparisonResult struct {
 	differences []string // Lists the keys or indices of values that are not the same
 }
 
-func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map[string]map[string][]string, ignoreOrdering bool, logger *zap.Logger) (bool, *models.Result) {
+func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map[string]map[string][]string, ignoreOrdering bool, unorderedFields []string, jsonOpts config.JSONCompareOptions, compareHeaders []string, logger *zap.Logger) (bool, *models.Result) {
 	bodyType := models.BodyTypePlain
-	if json.Valid([]byte(actualResponse.Body)) {
+	// a body truncated on recording is compared by the hash of its full content
+	truncated := tc.HTTPResp.BodyHash != ""
+	if json.Valid([]byte(actualResponse.Body)) && !truncated {
 		bodyType = models.BodyTypeJSON
 	}
 	pass := true
@@ -60,6 +63,9 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 			Actual:   actualResponse.Body,
 		}},
 	}
+	if truncated {
+		res.BodyResult[0].Actual = pkg.BodyPrefix(actualResponse.Body, len(tc.HTTPResp.Body))
+	}
 	noise := tc.Noise
 
 	var (
@@ -84,8 +90,39 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 		}
 	}
 
+	// with an allowlist of headers, the others are reported but treated as noise
+	if len(compareHeaders) != 0 {
+		for _, header := range []map[string]string{tc.HTTPResp.Header, actualResponse.Header} {
+			for k := range header {
+				if !containsHeader(compareHeaders, k) {
+					headerNoise[k] = []string{}
+				}
+			}
+		}
+	}
+
+	// the responses of graphql operations are compared by their data and errors, whose noise can be
+	// given relative to them, like data.user.updatedAt
+	expResp, actResp := tc.HTTPResp, *actualResponse
+	if truncated {
+		expResp.Body, actResp.Body = tc.HTTPResp.BodyHash, pkg.BodyHash(actualResponse.Body)
+	} else if gqlReq, ok := parseGraphQLRequest(tc.HTTPReq); ok {
+		logger.Debug("comparing the response of the graphql operation", zap.String("operation", gqlReq.OperationName))
+		expResp.Body, actResp.Body = graphQLResponse(expResp.Body), graphQLResponse(actResp.Body)
+		for field, regexArr := range noise {
+			if isGraphQLNoise(field) {
+				bodyNoise[field] = regexArr
+			}
+		}
+	}
+
+	// equivalent json values like "5" and 5 are made equal when the options are set for the test set
+	if bodyType == models.BodyTypeJSON {
+		expResp.Body, actResp.Body = canonicalJSON(expResp.Body, jsonOpts), canonicalJSON(actResp.Body, jsonOpts)
+	}
+
 	// stores the json body after removing the noise
-	cleanExp, cleanAct := tc.HTTPResp.Body, actualResponse.Body
+	cleanExp, cleanAct := expResp.Body, actResp.Body
 	var jsonComparisonResult JSONComparisonResult
 	if !Contains(MapToArray(noise), "body") && bodyType == models.BodyTypeJSON {
 		//validate the stored json
@@ -94,7 +131,7 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 			return false, res
 		}
 		if validatedJSON.isIdentical {
-			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering)
+			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering, unorderedPaths(unorderedFields))
 			pass = jsonComparisonResult.isExact
 			if err != nil {
 				return false, res
@@ -107,7 +144,7 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 		logger.Debug("cleanExp", zap.Any("", cleanExp))
 		logger.Debug("cleanAct", zap.Any("", cleanAct))
 	} else {
-		if !Contains(MapToArray(noise), "body") && tc.HTTPResp.Body != actualResponse.Body {
+		if !Contains(MapToArray(noise), "body") && expResp.Body != actResp.Body {
 			pass = false
 		}
 	}
@@ -120,6 +157,7 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 	}
 
 	res.HeadersResult = *hRes
+	res.Diffs = fieldDiffs(&expResp, &actResp, bodyNoise, headerNoise, Contains(MapToArray(noise), "body"))
 	if tc.HTTPResp.StatusCode == actualResponse.StatusCode {
 		res.StatusCode.Normal = true
 	} else {
@@ -164,7 +202,7 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 
 		if !res.BodyResult[0].Normal {
 			if json.Valid([]byte(actualResponse.Body)) {
-				patch, err := jsondiff.Compare(tc.HTTPResp.Body, actualResponse.Body)
+				patch, err := jsondiff.Compare(expResp.Body, actResp.Body)
 				if err != nil {
 					logger.Warn("failed to compute json diff", zap.Error(err))
 				}
@@ -177,7 +215,7 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 
 				}
 			} else {
-				logDiffs.PushBodyDiff(fmt.Sprint(tc.HTTPResp.Body), fmt.Sprint(actualResponse.Body), bodyNoise)
+				logDiffs.PushBodyDiff(fmt.Sprint(expResp.Body), fmt.Sprint(actResp.Body), bodyNoise)
 			}
 		}
 		_, err := newLogger.Printf(logs)
@@ -248,9 +286,9 @@ func InterfaceToString(val interface{}) string {
 	}
 }
 
-func JSONDiffWithNoiseControl(validatedJSON ValidatedJSON, noise map[string][]string, ignoreOrdering bool) (JSONComparisonResult, error) {
+func JSONDiffWithNoiseControl(validatedJSON ValidatedJSON, noise map[string][]string, ignoreOrdering bool, unordered map[string]bool) (JSONComparisonResult, error) {
 	var matchJSONComparisonResult JSONComparisonResult
-	matchJSONComparisonResult, err := matchJSONWithNoiseHandling("", validatedJSON.expected, validatedJSON.actual, noise, ignoreOrdering)
+	matchJSONComparisonResult, err := matchJSONWithNoiseHandling("", validatedJSON.expected, validatedJSON.actual, noise, ignoreOrdering, unordered)
 	if err != nil {
 		return matchJSONComparisonResult, err
 	}
@@ -288,8 +326,22 @@ func ValidateAndMarshalJSON(log *zap.Logger, exp, act *string) (ValidatedJSON, e
 	return validatedJSON, nil
 }
 
+// unorderedPaths returns the array paths compared as multisets relative to the body, like the keys of the body noise
+func unorderedPaths(fields []string) map[string]bool {
+	paths := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if field == "body" {
+			paths[""] = true
+			continue
+		}
+		paths[strings.TrimPrefix(field, "body.")] = true
+	}
+	return paths
+}
+
 // matchJSONWithNoiseHandling returns strcut if expected and actual JSON objects matches(are equal) and in exact order(isExact).
-func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseMap map[string][]string, ignoreOrdering bool) (JSONComparisonResult, error) {
+// The order of the arrays at the unordered paths is ignored even when ignoreOrdering isn't set.
+func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseMap map[string][]string, ignoreOrdering bool, unordered map[string]bool) (JSONComparisonResult, error) {
 	var matchJSONComparisonResult JSONComparisonResult
 	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
 		return matchJSONComparisonResult, errors.New("type not matched")
@@ -336,7 +388,7 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 			if !ok {
 				return matchJSONComparisonResult, nil
 			}
-			if valueMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(prefix+k, v, val, noiseMap, ignoreOrdering); !valueMatchJSONComparisonResult.matches || er != nil {
+			if valueMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(prefix+k, v, val, noiseMap, ignoreOrdering, unordered); !valueMatchJSONComparisonResult.matches || er != nil {
 				return valueMatchJSONComparisonResult, nil
 			} else if !valueMatchJSONComparisonResult.isExact {
 				isExact = false
@@ -375,7 +427,7 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 		for i := 0; i < expSlice.Len(); i++ {
 			matched := false
 			for j := 0; j < actSlice.Len(); j++ {
-				if valMatchJSONComparisonResult, err := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap, ignoreOrdering); err == nil && valMatchJSONComparisonResult.matches {
+				if valMatchJSONComparisonResult, err := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap, ignoreOrdering, unordered); err == nil && valMatchJSONComparisonResult.matches {
 					if !valMatchJSONComparisonResult.isExact {
 						for _, val := range valMatchJSONComparisonResult.differences {
 							prefixedVal := key + "[" + fmt.Sprint(j) + "]." + val // Prefix the value
@@ -398,9 +450,9 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 			matchJSONComparisonResult.isExact = isExact
 			return matchJSONComparisonResult, nil
 		}
-		if !ignoreOrdering {
+		if !ignoreOrdering && !unordered[key] {
 			for i := 0; i < expSlice.Len(); i++ {
-				if valMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(i).Interface(), noiseMap, ignoreOrdering); er != nil || !valMatchJSONComparisonResult.isExact {
+				if valMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(i).Interface(), noiseMap, ignoreOrdering, unordered); er != nil || !valMatchJSONComparisonResult.isExact {
 					isExact = false
 					break
 				}
@@ -771,6 +823,15 @@ func checkKey(res *[]models.HeaderResult, key string) bool {
 	return true
 }
 
+func containsHeader(headers []string, key string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h, key) {
+			return true
+		}
+	}
+	return false
+}
+
 func CompareHeaders(h1 http.Header, h2 http.Header, res *[]models.HeaderResult, noise map[string][]string) bool {
 	if res == nil {
 		return false
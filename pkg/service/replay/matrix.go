@@ -0,0 +1,154 @@
+package replay
+
+import (
+	"context"
+	"sort"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+)
+
+// envSets returns the env sets each test set is run under, a single unnamed one when there is no matrix
+func (r *replayer) envSets() []*config.EnvSet {
+	if len(r.config.Test.EnvMatrix) == 0 {
+		return []*config.EnvSet{nil}
+	}
+	sets := make([]*config.EnvSet, len(r.config.Test.EnvMatrix))
+	for i := range r.config.Test.EnvMatrix {
+		sets[i] = &r.config.Test.EnvMatrix[i]
+	}
+	return sets
+}
+
+// reportID is the id the results of the test set are written under, which tells apart its runs under the env sets
+func (r *replayer) reportID(testSetID string) string {
+	set := r.currentEnvSet()
+	if set == nil {
+		return testSetID
+	}
+	return testSetID + "@" + set.Name
+}
+
+// reportIDs returns the ids of the results of all the runs of the test set in the matrix
+func (r *replayer) reportIDs(testSetID string) []string {
+	if len(r.config.Test.EnvMatrix) == 0 {
+		return []string{testSetID}
+	}
+	var ids []string
+	for _, set := range r.config.Test.EnvMatrix {
+		ids = append(ids, testSetID+"@"+set.Name)
+	}
+	return ids
+}
+
+func (r *replayer) envSetName() string {
+	set := r.currentEnvSet()
+	if set == nil {
+		return ""
+	}
+	return set.Name
+}
+
+// runOptions launches the app with the variables of the current env set
+func (r *replayer) runOptions() models.RunOptions {
+	set := r.currentEnvSet()
+	if set == nil {
+		return models.RunOptions{}
+	}
+	return models.RunOptions{Env: set.Env}
+}
+
+// currentEnvSet returns the env set the current test set is run under, nil without a matrix
+func (r *replayer) currentEnvSet() *config.EnvSet {
+	r.envMu.RLock()
+	defer r.envMu.RUnlock()
+	return r.envSet
+}
+
+func (r *replayer) setEnvSet(set *config.EnvSet) {
+	r.envMu.Lock()
+	defer r.envMu.Unlock()
+	r.envSet = set
+}
+
+// envMatrix returns the status of each test set under each env set, from the verdicts of the test run
+func envMatrix(verdicts []models.TestSetVerdict) []models.EnvMatrixRow {
+	rows := map[string]*models.EnvMatrixRow{}
+	var testSets []string
+	for _, v := range verdicts {
+		if v.EnvSet == "" {
+			continue
+		}
+		row, ok := rows[v.TestSet]
+		if !ok {
+			row = &models.EnvMatrixRow{TestSet: v.TestSet, Statuses: map[string]string{}}
+			rows[v.TestSet] = row
+			testSets = append(testSets, v.TestSet)
+		}
+		row.Statuses[v.EnvSet] = v.Status
+	}
+	sort.Strings(testSets)
+	matrix := make([]models.EnvMatrixRow, 0, len(testSets))
+	for _, id := range testSets {
+		matrix = append(matrix, *rows[id])
+	}
+	return matrix
+}
+
+// runMatrix runs the test set under every env set of the matrix. It returns the first status which stops the test
+// run, else failed if the test set failed under any env set.
+func (r *replayer) runMatrix(ctx context.Context, testSetID, testRunID string, appID uint64) (models.TestSetStatus, error) {
+	defer func() {
+		r.setEnvSet(nil)
+	}()
+	result := models.TestSetStatusPassed
+	for _, set := range r.envSets() {
+		r.setEnvSet(set)
+		status, err := r.RunTestSet(ctx, testSetID, testRunID, appID, false)
+		if err != nil {
+			return status, err
+		}
+		switch status {
+		case models.TestSetStatusPassed:
+		case models.TestSetStatusFailed, models.TestSetStatusPreconditionFailed:
+			// the env set may lack a variable the others set
+			result = models.TestSetStatusFailed
+		default:
+			return status, nil
+		}
+	}
+	return result, nil
+}
+
+// printEnvMatrix prints the status of each test set under each env set, a test set being a row of the matrix
+func (r *replayer) printEnvMatrix(matrix []models.EnvMatrixRow) {
+	pp.SetColorScheme(models.PassingColorScheme)
+	if _, err := pp.Printf("\n\n\tEnv Matrix"); err != nil {
+		utils.LogError(r.logger, err, "failed to print the env matrix")
+		return
+	}
+	for _, set := range r.config.Test.EnvMatrix {
+		if _, err := pp.Printf("\t\t%s", set.Name); err != nil {
+			utils.LogError(r.logger, err, "failed to print the env matrix")
+			return
+		}
+	}
+	for _, row := range matrix {
+		if _, err := pp.Printf("\n\t%s", row.TestSet); err != nil {
+			utils.LogError(r.logger, err, "failed to print the env matrix")
+			return
+		}
+		for _, set := range r.config.Test.EnvMatrix {
+			status, ok := row.Statuses[set.Name]
+			if !ok {
+				status = "-"
+			}
+			if _, err := pp.Printf("\t\t%s", status); err != nil {
+				utils.LogError(r.logger, err, "failed to print the env matrix")
+				return
+			}
+		}
+	}
+}
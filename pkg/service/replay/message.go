@@ -0,0 +1,148 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// downstreamPollInterval is how often the outgoing calls are checked while the app processes a message
+const downstreamPollInterval = 100 * time.Millisecond
+
+// publishMessage publishes the message of the test case with the publish command, so that the app consumes it
+// again, and returns the names of the mocks of the outgoing calls made until the expected ones are all made
+// or the api timeout passes. The command reads the value of the message from stdin and its broker, topic,
+// key and headers (as json) from the KEPLOY_MESSAGE_* env vars. The messages captured from the fetches of
+// the app are delivered by the mocked broker instead, once the app fetches their topic again.
+func (r *replayer) publishMessage(ctx context.Context, appID uint64, tc *models.TestCase, expected []string, mocksEnabled bool) ([]string, error) {
+	if tc.Message.Fetched {
+		if !mocksEnabled {
+			return nil, errors.New("the messages captured from the fetches of the app are delivered by the mocked broker, hence the mocks are required")
+		}
+		r.logger.Debug("waiting for the app to fetch the message from the mocked broker", zap.String("testcase", tc.Name), zap.String("topic", tc.Message.Topic))
+		return r.awaitDownstream(ctx, appID, expected)
+	}
+	if r.config.Test.PublishCommand == "" {
+		return nil, errors.New("the publish command is required to run the test cases triggered by a message, set it with --publishCommand")
+	}
+	headers, err := json.Marshal(tc.Message.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", r.config.Test.PublishCommand)
+	cmd.Stdin = strings.NewReader(tc.Message.Value)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		"KEPLOY_MESSAGE_BROKER="+tc.Message.Broker,
+		"KEPLOY_MESSAGE_TOPIC="+tc.Message.Topic,
+		"KEPLOY_MESSAGE_KEY="+tc.Message.Key,
+		"KEPLOY_MESSAGE_HEADERS="+string(headers),
+	)
+	r.logger.Debug("publishing the message", zap.String("testcase", tc.Name), zap.String("topic", tc.Message.Topic))
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(strings.TrimSpace(err.Error() + ": " + stderr.String()))
+	}
+	if !mocksEnabled || len(expected) == 0 {
+		return nil, nil
+	}
+	return r.awaitDownstream(ctx, appID, expected)
+}
+
+// awaitDownstream returns the names of the mocks of the outgoing calls made until the expected ones are all made
+// or the api timeout passes
+func (r *replayer) awaitDownstream(ctx context.Context, appID uint64, expected []string) ([]string, error) {
+	// the consumed mocks are reset on every read, so they are gathered until all the expected ones are made
+	var consumed []string
+	seen := map[string]bool{}
+	timeout := time.NewTimer(time.Duration(r.config.Test.APITimeout) * time.Second)
+	defer timeout.Stop()
+	ticker := time.NewTicker(downstreamPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return consumed, ctx.Err()
+		case <-timeout.C:
+			return consumed, nil
+		case <-ticker.C:
+		}
+		names, err := r.instrumentation.GetConsumedMocks(ctx, appID)
+		if err != nil {
+			return consumed, err
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				consumed = append(consumed, name)
+			}
+		}
+		if len(missingCalls(expected, seen)) == 0 {
+			return consumed, nil
+		}
+	}
+}
+
+// compareDownstream passes the message test case when all the outgoing calls recorded while the message was
+// processed are made again. Nothing can be verified when the outgoing calls aren't mocked.
+func compareDownstream(expected, actual []string, mocksEnabled bool) (bool, *models.Result) {
+	result := &models.Result{
+		Downstream: &models.DownstreamResult{
+			Normal:   true,
+			Expected: expected,
+			Actual:   actual,
+		},
+	}
+	if !mocksEnabled {
+		return true, result
+	}
+	made := make(map[string]bool, len(actual))
+	for _, name := range actual {
+		made[name] = true
+	}
+	if missing := missingCalls(expected, made); len(missing) > 0 {
+		result.Downstream.Normal = false
+		result.Downstream.Missing = missing
+	}
+	return result.Downstream.Normal, result
+}
+
+func missingCalls(expected []string, made map[string]bool) []string {
+	var missing []string
+	for _, name := range expected {
+		if !made[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// testCaseWindow returns the time span in which the outgoing calls of the test case were recorded
+func testCaseWindow(tc *models.TestCase) (time.Time, time.Time) {
+	switch tc.Kind {
+	case models.MESSAGE:
+		return tc.Message.Consumed, tc.Message.Processed
+	case models.JOB:
+		return tc.Job.Started, tc.Job.Finished
+	default:
+		return tc.HTTPReq.Timestamp, tc.HTTPResp.Timestamp
+	}
+}
+
+func namesOf(mocks []*models.Mock) []string {
+	names := make([]string, 0, len(mocks))
+	for _, mock := range mocks {
+		names = append(names, mock.Name)
+	}
+	return names
+}
@@ -0,0 +1,95 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// mockReloadDelay lets an editor finish writing the mock file before it is read again
+const mockReloadDelay = 300 * time.Millisecond
+
+// watchMocks reloads the mocks of the test set into the proxy whenever its mock files change,
+// so that a hand edited mock is served without restarting. A mock file which fails to decode
+// leaves the mocks being served as they were. The watcher runs in the error group of the context.
+func (r *replayer) watchMocks(ctx context.Context, appID uint64, testSetID string) error {
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		return errors.New("failed to get the error group from the context")
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(r.config.Path, testSetID)
+	// the directory is watched rather than the files as editors often replace the file on save
+	err = watcher.Add(dir)
+	if err != nil {
+		closeErr := watcher.Close()
+		if closeErr != nil {
+			utils.LogError(r.logger, closeErr, "failed to close the mock watcher")
+		}
+		return err
+	}
+
+	g.Go(func() error {
+		defer utils.Recover(r.logger)
+		defer func() {
+			err := watcher.Close()
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to close the mock watcher")
+			}
+		}()
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				if filepath.Ext(event.Name) != ".yaml" || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) {
+					continue
+				}
+				reload = time.After(mockReloadDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+				utils.LogError(r.logger, err, "failed to watch the mock files", zap.String("dir", dir))
+			case <-reload:
+				reload = nil
+				r.reloadMocks(ctx, appID, testSetID)
+			}
+		}
+	})
+	r.logger.Info("watching the mock files for changes", zap.String("dir", dir))
+	return nil
+}
+
+func (r *replayer) reloadMocks(ctx context.Context, appID uint64, testSetID string) {
+	filteredMocks, err := r.mockDB.GetFilteredMocks(ctx, testSetID, time.Time{}, time.Now())
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to reload the filtered mocks, serving the previous ones")
+		return
+	}
+	unfilteredMocks, err := r.mockDB.GetUnFilteredMocks(ctx, testSetID, time.Time{}, time.Now())
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to reload the unfiltered mocks, serving the previous ones")
+		return
+	}
+	err = r.instrumentation.SetMocks(ctx, appID, filteredMocks, unfilteredMocks)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to set the reloaded mocks")
+		return
+	}
+	r.logger.Info("reloaded the mocks", zap.Int("mocks", len(filteredMocks)+len(unfilteredMocks)))
+}
@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// testSetRun is the outcome of a test set run by a worker of the scheduler
+type testSetRun struct {
+	testSetID string
+	status    models.TestSetStatus
+	err       error
+}
+
+// scheduler runs the test sets on a pool of workers, each against its own app. A test set is handed to a worker
+// only after the previous results were processed, so that a test set stopping the test run keeps the pending ones
+// from starting. With a single worker the test sets are run one after the other.
+type scheduler struct {
+	cancel  context.CancelFunc
+	pending []string
+	work    chan string
+	results chan testSetRun
+	running int
+	wg      sync.WaitGroup
+}
+
+// parallelism returns the number of the test sets run at once
+func (r *replayer) parallelism() int {
+	if r.config.Test.Parallel < 1 {
+		return 1
+	}
+	return r.config.Test.Parallel
+}
+
+// workerApps returns the app of each worker. The first worker runs the app booted with the test run, the others
+// set up their own instances once, in their own network namespaces, which share its hooks and proxy. The test sets
+// aren't run in parallel against a kubernetes target, which has a single instance of the app.
+func (r *replayer) workerApps(ctx context.Context, appID uint64) ([]uint64, error) {
+	workers := r.parallelism()
+	for len(r.parallelApps) < workers-1 {
+		id, err := r.instrumentation.Setup(ctx, r.config.Command, r.setupOptions())
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup the app of a parallel worker: %w", err)
+		}
+		err = r.instrumentation.Hook(ctx, id, models.HookOptions{Mode: models.MODE_TEST})
+		if err != nil {
+			return nil, fmt.Errorf("failed to hook the app of a parallel worker: %w", err)
+		}
+		r.parallelApps = append(r.parallelApps, id)
+	}
+	return append([]uint64{appID}, r.parallelApps[:workers-1]...), nil
+}
+
+// startScheduler starts a worker for each of the apps
+func (r *replayer) startScheduler(ctx context.Context, testRunID string, testSetIDs []string, apps []uint64) *scheduler {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &scheduler{
+		cancel:  cancel,
+		pending: testSetIDs,
+		work:    make(chan string),
+		results: make(chan testSetRun, len(apps)),
+	}
+	for _, appID := range apps {
+		s.wg.Add(1)
+		go func(appID uint64) {
+			defer s.wg.Done()
+			for testSetID := range s.work {
+				s.results <- r.runScheduled(ctx, testSetID, testRunID, appID, len(apps) == 1)
+			}
+		}(appID)
+	}
+	return s
+}
+
+// runScheduled runs the test set handed to a worker. A panic of the test set is recovered as its result, so that
+// the scheduler waiting for the result isn't blocked forever.
+func (r *replayer) runScheduled(ctx context.Context, testSetID, testRunID string, appID uint64, matrix bool) (run testSetRun) {
+	run.testSetID = testSetID
+	defer func() {
+		if p := recover(); p != nil {
+			utils.LogError(r.logger, nil, "recovered from a panic while running the test set", zap.String("testSet", testSetID), zap.String("stack trace", string(debug.Stack())))
+			run.status = models.TestSetStatusInternalErr
+			run.err = fmt.Errorf("panic while running the test set %s: %v", testSetID, p)
+		}
+	}()
+	if matrix {
+		run.status, run.err = r.runMatrix(ctx, testSetID, testRunID, appID)
+	} else {
+		// the env matrix isn't run in parallel, its env set being shared by the test sets
+		run.status, run.err = r.RunTestSet(ctx, testSetID, testRunID, appID, false)
+	}
+	return run
+}
+
+// next hands the pending test sets to the idle workers and returns the first result, false once all the test sets
+// were run
+func (s *scheduler) next() (testSetRun, bool) {
+	for s.running < cap(s.results) && len(s.pending) > 0 {
+		s.work <- s.pending[0]
+		s.pending = s.pending[1:]
+		s.running++
+	}
+	if s.running == 0 {
+		return testSetRun{}, false
+	}
+	run := <-s.results
+	s.running--
+	return run, true
+}
+
+// stop aborts the test sets still running and waits for the workers
+func (s *scheduler) stop() {
+	s.cancel()
+	close(s.work)
+	s.wg.Wait()
+}
@@ -0,0 +1,227 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/validate"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// noiseParts are the parts of the responses the noise of the config and of the test cases can refer to
+var noiseParts = map[string]bool{"body": true, "header": true, "trailer": true}
+
+// preflight checks that the test set can be run before the app is launched for it: its mocks parse, the noise of
+// the config refers to the fields of its recorded responses and the environment variables the app needs are set.
+// It returns the problems found, none when the test set can be run.
+func (r *replayer) preflight(testSetID string, testCases []*models.TestCase, mocksEnabled bool) []string {
+	var problems []string
+	if mocksEnabled {
+		problems = append(problems, r.checkMocks(testSetID)...)
+	}
+	problems = append(problems, r.checkNoise(testSetID, testCases)...)
+	problems = append(problems, r.checkEnv()...)
+	return problems
+}
+
+// failPreflight writes the report of the test set which failed its pre-flight checks, none of its test cases being run
+func (r *replayer) failPreflight(ctx context.Context, testRunID, testSetID, reportID string, total int, problems []string) (models.TestSetStatus, error) {
+	utils.LogError(r.logger, nil, "the test set failed its pre-flight checks", zap.String("testSet", testSetID), zap.Strings("problems", problems))
+	status := models.TestSetStatusPreconditionFailed
+	testReport := &models.TestReport{
+		Version:     models.GetVersion(),
+		TestSet:     testSetID,
+		Status:      string(status),
+		Total:       total,
+		EnvSet:      r.envSetName(),
+		ErrorType:   models.ReplaySetupError,
+		Error:       strings.Join(problems, "; "),
+		Environment: r.reportEnvironment(testRunID),
+	}
+	err := r.reportDB.InsertReport(ctx, testRunID, reportID, testReport)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to insert report")
+		return models.TestSetStatusInternalErr, &StorageError{Err: fmt.Errorf("failed to insert report: %w", err)}
+	}
+	r.addTestSetVerdict(testRunID, testSetID, testReport, status)
+	r.telemetry.TestSetRun(0, 0, testSetID, string(status))
+	return status, nil
+}
+
+// checkMocks checks the mocks of the test set against the schemas of their version
+func (r *replayer) checkMocks(testSetID string) []string {
+	name := r.mockDB.MockFileName() + ".yaml"
+	file := filepath.Join(r.config.Path, testSetID, name)
+	// the test set recorded no outgoing call
+	if _, err := os.Stat(file); err != nil {
+		return nil
+	}
+	var problems []string
+	for _, issue := range validate.Mocks(file) {
+		location := name
+		if issue.Doc > 0 {
+			location = fmt.Sprintf("document %d of %s", issue.Doc, name)
+		}
+		if issue.Name != "" {
+			location += " (" + issue.Name + ")"
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s", location, issue.Message))
+	}
+	return problems
+}
+
+// checkNoise checks the noise of the config and of the test cases. Their fields and values are regular expressions,
+// and the body and header fields of the noise of the test set should match a field of its recorded http responses.
+// The global noise isn't checked against the responses, since it may refer to the fields of other test sets.
+func (r *replayer) checkNoise(testSetID string, testCases []*models.TestCase) []string {
+	r.reloadMu.RLock()
+	globalNoise := r.config.Test.GlobalNoise
+	r.reloadMu.RUnlock()
+
+	recorded := recordedFields(testCases)
+	var problems []string
+	for _, scope := range []struct {
+		name  string
+		noise map[string]map[string][]string
+	}{{"global", globalNoise.Global}, {"test-set", globalNoise.Testsets[testSetID]}} {
+		for part, fields := range scope.noise {
+			if !noiseParts[part] {
+				problems = append(problems, fmt.Sprintf("the %s noise refers to %q, which isn't a part of the responses", scope.name, part))
+				continue
+			}
+			for field, regexArr := range fields {
+				if problem := checkNoiseField(part+"."+field, field, regexArr); problem != "" {
+					problems = append(problems, fmt.Sprintf("the %s noise %s", scope.name, problem))
+					continue
+				}
+				if paths, ok := recorded[part]; ok && scope.name == "test-set" && !matchesAnyPath(field, paths) {
+					problems = append(problems, fmt.Sprintf("the %s noise %s.%s matches no field of the recorded responses", scope.name, part, field))
+				}
+			}
+		}
+	}
+
+	for _, tc := range testCases {
+		for key, regexArr := range tc.Noise {
+			part, field, _ := strings.Cut(key, ".")
+			if !noiseParts[part] && !isGraphQLNoise(key) {
+				problems = append(problems, fmt.Sprintf("the noise %q of %s isn't a part of the responses", key, tc.Name))
+				continue
+			}
+			if field == "" {
+				field = key
+			}
+			if problem := checkNoiseField(key, field, regexArr); problem != "" {
+				problems = append(problems, fmt.Sprintf("the noise of %s %s", tc.Name, problem))
+			}
+		}
+	}
+	return problems
+}
+
+// checkNoiseField returns the problem of the noisy field and of the values it's ignored for, if any
+func checkNoiseField(key, field string, regexArr []string) string {
+	if _, err := regexp.Compile(field); err != nil {
+		return fmt.Sprintf("%s isn't a valid regular expression: %v", key, err)
+	}
+	for _, expr := range regexArr {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Sprintf("%s has the value %q, which isn't a valid regular expression: %v", key, expr, err)
+		}
+	}
+	return ""
+}
+
+// recordedFields returns the paths of the json bodies and the names of the headers of the recorded http responses.
+// A part is left out when no response has it, e.g. the body of a test set without json responses.
+func recordedFields(testCases []*models.TestCase) map[string]map[string]bool {
+	recorded := map[string]map[string]bool{}
+	add := func(part, path string) {
+		if recorded[part] == nil {
+			recorded[part] = map[string]bool{}
+		}
+		recorded[part][path] = true
+	}
+	for _, tc := range testCases {
+		if tc.Kind != models.HTTP {
+			continue
+		}
+		for name := range tc.HTTPResp.Header {
+			add("header", name)
+		}
+		bodies := []string{tc.HTTPResp.Body}
+		// the noise of the graphql responses is relative to their data and errors
+		if _, ok := parseGraphQLRequest(tc.HTTPReq); ok {
+			bodies = append(bodies, graphQLResponse(tc.HTTPResp.Body))
+		}
+		for _, body := range bodies {
+			var v interface{}
+			if json.Unmarshal([]byte(body), &v) != nil {
+				continue
+			}
+			jsonPaths("", v, func(path string) { add("body", path) })
+		}
+	}
+	return recorded
+}
+
+// jsonPaths calls add with the path of every field of the json value, the elements of an array sharing its path
+// like the keys of the body noise
+func jsonPaths(key string, v interface{}, add func(string)) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		prefix := ""
+		if key != "" {
+			prefix = key + "."
+		}
+		for k, child := range val {
+			add(prefix + k)
+			jsonPaths(prefix+k, child, add)
+		}
+	case []interface{}:
+		for _, child := range val {
+			jsonPaths(key, child, add)
+		}
+	}
+}
+
+// matchesAnyPath tells if the noisy field, which is compared as is or as a regular expression, matches a path
+func matchesAnyPath(field string, paths map[string]bool) bool {
+	if paths[field] {
+		return true
+	}
+	re, err := regexp.Compile(field)
+	if err != nil {
+		return false
+	}
+	for path := range paths {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEnv checks that the environment variables the app needs are set for it, by keploy or by the env set the test
+// set is run under
+func (r *replayer) checkEnv() []string {
+	set := map[string]bool{}
+	for _, env := range r.runOptions().Env {
+		name, _, _ := strings.Cut(env, "=")
+		set[name] = true
+	}
+	var problems []string
+	for _, name := range r.config.Test.RequiredEnv {
+		if _, ok := os.LookupEnv(name); !ok && !set[name] {
+			problems = append(problems, fmt.Sprintf("the environment variable %s isn't set", name))
+		}
+	}
+	return problems
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime/pprof"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/k0kubun/pp/v3"
@@ -21,10 +25,8 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-var completeTestReport = make(map[string]TestReportVerdict)
-var totalTests int
-var totalTestPassed int
-var totalTestFailed int
+// ErrTestRunNotFound is returned when the reports of a test run which doesn't exist are requested
+var ErrTestRunNotFound = errors.New("test run not found")
 
 type replayer struct {
 	logger          *zap.Logger
@@ -34,6 +36,33 @@ type replayer struct {
 	telemetry       Telemetry
 	instrumentation Instrumentation
 	config          config.Config
+
+	// app shared by the test sets when test.reuseApp is set, it's launched by the first test set and stopped with the test run
+	sharedAppCtx     context.Context
+	sharedAppErrChan chan models.AppError
+	sharedAppStarted bool
+	// sharedAppMu guards the shared app, whose started flag is reset when it stops so that the next test set
+	// launches it again
+	sharedAppMu sync.Mutex
+
+	// seed of the test case shuffling, picked once per test run when test.shuffle is random
+	shuffleSeed *int64
+	seedMu      sync.Mutex
+
+	// envSet is the env set of the matrix the current test set is run under, nil without a matrix
+	envSet *config.EnvSet
+	envMu  sync.RWMutex
+
+	// parallelApps are the apps of the workers running the test sets in parallel, besides the booted one
+	parallelApps []uint64
+
+	// runReports aggregate the test sets of each test run by its id, so that the test runs made at once e.g. through
+	// the graphql server don't mix up their summaries
+	runReports map[string]*models.TestRunReport
+	reportMu   sync.Mutex
+
+	// reloadMu guards the noise and the bypass rules which are reloaded between the test sets
+	reloadMu sync.RWMutex
 }
 
 func NewReplayer(logger *zap.Logger, testDB TestDB, mockDB MockDB, reportDB ReportDB, telemetry Telemetry, instrumentation Instrumentation, config config.Config) Service {
@@ -53,8 +82,11 @@ func (r *replayer) Start(ctx context.Context) error {
 	// creating error group to manage proper shutdown of all the go routines and to propagate the error to the caller
 	g, ctx := errgroup.WithContext(ctx)
 	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
+	ctx = pprof.WithLabels(ctx, pprof.Labels("keploy", "replay"))
+	pprof.SetGoroutineLabels(ctx)
 
-	var stopReason = "replay completed successfully"
+	// runErr is the typed error the test run failed with, which tells why keploy is stopped
+	var runErr error
 	var hookCancel context.CancelFunc
 
 	// defering the stop function to stop keploy in case of any error in record or in case of context cancellation
@@ -63,6 +95,10 @@ func (r *replayer) Start(ctx context.Context) error {
 		case <-ctx.Done():
 			break
 		default:
+			stopReason := "replay completed successfully"
+			if runErr != nil {
+				stopReason = runErr.Error()
+			}
 			err := utils.Stop(r.logger, stopReason)
 			if err != nil {
 				utils.LogError(r.logger, err, "failed to stop recording")
@@ -77,45 +113,141 @@ func (r *replayer) Start(ctx context.Context) error {
 		}
 	}()
 
+	// only the test cases which failed in the previous test run are run again
+	if testRunID := r.config.Test.RerunFailed; testRunID != "" {
+		selected, err := r.selectFailed(ctx, testRunID)
+		if err != nil {
+			runErr = &StorageError{Err: fmt.Errorf("failed to get the failed test cases of %s: %w", testRunID, err)}
+			utils.LogError(r.logger, err, "failed to get the failed test cases", zap.String("testRun", testRunID))
+			return runErr
+		}
+		if len(selected) == 0 {
+			r.logger.Info(fmt.Sprintf("no test case failed in %s, there is nothing to run again", testRunID))
+			return nil
+		}
+		r.logger.Info("running again the test cases which failed", zap.String("testRun", testRunID), zap.Any("testcases", selected))
+		r.config.Test.SelectedTests = selected
+	}
+
 	// BootReplay will start the hooks and proxy and return the testRunID and appID
 	testRunID, appID, hookCancel, err := r.BootReplay(ctx)
 	if err != nil {
-		stopReason = fmt.Sprintf("failed to boot replay: %v", err)
-		utils.LogError(r.logger, err, stopReason)
+		utils.LogError(r.logger, err, "failed to boot replay")
 		if err == context.Canceled {
 			return err
 		}
-		return fmt.Errorf(stopReason)
+		runErr = &SetupError{Err: fmt.Errorf("failed to boot replay: %w", err)}
+		return runErr
+	}
+
+	runErr = r.runTestRun(ctx, testRunID, appID)
+
+	// the test sets are run again on every change of the watched files until keploy is stopped, whether their test
+	// cases failed or not
+	if r.config.Test.Watch && (runErr == nil || isTestFailure(runErr)) {
+		return r.watch(ctx, appID)
+	}
+	return runErr
+}
+
+// runTestRun runs the selected test sets as one test run and writes its report. The returned error is typed by
+// the cause of the failure of the test run, nil if it passed or was stopped by the user.
+func (r *replayer) runTestRun(ctx context.Context, testRunID string, appID uint64) (runErr error) {
+	r.startRunReport(ctx, testRunID)
+	testRunResult := true
+	var abortReason string
+	// the test sets are aborted once the test run has run for the runTimeout, their partial reports being written
+	if r.config.Test.RunTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.Test.RunTimeout)
+		defer cancel()
+	}
+	defer func() {
+		r.completeRunReport(ctx, testRunID, testRunResult, abortReason, errorType(runErr))
+	}()
+
+	// each worker of the parallel test sets runs its own app
+	if r.config.Test.ReuseApp && !r.isK8sTarget() && r.parallelism() == 1 {
+		// the app is stopped with the test run, so that a run started after a change launches it again
+		appErrGrp, _ := errgroup.WithContext(ctx)
+		appCtx, appCancel := context.WithCancel(ctx)
+		appCtx = context.WithValue(appCtx, models.ErrGroupKey, appErrGrp)
+		defer func() {
+			appCancel()
+			err := appErrGrp.Wait()
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to stop the application")
+			}
+		}()
+		r.sharedAppMu.Lock()
+		r.sharedAppCtx = appCtx
+		r.sharedAppErrChan = make(chan models.AppError, 1)
+		r.sharedAppStarted = false
+		r.sharedAppMu.Unlock()
 	}
 
 	testSetIDs, err := r.testDB.GetAllTestSetIDs(ctx)
 	if err != nil {
-		stopReason = fmt.Sprintf("failed to get all test set ids: %v", err)
-		utils.LogError(r.logger, err, stopReason)
+		abortReason = fmt.Sprintf("failed to get all test set ids: %v", err)
+		utils.LogError(r.logger, err, abortReason)
 		if err == context.Canceled {
 			return err
 		}
-		return fmt.Errorf(stopReason)
+		return &StorageError{Err: fmt.Errorf("failed to get all test set ids: %w", err)}
+	}
+
+	// the api coverage is told against the endpoints of all the test sets, the selected ones or not
+	allTestSetIDs := testSetIDs
+
+	testSetIDs, missing := orderTestSets(testSetIDs, r.config.Test.TestSetOrder)
+	if len(missing) != 0 {
+		r.logger.Warn("test sets in the testSetOrder config not found", zap.Strings("test-sets", missing))
+	}
+
+	if r.config.Test.Impact.Base != "" {
+		testSetIDs = r.impactedTestSets(ctx, testSetIDs)
 	}
 
 	testSetResult := false
-	testRunResult := true
 	abortTestRun := false
 
+	var selected []string
 	for _, testSetID := range testSetIDs {
-
 		if _, ok := r.config.Test.SelectedTests[testSetID]; !ok && len(r.config.Test.SelectedTests) != 0 {
 			continue
 		}
+		selected = append(selected, testSetID)
+	}
 
-		testSetStatus, err := r.RunTestSet(ctx, testSetID, testRunID, appID, false)
+	apps, err := r.workerApps(ctx, appID)
+	if err != nil {
+		abortReason = fmt.Sprintf("failed to set up the apps of the parallel test sets: %v", err)
+		utils.LogError(r.logger, err, abortReason)
+		return &SetupError{Err: err}
+	}
+	if len(apps) > 1 && r.config.Test.Shuffle != "" {
+		// the seed is picked before the workers share it
+		if _, err := r.getShuffleSeed(); err != nil {
+			abortReason = fmt.Sprintf("failed to get the shuffle seed: %v", err)
+			return &SetupError{Err: err}
+		}
+	}
+	sched := r.startScheduler(ctx, testRunID, selected, apps)
+	defer sched.stop()
+
+	for {
+		run, ok := sched.next()
+		if !ok {
+			break
+		}
+		testSetID, testSetStatus, err := run.testSetID, run.status, run.err
 		if err != nil {
-			stopReason = fmt.Sprintf("failed to run test set: %v", err)
-			utils.LogError(r.logger, err, stopReason)
+			abortReason = fmt.Sprintf("failed to run test set: %v", err)
+			utils.LogError(r.logger, err, abortReason)
 			if err == context.Canceled {
 				return err
 			}
-			return fmt.Errorf(stopReason)
+			return fmt.Errorf("failed to run the test set %s: %w", testSetID, err)
 		}
 		switch testSetStatus {
 		case models.TestSetStatusAppHalted:
@@ -127,15 +259,21 @@ func (r *replayer) Start(ctx context.Context) error {
 		case models.TestSetStatusFaultUserApp:
 			testSetResult = false
 			abortTestRun = true
+		case models.TestSetStatusTimedOut:
+			abortReason = fmt.Sprintf("%s in %s", testSetStatus, testSetID)
+			r.logger.Warn(fmt.Sprintf("the test run exceeded the runTimeout of %s", r.config.Test.RunTimeout), zap.String("testSet", testSetID))
+			return fmt.Errorf("%w of %s", ErrRunTimedOut, r.config.Test.RunTimeout)
 		case models.TestSetStatusUserAbort:
+			abortReason = fmt.Sprintf("%s in %s", testSetStatus, testSetID)
 			return nil
-		case models.TestSetStatusFailed:
+		case models.TestSetStatusFailed, models.TestSetStatusPreconditionFailed:
 			testSetResult = false
 		case models.TestSetStatusPassed:
 			testSetResult = true
 		}
 		testRunResult = testRunResult && testSetResult
 		if abortTestRun {
+			abortReason = fmt.Sprintf("%s in %s", testSetStatus, testSetID)
 			break
 		}
 	}
@@ -144,12 +282,17 @@ func (r *replayer) Start(ctx context.Context) error {
 	if testRunResult {
 		testRunStatus = "pass"
 	}
-	r.telemetry.TestRun(totalTestPassed, totalTestFailed, len(testSetIDs), testRunStatus)
+	report := r.runReportOf(testRunID)
+	r.telemetry.TestRun(report.Success, report.Failure, len(testSetIDs), testRunStatus)
+
+	r.apiCoverage(ctx, testRunID, allTestSetIDs)
 
 	if !abortTestRun {
-		r.printSummary(ctx, testRunResult)
+		r.printSummary(ctx, testRunID, testRunResult)
+		r.writeHTMLReport(ctx, testRunID, testRunResult)
 	}
-	return nil
+
+	return runError(r.runReportOf(testRunID).TestSets)
 }
 
 func (r *replayer) BootReplay(ctx context.Context) (string, uint64, context.CancelFunc, error) {
@@ -166,7 +309,19 @@ func (r *replayer) BootReplay(ctx context.Context) (string, uint64, context.Canc
 
 	newTestRunID := pkg.NewID(testRunIDs, models.TestRunTemplateName)
 
-	appID, err := r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay})
+	// replaying against a deployed kubernetes workload doesn't require hooks, proxy or the application command
+	if r.isK8sTarget() {
+		cancel, err = r.startPortForward(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return "", 0, nil, err
+			}
+			return "", 0, nil, fmt.Errorf("failed to port-forward to the kubernetes target: %w", err)
+		}
+		return newTestRunID, 0, cancel, nil
+	}
+
+	appID, err := r.instrumentation.Setup(ctx, r.config.Command, r.setupOptions())
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return "", 0, nil, err
@@ -194,6 +349,10 @@ func (r *replayer) BootReplay(ctx context.Context) (string, uint64, context.Canc
 	return newTestRunID, appID, cancel, nil
 }
 
+func (r *replayer) setupOptions() models.SetupOptions {
+	return models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay, IsolateNetwork: r.config.Test.IsolateNetwork}
+}
+
 func (r *replayer) GetAllTestSetIDs(ctx context.Context) ([]string, error) {
 	return r.testDB.GetAllTestSetIDs(ctx)
 }
@@ -220,59 +379,142 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 	var appErr models.AppError
 	var success int
 	var failure int
+	var skipped, expectedFailures, unexpectedPasses int
 	var totalConsumedMocks = map[string]bool{}
 
 	testSetStatus := models.TestSetStatusPassed
 	testSetStatusByErrChan := models.TestSetStatusRunning
+	// the exit of the app during the test set, along with the test case in flight, is collected in a crash bundle
+	var appCrash atomic.Pointer[models.AppError]
+	// the error the app failed with, which is written in the report of the test set
+	var appFailure atomic.Pointer[models.AppError]
+	// the failing test cases whose recorded outgoing calls weren't all made against the mocks
+	var mismatched []string
+	var inFlight string
+	testSetStarted := time.Now()
+
+	// the results of the test set are written under the env set it's run under, if any
+	reportID := r.reportID(testSetID)
 
-	r.logger.Info("running", zap.Any("test-set", models.HighlightString(testSetID)))
+	r.logger.Info("running", zap.Any("test-set", models.HighlightString(testSetID)), zap.String("envSet", r.envSetName()))
 
 	testCases, err := r.testDB.GetTestCases(runTestSetCtx, testSetID)
 	if err != nil {
-		return models.TestSetStatusFailed, fmt.Errorf("failed to get test cases: %w", err)
+		return models.TestSetStatusFailed, &StorageError{Err: fmt.Errorf("failed to get test cases: %w", err)}
 	}
 
 	if len(testCases) == 0 {
 		return models.TestSetStatusPassed, nil
 	}
 
-	filteredMocks, err := r.mockDB.GetFilteredMocks(runTestSetCtx, testSetID, time.Time{}, time.Now())
-	if err != nil {
-		utils.LogError(r.logger, err, "failed to get filtered mocks")
-		return models.TestSetStatusFailed, err
+	if r.config.Test.Shuffle != "" {
+		seed, err := r.getShuffleSeed()
+		if err != nil {
+			return models.TestSetStatusFailed, &SetupError{Err: err}
+		}
+		shuffleTestCases(testCases, seed)
 	}
-	unfilteredMocks, err := r.mockDB.GetUnFilteredMocks(runTestSetCtx, testSetID, time.Time{}, time.Now())
+
+	scenarios, err := r.testDB.GetScenarios(runTestSetCtx, testSetID)
 	if err != nil {
-		utils.LogError(r.logger, err, "failed to get unfiltered mocks")
-		return models.TestSetStatusFailed, err
+		return models.TestSetStatusFailed, &StorageError{Err: fmt.Errorf("failed to get scenarios: %w", err)}
 	}
+	steps := scenarioSteps(scenarios)
+	testCases = groupScenarios(testCases, steps)
 
-	err = r.instrumentation.MockOutgoing(runTestSetCtx, appID, models.OutgoingOptions{
-		Rules:         r.config.BypassRules,
-		MongoPassword: r.config.Test.MongoPassword,
-		SQLDelay:      time.Duration(r.config.Test.Delay),
-	})
-	if err != nil {
-		utils.LogError(r.logger, err, "failed to mock outgoing")
-		return models.TestSetStatusFailed, err
+	// mocks are disabled while replaying against a deployed kubernetes workload
+	mocksEnabled := !r.isK8sTarget()
+
+	// the job is run by its test cases, so the app isn't started beforehand
+	jobTestSet := isJobTestSet(testCases)
+	if jobTestSet && !mocksEnabled {
+		return models.TestSetStatusFailed, &SetupError{Err: fmt.Errorf("the test set %s records the runs of a job, which can't be run against a kubernetes workload", testSetID)}
 	}
 
-	err = r.instrumentation.SetMocks(runTestSetCtx, appID, filteredMocks, unfilteredMocks)
-	if err != nil {
-		utils.LogError(r.logger, err, "failed to set mocks")
-		return models.TestSetStatusFailed, err
+	// the test set fails fast when it can't be run, before its mocks are loaded and the app is launched
+	if problems := r.preflight(testSetID, testCases, mocksEnabled); len(problems) > 0 {
+		total := len(testCases)
+		if selected := r.config.Test.SelectedTests[testSetID]; len(selected) != 0 {
+			total = len(selected)
+		}
+		return r.failPreflight(runTestSetCtx, testRunID, testSetID, reportID, total, problems)
 	}
 
-	if !serveTest {
-		runTestSetErrGrp.Go(func() error {
-			defer utils.Recover(r.logger)
-			appErr = r.RunApplication(runTestSetCtx, appID, models.RunOptions{})
-			if appErr.AppErrorType == models.ErrCtxCanceled {
-				return nil
+	// the mocks recorded for each test case are preferred over filtering them by timestamps
+	var mappings map[string][]string
+	if mocksEnabled {
+		mappings, err = r.mockDB.GetMappings(runTestSetCtx, testSetID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the mock mappings")
+			return models.TestSetStatusFailed, &StorageError{Err: err}
+		}
+	}
+
+	if mocksEnabled {
+		filteredMocks, err := r.mockDB.GetFilteredMocks(runTestSetCtx, testSetID, time.Time{}, time.Now())
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get filtered mocks")
+			return models.TestSetStatusFailed, &StorageError{Err: err}
+		}
+		unfilteredMocks, err := r.mockDB.GetUnFilteredMocks(runTestSetCtx, testSetID, time.Time{}, time.Now())
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get unfiltered mocks")
+			return models.TestSetStatusFailed, &StorageError{Err: err}
+		}
+
+		outgoingOpts := models.OutgoingOptions{
+			Rules:           r.bypassRules(),
+			MongoPassword:   r.config.Test.MongoPassword,
+			SQLDelay:        time.Duration(r.config.Test.Delay),
+			SQLFingerprint:  r.config.Test.SQLFingerprint,
+			GenericVerbatim: r.config.Test.GenericReplay == genericReplayVerbatim,
+		}
+		if r.config.Test.DebugMatching {
+			// the calls matching no mock are traced next to the reports of the test run
+			outgoingOpts.MatchDebug = &models.MatchDebugOptions{
+				Path:    filepath.Join(r.config.Path, "reports", testRunID, "matching.yaml"),
+				TestSet: testSetID,
 			}
-			appErrChan <- appErr
-			return nil
-		})
+		}
+		err = r.instrumentation.MockOutgoing(runTestSetCtx, appID, outgoingOpts)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to mock outgoing")
+			return models.TestSetStatusFailed, &SetupError{Err: err}
+		}
+
+		err = r.instrumentation.SetMocks(runTestSetCtx, appID, filteredMocks, unfilteredMocks)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to set mocks")
+			return models.TestSetStatusFailed, &SetupError{Err: err}
+		}
+	}
+
+	// the shared app keeps running across the test sets, only the mocks are swapped for each of them
+	// the app is launched again for every env set of the matrix, so it isn't shared then
+	_, sharedAppErrChan := r.sharedApp()
+	reuseApp := sharedAppErrChan != nil && !serveTest && !jobTestSet && r.currentEnvSet() == nil
+	appRunning := reuseApp && r.isSharedAppStarted()
+
+	if !serveTest && mocksEnabled && !appRunning && !jobTestSet {
+		if reuseApp {
+			err := r.startSharedApp(appID)
+			if err != nil {
+				return models.TestSetStatusInternalErr, &SetupError{Err: err}
+			}
+		} else {
+			runTestSetErrGrp.Go(func() error {
+				defer utils.Recover(r.logger)
+				appErr = r.RunApplication(runTestSetCtx, appID, r.runOptions())
+				if appErr.AppErrorType == models.ErrCtxCanceled {
+					return nil
+				}
+				appErrChan <- appErr
+				return nil
+			})
+		}
+	}
+	if reuseApp {
+		appErrChan = sharedAppErrChan
 	}
 
 	// Checking for errors in the mocking and application
@@ -280,13 +522,18 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		defer utils.Recover(r.logger)
 		select {
 		case err := <-appErrChan:
+			if err.AppErrorType != models.ErrCtxCanceled {
+				appFailure.Store(&err)
+			}
 			switch err.AppErrorType {
 			case models.ErrCommandError:
 				testSetStatusByErrChan = models.TestSetStatusFaultUserApp
 			case models.ErrUnExpected:
 				testSetStatusByErrChan = models.TestSetStatusAppHalted
+				appCrash.Store(&err)
 			case models.ErrAppStopped:
 				testSetStatusByErrChan = models.TestSetStatusAppHalted
+				appCrash.Store(&err)
 			case models.ErrCtxCanceled:
 				return nil
 			case models.ErrInternal:
@@ -296,7 +543,7 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 			}
 			utils.LogError(r.logger, err, "application failed to run")
 		case <-runTestSetCtx.Done():
-			testSetStatusByErrChan = models.TestSetStatusUserAbort
+			testSetStatusByErrChan = abortStatus(runTestSetCtx)
 		}
 		exitLoopChan <- true
 		runTestSetCtxCancel()
@@ -304,13 +551,25 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 	})
 
 	// Delay for user application to run
-	select {
-	case <-time.After(time.Duration(r.config.Test.Delay) * time.Second):
-	case <-runTestSetCtx.Done():
-		return models.TestSetStatusUserAbort, context.Canceled
+	if mocksEnabled && !appRunning && !jobTestSet {
+		select {
+		case <-time.After(time.Duration(r.config.Test.Delay) * time.Second):
+		case <-runTestSetCtx.Done():
+			return abortStatus(runTestSetCtx), context.Canceled
+		}
+	}
+
+	// a fresh token replaces the recorded ones, which have usually expired by the time the test set is run
+	authValue, err := r.authHeader(runTestSetCtx)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to obtain a token for the test set", zap.String("testSet", testSetID), zap.String("auth", r.config.Test.Auth.Type))
+		return models.TestSetStatusFailed, &SetupError{Err: err}
 	}
 
 	selectedTests := ArrayToMap(r.config.Test.SelectedTests[testSetID])
+	selectScenarios(selectedTests, scenarios)
+	masker := pkg.NewMasker(r.config.Masking.Fields)
+	schemas := inferSchemas(testCases)
 
 	testCasesCount := len(testCases)
 
@@ -320,15 +579,16 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 
 	// Inserting the initial report for the test set
 	testReport := &models.TestReport{
-		Version: models.GetVersion(),
-		Total:   testCasesCount,
-		Status:  string(models.TestStatusRunning),
+		Version:     models.GetVersion(),
+		Total:       testCasesCount,
+		Status:      string(models.TestStatusRunning),
+		Environment: r.reportEnvironment(testRunID),
 	}
 
-	err = r.reportDB.InsertReport(runTestSetCtx, testRunID, testSetID, testReport)
+	err = r.reportDB.InsertReport(runTestSetCtx, testRunID, reportID, testReport)
 	if err != nil {
 		utils.LogError(r.logger, err, "failed to insert report")
-		return models.TestSetStatusFailed, err
+		return models.TestSetStatusFailed, &StorageError{Err: err}
 	}
 
 	// var to exit the loop
@@ -354,63 +614,274 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 			break
 		}
 
+		if testCase.Skip {
+			r.logger.Info("skipping the testcase marked with skip", zap.Any("testcase id", testCase.Name), zap.Any("testset id", testSetID))
+			skipped++
+			now := time.Now().UTC().Unix()
+			err = r.reportDB.InsertTestCaseResult(runTestSetCtx, testRunID, reportID, &models.TestResult{
+				Kind:         testCase.Kind,
+				Name:         testSetID,
+				Status:       models.TestStatusSkipped,
+				Started:      now,
+				Completed:    now,
+				TestCaseID:   testCase.Name,
+				TestCasePath: filepath.Join(r.config.Path, testSetID),
+				MockPath:     filepath.Join(r.config.Path, testSetID, r.mockDB.MockFileName()+".yaml"),
+				Noise:        testCase.Noise,
+			})
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to insert test case result")
+				loopErr = &StorageError{Err: err}
+				break
+			}
+			continue
+		}
+
 		var testStatus models.TestStatus
 		var testResult *models.Result
 		var testPass bool
+		var downstreamCalls []string
+		var filteredMocks, unfilteredMocks []*models.Mock
+
+		if mocksEnabled {
+			if mockNames, ok := mappings[testCase.Name]; ok {
+				filteredMocks, unfilteredMocks, err = r.getMappedMocks(runTestSetCtx, testSetID, mockNames)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get the mapped mocks", zap.String("testcase", testCase.Name))
+					loopErr = &StorageError{Err: err}
+					break
+				}
+			} else {
+				afterTime, beforeTime := testCaseWindow(testCase)
+				filteredMocks, err = r.mockDB.GetFilteredMocks(runTestSetCtx, testSetID, afterTime, beforeTime)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get filtered mocks")
+					loopErr = &StorageError{Err: err}
+					break
+				}
+				unfilteredMocks, err = r.mockDB.GetUnFilteredMocks(runTestSetCtx, testSetID, afterTime, beforeTime)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get unfiltered mocks")
+					loopErr = &StorageError{Err: err}
+					break
+				}
+			}
 
-		filteredMocks, loopErr := r.mockDB.GetFilteredMocks(runTestSetCtx, testSetID, testCase.HTTPReq.Timestamp, testCase.HTTPResp.Timestamp)
-		if loopErr != nil {
-			utils.LogError(r.logger, err, "failed to get filtered mocks")
-			break
-		}
-		unfilteredMocks, loopErr := r.mockDB.GetUnFilteredMocks(runTestSetCtx, testSetID, testCase.HTTPReq.Timestamp, testCase.HTTPResp.Timestamp)
-		if loopErr != nil {
-			utils.LogError(r.logger, err, "failed to get unfiltered mocks")
-			break
-		}
-
-		loopErr = r.instrumentation.SetMocks(runTestSetCtx, appID, filteredMocks, unfilteredMocks)
-		if loopErr != nil {
-			utils.LogError(r.logger, err, "failed to set mocks")
-			break
+			err = r.instrumentation.SetMocks(runTestSetCtx, appID, filteredMocks, unfilteredMocks)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to set mocks")
+				loopErr = &SetupError{Err: err}
+				break
+			}
+			downstreamCalls = namesOf(filteredMocks)
 		}
 
 		started := time.Now().UTC()
-		resp, loopErr := r.SimulateRequest(runTestSetCtx, appID, testCase, testSetID)
+		inFlight = testCase.Name
+		var resp *models.HTTPResp
+		var grpcResp *models.GrpcResp
+		var wsFrames []models.WebSocketFrame
+		var consumedMocks []string
+		var exitCode int
+		// the first and the second response of a test case simulated twice to verify its idempotency
+		var firstResp, repeatedResp *models.HTTPResp
+		switch testCase.Kind {
+		case models.JOB:
+			exitCode, consumedMocks, loopErr = r.runJob(runTestSetCtx, appID, testCase)
+			if loopErr != nil {
+				utils.LogError(r.logger, loopErr, "failed to run the job", zap.String("testcase", testCase.Name))
+			}
+		case models.MESSAGE:
+			// the outgoing calls made while the app processes the message are what the test case verifies
+			consumedMocks, loopErr = r.publishMessage(runTestSetCtx, appID, testCase, downstreamCalls, mocksEnabled)
+			if loopErr != nil {
+				utils.LogError(r.logger, loopErr, "failed to publish the message", zap.String("testcase", testCase.Name))
+			}
+		case models.GRPC_EXPORT:
+			grpcResp, loopErr = r.simulateGrpc(runTestSetCtx, appID, testCase, testSetID)
+			if loopErr != nil {
+				utils.LogError(r.logger, loopErr, "failed to simulate the grpc request", zap.String("testcase", testCase.Name))
+			}
+			if mocksEnabled {
+				consumedMocks, err = r.instrumentation.GetConsumedMocks(runTestSetCtx, appID)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get consumed filtered mocks")
+				}
+			}
+		case models.WEBSOCKET:
+			wsFrames, loopErr = r.simulateWebSocket(runTestSetCtx, appID, testCase, testSetID)
+			if loopErr != nil {
+				utils.LogError(r.logger, loopErr, "failed to simulate the websocket connection", zap.String("testcase", testCase.Name))
+			}
+			if mocksEnabled {
+				consumedMocks, err = r.instrumentation.GetConsumedMocks(runTestSetCtx, appID)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get consumed filtered mocks")
+				}
+			}
+		default:
+			// the steps of a scenario send the values returned by the previous ones during the test
+			simulated := testCase
+			if step, ok := steps[testCase.Name]; ok {
+				simulated = step.request(testCase)
+			}
+			simulated = withAuth(simulated, r.config.Test.Auth.Header, authValue)
+			simulated, err = rewriteRequest(simulated, r.config.Test.URLRewrites)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to rewrite the url of the testcase", zap.String("testcase", testCase.Name))
+			}
+			resp, loopErr = r.SimulateRequest(runTestSetCtx, appID, simulated, testSetID)
+			if loopErr != nil {
+				utils.LogError(r.logger, err, "failed to simulate request")
+			}
+			if mocksEnabled {
+				consumedMocks, err = r.instrumentation.GetConsumedMocks(runTestSetCtx, appID)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get consumed filtered mocks")
+				}
+			}
+			if _, inScenario := steps[testCase.Name]; loopErr == nil && resp != nil && r.config.Test.Idempotency && !inScenario && isIdempotent(testCase.HTTPReq.Method) {
+				// the first response is kept as it was sent, before it's masked for the comparison
+				first := *resp
+				first.Header = make(map[string]string, len(resp.Header))
+				for k, v := range resp.Header {
+					first.Header[k] = v
+				}
+				firstResp = &first
+				repeatedResp, loopErr = r.simulateAgain(runTestSetCtx, appID, simulated, testSetID, filteredMocks, unfilteredMocks, mocksEnabled)
+				if loopErr != nil {
+					utils.LogError(r.logger, loopErr, "failed to simulate the request again to verify its idempotency", zap.String("testcase", testCase.Name))
+				}
+			}
+		}
 		if loopErr != nil {
-			utils.LogError(r.logger, err, "failed to simulate request")
 			break
 		}
-
-		consumedMocks, err := r.instrumentation.GetConsumedMocks(runTestSetCtx, appID)
-		if err != nil {
-			utils.LogError(r.logger, err, "failed to get consumed filtered mocks")
-		}
+		simulation := time.Since(started)
 		if r.config.Test.RemoveUnusedMocks {
 			for _, mockName := range consumedMocks {
 				totalConsumedMocks[mockName] = true
 			}
 		}
 
-		testPass, testResult = r.compareResp(testCase, resp, testSetID)
+		var mockOrder *models.MockOrderResult
+		if mocksEnabled && r.config.Test.VerifyMockOrder {
+			callOrder, err := r.instrumentation.GetMockCallOrder(runTestSetCtx, appID)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to get the order of the consumed mocks")
+			} else {
+				mockOrder = verifyMockOrder(filteredMocks, callOrder)
+			}
+		}
+
+		switch testCase.Kind {
+		case models.JOB:
+			testPass, testResult = compareJob(testCase, exitCode, downstreamCalls, consumedMocks)
+			if !testPass {
+				r.logger.Info("the job didn't exit or make the outgoing calls as recorded", zap.Any("testcase id", testCase.Name), zap.Int("exit code", exitCode), zap.Strings("missing", testResult.Downstream.Missing))
+			}
+		case models.MESSAGE:
+			testPass, testResult = compareDownstream(downstreamCalls, consumedMocks, mocksEnabled)
+			if !testPass {
+				r.logger.Info("the outgoing calls recorded while the message was processed weren't all made", zap.Any("testcase id", testCase.Name), zap.Strings("missing", testResult.Downstream.Missing))
+			}
+		case models.GRPC_EXPORT:
+			testPass, testResult = r.compareGrpc(testCase, grpcResp, testSetID)
+		case models.WEBSOCKET:
+			testPass, testResult = r.compareWebSocket(testCase, wsFrames, testSetID)
+		default:
+			expected := testCase
+			step, inScenario := steps[testCase.Name]
+			if inScenario && resp != nil {
+				step.capture(resp)
+				expected = step.expected(testCase)
+			}
+			// the recorded response holds the hashes of the masked fields, so compare against the masked actual response
+			if resp != nil {
+				masker.MaskHTTPResp(resp)
+			}
+			testPass, testResult = r.compareResp(expected, resp, testSetID)
+			if inScenario && resp != nil && testResult != nil {
+				var assertPass bool
+				assertPass, testResult.Assertions = step.assert(resp)
+				if !assertPass {
+					r.logger.Info("the assertions of the scenario step failed", zap.Any("testcase id", testCase.Name), zap.Any("assertions", testResult.Assertions))
+					testPass = false
+				}
+			}
+		}
+		// the data persisted by the test case is verified against the test database
+		if len(testCase.Verify) > 0 && testResult != nil {
+			var statePass bool
+			statePass, testResult.StateChecks = r.checkState(runTestSetCtx, testCase, testSetID)
+			if !statePass {
+				r.logger.Info("the verification queries didn't return the expected data", zap.Any("testcase id", testCase.Name), zap.Any("state checks", testResult.StateChecks))
+				testPass = false
+			}
+		}
+		// the shape of the response is checked against the recorded ones, its noisy fields included
+		if drift := r.config.Test.SchemaDrift; drift != "" && drift != schemaDriftOff && resp != nil && testResult != nil && testCase.Kind == models.HTTP {
+			testResult.SchemaDrift = schemas.drift(testCase, resp)
+			if len(testResult.SchemaDrift) > 0 {
+				r.logger.Warn("the shape of the response drifted from the recorded ones", zap.Any("testcase id", testCase.Name), zap.Any("schema drift", testResult.SchemaDrift))
+				if drift == schemaDriftFail {
+					testPass = false
+				}
+			}
+		}
+		if repeatedResp != nil && testResult != nil {
+			testResult.Idempotency = r.compareRepeated(testCase, firstResp, repeatedResp, testSetID)
+			if !testResult.Idempotency.Normal {
+				r.logger.Info("the response of the request sent again differs from the first one", zap.Any("testcase id", testCase.Name), zap.Any("diffs", testResult.Idempotency.Diffs))
+				testPass = false
+			}
+		}
+		if mockOrder != nil && testResult != nil {
+			testResult.MockOrder = mockOrder
+			if !mockOrder.Normal {
+				r.logger.Info("the outgoing calls were made in a different order than recorded", zap.Any("testcase id", testCase.Name), zap.Strings("expected", mockOrder.Expected), zap.Strings("actual", mockOrder.Actual), zap.Strings("missing", mockOrder.Missing))
+				testPass = false
+			}
+		}
 		if !testPass {
 			// log the consumed mocks during the test run of the test case for test set
 			r.logger.Info("result", zap.Any("testcase id", models.HighlightFailingString(testCase.Name)), zap.Any("testset id", models.HighlightFailingString(testSetID)), zap.Any("passed", models.HighlightFailingString(testPass)), zap.Any("consumed mocks", consumedMocks))
 		} else {
 			r.logger.Info("result", zap.Any("testcase id", models.HighlightPassingString(testCase.Name)), zap.Any("testset id", models.HighlightPassingString(testSetID)), zap.Any("passed", models.HighlightPassingString(testPass)))
 		}
-		if testPass {
+		switch {
+		case testCase.Xfail != "" && testPass:
+			r.logger.Warn("the testcase expected to fail passed, its known issue may be fixed", zap.Any("testcase id", testCase.Name), zap.String("xfail", testCase.Xfail))
+			testStatus = models.TestStatusXPassed
+			unexpectedPasses++
+		case testCase.Xfail != "":
+			r.logger.Info("the testcase failed as expected", zap.Any("testcase id", testCase.Name), zap.String("xfail", testCase.Xfail))
+			testStatus = models.TestStatusXFailed
+			expectedFailures++
+		case testPass:
 			testStatus = models.TestStatusPassed
 			success++
-		} else {
+		default:
 			testStatus = models.TestStatusFailed
 			failure++
 			testSetStatus = models.TestSetStatusFailed
+			// the test case failing while its recorded outgoing calls weren't all made is blamed on the mocks
+			if _, downstream := compareDownstream(downstreamCalls, consumedMocks, mocksEnabled); !downstream.Downstream.Normal {
+				mismatched = append(mismatched, testCase.Name)
+			}
+		}
+
+		var appOutput []models.AppOutputLine
+		if !testPass {
+			appOutput, err = r.instrumentation.GetAppOutput(runTestSetCtx, appID, started, time.Now())
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to get the output of the app", zap.String("testcase", testCase.Name))
+			}
 		}
 
 		if testResult != nil {
 			testCaseResult := &models.TestResult{
-				Kind:       models.HTTP,
+				Kind:       testCase.Kind,
 				Name:       testSetID,
 				Status:     testStatus,
 				Started:    started.Unix(),
@@ -439,26 +910,34 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 					Timestamp:     testCase.HTTPResp.Timestamp,
 				},
 				TestCasePath: filepath.Join(r.config.Path, testSetID),
-				MockPath:     filepath.Join(r.config.Path, testSetID, "mocks.yaml"),
+				MockPath:     filepath.Join(r.config.Path, testSetID, r.mockDB.MockFileName()+".yaml"),
 				Noise:        testCase.Noise,
 				Result:       *testResult,
+				Xfail:        testCase.Xfail,
+				Duration:     simulation.Milliseconds(),
+				AppOutput:    appOutput,
 			}
-			loopErr = r.reportDB.InsertTestCaseResult(runTestSetCtx, testRunID, testSetID, testCaseResult)
-			if loopErr != nil {
+			err = r.reportDB.InsertTestCaseResult(runTestSetCtx, testRunID, reportID, testCaseResult)
+			if err != nil {
 				utils.LogError(r.logger, err, "failed to insert test case result")
+				loopErr = &StorageError{Err: err}
 				break
 			}
+			inFlight = ""
 		} else {
 			utils.LogError(r.logger, nil, "test result is nil")
 			break
 		}
 	}
 
-	testCaseResults, err := r.reportDB.GetTestCaseResults(runTestSetCtx, testRunID, testSetID)
+	testCaseResults, err := r.reportDB.GetTestCaseResults(runTestSetCtx, testRunID, reportID)
 	if err != nil {
-		if runTestSetCtx.Err() != context.Canceled {
+		if ctxErr := runTestSetCtx.Err(); ctxErr != context.Canceled && ctxErr != context.DeadlineExceeded {
 			utils.LogError(r.logger, err, "failed to get test case results")
 			testSetStatus = models.TestSetStatusInternalErr
+			if loopErr == nil {
+				loopErr = &StorageError{Err: fmt.Errorf("failed to get test case results: %w", err)}
+			}
 		}
 	}
 
@@ -476,25 +955,34 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 	}
 
 	testReport = &models.TestReport{
-		Version: models.GetVersion(),
-		TestSet: testSetID,
-		Status:  string(testSetStatus),
-		Total:   testCasesCount,
-		Success: success,
-		Failure: failure,
-		Tests:   testCaseResults,
+		Version:          models.GetVersion(),
+		TestSet:          testSetID,
+		Status:           string(testSetStatus),
+		Total:            testCasesCount,
+		Success:          success,
+		Failure:          failure,
+		Tests:            testCaseResults,
+		Skipped:          skipped,
+		ExpectedFailures: expectedFailures,
+		UnexpectedPasses: unexpectedPasses,
+		EnvSet:           r.envSetName(),
+		Environment:      r.reportEnvironment(testRunID),
+	}
+	setErrorType(testReport, testSetStatus, loopErr, appFailure.Load(), mismatched)
+	if crash := appCrash.Load(); crash != nil {
+		testReport.CrashBundle = r.collectCrash(context.WithoutCancel(runTestSetCtx), appID, testRunID, reportID, inFlight, *crash, testSetStarted)
 	}
 
 	// final report should have reason for sudden stop of the test run so this should get canceled
 	reportCtx := context.WithoutCancel(runTestSetCtx)
-	err = r.reportDB.InsertReport(reportCtx, testRunID, testSetID, testReport)
+	err = r.reportDB.InsertReport(reportCtx, testRunID, reportID, testReport)
 	if err != nil {
 		utils.LogError(r.logger, err, "failed to insert report")
-		return models.TestSetStatusInternalErr, fmt.Errorf("failed to insert report")
+		return models.TestSetStatusInternalErr, &StorageError{Err: fmt.Errorf("failed to insert report: %w", err)}
 	}
 
-	// remove the unused mocks by the test cases of a testset
-	if r.config.Test.RemoveUnusedMocks && testSetStatus == models.TestSetStatusPassed {
+	// remove the unused mocks by the test cases of a testset, unless they're consumed under another env set
+	if mocksEnabled && r.config.Test.RemoveUnusedMocks && testSetStatus == models.TestSetStatusPassed && r.currentEnvSet() == nil {
 		r.logger.Debug("consumed mocks from the completed testset", zap.Any("for test-set", testSetID), zap.Any("consumed mocks", totalConsumedMocks))
 		// delete the unused mocks from the data store
 		err = r.mockDB.UpdateMocks(runTestSetCtx, testSetID, totalConsumedMocks)
@@ -503,18 +991,7 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		}
 	}
 
-	// TODO Need to decide on whether to use global variable or not
-	verdict := TestReportVerdict{
-		total:  testReport.Total,
-		failed: testReport.Failure,
-		passed: testReport.Success,
-		status: testSetStatus == models.TestSetStatusPassed,
-	}
-
-	completeTestReport[testSetID] = verdict
-	totalTests += testReport.Total
-	totalTestPassed += testReport.Success
-	totalTestFailed += testReport.Failure
+	r.addTestSetVerdict(testRunID, testSetID, testReport, testSetStatus)
 
 	if testSetStatus == models.TestSetStatusFailed || testSetStatus == models.TestSetStatusPassed {
 		if testSetStatus == models.TestSetStatusFailed {
@@ -522,7 +999,7 @@ func (r *replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		} else {
 			pp.SetColorScheme(models.PassingColorScheme)
 		}
-		if _, err := pp.Printf("\n <=========================================> \n  TESTRUN SUMMARY. For test-set: %s\n"+"\tTotal tests: %s\n"+"\tTotal test passed: %s\n"+"\tTotal test failed: %s\n <=========================================> \n\n", testReport.TestSet, testReport.Total, testReport.Success, testReport.Failure); err != nil {
+		if _, err := pp.Printf("\n <=========================================> \n  TESTRUN SUMMARY. For test-set: %s\n"+"\tTotal tests: %s\n"+"\tTotal test passed: %s\n"+"\tTotal test failed: %s\n"+"\tTotal test skipped: %s\n"+"\tTotal test xfailed: %s\n"+"\tTotal test xpassed: %s\n <=========================================> \n\n", testReport.TestSet, testReport.Total, testReport.Success, testReport.Failure, testReport.Skipped, testReport.ExpectedFailures, testReport.UnexpectedPasses); err != nil {
 			utils.LogError(r.logger, err, "failed to print testrun summary")
 		}
 	}
@@ -543,25 +1020,40 @@ func (r *replayer) GetTestSetStatus(ctx context.Context, testRunID string, testS
 	return status, nil
 }
 
+func (r *replayer) GetTestCaseResults(ctx context.Context, testRunID string, testSetID string) ([]models.TestResult, error) {
+	results, err := r.reportDB.GetTestCaseResults(ctx, testRunID, testSetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test case results: %w", err)
+	}
+	return results, nil
+}
+
+func (r *replayer) ExportReports(ctx context.Context, testRunID string, w io.Writer) error {
+	testRunIDs, err := r.reportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the test run ids: %w", err)
+	}
+	if !Contains(testRunIDs, testRunID) {
+		return fmt.Errorf("%w: %s", ErrTestRunNotFound, testRunID)
+	}
+	return r.reportDB.ExportReports(ctx, testRunID, w)
+}
+
 func (r *replayer) SimulateRequest(ctx context.Context, appID uint64, tc *models.TestCase, testSetID string) (*models.HTTPResp, error) {
 	switch tc.Kind {
 	case models.HTTP:
 		r.logger.Debug("Before simulating the request", zap.Any("Test case", tc))
-		cmdType := utils.FindDockerCmd(r.config.Command)
-		if cmdType == utils.Docker || cmdType == utils.DockerCompose {
-			var err error
-
-			userIP, err := r.instrumentation.GetAppIP(ctx, appID)
-			if err != nil {
-				utils.LogError(r.logger, err, "failed to get the app ip")
-				return nil, err
-			}
-
-			tc.HTTPReq.URL, err = replaceHostToIP(tc.HTTPReq.URL, userIP)
-			if err != nil {
-				utils.LogError(r.logger, err, "failed to replace host to docker container's IP")
-			}
-			r.logger.Debug("", zap.Any("replaced URL in case of docker env", tc.HTTPReq.URL))
+		protocol, err := appPortProtocol(&r.config, tc.HTTPReq.URL)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the protocol of the app port", zap.String("url", tc.HTTPReq.URL))
+			return nil, err
+		}
+		if protocol != config.ProtocolHTTP {
+			return nil, fmt.Errorf("http testcase %s targets an app port declared as %s", tc.Name, protocol)
+		}
+		tc.HTTPReq.URL, err = r.appURL(ctx, appID, tc.HTTPReq.URL)
+		if err != nil {
+			return nil, err
 		}
 		r.logger.Debug(fmt.Sprintf("the url of the testcase: %v", tc.HTTPReq.URL))
 		resp, err := pkg.SimulateHTTP(ctx, *tc, testSetID, r.logger, r.config.Test.APITimeout)
@@ -572,26 +1064,98 @@ func (r *replayer) SimulateRequest(ctx context.Context, appID uint64, tc *models
 	return nil, nil
 }
 
+// appURL returns the url of a test case with the address the app is reachable at during the test, the address of
+// the kubernetes port-forward or of the container of the app
+func (r *replayer) appURL(ctx context.Context, appID uint64, rawURL string) (string, error) {
+	cmdType := utils.FindDockerCmd(r.config.Command)
+	if r.isK8sTarget() {
+		replaced, err := r.replaceHostToK8sTarget(rawURL)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to replace host to the kubernetes port-forward address")
+			return rawURL, err
+		}
+		return replaced, nil
+	}
+	if cmdType == utils.Docker || cmdType == utils.DockerCompose || r.config.Test.IsolateNetwork {
+		userIP, err := r.instrumentation.GetAppIP(ctx, appID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the app ip")
+			return rawURL, err
+		}
+		replaced, err := replaceHostToIP(rawURL, userIP)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to replace host to docker container's IP")
+			return rawURL, nil
+		}
+		r.logger.Debug("", zap.Any("replaced URL in case of docker env", replaced))
+		return replaced, nil
+	}
+	return rawURL, nil
+}
+
 func (r *replayer) compareResp(tc *models.TestCase, actualResponse *models.HTTPResp, testSetID string) (bool, *models.Result) {
 
-	noiseConfig := r.config.Test.GlobalNoise.Global
-	if tsNoise, ok := r.config.Test.GlobalNoise.Testsets[testSetID]; ok {
-		noiseConfig = LeftJoinNoise(r.config.Test.GlobalNoise.Global, tsNoise)
+	r.reloadMu.RLock()
+	globalNoise := r.config.Test.GlobalNoise
+	unordered := r.config.Test.UnorderedFields
+	jsonCompare := r.config.Test.JSONCompare
+	compareHeaders := r.config.Test.CompareHeaders
+	r.reloadMu.RUnlock()
+
+	noiseConfig := globalNoise.Global
+	if tsNoise, ok := globalNoise.Testsets[testSetID]; ok {
+		noiseConfig = LeftJoinNoise(globalNoise.Global, tsNoise)
 	}
-	return match(tc, actualResponse, noiseConfig, r.config.Test.IgnoreOrdering, r.logger)
+	unorderedFields := append(append([]string{}, unordered.Global...), unordered.Testsets[testSetID]...)
+	return match(tc, actualResponse, noiseConfig, r.config.Test.IgnoreOrdering, unorderedFields, jsonCompareOptions(jsonCompare, testSetID), compareHeaders, r.logger)
 }
 
-func (r *replayer) printSummary(ctx context.Context, testRunResult bool) {
-	if totalTests > 0 {
-		testSuiteNames := make([]string, 0, len(completeTestReport))
-		for testSuiteName := range completeTestReport {
-			testSuiteNames = append(testSuiteNames, testSuiteName)
+// getMappedMocks returns the mocks recorded for a test case by their names instead of filtering them by timestamps.
+// The mapped config mocks are flagged as filtered and sorted before the rest so that they're preferred while matching.
+func (r *replayer) getMappedMocks(ctx context.Context, testSetID string, mockNames []string) ([]*models.Mock, []*models.Mock, error) {
+	mapped := make(map[string]bool, len(mockNames))
+	for _, name := range mockNames {
+		mapped[name] = true
+	}
+	tcsMocks, err := r.mockDB.GetFilteredMocks(ctx, testSetID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, nil, err
+	}
+	configMocks, err := r.mockDB.GetUnFilteredMocks(ctx, testSetID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filteredMocks := make([]*models.Mock, 0, len(mockNames))
+	for _, mock := range tcsMocks {
+		if mapped[mock.Name] {
+			mock.TestModeInfo.IsFiltered = true
+			filteredMocks = append(filteredMocks, mock)
+		}
+	}
+	unfilteredMocks := make([]*models.Mock, 0, len(configMocks))
+	var rest []*models.Mock
+	for _, mock := range configMocks {
+		mock.TestModeInfo.IsFiltered = mapped[mock.Name]
+		if mock.TestModeInfo.IsFiltered {
+			unfilteredMocks = append(unfilteredMocks, mock)
+		} else {
+			rest = append(rest, mock)
 		}
-		sort.SliceStable(testSuiteNames, func(i, j int) bool {
-			testSuitePartsI := strings.Split(testSuiteNames[i], "-")
-			testSuitePartsJ := strings.Split(testSuiteNames[j], "-")
+	}
+	return filteredMocks, append(unfilteredMocks, rest...), nil
+}
+
+func (r *replayer) printSummary(ctx context.Context, testRunID string, testRunResult bool) {
+	report := r.runReportOf(testRunID)
+
+	if report.Total > 0 {
+		testSuites := report.TestSets
+		sort.SliceStable(testSuites, func(i, j int) bool {
+			testSuitePartsI := strings.Split(testSuites[i].TestSet, "-")
+			testSuitePartsJ := strings.Split(testSuites[j].TestSet, "-")
 			if len(testSuitePartsI) < 3 || len(testSuitePartsJ) < 3 {
-				return testSuiteNames[i] < testSuiteNames[j]
+				return testSuites[i].TestSet < testSuites[j].TestSet
 			}
 			testSuiteIDNumberI, err1 := strconv.Atoi(testSuitePartsI[2])
 			testSuiteIDNumberJ, err2 := strconv.Atoi(testSuitePartsJ[2])
@@ -600,7 +1164,7 @@ func (r *replayer) printSummary(ctx context.Context, testRunResult bool) {
 			}
 			return testSuiteIDNumberI < testSuiteIDNumberJ
 		})
-		if _, err := pp.Printf("\n <=========================================> \n  COMPLETE TESTRUN SUMMARY. \n\tTotal tests: %s\n"+"\tTotal test passed: %s\n"+"\tTotal test failed: %s\n", totalTests, totalTestPassed, totalTestFailed); err != nil {
+		if _, err := pp.Printf("\n <=========================================> \n  COMPLETE TESTRUN SUMMARY. \n\tTotal tests: %s\n"+"\tTotal test passed: %s\n"+"\tTotal test failed: %s\n"+"\tTotal test skipped: %s\n"+"\tTotal test xpassed: %s\n", report.Total, report.Success, report.Failure, report.Skipped, report.UnexpectedPasses); err != nil {
 			utils.LogError(r.logger, err, "failed to print test run summary")
 			return
 		}
@@ -608,17 +1172,53 @@ func (r *replayer) printSummary(ctx context.Context, testRunResult bool) {
 			utils.LogError(r.logger, err, "failed to print test suite summary")
 			return
 		}
-		for _, testSuiteName := range testSuiteNames {
-			if completeTestReport[testSuiteName].status {
+		for _, testSuite := range testSuites {
+			if testSuite.Status == string(models.TestSetStatusPassed) {
 				pp.SetColorScheme(models.PassingColorScheme)
 			} else {
 				pp.SetColorScheme(models.FailingColorScheme)
 			}
-			if _, err := pp.Printf("\n\t%s\t\t%s\t\t%s\t\t%s", testSuiteName, completeTestReport[testSuiteName].total, completeTestReport[testSuiteName].passed, completeTestReport[testSuiteName].failed); err != nil {
+			name := testSuite.TestSet
+			if testSuite.EnvSet != "" {
+				name += "@" + testSuite.EnvSet
+			}
+			if _, err := pp.Printf("\n\t%s\t\t%s\t\t%s\t\t%s", name, testSuite.Total, testSuite.Success, testSuite.Failure); err != nil {
 				utils.LogError(r.logger, err, "failed to print test suite details")
 				return
 			}
 		}
+		if len(r.config.Test.EnvMatrix) > 0 {
+			r.printEnvMatrix(envMatrix(report.TestSets))
+		}
+		if len(report.Slowest) > 0 {
+			pp.SetColorScheme(models.PassingColorScheme)
+			if _, err := pp.Printf("\n\n\tSlowest Tests\t\tTest Suite Name\t\tDuration\n"); err != nil {
+				utils.LogError(r.logger, err, "failed to print the slowest tests")
+				return
+			}
+			for _, slow := range report.Slowest {
+				if _, err := pp.Printf("\n\t%s\t\t%s\t\t%s", slow.TestCaseID, slow.TestSet, (time.Duration(slow.Duration) * time.Millisecond).String()); err != nil {
+					utils.LogError(r.logger, err, "failed to print the slowest tests")
+					return
+				}
+			}
+		}
+		if coverage := report.APICoverage; coverage != nil && coverage.Total > 0 {
+			pp.SetColorScheme(models.PassingColorScheme)
+			if _, err := pp.Printf("\n\n\tAPI coverage: %s of %s endpoints and status codes exercised (%s%%)", coverage.Covered, coverage.Total, fmt.Sprintf("%.1f", coverage.Percentage)); err != nil {
+				utils.LogError(r.logger, err, "failed to print the api coverage")
+				return
+			}
+			for _, e := range coverage.Endpoints {
+				if e.Covered {
+					continue
+				}
+				if _, err := pp.Printf("\n\tuntested\t%s %s\t%s", e.Method, e.Path, e.StatusCode); err != nil {
+					utils.LogError(r.logger, err, "failed to print the api coverage")
+					return
+				}
+			}
+		}
 		if _, err := pp.Printf("\n<=========================================> \n\n"); err != nil {
 			utils.LogError(r.logger, err, "failed to print separator")
 			return
@@ -644,10 +1244,97 @@ func (r *replayer) printSummary(ctx context.Context, testRunResult bool) {
 	}
 }
 
+// getShuffleSeed returns the seed to shuffle the test cases with and prints it so that the order can be reproduced
+func (r *replayer) getShuffleSeed() (int64, error) {
+	r.seedMu.Lock()
+	defer r.seedMu.Unlock()
+	if r.shuffleSeed != nil {
+		return *r.shuffleSeed, nil
+	}
+	var seed int64
+	if r.config.Test.Shuffle == "random" {
+		seed = time.Now().UnixNano()
+	} else {
+		var err error
+		seed, err = strconv.ParseInt(r.config.Test.Shuffle, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid shuffle seed %q: %w", r.config.Test.Shuffle, err)
+		}
+	}
+	r.shuffleSeed = &seed
+	r.logger.Info(fmt.Sprintf("shuffling the test cases with seed %d, use --shuffle=%d to reproduce the order", seed, seed))
+	return seed, nil
+}
+
+// startSharedApp launches the app which is reused by all the test sets of the test run
+func (r *replayer) startSharedApp(appID uint64) error {
+	appCtx, appErrChan := r.sharedApp()
+	g, ok := appCtx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		return errors.New("failed to get the error group from the context")
+	}
+	r.sharedAppMu.Lock()
+	// the app stopped after the test set it ran for was done, the test set launching it again isn't failed for it
+	select {
+	case appErr := <-appErrChan:
+		r.logger.Warn("the shared application stopped between the test sets, launching it again", zap.Error(appErr))
+	default:
+	}
+	r.sharedAppStarted = true
+	r.sharedAppMu.Unlock()
+
+	g.Go(func() error {
+		defer utils.Recover(r.logger)
+		appErr := r.RunApplication(appCtx, appID, models.RunOptions{})
+		if appErr.AppErrorType == models.ErrCtxCanceled {
+			return nil
+		}
+		// the test set running fails for the stopped app and the next one launches it again
+		r.sharedAppMu.Lock()
+		r.sharedAppStarted = false
+		appErrChan <- appErr
+		r.sharedAppMu.Unlock()
+		return nil
+	})
+	return nil
+}
+
+// sharedApp returns the context and the error channel of the app shared by the test sets, nil when it isn't shared
+func (r *replayer) sharedApp() (context.Context, chan models.AppError) {
+	r.sharedAppMu.Lock()
+	defer r.sharedAppMu.Unlock()
+	return r.sharedAppCtx, r.sharedAppErrChan
+}
+
+func (r *replayer) isSharedAppStarted() bool {
+	r.sharedAppMu.Lock()
+	defer r.sharedAppMu.Unlock()
+	return r.sharedAppStarted
+}
+
 func (r *replayer) RunApplication(ctx context.Context, appID uint64, opts models.RunOptions) models.AppError {
 	return r.instrumentation.Run(ctx, appID, opts)
 }
 
+// Reload applies the noise of the reloaded config to the next test cases and its bypass rules to the next test set
+func (r *replayer) Reload(_ context.Context, cfg config.Config) error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	r.config.Test.GlobalNoise = cfg.Test.GlobalNoise
+	r.config.Test.UnorderedFields = cfg.Test.UnorderedFields
+	r.config.Test.JSONCompare = cfg.Test.JSONCompare
+	r.config.Test.CompareHeaders = cfg.Test.CompareHeaders
+	r.config.BypassRules = cfg.BypassRules
+	r.logger.Info("reloaded the config, the noise applies to the next test cases and the bypass rules to the next test set", zap.Int("bypassRules", len(cfg.BypassRules)))
+	return nil
+}
+
+func (r *replayer) bypassRules() []config.BypassRule {
+	r.reloadMu.RLock()
+	defer r.reloadMu.RUnlock()
+	return r.config.BypassRules
+}
+
 func (r *replayer) ProvideMocks(ctx context.Context) error {
 	var stopReason string
 	var hookCancel context.CancelFunc
@@ -705,6 +1392,42 @@ func (r *replayer) ProvideMocks(ctx context.Context) error {
 		}
 		return fmt.Errorf(stopReason)
 	}
+
+	// the hand edited mocks are served without a restart, the mocks being served as they were if watching fails
+	watchErrGrp, watchCtx := errgroup.WithContext(ctx)
+	watchCtx = context.WithValue(watchCtx, models.ErrGroupKey, watchErrGrp)
+	defer func() {
+		err := watchErrGrp.Wait()
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to stop watching the mock files")
+		}
+	}()
+	err = r.watchMocks(watchCtx, appID, "")
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to watch the mock files, the changes to them need a restart")
+	}
 	<-ctx.Done()
 	return nil
 }
+
+func (r *replayer) IngestTestCase(ctx context.Context, testSetID string, tc *models.TestCase) error {
+	if tc.Version == "" {
+		tc.Version = models.GetVersion()
+	}
+	if tc.Kind == "" {
+		tc.Kind = models.HTTP
+	}
+	if tc.Created == 0 {
+		tc.Created = time.Now().Unix()
+	}
+	if tc.Noise == nil {
+		tc.Noise = map[string][]string{}
+	}
+	pkg.NewMasker(r.config.Masking.Fields).MaskTestCase(tc)
+	err := r.testDB.InsertTestCase(ctx, tc, testSetID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to insert the ingested testcase", zap.String("testSetID", testSetID))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// selectFailed selects the test cases which failed in the previous test run, the test sets without any failure being
+// left out. A selection of the test sets and their test cases narrows them down further.
+func (r *replayer) selectFailed(ctx context.Context, testRunID string) (map[string][]string, error) {
+	testRunIDs, err := r.reportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the test run ids: %w", err)
+	}
+	if !Contains(testRunIDs, testRunID) {
+		return nil, fmt.Errorf("%w: %s", ErrTestRunNotFound, testRunID)
+	}
+	testSetIDs, err := r.testDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the test set ids: %w", err)
+	}
+
+	selected := map[string][]string{}
+	for _, testSetID := range testSetIDs {
+		chosen, isChosen := r.config.Test.SelectedTests[testSetID]
+		if !isChosen && len(r.config.Test.SelectedTests) != 0 {
+			continue
+		}
+		chosenTests := ArrayToMap(chosen)
+		failed := map[string]bool{}
+		var names []string
+		// the test set failed under an env set of the matrix if any
+		for _, reportID := range r.reportIDs(testSetID) {
+			report, err := r.reportDB.GetReport(ctx, testRunID, reportID)
+			if err != nil {
+				r.logger.Debug("the test set wasn't run in the test run", zap.String("testSet", reportID), zap.String("testRun", testRunID), zap.Error(err))
+				continue
+			}
+			for _, test := range report.Tests {
+				if test.Status != models.TestStatusFailed || failed[test.TestCaseID] {
+					continue
+				}
+				if _, ok := chosenTests[test.TestCaseID]; !ok && len(chosenTests) != 0 {
+					continue
+				}
+				failed[test.TestCaseID] = true
+				names = append(names, test.TestCaseID)
+			}
+		}
+		if len(names) > 0 {
+			selected[testSetID] = names
+		}
+	}
+	return selected, nil
+}
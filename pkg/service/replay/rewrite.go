@@ -0,0 +1,108 @@
+package replay
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// rewriteRequest returns the test case with its url and Host header rewritten by the first rule matching it,
+// the recorded test case being left as is for the report
+func rewriteRequest(tc *models.TestCase, rules []config.URLRewrite) (*models.TestCase, error) {
+	rewritten, changed, err := rewriteURL(tc.HTTPReq.URL, rules)
+	if err != nil || !changed {
+		return tc, err
+	}
+	u, err := url.Parse(rewritten)
+	if err != nil {
+		return tc, err
+	}
+	simulated := *tc
+	simulated.HTTPReq.URL = rewritten
+	simulated.HTTPReq.Header = make(map[string]string, len(tc.HTTPReq.Header))
+	for k, v := range tc.HTTPReq.Header {
+		// the Host header recorded behind a gateway names the gateway
+		if strings.EqualFold(k, "Host") {
+			v = u.Host
+		}
+		simulated.HTTPReq.Header[k] = v
+	}
+	return &simulated, nil
+}
+
+// rewriteURL rewrites the url by the first rule matching it, telling if one did
+func rewriteURL(currentURL string, rules []config.URLRewrite) (string, bool, error) {
+	u, err := url.Parse(currentURL)
+	if err != nil {
+		return currentURL, false, err
+	}
+	for _, rule := range rules {
+		if !matchesURL(u, rule.Match) {
+			continue
+		}
+		to := rule.To
+		if to.Scheme != "" {
+			u.Scheme = to.Scheme
+		}
+		if to.Host != "" || to.Port != "" {
+			host, port := u.Hostname(), u.Port()
+			if to.Host != "" {
+				host = to.Host
+			}
+			if to.Port != "" {
+				port = to.Port
+			}
+			u.Host = joinHostPort(host, port)
+		}
+		if rule.Match.PathPrefix != "" || to.PathPrefix != "" {
+			rest := strings.TrimPrefix(u.Path, strings.TrimSuffix(rule.Match.PathPrefix, "/"))
+			u.Path = strings.TrimSuffix(to.PathPrefix, "/") + rest
+			u.RawPath = ""
+		}
+		return u.String(), true, nil
+	}
+	return currentURL, false, nil
+}
+
+func matchesURL(u *url.URL, m config.URLParts) bool {
+	if m.Scheme != "" && !strings.EqualFold(m.Scheme, u.Scheme) {
+		return false
+	}
+	if m.Host != "" && !strings.EqualFold(m.Host, u.Hostname()) {
+		return false
+	}
+	if m.Port != "" && m.Port != portOf(u) {
+		return false
+	}
+	if m.PathPrefix != "" {
+		prefix := strings.TrimSuffix(m.PathPrefix, "/")
+		if u.Path != prefix && !strings.HasPrefix(u.Path, prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// portOf returns the port of the url, the default one of its scheme when it has none
+func portOf(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+func joinHostPort(host, port string) string {
+	if port == "" {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,218 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func (r *replayer) newRunReport(ctx context.Context, testRunID string) *models.TestRunReport {
+	hostname, err := os.Hostname()
+	if err != nil {
+		r.logger.Debug("failed to get the hostname for the run report", zap.Error(err))
+	}
+	return &models.TestRunReport{
+		Version: models.GetVersion(),
+		Name:    testRunID,
+		Started: time.Now().Unix(),
+		Status:  string(models.TestStatusRunning),
+		Environment: models.RunEnvironment{
+			KeployVersion: utils.Version,
+			OS:            runtime.GOOS,
+			Kernel:        kernelRelease(),
+			Arch:          runtime.GOARCH,
+			Hostname:      hostname,
+			Language:      r.config.Test.Language,
+			Command:       r.config.Command,
+			Git:           r.gitInfo(ctx),
+		},
+	}
+}
+
+// kernelRelease returns the release of the linux kernel, empty when it can't be read
+func kernelRelease() string {
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(release))
+}
+
+// gitInfo returns the commit checked out in the working directory, nil when it isn't a git repository
+func (r *replayer) gitInfo(ctx context.Context) *models.GitInfo {
+	commit, err := git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		r.logger.Debug("the test run isn't attributed to a commit", zap.Error(err))
+		return nil
+	}
+	info := &models.GitInfo{Commit: commit[0]}
+	if branch, err := git(ctx, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		info.Branch = branch[0]
+	}
+	// the test cases, the mocks and the reports written by keploy don't make the working directory dirty
+	changes, err := git(ctx, "status", "--porcelain", "--", ":/", ":(exclude)"+r.config.Path)
+	if err != nil {
+		// the keploy directory is outside of the repository
+		changes, err = git(ctx, "status", "--porcelain")
+	}
+	if err == nil {
+		info.Dirty = len(changes) > 0 && changes[0] != ""
+	}
+	return info
+}
+
+// startRunReport starts the report of the test run, replacing the one of a previous test run with the same id
+func (r *replayer) startRunReport(ctx context.Context, testRunID string) {
+	report := r.newRunReport(ctx, testRunID)
+	r.reportMu.Lock()
+	defer r.reportMu.Unlock()
+	if r.runReports == nil {
+		r.runReports = map[string]*models.TestRunReport{}
+	}
+	r.runReports[testRunID] = report
+}
+
+// runReport returns the report of the test run, which is started for the test sets run outside of a test run, e.g.
+// through the graphql server. The reportMu has to be held.
+func (r *replayer) runReport(testRunID string) *models.TestRunReport {
+	if r.runReports == nil {
+		r.runReports = map[string]*models.TestRunReport{}
+	}
+	report, ok := r.runReports[testRunID]
+	if !ok {
+		report = r.newRunReport(context.Background(), testRunID)
+		r.runReports[testRunID] = report
+	}
+	return report
+}
+
+// runReportOf returns a copy of the report of the test run, which can be read while its test sets are still run
+func (r *replayer) runReportOf(testRunID string) models.TestRunReport {
+	r.reportMu.Lock()
+	defer r.reportMu.Unlock()
+	report := *r.runReport(testRunID)
+	report.TestSets = append([]models.TestSetVerdict{}, report.TestSets...)
+	return report
+}
+
+// reportEnvironment returns the environment of the test run, written in the reports of its test sets
+func (r *replayer) reportEnvironment(testRunID string) *models.RunEnvironment {
+	r.reportMu.Lock()
+	defer r.reportMu.Unlock()
+	env := r.runReport(testRunID).Environment
+	return &env
+}
+
+// addTestSetVerdict records the outcome of the test set in the run report, a test set run again replaces its previous outcome
+func (r *replayer) addTestSetVerdict(testRunID, testSetID string, testReport *models.TestReport, status models.TestSetStatus) {
+	r.reportMu.Lock()
+	defer r.reportMu.Unlock()
+	runReport := r.runReport(testRunID)
+	verdict := models.TestSetVerdict{
+		TestSet:          testSetID,
+		Status:           string(status),
+		Total:            testReport.Total,
+		Success:          testReport.Success,
+		Failure:          testReport.Failure,
+		Skipped:          testReport.Skipped,
+		UnexpectedPasses: testReport.UnexpectedPasses,
+		EnvSet:           testReport.EnvSet,
+		ErrorType:        testReport.ErrorType,
+		Error:            testReport.Error,
+		MockMismatches:   testReport.MockMismatches,
+	}
+	replaced := false
+	for i := range runReport.TestSets {
+		if runReport.TestSets[i].TestSet == testSetID && runReport.TestSets[i].EnvSet == verdict.EnvSet {
+			runReport.TestSets[i] = verdict
+			replaced = true
+		}
+	}
+	if !replaced {
+		runReport.TestSets = append(runReport.TestSets, verdict)
+	}
+
+	runReport.Total, runReport.Success, runReport.Failure = 0, 0, 0
+	runReport.Skipped, runReport.UnexpectedPasses = 0, 0
+	for _, v := range runReport.TestSets {
+		runReport.Total += v.Total
+		runReport.Success += v.Success
+		runReport.Failure += v.Failure
+		runReport.Skipped += v.Skipped
+		runReport.UnexpectedPasses += v.UnexpectedPasses
+	}
+	slowestID := testSetID
+	if verdict.EnvSet != "" {
+		slowestID += "@" + verdict.EnvSet
+	}
+	runReport.Slowest = slowestTests(runReport.Slowest, slowestID, testReport.Tests, r.config.Test.SlowestTests)
+}
+
+// slowestTests returns the n slowest test cases of the run once the results of the test set replace its previous ones
+func slowestTests(slowest []models.SlowTest, testSetID string, results []models.TestResult, n int) []models.SlowTest {
+	merged := make([]models.SlowTest, 0, len(slowest)+len(results))
+	for _, s := range slowest {
+		if s.TestSet != testSetID {
+			merged = append(merged, s)
+		}
+	}
+	for _, res := range results {
+		if res.Status == models.TestStatusSkipped {
+			continue
+		}
+		merged = append(merged, models.SlowTest{TestSet: testSetID, TestCaseID: res.TestCaseID, Duration: res.Duration})
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Duration > merged[j].Duration
+	})
+	if n < 0 {
+		n = 0
+	}
+	if len(merged) > n {
+		merged = merged[:n]
+	}
+	return merged
+}
+
+// completeRunReport sets the outcome of the test run and writes its report
+func (r *replayer) completeRunReport(ctx context.Context, testRunID string, passed bool, abortReason string, errType models.ReplayErrorType) {
+	r.reportMu.Lock()
+	report, ok := r.runReports[testRunID]
+	if !ok {
+		r.reportMu.Unlock()
+		return
+	}
+	// the completed test run isn't accounted anymore
+	delete(r.runReports, testRunID)
+	completed := time.Now()
+	report.Completed = completed.Unix()
+	report.Duration = completed.Sub(time.Unix(report.Started, 0)).Round(time.Second).String()
+	report.AbortReason = abortReason
+	report.ErrorType = errType
+	report.EnvMatrix = envMatrix(report.TestSets)
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		report.Status = models.TestRunStatusTimedOut
+	case abortReason != "":
+		report.Status = models.TestRunStatusAborted
+	case passed:
+		report.Status = models.TestRunStatusPassed
+	default:
+		report.Status = models.TestRunStatusFailed
+	}
+	r.reportMu.Unlock()
+
+	// the report is written even when the test run is cancelled
+	err := r.reportDB.InsertRunReport(context.WithoutCancel(ctx), report.Name, report)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to write the run report", zap.String("testRunID", report.Name))
+	}
+}
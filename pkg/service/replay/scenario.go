@@ -0,0 +1,178 @@
+package replay
+
+import (
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// scenarioRun holds the values of the variables of a scenario returned during the test by their recorded ones
+type scenarioRun struct {
+	scenario *models.Scenario
+	values   map[string]string
+}
+
+// scenarioStep is a test case of the test set which is a step of a scenario
+type scenarioStep struct {
+	run   *scenarioRun
+	index int
+}
+
+// scenarioSteps returns the steps of the scenarios by the names of their test cases
+func scenarioSteps(scenarios []models.Scenario) map[string]scenarioStep {
+	steps := map[string]scenarioStep{}
+	for i := range scenarios {
+		run := &scenarioRun{scenario: &scenarios[i], values: map[string]string{}}
+		for j, step := range scenarios[i].Steps {
+			steps[step.TestCase] = scenarioStep{run: run, index: j}
+		}
+	}
+	return steps
+}
+
+// groupScenarios orders the steps of each scenario one after the other, from where its first step is, so that
+// the scenario is run as a unit even when the test cases are shuffled
+func groupScenarios(testCases []*models.TestCase, steps map[string]scenarioStep) []*models.TestCase {
+	if len(steps) == 0 {
+		return testCases
+	}
+	byName := make(map[string]*models.TestCase, len(testCases))
+	for _, tc := range testCases {
+		byName[tc.Name] = tc
+	}
+	grouped := make([]*models.TestCase, 0, len(testCases))
+	added := map[*scenarioRun]bool{}
+	for _, tc := range testCases {
+		step, ok := steps[tc.Name]
+		if !ok {
+			grouped = append(grouped, tc)
+			continue
+		}
+		if added[step.run] {
+			continue
+		}
+		added[step.run] = true
+		for _, s := range step.run.scenario.Steps {
+			if stepTc, ok := byName[s.TestCase]; ok {
+				grouped = append(grouped, stepTc)
+			}
+		}
+	}
+	return grouped
+}
+
+// selectScenarios adds the other steps of the scenarios of the selected test cases, which can't run alone
+func selectScenarios(selected map[string]bool, scenarios []models.Scenario) {
+	if len(selected) == 0 {
+		return
+	}
+	for _, sc := range scenarios {
+		for _, step := range sc.Steps {
+			if !selected[step.TestCase] {
+				continue
+			}
+			for _, s := range sc.Steps {
+				selected[s.TestCase] = true
+			}
+			break
+		}
+	}
+}
+
+// request returns the test case with the recorded values of the variables returned by the previous steps
+// replaced by the values they returned during the test
+func (s scenarioStep) request(tc *models.TestCase) *models.TestCase {
+	var replacements []string
+	for _, v := range s.run.scenario.Variables {
+		if actual, ok := s.run.values[v.Value]; ok && v.Step < s.index && actual != v.Value {
+			replacements = append(replacements, v.Value, actual)
+		}
+	}
+	if len(replacements) == 0 {
+		return tc
+	}
+	replacer := strings.NewReplacer(replacements...)
+	req := *tc
+	req.HTTPReq.URL = replacer.Replace(tc.HTTPReq.URL)
+	req.HTTPReq.Body = replacer.Replace(tc.HTTPReq.Body)
+	req.HTTPReq.Header = make(map[string]string, len(tc.HTTPReq.Header))
+	for k, v := range tc.HTTPReq.Header {
+		req.HTTPReq.Header[k] = replacer.Replace(v)
+	}
+	return &req
+}
+
+// capture keeps the values of the variables returned by the step during the test
+func (s scenarioStep) capture(resp *models.HTTPResp) {
+	for _, v := range s.run.scenario.Variables {
+		if v.Step != s.index {
+			continue
+		}
+		if actual, ok := pkg.ResponseField(resp, v.Path); ok {
+			s.run.values[v.Value] = actual
+		}
+	}
+}
+
+// expected returns the test case with the fields of its response returning variables ignored as noise,
+// as they change with every run, e.g. a token
+func (s scenarioStep) expected(tc *models.TestCase) *models.TestCase {
+	var noisy []string
+	for _, v := range s.run.scenario.Variables {
+		if v.Step == s.index {
+			noisy = append(noisy, variableNoise(v.Path))
+		}
+	}
+	if len(noisy) == 0 {
+		return tc
+	}
+	expected := *tc
+	expected.Noise = make(map[string][]string, len(tc.Noise)+len(noisy))
+	for k, v := range tc.Noise {
+		expected.Noise[k] = v
+	}
+	for _, field := range noisy {
+		expected.Noise[field] = []string{}
+	}
+	return &expected
+}
+
+// assert checks the assertions of the step against the response of the test
+func (s scenarioStep) assert(resp *models.HTTPResp) (bool, []models.AssertionResult) {
+	pass := true
+	var results []models.AssertionResult
+	for _, a := range s.run.scenario.Steps[s.index].Assert {
+		actual, _ := pkg.ResponseField(resp, a.Path)
+		result := models.AssertionResult{
+			Normal:   actual == a.Equals,
+			Path:     a.Path,
+			Expected: a.Equals,
+			Actual:   actual,
+		}
+		pass = pass && result.Normal
+		results = append(results, result)
+	}
+	return pass, results
+}
+
+// variableNoise returns the noise key of the field of the response returning the variable, whose array
+// indices are left out like in the noise of the body
+func variableNoise(path string) string {
+	kind, rest, _ := strings.Cut(path, ".")
+	switch kind {
+	case "cookie":
+		return "header.Set-Cookie"
+	case "body":
+		var keys []string
+		for _, key := range strings.Split(rest, ".") {
+			if _, err := strconv.Atoi(key); err != nil {
+				keys = append(keys, key)
+			}
+		}
+		return "body." + strings.Join(keys, ".")
+	default:
+		return path
+	}
+}
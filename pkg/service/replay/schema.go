@@ -0,0 +1,161 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// the modes of the schema drift detection besides warn, the default
+const (
+	schemaDriftOff  = "off"
+	schemaDriftFail = "fail"
+)
+
+// responseSchema is the shape of the json responses recorded for an endpoint, the types seen at each path
+// of their bodies and the number of responses having the path
+type responseSchema struct {
+	samples int
+	types   map[string]map[string]bool
+	seen    map[string]int
+}
+
+// responseSchemas are the schemas of the endpoints of a test set, by method, route and status code
+type responseSchemas map[string]*responseSchema
+
+func schemaKey(tc *models.TestCase) string {
+	method, path := endpointOf(tc.HTTPReq)
+	return fmt.Sprintf("%s %s %d", method, path, tc.HTTPResp.StatusCode)
+}
+
+// inferSchemas infers the schema of the responses of each endpoint from the recorded json bodies
+func inferSchemas(testCases []*models.TestCase) responseSchemas {
+	schemas := responseSchemas{}
+	for _, tc := range testCases {
+		if tc.Kind != models.HTTP || tc.HTTPResp.BodyHash != "" {
+			continue
+		}
+		shape, ok := bodyShape(tc.HTTPResp.Body)
+		if !ok {
+			continue
+		}
+		key := schemaKey(tc)
+		s, ok := schemas[key]
+		if !ok {
+			s = &responseSchema{types: map[string]map[string]bool{}, seen: map[string]int{}}
+			schemas[key] = s
+		}
+		s.samples++
+		for path, types := range shape {
+			if s.types[path] == nil {
+				s.types[path] = map[string]bool{}
+			}
+			for t := range types {
+				s.types[path][t] = true
+			}
+			s.seen[path]++
+		}
+	}
+	return schemas
+}
+
+// drift returns the paths of the actual body whose type isn't one recorded for the endpoint, and the paths
+// present in all the recorded bodies which are missing. The values are ignored, noisy or not.
+func (schemas responseSchemas) drift(tc *models.TestCase, actual *models.HTTPResp) []models.SchemaDrift {
+	s, ok := schemas[schemaKey(tc)]
+	if !ok || actual == nil {
+		return nil
+	}
+	shape, ok := bodyShape(actual.Body)
+	if !ok {
+		return nil
+	}
+	var drifts []models.SchemaDrift
+	for path, types := range shape {
+		expected, ok := s.types[path]
+		// a field only recorded as null tells nothing of its type
+		if !ok || (len(expected) == 1 && expected["null"]) {
+			continue
+		}
+		for t := range types {
+			if !expected[t] {
+				drifts = append(drifts, models.SchemaDrift{Path: path, Expected: sortedKeys(expected), Actual: sortedKeys(types)})
+				break
+			}
+		}
+	}
+	for path, seen := range s.seen {
+		if seen < s.samples {
+			continue
+		}
+		// a field of a missing object or of an empty array isn't reported on its own
+		if _, ok := shape[path]; ok {
+			continue
+		}
+		if _, ok := shape[parentPath(path)]; !ok && path != "body" {
+			continue
+		}
+		drifts = append(drifts, models.SchemaDrift{Path: path, Expected: sortedKeys(s.types[path])})
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Path < drifts[j].Path })
+	return drifts
+}
+
+// bodyShape returns the json types at each path of the body, body.items[] being the elements of the items array
+func bodyShape(body string) (map[string]map[string]bool, bool) {
+	var v interface{}
+	if body == "" || json.Unmarshal([]byte(body), &v) != nil {
+		return nil, false
+	}
+	shape := map[string]map[string]bool{}
+	addShape(shape, "body", v)
+	return shape, true
+}
+
+func addShape(shape map[string]map[string]bool, path string, v interface{}) {
+	if shape[path] == nil {
+		shape[path] = map[string]bool{}
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		shape[path]["object"] = true
+		for k, e := range val {
+			addShape(shape, path+"."+k, e)
+		}
+	case []interface{}:
+		shape[path]["array"] = true
+		for _, e := range val {
+			addShape(shape, path+"[]", e)
+		}
+	case string:
+		shape[path]["string"] = true
+	case float64:
+		shape[path]["number"] = true
+	case bool:
+		shape[path]["boolean"] = true
+	default:
+		shape[path]["null"] = true
+	}
+}
+
+func parentPath(path string) string {
+	if strings.HasSuffix(path, "[]") {
+		return strings.TrimSuffix(path, "[]")
+	}
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
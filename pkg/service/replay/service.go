@@ -2,8 +2,10 @@ package replay
 
 import (
 	"context"
+	"io"
 	"time"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
 )
 
@@ -17,10 +19,14 @@ type Instrumentation interface {
 	SetMocks(ctx context.Context, id uint64, filtered []*models.Mock, unFiltered []*models.Mock) error
 	// GetConsumedMocks to log the names of the mocks that were consumed during the test run of failed test cases
 	GetConsumedMocks(ctx context.Context, id uint64) ([]string, error)
+	// GetMockCallOrder returns the mocks consumed by the current test case in the order of the outgoing calls
+	GetMockCallOrder(ctx context.Context, id uint64) ([]string, error)
 	// Run is blocking call and will execute until error
 	Run(ctx context.Context, id uint64, opts models.RunOptions) models.AppError
 
 	GetAppIP(ctx context.Context, id uint64) (string, error)
+	// GetAppOutput returns the lines printed by the app from the start to the end time
+	GetAppOutput(ctx context.Context, id uint64, from, to time.Time) ([]models.AppOutputLine, error)
 }
 
 type Service interface {
@@ -29,19 +35,39 @@ type Service interface {
 	GetAllTestSetIDs(ctx context.Context) ([]string, error)
 	RunTestSet(ctx context.Context, testSetID string, testRunID string, appID uint64, serveTest bool) (models.TestSetStatus, error)
 	GetTestSetStatus(ctx context.Context, testRunID string, testSetID string) (models.TestSetStatus, error)
+	// GetTestCaseResults returns the results of the test cases of the test set in the test run
+	GetTestCaseResults(ctx context.Context, testRunID string, testSetID string) ([]models.TestResult, error)
 	RunApplication(ctx context.Context, appID uint64, opts models.RunOptions) models.AppError
 	ProvideMocks(ctx context.Context) error
+	// IngestTestCase stores a test case pushed from outside of keploy (e.g. a gateway or middleware) in the given test set
+	IngestTestCase(ctx context.Context, testSetID string, tc *models.TestCase) error
+	// ExportReports writes a zip archive of the reports of the test run, it fails when there is no such test run
+	ExportReports(ctx context.Context, testRunID string, w io.Writer) error
+	// UpdateTestCaseResponse replaces the expected response of the http test case
+	UpdateTestCaseResponse(ctx context.Context, testSetID string, testCaseID string, resp models.HTTPResp) error
+	// UpdateTestCaseNoise adds and removes the noisy fields, like body.id or header.Date, of the test case
+	UpdateTestCaseNoise(ctx context.Context, testSetID string, testCaseID string, add []string, remove []string) error
+	DeleteTestCase(ctx context.Context, testSetID string, testCaseID string) error
+	// Reload applies the reloadable parts of the config, the noise and the bypass rules, without restarting
+	Reload(ctx context.Context, cfg config.Config) error
 }
 
 type TestDB interface {
 	GetAllTestSetIDs(ctx context.Context) ([]string, error)
 	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+	InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error
+	UpdateTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error
+	DeleteTestCase(ctx context.Context, testSetID string, name string) error
+	// GetScenarios returns the flows of test cases of the test set which are replayed in order as a unit
+	GetScenarios(ctx context.Context, testSetID string) ([]models.Scenario, error)
 }
 
 type MockDB interface {
 	GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
 	GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
 	UpdateMocks(ctx context.Context, testSetID string, mockNames map[string]bool) error
+	GetMappings(ctx context.Context, testSetID string) (map[string][]string, error)
+	MockFileName() string
 }
 
 type ReportDB interface {
@@ -50,6 +76,10 @@ type ReportDB interface {
 	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
 	InsertTestCaseResult(ctx context.Context, testRunID string, testSetID string, result *models.TestResult) error
 	InsertReport(ctx context.Context, testRunID string, testSetID string, testReport *models.TestReport) error
+	InsertRunReport(ctx context.Context, testRunID string, report *models.TestRunReport) error
+	ExportReports(ctx context.Context, testRunID string, w io.Writer) error
+	InsertCrashBundle(ctx context.Context, testRunID string, testSetID string, bundle *models.CrashBundle) (string, error)
+	InsertHTMLReport(ctx context.Context, testRunID string, html []byte) (string, error)
 }
 
 type Telemetry interface {
@@ -0,0 +1,96 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// checkState runs the verification queries of the test case against the test database with the command of
+// their kind, and returns whether they all got the expected output. The mocked datastores hold no data to query,
+// so the checks need the calls of the app to the test database passed through.
+func (r *replayer) checkState(ctx context.Context, tc *models.TestCase, testSetID string) (bool, []models.StateCheckResult) {
+	pass := true
+	results := make([]models.StateCheckResult, 0, len(tc.Verify))
+	for _, check := range tc.Verify {
+		result := models.StateCheckResult{
+			Name:     check.Name,
+			Query:    check.Query,
+			Expected: strings.TrimSpace(check.Expected),
+		}
+		actual, err := r.runStateQuery(ctx, check, tc.Name, testSetID)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Actual = actual
+			result.Normal = sameState(result.Expected, actual)
+		}
+		pass = pass && result.Normal
+		results = append(results, result)
+	}
+	return pass, results
+}
+
+// runStateQuery runs the query with the command of its kind, which reads it from stdin, and returns the trimmed
+// output. The test case and its test set are passed in the KEPLOY_TEST_CASE and KEPLOY_TEST_SET env vars.
+func (r *replayer) runStateQuery(ctx context.Context, check models.StateCheck, testCase, testSetID string) (string, error) {
+	var command string
+	switch check.Kind {
+	case models.StateCheckSQL:
+		command = r.config.Test.StateCheck.SQLCommand
+	case models.StateCheckMongo:
+		command = r.config.Test.StateCheck.MongoCommand
+	default:
+		return "", fmt.Errorf("unsupported kind %q of verification query, supported kinds are sql and mongo", check.Kind)
+	}
+	if command == "" {
+		return "", fmt.Errorf("no command is set to run the %s verification queries, set it in test.stateCheck of the config", check.Kind)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(r.config.Test.APITimeout)*time.Second)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = strings.NewReader(check.Query)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		"KEPLOY_TEST_CASE="+testCase,
+		"KEPLOY_TEST_SET="+testSetID,
+	)
+	r.logger.Debug("running the verification query", zap.String("testcase", testCase), zap.String("kind", check.Kind), zap.String("query", check.Query))
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// sameState compares the output of the verification query with the expected one as json when both are json, the
+// order of the keys and of the rows ignored, and otherwise line by line, the whitespace around the lines ignored
+func sameState(expected, actual string) bool {
+	if json.Valid([]byte(expected)) && json.Valid([]byte(actual)) {
+		var exp, act interface{}
+		if json.Unmarshal([]byte(expected), &exp) == nil && json.Unmarshal([]byte(actual), &act) == nil {
+			result, err := matchJSONWithNoiseHandling("", exp, act, map[string][]string{}, true, nil)
+			return err == nil && result.matches
+		}
+	}
+	expLines, actLines := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	if len(expLines) != len(actLines) {
+		return false
+	}
+	for i := range expLines {
+		if strings.TrimSpace(expLines[i]) != strings.TrimSpace(actLines[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,19 +1,21 @@
 package replay
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 
 	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
 )
 
-type TestReportVerdict struct {
-	total  int
-	passed int
-	failed int
-	status bool
-}
+// genericReplayVerbatim serves the generic mocks in their recorded order to the requests matching none of them
+const genericReplayVerbatim = "verbatim"
 
 func LeftJoinNoise(globalNoise config.GlobalNoise, tsNoise config.GlobalNoise) config.GlobalNoise {
 	noise := globalNoise
@@ -27,20 +29,130 @@ func LeftJoinNoise(globalNoise config.GlobalNoise, tsNoise config.GlobalNoise) c
 }
 
 func replaceHostToIP(currentURL string, ipAddress string) (string, error) {
-	// Parse the current URL
-	parsedURL, err := url.Parse(currentURL)
+	if ipAddress == "" {
+		return currentURL, fmt.Errorf("failed to replace url in case of docker env")
+	}
+	// the hostname is replaced by the ip address, whatever the url
+	replaced, _, err := rewriteURL(currentURL, []config.URLRewrite{{To: config.URLParts{Host: ipAddress}}})
+	return replaced, err
+}
 
+// appPortProtocol returns the protocol declared for the port of the testcase url
+func appPortProtocol(conf *config.Config, currentURL string) (string, error) {
+	parsedURL, err := url.Parse(currentURL)
+	if err != nil {
+		return "", err
+	}
+	portStr := parsedURL.Port()
+	if portStr == "" {
+		if parsedURL.Scheme == "https" {
+			portStr = "443"
+		} else {
+			portStr = "80"
+		}
+	}
+	port, err := strconv.ParseUint(portStr, 10, 32)
 	if err != nil {
-		// Return the original URL if parsing fails
-		return currentURL, err
+		return "", err
 	}
+	return config.GetAppPortProtocol(conf, uint32(port)), nil
+}
 
-	if ipAddress == "" {
-		return currentURL, fmt.Errorf("failed to replace url in case of docker env")
+// orderTestSets moves the test sets listed in order to the front in the given sequence,
+// the unlisted ones follow in their original order. Listed sets which don't exist are returned as missing.
+func orderTestSets(testSetIDs []string, order []string) (ordered []string, missing []string) {
+	if len(order) == 0 {
+		return testSetIDs, nil
+	}
+	exists := make(map[string]bool, len(testSetIDs))
+	for _, id := range testSetIDs {
+		exists[id] = true
+	}
+	listed := make(map[string]bool, len(order))
+	for _, id := range order {
+		if listed[id] {
+			continue
+		}
+		listed[id] = true
+		if !exists[id] {
+			missing = append(missing, id)
+			continue
+		}
+		ordered = append(ordered, id)
+	}
+	for _, id := range testSetIDs {
+		if !listed[id] {
+			ordered = append(ordered, id)
+		}
 	}
+	return ordered, missing
+}
+
+// shuffleTestCases randomizes the order of the test cases, the same seed always gives the same order
+func shuffleTestCases(testCases []*models.TestCase, seed int64) {
+	rnd := rand.New(rand.NewSource(seed))
+	rnd.Shuffle(len(testCases), func(i, j int) {
+		testCases[i], testCases[j] = testCases[j], testCases[i]
+	})
+}
+
+// verifyMockOrder compares the order in which the mocks were consumed with the order in which the mocks of the
+// test case were recorded, by the time of their requests and then the sequence numbers in their names (e.g. mock-3).
+// The recorded mocks which weren't consumed are reported missing.
+func verifyMockOrder(recorded []*models.Mock, consumed []string) *models.MockOrderResult {
+	mocks := make([]*models.Mock, len(recorded))
+	copy(mocks, recorded)
+	sort.SliceStable(mocks, func(i, j int) bool {
+		ti, tj := mocks[i].Spec.ReqTimestampMock, mocks[j].Spec.ReqTimestampMock
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return mockSeq(mocks[i].Name) < mockSeq(mocks[j].Name)
+	})
+
+	made := make(map[string]bool, len(consumed))
+	for _, name := range consumed {
+		made[name] = true
+	}
+	result := &models.MockOrderResult{
+		Normal:   true,
+		Expected: namesOf(mocks),
+		Actual:   consumed,
+	}
+	var order []string
+	for _, name := range result.Expected {
+		if made[name] {
+			order = append(order, name)
+		} else {
+			result.Missing = append(result.Missing, name)
+		}
+	}
+	if len(result.Missing) != 0 || len(order) != len(consumed) {
+		result.Normal = false
+		return result
+	}
+	for i := range consumed {
+		if consumed[i] != order[i] {
+			result.Normal = false
+			break
+		}
+	}
+	return result
+}
+
+func mockSeq(name string) int {
+	seq, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	if err != nil {
+		return -1
+	}
+	return seq
+}
 
-	// Replace hostname with the IP address
-	parsedURL.Host = strings.Replace(parsedURL.Host, parsedURL.Hostname(), ipAddress, 1)
-	// Return the modified URL
-	return parsedURL.String(), nil
+// abortStatus is the status of a test set whose context is done, timed out when the test run exceeded the
+// runTimeout and aborted by the user otherwise
+func abortStatus(ctx context.Context) models.TestSetStatus {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return models.TestSetStatusTimedOut
+	}
+	return models.TestSetStatusUserAbort
 }
@@ -0,0 +1,242 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// watchDelay lets a burst of writes, such as a build or a save of several files, settle into one run
+const watchDelay = 500 * time.Millisecond
+
+// watch runs the selected test sets again on every change of the watched files until keploy is stopped.
+// The app is launched again by the test sets, so it picks up the change, after the build command if set.
+func (r *replayer) watch(ctx context.Context, appID uint64) error {
+	changes, err := r.watchFiles(ctx)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to watch the files for changes")
+		return err
+	}
+	for {
+		r.logger.Info("watching for changes to run the test sets again", zap.Strings("paths", r.watchPaths()))
+		select {
+		case <-ctx.Done():
+			return nil
+		case file := <-changes:
+			r.logger.Info("change detected, running the test sets again", zap.String("file", file))
+		}
+
+		if r.config.Test.BuildCommand != "" {
+			err := r.build(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				utils.LogError(r.logger, err, "failed to build the application, waiting for the next change", zap.String("command", r.config.Test.BuildCommand))
+				continue
+			}
+			// the files written by the build, such as the binary, aren't changes to run again for
+			skipChanges(changes, 2*watchDelay)
+		}
+
+		testRunIDs, err := r.reportDB.GetAllTestRunIDs(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			utils.LogError(r.logger, err, "failed to get all test run ids")
+			return err
+		}
+		err = r.runTestRun(ctx, pkg.NewID(testRunIDs, models.TestRunTemplateName), appID)
+		if err != nil && !isTestFailure(err) {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			// a failing run is reported and the next change gets another chance
+			utils.LogError(r.logger, err, "failed to run the test sets, waiting for the next change")
+		}
+	}
+}
+
+// skipChanges drops the changes sent until none is sent for the given time
+func skipChanges(changes <-chan string, quiet time.Duration) {
+	timer := time.NewTimer(quiet)
+	defer timer.Stop()
+	for {
+		select {
+		case <-changes:
+			timer.Reset(quiet)
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+func (r *replayer) build(ctx context.Context) error {
+	r.logger.Info("building the application", zap.String("command", r.config.Test.BuildCommand))
+	cmd := exec.CommandContext(ctx, "sh", "-c", r.config.Test.BuildCommand)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (r *replayer) watchPaths() []string {
+	if len(r.config.Test.WatchPaths) == 0 {
+		return []string{"."}
+	}
+	return r.config.Test.WatchPaths
+}
+
+// watchFiles sends the changed file once the writes settle. The changes made while the test sets run
+// are coalesced into the next run.
+func (r *replayer) watchFiles(ctx context.Context) (<-chan string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// a watched file is matched by its name as its directory is watched, editors often replacing the file on save
+	files := map[string]bool{}
+	for _, path := range r.watchPaths() {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			r.closeWatcher(watcher)
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			r.closeWatcher(watcher)
+			return nil, err
+		}
+		if !info.IsDir() {
+			files[abs] = true
+			err = watcher.Add(filepath.Dir(abs))
+		} else {
+			err = r.addWatchDirs(watcher, abs)
+		}
+		if err != nil {
+			r.closeWatcher(watcher)
+			return nil, err
+		}
+	}
+
+	changes := make(chan string, 1)
+	go func() {
+		defer utils.Recover(r.logger)
+		defer r.closeWatcher(watcher)
+		var settle <-chan time.Time
+		var changed string
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+					continue
+				}
+				name, err := filepath.Abs(event.Name)
+				if err != nil || r.excluded(name) {
+					continue
+				}
+				if len(files) > 0 && !files[name] && !r.underWatchedDir(name) {
+					continue
+				}
+				// the directories created under a watched one are watched too
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(name); err == nil && info.IsDir() {
+						err := r.addWatchDirs(watcher, name)
+						if err != nil {
+							utils.LogError(r.logger, err, "failed to watch the new directory", zap.String("dir", name))
+						}
+					}
+				}
+				changed = name
+				settle = time.After(watchDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				utils.LogError(r.logger, err, "failed to watch the files for changes")
+			case <-settle:
+				settle = nil
+				select {
+				case changes <- changed:
+				default:
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// addWatchDirs watches the directory and the ones under it which aren't excluded
+func (r *replayer) addWatchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && r.excluded(path) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// underWatchedDir tells if the file is in one of the watched directories rather than a watched file's
+func (r *replayer) underWatchedDir(name string) bool {
+	for _, path := range r.watchPaths() {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(abs)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if name == abs || strings.HasPrefix(name, abs+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded tells if the file isn't watched, the keploy directory where the reports are written and the
+// hidden files never being watched
+func (r *replayer) excluded(name string) bool {
+	if name == r.config.Path || strings.HasPrefix(name, r.config.Path+string(filepath.Separator)) {
+		return true
+	}
+	base := filepath.Base(name)
+	if strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~") {
+		return true
+	}
+	for _, pattern := range r.config.Test.WatchExclude {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *replayer) closeWatcher(watcher *fsnotify.Watcher) {
+	err := watcher.Close()
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to close the file watcher")
+	}
+}
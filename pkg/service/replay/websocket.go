@@ -0,0 +1,250 @@
+package replay
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// simulateWebSocket opens the websocket connection of the test case to the app and sends the recorded messages of
+// the client in their order, reading the messages the server sent before each of them as recorded. It returns the
+// messages of the server, which end early when the server closes the connection or doesn't send a recorded message
+// within the api timeout.
+func (r *replayer) simulateWebSocket(ctx context.Context, appID uint64, tc *models.TestCase, testSetID string) ([]models.WebSocketFrame, error) {
+	r.logger.Info("starting test for of", zap.Any("test case", models.HighlightString(tc.Name)), zap.Any("test set", models.HighlightString(testSetID)))
+	rawURL, err := r.appURL(ctx, appID, tc.HTTPReq.URL)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasPrefix(rawURL, "https://"):
+		rawURL = "wss://" + strings.TrimPrefix(rawURL, "https://")
+	case strings.HasPrefix(rawURL, "http://"):
+		rawURL = "ws://" + strings.TrimPrefix(rawURL, "http://")
+	}
+
+	// the dialer sends the headers of the handshake itself
+	header := pkg.ToHTTPHeader(tc.HTTPReq.Header)
+	deflate := false
+	for k, v := range header {
+		switch key := strings.ToLower(k); {
+		case key == "host", key == "connection", key == "upgrade":
+			delete(header, k)
+		case key == "sec-websocket-extensions":
+			deflate = strings.Contains(strings.ToLower(strings.Join(v, ",")), "permessage-deflate")
+			delete(header, k)
+		case strings.HasPrefix(key, "sec-websocket-") && key != "sec-websocket-protocol":
+			delete(header, k)
+		}
+	}
+	header.Set("KEPLOY-TEST-ID", tc.Name)
+
+	timeout := time.Duration(r.config.Test.APITimeout) * time.Second
+	dialer := websocket.Dialer{
+		HandshakeTimeout:  timeout,
+		EnableCompression: deflate,
+	}
+	r.logger.Debug("opening the websocket connection of the testcase", zap.String("url", rawURL))
+	conn, resp, err := dialer.DialContext(ctx, rawURL, header)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to open the websocket connection to the app", zap.String("testcase", tc.Name))
+		return nil, err
+	}
+	defer conn.Close()
+
+	var actual []models.WebSocketFrame
+	for _, frame := range tc.Frames {
+		if ctx.Err() != nil {
+			return actual, ctx.Err()
+		}
+		if frame.Origin == models.FromServer {
+			// the pings and the pongs of the server are answered and consumed by the connection
+			if frame.Type == models.WebSocketPing || frame.Type == models.WebSocketPong {
+				continue
+			}
+			received, ok := readWebSocket(conn, timeout)
+			if !ok {
+				r.logger.Debug("the app didn't send the recorded websocket message", zap.String("testcase", tc.Name), zap.String("type", frame.Type))
+				return actual, nil
+			}
+			actual = append(actual, received)
+			if received.Type == models.WebSocketClose {
+				return actual, nil
+			}
+			continue
+		}
+		if err := writeWebSocket(conn, frame, timeout); err != nil {
+			r.logger.Debug("failed to send the websocket message to the app", zap.String("testcase", tc.Name), zap.Error(err))
+			return actual, nil
+		}
+	}
+	return actual, nil
+}
+
+// writeWebSocket sends the recorded message of the client
+func writeWebSocket(conn *websocket.Conn, frame models.WebSocketFrame, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	switch frame.Type {
+	case models.WebSocketText:
+		return conn.WriteMessage(websocket.TextMessage, []byte(frame.Data))
+	case models.WebSocketBinary:
+		data, err := base64.StdEncoding.DecodeString(frame.Data)
+		if err != nil {
+			return fmt.Errorf("failed to decode the binary websocket message: %w", err)
+		}
+		return conn.WriteMessage(websocket.BinaryMessage, data)
+	case models.WebSocketPing:
+		return conn.WriteControl(websocket.PingMessage, []byte(frame.Data), deadline)
+	case models.WebSocketPong:
+		return conn.WriteControl(websocket.PongMessage, []byte(frame.Data), deadline)
+	case models.WebSocketClose:
+		if frame.Data == "" {
+			return conn.WriteControl(websocket.CloseMessage, nil, deadline)
+		}
+		codeText, reason, _ := strings.Cut(frame.Data, " ")
+		code, err := strconv.Atoi(codeText)
+		if err != nil {
+			return fmt.Errorf("invalid websocket close message %q: %w", frame.Data, err)
+		}
+		return conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+	}
+	return fmt.Errorf("unknown websocket message type %q", frame.Type)
+}
+
+// readWebSocket reads the next message of the server, the close message included. It reports false when the server
+// sent no message within the timeout or the connection broke.
+func readWebSocket(conn *websocket.Conn, timeout time.Duration) (models.WebSocketFrame, bool) {
+	frame := models.WebSocketFrame{Origin: models.FromServer}
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return frame, false
+	}
+	messageType, data, err := conn.ReadMessage()
+	frame.Timestamp = time.Now()
+	var closeErr *websocket.CloseError
+	switch {
+	case errors.As(err, &closeErr):
+		frame.Type = models.WebSocketClose
+		if closeErr.Code != websocket.CloseNoStatusReceived {
+			frame.Data = fmt.Sprintf("%d %s", closeErr.Code, closeErr.Text)
+		}
+	case err != nil:
+		return frame, false
+	case messageType == websocket.BinaryMessage:
+		frame.Type, frame.Data = models.WebSocketBinary, base64.StdEncoding.EncodeToString(data)
+	default:
+		frame.Type, frame.Data = models.WebSocketText, string(data)
+	}
+	return frame, true
+}
+
+func (r *replayer) compareWebSocket(tc *models.TestCase, actual []models.WebSocketFrame, testSetID string) (bool, *models.Result) {
+	r.reloadMu.RLock()
+	globalNoise := r.config.Test.GlobalNoise
+	unordered := r.config.Test.UnorderedFields
+	jsonCompare := r.config.Test.JSONCompare
+	r.reloadMu.RUnlock()
+
+	noiseConfig := globalNoise.Global
+	if tsNoise, ok := globalNoise.Testsets[testSetID]; ok {
+		noiseConfig = LeftJoinNoise(globalNoise.Global, tsNoise)
+	}
+	unorderedFields := append(append([]string{}, unordered.Global...), unordered.Testsets[testSetID]...)
+	return matchWebSocket(tc, actual, noiseConfig, r.config.Test.IgnoreOrdering, unorderedFields, jsonCompareOptions(jsonCompare, testSetID), r.logger)
+}
+
+// matchWebSocket compares the messages the server sent during the test with the recorded ones in their order, the
+// pings and the pongs left out. The test case passes when the server sent the same messages, the missing and the
+// unexpected ones failing it. The json text messages are compared like the json bodies of the http responses, with
+// the body noise of the testcase and the config, e.g. body.ts ignoring the ts field of every message.
+func matchWebSocket(tc *models.TestCase, actual []models.WebSocketFrame, noiseConfig map[string]map[string][]string, ignoreOrdering bool, unorderedFields []string, jsonOpts config.JSONCompareOptions, logger *zap.Logger) (bool, *models.Result) {
+	bodyNoise := map[string][]string{}
+	for field, regexArr := range noiseConfig["body"] {
+		bodyNoise[field] = regexArr
+	}
+	ignoreBody := false
+	for field, regexArr := range tc.Noise {
+		a := strings.Split(field, ".")
+		switch {
+		case field == "body":
+			ignoreBody = true
+		case len(a) > 1 && a[0] == "body":
+			bodyNoise[strings.Join(a[1:], ".")] = regexArr
+		}
+	}
+	unordered := unorderedPaths(unorderedFields)
+
+	var expected []models.WebSocketFrame
+	for _, frame := range tc.Frames {
+		if frame.Origin == models.FromServer && frame.Type != models.WebSocketPing && frame.Type != models.WebSocketPong {
+			expected = append(expected, frame)
+		}
+	}
+	result := &models.WebSocketResult{Normal: true}
+	for i := 0; i < max(len(expected), len(actual)); i++ {
+		var frameResult models.WebSocketFrameResult
+		switch {
+		case i >= len(actual):
+			frameResult = models.WebSocketFrameResult{Type: expected[i].Type, Expected: expected[i].Data}
+		case i >= len(expected):
+			frameResult = models.WebSocketFrameResult{Type: actual[i].Type, Actual: actual[i].Data}
+		default:
+			frameResult = models.WebSocketFrameResult{
+				Normal:   expected[i].Type == actual[i].Type && sameWebSocketData(expected[i], actual[i], bodyNoise, ignoreBody, ignoreOrdering, unordered, jsonOpts, logger),
+				Type:     expected[i].Type,
+				Expected: expected[i].Data,
+				Actual:   actual[i].Data,
+			}
+			if expected[i].Type != actual[i].Type {
+				frameResult.Type = expected[i].Type + "/" + actual[i].Type
+			}
+		}
+		if !frameResult.Normal {
+			result.Normal = false
+		}
+		result.Frames = append(result.Frames, frameResult)
+	}
+	if !result.Normal {
+		logger.Info("the websocket messages of the app differ from the recorded ones", zap.String("testcase", tc.Name),
+			zap.Int("expected messages", len(expected)), zap.Int("actual messages", len(actual)))
+	}
+	return result.Normal, &models.Result{WebSocket: result}
+}
+
+// sameWebSocketData reports whether the server sent the recorded message. The text messages are compared as json
+// with the noise when both are json, the others exactly.
+func sameWebSocketData(expected, actual models.WebSocketFrame, bodyNoise map[string][]string, ignoreBody, ignoreOrdering bool, unordered map[string]bool, jsonOpts config.JSONCompareOptions, logger *zap.Logger) bool {
+	if expected.Type != models.WebSocketText {
+		return expected.Data == actual.Data
+	}
+	if ignoreBody {
+		return true
+	}
+	if !json.Valid([]byte(expected.Data)) || !json.Valid([]byte(actual.Data)) {
+		return expected.Data == actual.Data
+	}
+	exp, act := canonicalJSON(expected.Data, jsonOpts), canonicalJSON(actual.Data, jsonOpts)
+	validatedJSON, err := ValidateAndMarshalJSON(logger, &exp, &act)
+	if err != nil || !validatedJSON.isIdentical {
+		return false
+	}
+	result, err := JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering, unordered)
+	return err == nil && result.isExact
+}
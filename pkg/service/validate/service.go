@@ -0,0 +1,16 @@
+// Package validate checks the recorded test cases, mocks and reports against the schemas of their version.
+package validate
+
+import "context"
+
+type Service interface {
+	Validate(ctx context.Context, path string) ([]Issue, error)
+}
+
+// Issue is a corruption found in a yaml document of the keploy directory
+type Issue struct {
+	File    string `json:"file"`
+	Doc     int    `json:"doc"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
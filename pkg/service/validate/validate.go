@@ -0,0 +1,246 @@
+package validate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
+	"go.keploy.io/server/v2/pkg/platform/yaml/testdb"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// versions are the versions of the yaml documents which keploy can read
+var versions = map[models.Version]bool{
+	models.V1Beta1:                          true,
+	models.Version("api.keploy.io/v1beta2"): true,
+}
+
+var lineRegex = regexp.MustCompile(`line (\d+):`)
+
+type validator struct {
+	logger *zap.Logger
+}
+
+func New(logger *zap.Logger) Service {
+	return &validator{
+		logger: logger,
+	}
+}
+
+func (v *validator) Validate(ctx context.Context, path string) ([]Issue, error) {
+	if _, err := os.Stat(path); err != nil {
+		utils.LogError(v.logger, err, "failed to find the keploy directory", zap.String("path", path))
+		return nil, err
+	}
+	issues := []Issue{}
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// the hidden directories like the backup of the migrated v1 recordings aren't read by keploy
+		if entry.IsDir() && file != path && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || filepath.Ext(file) != ".yaml" {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		var fileIssues []Issue
+		switch {
+		case len(parts) == 3 && parts[0] == "reports" && parts[2] == "matching.yaml":
+			// the trace of the calls which matched no mock isn't a report
+			return nil
+		case len(parts) == 3 && parts[0] == "reports":
+			fileIssues = v.validateReport(file)
+		case len(parts) == 3 && parts[1] == "tests":
+			fileIssues = v.validateDocs(file, true)
+		case len(parts) == 2 && parts[1] == "mocks.yaml":
+			fileIssues = v.validateDocs(file, false)
+		default:
+			return nil
+		}
+		issues = append(issues, fileIssues...)
+		return nil
+	})
+	if err != nil {
+		utils.LogError(v.logger, err, "failed to walk the keploy directory", zap.String("path", path))
+		return nil, err
+	}
+	return issues, nil
+}
+
+// Mocks checks the mock documents of the file, like the mocks of a test set before it's run
+func Mocks(file string) []Issue {
+	return (&validator{}).validateDocs(file, false)
+}
+
+// validateDocs checks the test case or mock documents of the file
+func (v *validator) validateDocs(file string, isTestCase bool) []Issue {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return []Issue{{File: file, Message: err.Error()}}
+	}
+	var issues []Issue
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	for i := 1; ; i++ {
+		var doc yaml.NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// the rest of the file can't be read once a document is malformed
+			issues = append(issues, Issue{File: file, Doc: i, Message: err.Error()})
+			break
+		}
+		issue := Issue{File: file, Doc: i, Name: doc.Name}
+		if msg := checkDoc(&doc, isTestCase); msg != "" {
+			issue.Message = msg
+			issues = append(issues, issue)
+		}
+	}
+	if isTestCase && len(issues) == 0 && len(bytes.TrimSpace(data)) == 0 {
+		issues = append(issues, Issue{File: file, Message: "empty test case file"})
+	}
+	return issues
+}
+
+// checkDoc returns the corruption of the document, if any
+func checkDoc(doc *yaml.NetworkTrafficDoc, isTestCase bool) string {
+	switch {
+	case !versions[doc.Version]:
+		return fmt.Sprintf("field version: unsupported version %q", doc.Version)
+	case doc.Name == "":
+		return "field name: missing"
+	case doc.Spec.Kind == 0:
+		return "field spec: missing"
+	}
+
+	var spec interface{}
+	switch doc.Kind {
+	case models.HTTP:
+		spec = &models.HTTPSchema{}
+	case models.GRPC_EXPORT:
+		spec = &models.GrpcSpec{}
+	case models.Mongo:
+		spec = &models.MongoSpec{}
+	case models.GENERIC, models.UDP:
+		spec = &models.GenericSchema{}
+	case models.Postgres:
+		spec = &models.PostgresSpec{}
+	case models.REDIS:
+		spec = &models.RedisSchema{}
+	case models.KAFKA:
+		spec = &models.KafkaSchema{}
+	case models.SQL:
+		spec = &models.MySQLSpec{}
+	case models.MESSAGE:
+		spec = &models.MessageSchema{}
+	case models.JOB:
+		spec = &models.JobSchema{}
+	case models.WEBSOCKET:
+		spec = &models.WebSocketSchema{}
+	}
+	if spec == nil || (isTestCase && !isTestCaseKind(doc.Kind)) {
+		return fmt.Sprintf("field kind: unsupported kind %q", doc.Kind)
+	}
+	if err := decodeStrict(&doc.Spec, spec); err != nil {
+		return "field spec: " + err.Error()
+	}
+
+	// the nested messages are only checked by decoding the document like the replay does
+	var err error
+	if isTestCase {
+		_, err = testdb.Decode(doc, zap.NewNop())
+	} else {
+		_, err = mockdb.Decode(doc, zap.NewNop())
+	}
+	if err != nil {
+		return "field spec: " + err.Error()
+	}
+	return ""
+}
+
+// isTestCaseKind tells if the documents of the kind can be test cases
+func isTestCaseKind(kind models.Kind) bool {
+	switch kind {
+	case models.HTTP, models.GRPC_EXPORT, models.MESSAGE, models.JOB, models.WEBSOCKET:
+		return true
+	}
+	return false
+}
+
+// validateReport checks the test report of the file
+func (v *validator) validateReport(file string) []Issue {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return []Issue{{File: file, Message: err.Error()}}
+	}
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var version models.Version
+	var name string
+	if filepath.Base(file) == "run-report.yaml" {
+		var report models.TestRunReport
+		err = dec.Decode(&report)
+		version, name = report.Version, report.Name
+	} else {
+		var report models.TestReport
+		err = dec.Decode(&report)
+		version, name = report.Version, report.Name
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return []Issue{{File: file, Doc: 1, Message: err.Error()}}
+	}
+	if !versions[version] {
+		return []Issue{{File: file, Doc: 1, Name: name, Message: fmt.Sprintf("field version: unsupported version %q", version)}}
+	}
+	return nil
+}
+
+// decodeStrict decodes the node failing on the unknown fields, the lines of the errors are those of the file
+func decodeStrict(node *yamlLib.Node, out interface{}) error {
+	data, err := yamlLib.Marshal(node)
+	if err != nil {
+		return err
+	}
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(out)
+	if err == nil {
+		return nil
+	}
+	// the spec is re-encoded from its first line
+	offset := node.Line - 1
+	if len(node.Content) > 0 {
+		offset = node.Content[0].Line - 1
+	}
+	return errors.New(lineRegex.ReplaceAllStringFunc(err.Error(), func(match string) string {
+		n, convErr := strconv.Atoi(lineRegex.FindStringSubmatch(match)[1])
+		if convErr != nil {
+			return match
+		}
+		return "line " + strconv.Itoa(n+offset) + ":"
+	}))
+}
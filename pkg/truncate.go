@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"unicode/utf8"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// BodyHash returns the hash of the full content of a body, by which a truncated body is compared
+func BodyHash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// TruncateBody stores the first max bytes of a response body above the cap, marking it with the hash and the
+// size of its full content. It reports whether the body was truncated, a cap of zero keeping the bodies whole.
+func TruncateBody(resp *models.HTTPResp, max int) bool {
+	if max <= 0 || len(resp.Body) <= max || resp.BodyHash != "" {
+		return false
+	}
+	resp.BodyHash = BodyHash(resp.Body)
+	resp.BodySize = len(resp.Body)
+	resp.Body = BodyPrefix(resp.Body, max)
+	return true
+}
+
+// BodyPrefix returns at most the first n bytes of the body without splitting a multi-byte character
+func BodyPrefix(body string, n int) string {
+	if len(body) <= n {
+		return body
+	}
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n--
+	}
+	return body[:n]
+}
@@ -187,7 +187,7 @@ func ReadSessionIndices(path string, Logger *zap.Logger) ([]string, error) {
 	}
 
 	for _, v := range files {
-		if v.Name() != "reports" {
+		if v.Name() != "reports" && v.Name() != models.GlobalMocksDir {
 			indices = append(indices, v.Name())
 		}
 	}
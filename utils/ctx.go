@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -30,6 +32,10 @@ func NewCtx() context.Context {
 		<-sigs
 		fmt.Println("Signal received, canceling context...")
 		cancel()
+		// the in-flight captures are drained after the cancellation, a second signal skips the drain
+		<-sigs
+		fmt.Println("Signal received again, exiting without draining...")
+		os.Exit(1)
 	}()
 
 	return ctx
@@ -63,3 +69,60 @@ func Stop(logger *zap.Logger, reason string) error {
 func SetCancel(c context.CancelFunc) {
 	cancel = c
 }
+
+var (
+	drainMu sync.Mutex
+	drainID int
+	drains  = map[int]func(ctx context.Context){}
+)
+
+// RegisterDrain adds a function which completes the in-flight work of a component, such as the
+// half-captured calls, once keploy is stopped. The returned function removes it again.
+func RegisterDrain(fn func(ctx context.Context)) func() {
+	drainMu.Lock()
+	defer drainMu.Unlock()
+	drainID++
+	id := drainID
+	drains[id] = fn
+	return func() {
+		drainMu.Lock()
+		defer drainMu.Unlock()
+		delete(drains, id)
+	}
+}
+
+// Drain runs the registered drain functions and waits for them for at most the timeout.
+// It is called after Stop, before the hooks and the proxy are torn down.
+func Drain(logger *zap.Logger, timeout time.Duration) {
+	drainMu.Lock()
+	fns := make([]func(ctx context.Context), 0, len(drains))
+	for _, fn := range drains {
+		fns = append(fns, fn)
+	}
+	drainMu.Unlock()
+	if len(fns) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	logger.Info("draining the in-flight captures, press Ctrl+C again to skip", zap.Duration("timeout", timeout))
+	var wg sync.WaitGroup
+	for _, fn := range fns {
+		wg.Add(1)
+		go func(fn func(ctx context.Context)) {
+			defer wg.Done()
+			defer Recover(logger)
+			fn(ctx)
+		}(fn)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		logger.Warn("timed out draining the in-flight captures, the rest are dropped", zap.Duration("timeout", timeout))
+	}
+}
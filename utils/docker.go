@@ -71,8 +71,14 @@ func StartInDocker(ctx context.Context, logger *zap.Logger, conf *config.Config)
 	if conf.InDocker || !(cmdType == Docker || cmdType == DockerCompose) {
 		return nil
 	}
+	containerRuntime := FindContainerRuntime(conf.Command)
+	if containerRuntime == RuntimeNerdctl {
+		// containerd has no docker api for the keploy container, keploy runs on the host and reaches the app over
+		// the bridge of its network
+		return nil
+	}
 	// pass the all the commands and args to the docker version of Keploy
-	err := RunInDocker(ctx, logger)
+	err := RunInDocker(ctx, logger, containerRuntime)
 	if err != nil {
 		LogError(logger, err, "failed to run the command in docker")
 		return err
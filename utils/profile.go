@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	runtimePprof "runtime/pprof"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ProfileDir is the directory the profiles are written to on exit
+const ProfileDir = "keploy-profile"
+
+var profiler struct {
+	mu      sync.Mutex
+	server  *http.Server
+	cpuFile *os.File
+}
+
+// StartProfiling serves the net/http/pprof handlers on the address and records a cpu profile until StopProfiling.
+// The goroutines of the proxy and of the record and replay services carry a "keploy" label to tell them apart.
+func StartProfiling(logger *zap.Logger, addr string) error {
+	profiler.mu.Lock()
+	defer profiler.mu.Unlock()
+	if profiler.server != nil {
+		return nil
+	}
+
+	err := os.MkdirAll(ProfileDir, 0o777)
+	if err != nil {
+		LogError(logger, err, "failed to create the profile directory", zap.String("dir", ProfileDir))
+		return err
+	}
+	cpuFile, err := os.Create(filepath.Join(ProfileDir, "cpu.pprof"))
+	if err != nil {
+		LogError(logger, err, "failed to create the cpu profile")
+		return err
+	}
+	err = runtimePprof.StartCPUProfile(cpuFile)
+	if err != nil {
+		LogError(logger, err, "failed to start the cpu profile")
+		if err := cpuFile.Close(); err != nil {
+			LogError(logger, err, "failed to close the cpu profile")
+		}
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		runtimePprof.StopCPUProfile()
+		if err := cpuFile.Close(); err != nil {
+			LogError(logger, err, "failed to close the cpu profile")
+		}
+		LogError(logger, err, "failed to listen for the profiling server", zap.String("addr", addr))
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		defer Recover(logger)
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			LogError(logger, err, "profiling server stopped")
+		}
+	}()
+
+	profiler.server = server
+	profiler.cpuFile = cpuFile
+	logger.Info("profiling enabled", zap.String("pprof", "http://"+listener.Addr().String()+"/debug/pprof/"), zap.String("dir", ProfileDir))
+	return nil
+}
+
+// StopProfiling stops the profiling server and writes the cpu, heap and goroutine profiles
+func StopProfiling(logger *zap.Logger) {
+	profiler.mu.Lock()
+	defer profiler.mu.Unlock()
+	if profiler.server == nil {
+		return
+	}
+
+	runtimePprof.StopCPUProfile()
+	if err := profiler.cpuFile.Close(); err != nil {
+		LogError(logger, err, "failed to close the cpu profile")
+	}
+	if err := profiler.server.Close(); err != nil {
+		LogError(logger, err, "failed to stop the profiling server")
+	}
+	profiler.server = nil
+
+	for _, name := range []string{"heap", "goroutine"} {
+		writeProfile(logger, name)
+	}
+	logger.Info("profiles written", zap.String("dir", ProfileDir))
+}
+
+func writeProfile(logger *zap.Logger, name string) {
+	file, err := os.Create(filepath.Join(ProfileDir, name+".pprof"))
+	if err != nil {
+		LogError(logger, err, "failed to create the profile", zap.String("profile", name))
+		return
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			LogError(logger, err, "failed to close the profile", zap.String("profile", name))
+		}
+	}()
+	err = runtimePprof.Lookup(name).WriteTo(file, 0)
+	if err != nil {
+		LogError(logger, err, "failed to write the profile", zap.String("profile", name))
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"runtime/debug"
 	"strconv"
@@ -132,6 +133,20 @@ type GitHubRelease struct {
 
 var ErrGitHubAPIUnresponsive = errors.New("GitHub API is unresponsive")
 
+// ExitError is the error of a command which exits keploy with its code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 var Emoji = "\U0001F430" + " Keploy:"
 var ConfigGuide = `
 # Example on using tests
@@ -308,9 +323,10 @@ func FindDockerCmd(cmd string) CmdType {
 	// Convert command to lowercase for case-insensitive comparison
 	cmdLower := strings.TrimSpace(strings.ToLower(cmd))
 
-	// Define patterns for Docker and Docker Compose
-	dockerPatterns := []string{"docker", "sudo docker"}
-	dockerComposePatterns := []string{"docker-compose", "sudo docker-compose", "docker compose", "sudo docker compose"}
+	// Define patterns for Docker and Docker Compose, podman and nerdctl (containerd) take the same arguments
+	dockerPatterns := []string{"docker", "sudo docker", "podman", "sudo podman", "nerdctl", "sudo nerdctl"}
+	dockerComposePatterns := []string{"docker-compose", "sudo docker-compose", "docker compose", "sudo docker compose",
+		"podman-compose", "sudo podman-compose", "podman compose", "sudo podman compose", "nerdctl compose", "sudo nerdctl compose"}
 
 	// Check for Docker Compose command patterns and file extensions
 	for _, pattern := range dockerComposePatterns {
@@ -327,6 +343,68 @@ func FindDockerCmd(cmd string) CmdType {
 	return Native
 }
 
+// imageRef matches an image reference, optionally pinned by its digest e.g. ghcr.io/org/app:1.2@sha256:<hex>
+var imageRef = regexp.MustCompile(`^[a-z0-9]+([._/:-][a-zA-Z0-9]+)*(@sha256:[0-9a-f]{64})?$`)
+
+// ImageRunCmd returns the docker command running the app from its image in the container and network keploy
+// attaches to. The image is pulled on every run unless it's pinned by digest, which also tells whether it is.
+func ImageRunCmd(image, container, network string) (string, bool, error) {
+	if !imageRef.MatchString(image) {
+		return "", false, fmt.Errorf("invalid image reference %q", image)
+	}
+	pinned := strings.Contains(image, "@sha256:")
+	pull := "always"
+	if pinned {
+		pull = "missing"
+	}
+	return fmt.Sprintf("docker run --rm --pull %s --name %s --network %s %s", pull, container, network, image), pinned, nil
+}
+
+// ContainerRuntime is the engine running the containers of the app
+type ContainerRuntime string
+
+// ContainerRuntime constants
+const (
+	RuntimeDocker  ContainerRuntime = "docker"
+	RuntimePodman  ContainerRuntime = "podman"
+	RuntimeNerdctl ContainerRuntime = "nerdctl"
+)
+
+// FindContainerRuntime returns the runtime of the docker like command, docker for any other command
+func FindContainerRuntime(cmd string) ContainerRuntime {
+	fields := strings.Fields(strings.ToLower(cmd))
+	if len(fields) > 1 && fields[0] == "sudo" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return RuntimeDocker
+	}
+	switch fields[0] {
+	case "podman", "podman-compose":
+		return RuntimePodman
+	case "nerdctl":
+		return RuntimeNerdctl
+	}
+	return RuntimeDocker
+}
+
+// DockerCompatibleCmd rewrites a podman command to run with the docker cli, which talks to the docker compatible
+// api of podman through its socket mounted in the keploy container
+func DockerCompatibleCmd(cmd string) string {
+	if FindContainerRuntime(cmd) != RuntimePodman {
+		return cmd
+	}
+	for _, r := range []struct{ podman, docker string }{
+		{"podman-compose", "docker-compose"},
+		{"podman", "docker"},
+	} {
+		if i := strings.Index(cmd, r.podman); i >= 0 {
+			return cmd[:i] + r.docker + cmd[i+len(r.podman):]
+		}
+	}
+	return cmd
+}
+
 type CmdType string
 
 // CmdType constants
@@ -367,9 +445,24 @@ type TestFlags struct {
 	WithCoverage       bool
 }
 
-func getAlias(ctx context.Context, logger *zap.Logger) (string, error) {
+func getAlias(ctx context.Context, logger *zap.Logger, containerRuntime ContainerRuntime) (string, error) {
 	// Get the name of the operating system.
 	osName := runtime.GOOS
+	if containerRuntime == RuntimePodman {
+		if osName != "linux" {
+			LogError(logger, nil, "podman is only supported on linux")
+			return "", errors.New("failed to get alias")
+		}
+		// the rootful podman serves the docker api on its socket, which the keploy container uses as the docker socket
+		img := "ghcr.io/keploy/keploy:" + "v" + Version
+		logger.Info("Starting keploy in podman with image", zap.String("image:", img))
+		ttyFlag := ""
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			ttyFlag = " -it "
+		}
+		alias := "sudo podman container run --name keploy-v2 -e BINARY_TO_DOCKER=true -p 16789:16789 --privileged --pid=host" + ttyFlag + " -v " + os.Getenv("PWD") + ":" + os.Getenv("PWD") + " -w " + os.Getenv("PWD") + " -v /sys/fs/cgroup:/sys/fs/cgroup -v /sys/kernel/debug:/sys/kernel/debug -v /sys/fs/bpf:/sys/fs/bpf -v /run/podman/podman.sock:/var/run/docker.sock -v " + os.Getenv("HOME") + "/.keploy-config:/root/.keploy-config -v " + os.Getenv("HOME") + "/.keploy:/root/.keploy --rm " + img
+		return alias, nil
+	}
 	//TODO: configure the hardcoded port mapping
 	img := "ghcr.io/keploy/keploy:" + "v" + Version
 	logger.Info("Starting keploy in docker with image", zap.String("image:", img))
@@ -415,9 +508,9 @@ func getAlias(ctx context.Context, logger *zap.Logger) (string, error) {
 	return "", errors.New("failed to get alias")
 }
 
-func RunInDocker(ctx context.Context, logger *zap.Logger) error {
+func RunInDocker(ctx context.Context, logger *zap.Logger, containerRuntime ContainerRuntime) error {
 	//Get the correct keploy alias.
-	keployAlias, err := getAlias(ctx, logger)
+	keployAlias, err := getAlias(ctx, logger, containerRuntime)
 	if err != nil {
 		return err
 	}